@@ -2,26 +2,57 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
+	"unicorn-auth/internal/alerts"
+	"unicorn-auth/internal/analytics"
+	"unicorn-auth/internal/apikeys"
 	"unicorn-auth/internal/cleanup"
 	"unicorn-auth/internal/config"
 	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/health"
 	"unicorn-auth/internal/http/router"
+	"unicorn-auth/internal/jobs"
+	"unicorn-auth/internal/lifecycle"
+	"unicorn-auth/internal/logging"
+	"unicorn-auth/internal/mail"
+	"unicorn-auth/internal/models"
 	adminmod "unicorn-auth/internal/modules/admin"
+	analyticsmod "unicorn-auth/internal/modules/analytics"
+	apikeymod "unicorn-auth/internal/modules/apikeys"
 	appmod "unicorn-auth/internal/modules/applications"
 	chatmod "unicorn-auth/internal/modules/chat"
 	companymod "unicorn-auth/internal/modules/company"
+	notifmod "unicorn-auth/internal/modules/notifications"
+	orgmod "unicorn-auth/internal/modules/org"
 	profilemod "unicorn-auth/internal/modules/profile"
 	resumemod "unicorn-auth/internal/modules/resumes"
+	savedsearchmod "unicorn-auth/internal/modules/savedsearch"
+	skillmod "unicorn-auth/internal/modules/skills"
 	submod "unicorn-auth/internal/modules/subscription"
+	syndicationmod "unicorn-auth/internal/modules/syndication"
+	tgmod "unicorn-auth/internal/modules/telegram"
 	vacmod "unicorn-auth/internal/modules/vacancies"
+	webhookmod "unicorn-auth/internal/modules/webhooks"
+	"unicorn-auth/internal/notify"
+	"unicorn-auth/internal/payments"
+	"unicorn-auth/internal/platformstats"
 	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/scheduler"
 	"unicorn-auth/internal/security"
+	"unicorn-auth/internal/taxonomy"
+	"unicorn-auth/internal/telegram"
+	"unicorn-auth/internal/telemetry"
+	"unicorn-auth/internal/vacimport"
+	"unicorn-auth/internal/verification"
+	"unicorn-auth/internal/webhooks"
 
 	"github.com/joho/godotenv"
 )
@@ -31,57 +62,198 @@ func main() {
 
 	cfg := config.MustLoad()
 
+	// Логи в stdout: JSON в prod, текст в dev; секреты вычищаются
+	logger := logging.New(cfg.AppEnv, os.Stdout)
+	logging.SetDefault(logger)
+
 	sec, err := security.NewSecurity(cfg.JWTHS256Secret, cfg.TotpEncKeyB64)
 	if err != nil {
-		log.Fatal(err)
+		fatal("security", err)
+	}
+
+	// SIGTERM/SIGINT запускают остановку: новые запросы не принимаются,
+	// текущие дорабатывают, фоновые задачи завершаются
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Трейсы: заголовки пробрасываются всегда, экспорт — только с OTLP endpoint
+	shutdownTracing, err := telemetry.InitTracing(ctx, cfg.TracingEnabled)
+	if err != nil {
+		fatal("tracing", err)
 	}
 
-	ctx := context.Background()
-	d := db.Connect(ctx, cfg.MongoURI, cfg.MongoDB)
-	defer func() { _ = d.Close(context.Background()) }()
-	d.EnsureIndexes(ctx)
+	d := db.Connect(ctx, cfg.MongoURI, cfg.MongoDB, telemetry.MongoMonitor(cfg.MongoSlow, logger))
+
+	// Периодические задачи по cron; расписание и история запусков — в Mongo,
+	// каждый запуск выполняет один инстанс из всех реплик
+	sched := scheduler.New(repo.NewSchedulerRepo(d), logger)
+
+	// Фоновые задачи под присмотром: перезапуск при падении, статус последнего прохода
+	sup := jobs.New(logger)
+	telemetry.ObserveJobs(sup.Observe)
+	probe := health.NewProbe(d, sup)
 
 	users := repo.NewUserRepo(d)
 	sessions := repo.NewSessionRepo(d)
 	profiles := repo.NewProfileRepo(d)
 	vac := repo.NewVacancyRepo(d)
 	resumes := repo.NewResumeRepo(d)
+	snapshots := repo.NewResumeSnapshotRepo(d)
 	apps := repo.NewApplicationRepo(d)
 	chatRepo := repo.NewChatRepo(d)
 	admins := repo.NewAdminRepo(d)
 	subs := repo.NewSubscriptionRepo(d)
+	promos := repo.NewPromoRepo(d)
+	orgs := repo.NewOrgRepo(d)
+	verifs := repo.NewVerificationRepo(d)
+	reviews := repo.NewReviewRepo(d)
+	searches := repo.NewSavedSearchRepo(d)
+	notifications := repo.NewNotificationRepo(d)
+	tgLinks := repo.NewTelegramRepo(d)
+	webhookRepo := repo.NewWebhookRepo(d)
+	apiKeys := repo.NewAPIKeyRepo(d)
+	feeds := repo.NewVacancyFeedRepo(d)
+	skills := repo.NewSkillRepo(d)
+	stats := repo.NewAnalyticsRepo(d)
+	platformStats := repo.NewPlatformStatsRepo(d)
+
+	// Ключи API принимаются в RequireAuth наравне с JWT
+	sec.APIKeys = apikeys.NewVerifier(apiKeys, users)
+
+	tax := taxonomy.NewService(skills, logger)
+	views := analytics.NewService(stats, logger)
+	platform := platformstats.NewService(platformStats, subs, logger)
 
-	bootstrapAdmin(ctx, admins)
+	// Индексы и миграции выполняет один инстанс под арендой "migrations";
+	// остальные ждут, пока он закончит, и повторяют их уже без изменений
+	err = sched.Exclusive(ctx, "migrations", func(ctx context.Context) error {
+		d.EnsureIndexes(ctx)
+		// Справочник навыков: при первом запуске заполняется и приводит к себе старые теги
+		if err := tax.Migrate(ctx, d); err != nil {
+			return fmt.Errorf("skills taxonomy: %w", err)
+		}
+		// Аналитика откликов: при первом запуске строится статистика за всю историю
+		if err := views.Migrate(ctx, d); err != nil {
+			return fmt.Errorf("analytics: %w", err)
+		}
+		if err := platform.Migrate(ctx, d); err != nil {
+			return fmt.Errorf("platform stats: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		fatal("migrate", err)
+	}
 
-	robokassa := security.NewRobokassa(
-		cfg.RobokassaMerchantLogin,
-		cfg.RobokassaPassword1,
-		cfg.RobokassaPassword2,
-		cfg.RobokassaTestMode,
-	)
+	bootstrapAdmin(ctx, admins, logger)
 
-	r := router.New(cfg, sec, users, sessions, resumes, vac)
+	pay, paymentsEnabled := newPayments(cfg, logger)
+	mailer := newMailer(cfg, logger)
+
+	// Уведомления: в кабинет и на почту; события доставляются в фоне
+	inApp := notify.NewInApp(notifications)
+	emailCh := notify.NewEmail(mailer, cfg.FrontendURL)
+	events := notify.NewEmitter(notifications, orgs, logger, inApp, emailCh)
+
+	// Вебхуки компаний: события пишутся в очередь в Mongo и отправляются в фоне
+	hooks := webhooks.NewDispatcher(webhookRepo, nil, logger)
+
+	// Telegram-бот подключается третьим каналом, если задан токен
+	var bot *tgmod.Bot
+	if cfg.TelegramBotToken != "" {
+		client := telegram.NewHTTPClient(cfg.TelegramAPIURL, cfg.TelegramBotToken)
+		bot = tgmod.NewBot(client, cfg.TelegramBotUsername, tgLinks, users, orgs, apps, vac, chatRepo, events, hooks, logger)
+		bot.StoreOffsets(tgLinks)
+		events.AddChannel(bot.Channel())
+	}
+
+	// Импорт вакансий из XML-фидов компаний
+	feedPoller := vacimport.NewPoller(feeds, vacimport.NewImporter(users, profiles, vac, tax), nil, logger)
+
+	r := router.New(cfg, logger, probe, sec, users, sessions, resumes, vac)
 
 	// Register modules
 	profilemod.Register(r, sec, users, profiles)
-	companymod.Register(r, profiles)
-	vacmod.Register(r, sec, users, vac)
-	resumemod.Register(r, sec, users, resumes, apps)
-	appmod.Register(r, sec, users, vac, resumes, apps)
-	chatmod.Register(r, sec, users, apps, chatRepo, vac, profiles)
-	adminmod.Register(r, sec, admins, users)
+	companymod.Register(r, sec, users, orgs, profiles, vac, apps, reviews, verifs, verification.NewChecker(nil, nil))
+	life := lifecycle.NewService(vac, apps, events, hooks, logger)
+	vacmod.Register(r, sec, users, orgs, profiles, vac, life, feeds, feedPoller, tax, views)
+	resumemod.Register(r, sec, users, orgs, profiles, resumes, snapshots, apps, vac, tax)
+	appmod.Register(r, sec, users, orgs, vac, resumes, snapshots, apps, events, hooks)
+	chatmod.Register(r, sec, users, orgs, apps, chatRepo, vac, profiles, events, hooks)
+	// Доставка вебхуков из очереди
+	sched.Register("webhooks", "@every 10s", hooks.RunOnce)
+	// Публикация запланированных и закрытие просроченных вакансий
+	sched.Register("lifecycle", "* * * * *", life.RunOnce)
+	// Очистка старых скрытых записей
+	sched.Register("cleanup", "30 3 * * *", cleanup.NewCleaner(apps, logger).RunOnce)
+	// Резервы промокодов, не оплаченные за час
+	sched.Register("promos", "*/10 * * * *", cleanup.NewPromoReleaser(promos, logger).RunOnce)
+	// Рассылка новых вакансий по сохраненным поискам
+	sched.Register("alerts", "* * * * *", alerts.NewWorker(searches, vac, users, logger, inApp, emailCh).RunOnce)
+	sched.Register("vacimport", "*/5 * * * *", feedPoller.RunOnce)
+	// Популярность навыков для автодополнения
+	sched.Register("taxonomy", "0 * * * *", tax.RunOnce)
+	// Дневная статистика воронки найма
+	sched.Register("analytics", "*/15 * * * *", views.RunOnce)
+	// Сводка платформы для админки
+	sched.Register("platformstats", "*/30 * * * *", platform.RunOnce)
+	if err := sched.Sync(ctx); err != nil {
+		fatal("sync scheduled jobs", err)
+	}
+
+	adminmod.Register(r, sec, admins, users, profiles, vac, verifs, skills, tax, platform, sup, sched)
+	orgmod.Register(r, orgmod.Config{FrontendURL: cfg.FrontendURL}, sec, users, orgs, profiles, vac, apps, mailer)
+	savedsearchmod.Register(r, sec, users, searches, vac, tax)
+	notifmod.Register(r, sec, users, notifications)
+	tgmod.Register(r, sec, users, tgLinks, bot)
+	webhookmod.Register(r, sec, users, orgs, webhookRepo, hooks)
+	apikeymod.Register(r, sec, users, orgs, apiKeys)
+	syndicationmod.Register(r, syndicationmod.Config{PublicURL: cfg.PublicURL, FrontendURL: cfg.FrontendURL}, vac, profiles)
+	skillmod.Register(r, tax)
+	analyticsmod.Register(r, sec, users, orgs, vac, stats)
 
 	// Subscription module
 	subCfg := submod.Config{
-		Price:            cfg.SubscriptionPrice,
-		DurationDays:     cfg.SubscriptionDuration,
-		RobokassaEnabled: cfg.RobokassaMerchantLogin != "" && cfg.RobokassaPassword1 != "",
+		Price:           cfg.SubscriptionPrice,
+		DurationDays:    cfg.SubscriptionDuration,
+		PaymentsEnabled: paymentsEnabled,
+		Plan:            cfg.SubscriptionPlan,
+		TrialDays:       cfg.SubscriptionTrialDays,
+		Seller: models.Requisites{
+			LegalName:   cfg.SellerName,
+			INN:         cfg.SellerINN,
+			KPP:         cfg.SellerKPP,
+			OGRN:        cfg.SellerOGRN,
+			Address:     cfg.SellerAddress,
+			BankName:    cfg.SellerBank,
+			BIK:         cfg.SellerBIK,
+			Account:     cfg.SellerAccount,
+			CorrAccount: cfg.SellerCorrAccount,
+		},
+		ReceiptSno: cfg.ReceiptSno,
+		ReceiptTax: cfg.ReceiptTax,
 	}
-	submod.Register(r, subCfg, sec, robokassa, users, subs, vac, resumes)
+	submod.Register(r, subCfg, sec, pay, users, subs, promos, profiles, vac, resumes, events)
 
-	// Запускаем фоновую очистку старых скрытых записей (каждые 24 часа)
-	cleaner := cleanup.NewCleaner(apps)
-	go cleaner.Start(context.Background(), 24*time.Hour)
+	// Фоновые задачи; имена совпадают с метками unicorn_jobs_* в /metrics.
+	// Очередь уведомлений в памяти процесса: ее разбирает каждый инстанс
+	sup.Go("notify", 0, events.Start)
+	sup.Go("analytics_views", 0, views.Start)
+	if cfg.SchedulerEnabled {
+		sup.Every("scheduler", 5*time.Second, sched.Start)
+	}
+	if bot != nil {
+		// Telegram отвечает 409 Conflict на параллельные getUpdates с одним токеном:
+		// опрашивает инстанс, взявший аренду "telegram", остальные ждут ее
+		sup.Go("telegram", 0, func(ctx context.Context) {
+			for ctx.Err() == nil {
+				_ = sched.Exclusive(ctx, "telegram", func(ctx context.Context) error {
+					bot.Start(ctx)
+					return nil
+				})
+			}
+		})
+	}
 
 	srv := &http.Server{
 		Addr:              cfg.HTTPAddr,
@@ -89,13 +261,90 @@ func main() {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	log.Printf("listening on %s", cfg.HTTPAddr)
-	if err := srv.ListenAndServe(); err != nil {
-		log.Fatal(err)
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.ListenAndServe() }()
+	logger.Info("listening", "addr", cfg.HTTPAddr, "env", cfg.AppEnv)
+
+	select {
+	case err := <-serveErr:
+		fatal("http server", err)
+	case <-ctx.Done():
+	}
+	// Повторный сигнал завершает процесс сразу
+	stop()
+	shutdown(logger, cfg.ShutdownDrain, cfg.ShutdownTimeout, srv, probe, sup, d, shutdownTracing)
+}
+
+// shutdown останавливает сервис в пределах timeout: /readyz отвечает 503,
+// сервер перестает принимать соединения и ждет текущие запросы, затем
+// останавливаются фоновые задачи, дописываются трейсы и закрывается MongoDB.
+// События, еще не разобранные из очереди уведомлений, теряются
+func shutdown(logger *slog.Logger, drain, timeout time.Duration, srv *http.Server, probe *health.Probe, sup *jobs.Supervisor,
+	d *db.Database, shutdownTracing func(context.Context) error) {
+	logger.Info("shutting down", "drain", drain.String(), "timeout", timeout.String())
+	probe.Drain()
+	// Балансировщик должен увидеть 503 на /readyz, пока сервер еще принимает запросы
+	time.Sleep(drain)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("http shutdown", "err", err)
+	}
+	deadline, _ := ctx.Deadline()
+	if err := sup.Stop(time.Until(deadline)); err != nil {
+		logger.Error("stop jobs", "err", err)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("flush traces", "err", err)
+	}
+	if err := d.Close(ctx); err != nil {
+		logger.Error("close mongo", "err", err)
+	}
+	logger.Info("stopped")
+}
+
+// fatal пишет ошибку запуска и завершает процесс
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+// newPayments собирает настроенные платежные провайдеры.
+// Robokassa подключается всегда, когда заданы ключи, чтобы принимать
+// callback'и и делать возвраты по старым платежам даже после смены провайдера.
+func newPayments(cfg config.Config, logger *slog.Logger) (*payments.Registry, bool) {
+	var providers []payments.Provider
+	if cfg.RobokassaMerchantLogin != "" && cfg.RobokassaPassword1 != "" {
+		providers = append(providers, payments.NewRobokassa(payments.RobokassaConfig{
+			MerchantLogin: cfg.RobokassaMerchantLogin,
+			Password1:     cfg.RobokassaPassword1,
+			Password2:     cfg.RobokassaPassword2,
+			Password3:     cfg.RobokassaPassword3,
+			TestMode:      cfg.RobokassaTestMode,
+			HashAlgo:      cfg.RobokassaHashAlgo,
+		}))
+	}
+	if cfg.YooKassaShopID != "" && cfg.YooKassaSecretKey != "" {
+		providers = append(providers, payments.NewYooKassa(payments.YooKassaConfig{
+			ShopID:    cfg.YooKassaShopID,
+			SecretKey: cfg.YooKassaSecretKey,
+			ReturnURL: cfg.YooKassaReturnURL,
+		}))
+	}
+	if cfg.PaymentProvider == "fake" {
+		providers = append(providers, payments.NewFake(cfg.PublicURL+"/api/subscription/fake/result", cfg.FakePaymentSecret))
 	}
+
+	reg := payments.NewRegistry(cfg.PaymentProvider, providers...)
+	if reg.Default() == nil {
+		logger.Warn("payment provider is not configured, payments disabled", "provider", cfg.PaymentProvider)
+		return reg, false
+	}
+	return reg, true
 }
 
-func bootstrapAdmin(ctx context.Context, admins *repo.AdminRepo) {
+func bootstrapAdmin(ctx context.Context, admins *repo.AdminRepo, logger *slog.Logger) {
 	login := strings.TrimSpace(os.Getenv("ADMIN_BOOTSTRAP_LOGIN"))
 	pass := strings.TrimSpace(os.Getenv("ADMIN_BOOTSTRAP_PASSWORD"))
 	if login == "" || pass == "" {
@@ -107,13 +356,30 @@ func bootstrapAdmin(ctx context.Context, admins *repo.AdminRepo) {
 	}
 	hash, err := security.HashPassword(pass)
 	if err != nil {
-		log.Printf("admin bootstrap: %v", err)
+		logger.Error("admin bootstrap", "err", err)
 		return
 	}
 	_, err = admins.Create(ctx, login, hash)
 	if err != nil {
-		log.Printf("admin bootstrap: %v", err)
+		logger.Error("admin bootstrap", "err", err)
 		return
 	}
-	log.Printf("admin bootstrap created: %s", login)
+	logger.Info("admin bootstrap created", "login", login)
+}
+
+// newMailer выбирает отправку писем: SMTP, если настроен, иначе запись в лог
+func newMailer(cfg config.Config, logger *slog.Logger) mail.Sender {
+	if cfg.SMTPHost == "" {
+		if cfg.AppEnv == "prod" {
+			logger.Warn("SMTP_HOST is not set, emails will only be logged")
+		}
+		return mail.Log{Logger: logger}
+	}
+	return mail.NewSMTP(mail.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		User:     cfg.SMTPUser,
+		Password: cfg.SMTPPassword,
+		From:     cfg.MailFrom,
+	})
 }
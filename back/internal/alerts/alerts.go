@@ -0,0 +1,193 @@
+// Package alerts рассылает новые вакансии по сохраненным поискам соискателей.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/notify"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/telemetry"
+)
+
+const (
+	// Вакансии моложе settle не берутся: запись могла еще не попасть в выборку
+	settle = 30 * time.Second
+	// Повтор, если ни один канал не доставил рассылку
+	retryDelay = 5 * time.Minute
+	// Сколько вакансий попадает в одну рассылку; остальные за этот период не отправляются
+	digestLimit = 50
+	// Сколько вакансий перечисляется в тексте
+	listLimit = 20
+	batchSize = 200
+)
+
+// Worker проверяет поиски, у которых подошло время рассылки
+type Worker struct {
+	searches *repo.SavedSearchRepo
+	vac      *repo.VacancyRepo
+	users    *repo.UserRepo
+	channels map[string]notify.Channel
+	log      *slog.Logger
+}
+
+func NewWorker(searches *repo.SavedSearchRepo, vac *repo.VacancyRepo, users *repo.UserRepo, log *slog.Logger, channels ...notify.Channel) *Worker {
+	w := &Worker{searches: searches, vac: vac, users: users, channels: map[string]notify.Channel{}, log: log.With("component", "alerts")}
+	for _, ch := range channels {
+		w.channels[ch.Name()] = ch
+	}
+	return w
+}
+
+// NextRun — когда поиск проверяется в следующий раз
+func NextRun(frequency string, now time.Time) time.Time {
+	switch frequency {
+	case models.AlertDaily:
+		return now.Add(24 * time.Hour)
+	case models.AlertWeekly:
+		return now.Add(7 * 24 * time.Hour)
+	default:
+		return now
+	}
+}
+
+// RunOnce обрабатывает все поиски, у которых подошло время
+func (w *Worker) RunOnce(ctx context.Context) error {
+	job := telemetry.StartJob("alerts")
+	defer job.Done()
+	for {
+		now := time.Now().UTC()
+		due, err := w.searches.ListDue(ctx, now, batchSize)
+		if err != nil {
+			job.Fail(err)
+			w.log.Error("list due searches", "err", err)
+			return err
+		}
+		for i := range due {
+			if err := w.process(ctx, &due[i], now); err != nil {
+				job.Fail(err)
+				w.log.Error("process search", "search_id", due[i].SearchID, "err", err)
+			}
+		}
+		if len(due) < batchSize || ctx.Err() != nil {
+			return job.Err()
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context, s *models.SavedSearch, now time.Time) error {
+	until := now.Add(-settle)
+	if !until.After(s.CheckedUntil) {
+		return w.searches.MarkRun(ctx, s.SearchID, s.CheckedUntil, NextRun(s.Frequency, now), false)
+	}
+	next := NextRun(s.Frequency, now)
+
+	u, err := w.users.FindByUserID(ctx, s.UserID)
+	if err != nil {
+		return err
+	}
+	if u == nil || u.Status.Deleted || u.Status.Blocked {
+		return w.searches.MarkRun(ctx, s.SearchID, until, next, false)
+	}
+
+	filter := repo.VacancyFilter{Query: s.Query, Tags: s.Tags, Location: s.Location, SalaryMin: s.SalaryMin}
+	found, err := w.vac.FindPublishedBetween(ctx, filter, s.CheckedUntil, until, digestLimit)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		return w.searches.MarkRun(ctx, s.SearchID, until, next, false)
+	}
+
+	ids := make([]string, 0, len(found))
+	for _, v := range found {
+		ids = append(ids, v.VacancyID)
+	}
+	claimed, err := w.searches.ClaimSent(ctx, s.UserID, s.SearchID, ids)
+	if err != nil {
+		if len(claimed) > 0 {
+			_ = w.searches.UnclaimSent(ctx, s.UserID, claimed)
+		}
+		return err
+	}
+	if len(claimed) == 0 {
+		return w.searches.MarkRun(ctx, s.SearchID, until, next, false)
+	}
+
+	isClaimed := map[string]bool{}
+	for _, id := range claimed {
+		isClaimed[id] = true
+	}
+	var fresh []models.Vacancy
+	for _, v := range found {
+		if isClaimed[v.VacancyID] {
+			fresh = append(fresh, v)
+		}
+	}
+
+	msg := digest(s, fresh)
+	delivered := false
+	for _, name := range s.Channels {
+		ch, ok := w.channels[name]
+		if !ok {
+			continue
+		}
+		if err := ch.Deliver(ctx, msg); err != nil {
+			w.log.Error("deliver alert", "channel", name, "search_id", s.SearchID, "err", err)
+			continue
+		}
+		delivered = true
+	}
+	if !delivered {
+		// Ни один канал не сработал — снимаем отметки и пробуем позже с того же места
+		if err := w.searches.UnclaimSent(ctx, s.UserID, claimed); err != nil {
+			return err
+		}
+		return w.searches.MarkRun(ctx, s.SearchID, s.CheckedUntil, now.Add(retryDelay), false)
+	}
+	return w.searches.MarkRun(ctx, s.SearchID, until, next, true)
+}
+
+func digest(s *models.SavedSearch, items []models.Vacancy) notify.Message {
+	var b strings.Builder
+	for i, v := range items {
+		if i == listLimit {
+			fmt.Fprintf(&b, "…и еще %d\n", len(items)-listLimit)
+			break
+		}
+		b.WriteString("• ")
+		b.WriteString(v.Title)
+		if v.Location != "" {
+			b.WriteString(" — ")
+			b.WriteString(v.Location)
+		}
+		b.WriteString("\n")
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, v := range items {
+		ids = append(ids, v.VacancyID)
+	}
+	link := "/saved-searches/" + s.SearchID
+	if len(items) == 1 {
+		link = "/vacancies/" + items[0].VacancyID
+	}
+	return notify.Message{
+		UserID: s.UserID,
+		Type:   "job_alert",
+		Title:  fmt.Sprintf("Новые вакансии по поиску «%s»: %d", s.Name, len(items)),
+		Text:   strings.TrimRight(b.String(), "\n"),
+		Link:   link,
+		Data: map[string]string{
+			"searchId":   s.SearchID,
+			"count":      strconv.Itoa(len(items)),
+			"vacancyIds": strings.Join(ids, ","),
+		},
+		Email: s.Email,
+	}
+}
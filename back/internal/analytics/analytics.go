@@ -0,0 +1,176 @@
+// Package analytics считает воронку найма: просмотры вакансий, отклики,
+// ответы компании и приглашения. Сырые события сворачиваются в дневные срезы
+// vacancy_stats_daily, отчеты строятся по ним.
+package analytics
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/telemetry"
+)
+
+const (
+	migrationName = "analytics_backfill_v1"
+
+	// recentDays — сколько последних дней пересчитывается на каждом проходе:
+	// отклик, поданный вчера, сегодня могут открыть и отклонить
+	recentDays = 3
+)
+
+type Service struct {
+	stats *repo.AnalyticsRepo
+	views chan *models.VacancyView
+	log   *slog.Logger
+}
+
+func NewService(stats *repo.AnalyticsRepo, log *slog.Logger) *Service {
+	return &Service{
+		stats: stats,
+		views: make(chan *models.VacancyView, 1024),
+		log:   log.With("component", "analytics"),
+	}
+}
+
+// Visitor — ключ посетителя для учета просмотров: userId вошедшего
+// пользователя или хеш IP и User-Agent анонимного
+func Visitor(userID, ip, userAgent string) string {
+	if userID != "" {
+		return "u:" + userID
+	}
+	sum := sha256.Sum256([]byte(ip + "\n" + userAgent))
+	return "a:" + hex.EncodeToString(sum[:12])
+}
+
+// RecordView ставит просмотр вакансии в очередь, не задерживая ответ;
+// при переполнении просмотр теряется с записью в лог
+func (s *Service) RecordView(v *models.Vacancy, visitor string) {
+	now := time.Now().UTC()
+	view := &models.VacancyView{
+		VacancyID: v.VacancyID,
+		CompanyID: v.CompanyID,
+		Day:       now.Format(repo.DayLayout),
+		Visitor:   visitor,
+		At:        now,
+	}
+	select {
+	case s.views <- view:
+	default:
+		s.log.Warn("view queue is full, view dropped", "vacancy_id", view.VacancyID)
+	}
+}
+
+// Start записывает просмотры из очереди до отмены контекста
+func (s *Service) Start(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case view := <-s.views:
+			rctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			if _, err := s.stats.RecordView(rctx, view); err != nil {
+				s.log.Error("record view", "vacancy_id", view.VacancyID, "err", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// RunOnce пересчитывает статистику последних дней
+func (s *Service) RunOnce(ctx context.Context) error {
+	job := telemetry.StartJob("analytics")
+	defer job.Done()
+	from := time.Now().UTC().AddDate(0, 0, -(recentDays - 1))
+	if _, err := s.stats.Rollup(ctx, from); err != nil {
+		job.Fail(err)
+		s.log.Error("rollup", "err", err)
+	}
+	return job.Err()
+}
+
+// Migrate один раз проставляет время решения старым откликам и строит
+// статистику за всю историю
+func (s *Service) Migrate(ctx context.Context, d *db.Database) error {
+	done, err := d.MigrationDone(ctx, migrationName)
+	if err != nil || done {
+		return err
+	}
+	n, err := s.stats.BackfillDecisions(ctx)
+	if err != nil {
+		return err
+	}
+	days, err := s.stats.Rollup(ctx, time.Time{})
+	if err != nil {
+		return err
+	}
+	s.log.Info("analytics backfilled", "decisions", n, "days", days)
+	return d.MarkMigration(ctx, migrationName)
+}
+
+// Stage — этап воронки; Rate — доля от предыдущего этапа
+type Stage struct {
+	Stage string  `json:"stage"`
+	Count int64   `json:"count"`
+	Rate  float64 `json:"rate"`
+}
+
+// Summary — показатели за период
+type Summary struct {
+	models.VacancyStats
+
+	Funnel []Stage `json:"funnel"`
+	// Доля откликов, которые компания открыла; на которые ответила
+	// (без отсеченных анкетой); ответов, ставших приглашением
+	ViewedRate   float64 `json:"viewedRate"`
+	ResponseRate float64 `json:"responseRate"`
+	AcceptRate   float64 `json:"acceptRate"`
+	// Среднее время от отклика до первого решения и до приглашения, часы; 0 — нет данных
+	AvgFirstResponseHours float64 `json:"avgFirstResponseHours"`
+	AvgTimeToHireHours    float64 `json:"avgTimeToHireHours"`
+}
+
+// Summarize считает конверсии и средние времена по счетчикам.
+// Счетчики относятся к дням событий, поэтому на коротком периоде доля
+// этапа может превышать 1: отклик подан до периода, а рассмотрен в нем.
+func Summarize(st models.VacancyStats) Summary {
+	s := Summary{
+		VacancyStats: st,
+		ViewedRate:   ratio(st.Viewed, st.Applications),
+		// Отсеченные анкетой не ждут ответа компании
+		ResponseRate:          ratio(st.Responded, st.Applications-st.KnockedOut),
+		AcceptRate:            ratio(st.Accepted, st.Responded),
+		AvgFirstResponseHours: hours(st.ResponseSumMs, st.Responded),
+		AvgTimeToHireHours:    hours(st.HireSumMs, st.Accepted),
+	}
+	s.Funnel = []Stage{
+		{Stage: "views", Count: st.Views, Rate: ratio(st.Views, st.Views)},
+		{Stage: "applications", Count: st.Applications, Rate: ratio(st.Applications, st.Views)},
+		{Stage: "viewed", Count: st.Viewed, Rate: s.ViewedRate},
+		{Stage: "responded", Count: st.Responded, Rate: s.ResponseRate},
+		{Stage: "accepted", Count: st.Accepted, Rate: s.AcceptRate},
+	}
+	return s
+}
+
+func ratio(a, b int64) float64 {
+	if b <= 0 {
+		return 0
+	}
+	return round(float64(a) / float64(b))
+}
+
+func hours(sumMs, n int64) float64 {
+	if n <= 0 {
+		return 0
+	}
+	return round(float64(sumMs) / float64(n) / float64(time.Hour/time.Millisecond))
+}
+
+func round(f float64) float64 { return math.Round(f*10000) / 10000 }
@@ -0,0 +1,114 @@
+// Package apikeys выпускает и проверяет ключи API компаний.
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"unicorn-auth/internal/logging"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/security"
+)
+
+const (
+	DefaultRateLimit = 60  // запросов в минуту
+	MaxRateLimit     = 600 // больше не выдаем: общий лимит по IP все равно действует
+	prefixLen        = 12  // сколько символов ключа показывается в списке
+)
+
+// Generate возвращает ключ (показывается один раз), его видимое начало и хеш для хранения
+func Generate() (raw, prefix, hash string) {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	raw = security.APIKeyPrefix + hex.EncodeToString(b)
+	return raw, raw[:prefixLen], Hash(raw)
+}
+
+func Hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Verifier — реализация security.APIKeyVerifier поверх Mongo.
+// Лимит запросов считается в памяти процесса, отдельно для каждого ключа.
+type Verifier struct {
+	keys  *repo.APIKeyRepo
+	users *repo.UserRepo
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func NewVerifier(keys *repo.APIKeyRepo, users *repo.UserRepo) *Verifier {
+	return &Verifier{keys: keys, users: users, buckets: map[string]*bucket{}}
+}
+
+func (v *Verifier) Verify(ctx context.Context, rawKey, ip string) (*security.APIKeyPrincipal, error) {
+	k, err := v.keys.GetByHash(ctx, Hash(rawKey))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if k == nil || k.RevokedAt != nil || (k.ExpiresAt != nil && now.After(*k.ExpiresAt)) {
+		return nil, security.ErrAPIKeyInvalid
+	}
+	// Ключ действует от имени владельца: заблокированный аккаунт отключает и ключи
+	u, err := v.users.FindByUserID(ctx, k.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil || u.Status.Blocked || u.Status.Deleted {
+		return nil, security.ErrAPIKeyInvalid
+	}
+	if !v.allow(k.KeyID, k.RateLimit, now) {
+		return nil, security.ErrAPIKeyRateLimited
+	}
+	if err := v.keys.Touch(ctx, k.KeyID, ip, now); err != nil {
+		logging.FromContext(ctx).Error("touch api key", "key_id", k.KeyID, "err", err)
+	}
+	return &security.APIKeyPrincipal{
+		KeyID:    k.KeyID,
+		UserID:   k.CompanyID,
+		UserType: string(u.Type),
+		Scopes:   k.Scopes,
+	}, nil
+}
+
+// allow — token bucket: perMinute запросов в минуту, всплеск до 10 секунд лимита
+func (v *Verifier) allow(keyID string, perMinute int, now time.Time) bool {
+	if perMinute <= 0 {
+		perMinute = DefaultRateLimit
+	}
+	rate := float64(perMinute) / 60
+	burst := float64(perMinute) / 6
+	if burst < 1 {
+		burst = 1
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	b, ok := v.buckets[keyID]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		v.buckets[keyID] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
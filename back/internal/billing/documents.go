@@ -0,0 +1,323 @@
+// Package billing формирует бухгалтерские документы по платежам подписки:
+// счет на оплату и квитанцию с данными фискального чека.
+package billing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/pdf"
+)
+
+var msk = time.FixedZone("MSK", 3*60*60)
+
+// NewReceipt собирает чек на одну позицию «подписка»
+func NewReceipt(sno, tax, itemName string, amount float64) *models.FiscalReceipt {
+	return &models.FiscalReceipt{
+		Sno: sno,
+		Items: []models.ReceiptItem{{
+			Name:          itemName,
+			Quantity:      1,
+			Sum:           amount,
+			PaymentMethod: "full_payment",
+			PaymentObject: "service",
+			Tax:           tax,
+		}},
+	}
+}
+
+// InvoiceNo — номер счета по порядковому номеру; у старых платежей без
+// номера счета вместо него берется InvID
+func InvoiceNo(seq int64) string {
+	return fmt.Sprintf("UN-%d", seq)
+}
+
+// vatAmount считает НДС, включенный в сумму, и подпись для строки итога
+func vatAmount(tax string, sum float64) (float64, string) {
+	switch tax {
+	case "vat20", "vat120":
+		return sum * 20 / 120, "В т.ч. НДС 20%"
+	case "vat10", "vat110":
+		return sum * 10 / 110, "В т.ч. НДС 10%"
+	case "vat0":
+		return 0, "НДС 0%"
+	}
+	return 0, "Без НДС"
+}
+
+var snoNames = map[string]string{
+	"osn":                "ОСН",
+	"usn_income":         "УСН доход",
+	"usn_income_outcome": "УСН доход - расход",
+	"esn":                "ЕСХН",
+	"patent":             "Патент",
+}
+
+func money(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	whole, frac, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String() + "," + frac
+}
+
+func partyLines(name string, req *models.Requisites) []string {
+	if req == nil {
+		return []string{name}
+	}
+	out := []string{req.LegalName}
+	if out[0] == "" {
+		out[0] = name
+	}
+	ids := "ИНН " + req.INN
+	if req.KPP != "" {
+		ids += ", КПП " + req.KPP
+	}
+	if req.OGRN != "" {
+		ids += ", ОГРН " + req.OGRN
+	}
+	out = append(out, ids)
+	if req.Address != "" {
+		out = append(out, req.Address)
+	}
+	return out
+}
+
+const (
+	left  = 50.0
+	right = pdf.PageWidth - 50
+)
+
+// Invoice формирует PDF счета на оплату
+func Invoice(seller models.Requisites, sub *models.Subscription) ([]byte, error) {
+	doc, err := pdf.New("Счет " + sub.InvoiceNo)
+	if err != nil {
+		return nil, err
+	}
+	p := doc.AddPage()
+
+	// Банковские реквизиты получателя
+	y := 60.0
+	p.Rect(left, y, right-left, 62, -1)
+	p.Text(left+6, y+14, 9, false, seller.BankName)
+	p.Text(left+6, y+28, 8, false, "Банк получателя")
+	p.Text(330, y+14, 9, false, "БИК "+seller.BIK)
+	p.Text(330, y+28, 9, false, "Сч. № "+seller.CorrAccount)
+	p.Line(left, y+34, right, y+34, 0.5)
+	p.Text(left+6, y+46, 9, false, "ИНН "+seller.INN+"   КПП "+seller.KPP)
+	p.Text(left+6, y+58, 9, false, seller.LegalName)
+	p.Text(330, y+46, 9, false, "Сч. № "+seller.Account)
+
+	y += 100
+	p.Text(left, y, 15, true, fmt.Sprintf("Счет на оплату № %s от %s", sub.InvoiceNo, sub.CreatedAt.In(msk).Format("02.01.2006")))
+	p.Line(left, y+8, right, y+8, 1.5)
+
+	y += 30
+	p.Text(left, y, 9, false, "Поставщик:")
+	for i, l := range partyLines(seller.LegalName, &seller) {
+		p.Text(left+80, y+float64(i)*12, 9, i == 0, l)
+	}
+	y += 44
+	p.Text(left, y, 9, false, "Покупатель:")
+	var buyer models.BillingParty
+	if sub.Buyer != nil {
+		buyer = *sub.Buyer
+	}
+	for i, l := range partyLines(buyer.DisplayName, buyer.Requisites) {
+		p.Text(left+80, y+float64(i)*12, 9, i == 0, l)
+	}
+
+	// Таблица позиций
+	y += 50
+	cols := []float64{left, left + 25, 350, 395, 430, 495, right}
+	heads := []string{"№", "Наименование", "Кол-во", "Ед.", "Цена", "Сумма"}
+	p.Rect(left, y, right-left, 18, 0.92)
+	for i, h := range heads {
+		p.Text(cols[i]+4, y+12, 9, true, h)
+	}
+	items := invoiceItems(sub)
+	total := 0.0
+	tax := "none"
+	for i, it := range items {
+		ry := y + 18 + float64(i)*18
+		p.Text(cols[0]+4, ry+12, 9, false, fmt.Sprint(i+1))
+		p.Text(cols[1]+4, ry+12, 9, false, it.Name)
+		p.TextRight(cols[3]-4, ry+12, 9, false, fmt.Sprint(it.Quantity))
+		p.Text(cols[3]+4, ry+12, 9, false, "шт")
+		p.TextRight(cols[5]-4, ry+12, 9, false, money(it.Sum/it.Quantity))
+		p.TextRight(cols[6]-4, ry+12, 9, false, money(it.Sum))
+		total += it.Sum
+		tax = it.Tax
+	}
+	bottom := y + 18 + float64(len(items))*18
+	p.Rect(left, y, right-left, bottom-y, -1)
+	for _, x := range cols[1 : len(cols)-1] {
+		p.Line(x, y, x, bottom, 0.5)
+	}
+
+	y = bottom + 18
+	vat, vatLabel := vatAmount(tax, total)
+	p.TextRight(cols[5]-4, y, 9, true, "Итого:")
+	p.TextRight(right-4, y, 9, true, money(total))
+	y += 14
+	p.TextRight(cols[5]-4, y, 9, true, vatLabel+":")
+	if vat > 0 {
+		p.TextRight(right-4, y, 9, true, money(vat))
+	} else {
+		p.TextRight(right-4, y, 9, true, "-")
+	}
+	y += 14
+	p.TextRight(cols[5]-4, y, 9, true, "Всего к оплате:")
+	p.TextRight(right-4, y, 9, true, money(total))
+
+	y += 26
+	p.Text(left, y, 9, false, fmt.Sprintf("Всего наименований %d, на сумму %s %s", len(items), money(total), currencyName(sub.Currency)))
+	y += 20
+	p.Text(left, y, 9, true, "Статус: "+statusText(sub))
+	p.Line(left, y+12, right, y+12, 1.5)
+
+	return doc.Bytes()
+}
+
+// Receipt формирует PDF квитанции об оплате с данными чека (54-ФЗ).
+// Сам фискальный чек пробивает онлайн-касса провайдера.
+func Receipt(seller models.Requisites, sub *models.Subscription) ([]byte, error) {
+	doc, err := pdf.New("Квитанция " + sub.InvoiceNo)
+	if err != nil {
+		return nil, err
+	}
+	p := doc.AddPage()
+	width := 300.0
+	x := (pdf.PageWidth - width) / 2
+	y := 60.0
+
+	center := func(size float64, isBold bool, s string) {
+		p.Text(x+(width-doc.TextWidth(s, size, isBold))/2, y, size, isBold, s)
+		y += size * 1.5
+	}
+	row := func(k, v string) {
+		p.Text(x, y, 9, false, k)
+		p.TextRight(x+width, y, 9, false, v)
+		y += 14
+	}
+
+	center(12, true, "КВИТАНЦИЯ ОБ ОПЛАТЕ")
+	center(9, false, "Приход, данные чека онлайн-кассы")
+	y += 4
+	center(9, true, seller.LegalName)
+	center(8, false, "ИНН "+seller.INN)
+	if seller.Address != "" {
+		for _, l := range doc.Wrap(seller.Address, 8, false, width) {
+			center(8, false, l)
+		}
+	}
+	p.Line(x, y, x+width, y, 0.5)
+	y += 16
+
+	paidAt := sub.StartDate
+	if paidAt.IsZero() {
+		paidAt = sub.UpdatedAt
+	}
+	row("Документ", sub.InvoiceNo)
+	row("Дата и время", paidAt.In(msk).Format("02.01.2006 15:04"))
+	row("Номер платежа (InvId)", fmt.Sprint(sub.InvID))
+	if sub.Provider != "" {
+		row("Платежная система", sub.Provider)
+	}
+	if sub.Receipt != nil && sub.Receipt.Sno != "" {
+		row("СНО", snoName(sub.Receipt.Sno))
+	}
+	p.Line(x, y-4, x+width, y-4, 0.5)
+	y += 10
+
+	total := 0.0
+	tax := "none"
+	for i, it := range invoiceItems(sub) {
+		y = p.Paragraph(x, y, width, 9, true, fmt.Sprintf("%d. %s", i+1, it.Name))
+		row(fmt.Sprintf("%v x %s", it.Quantity, money(it.Sum/it.Quantity)), money(it.Sum))
+		row("Признак способа расчета", paymentMethodName(it.PaymentMethod))
+		row("Признак предмета расчета", paymentObjectName(it.PaymentObject))
+		_, vatLabel := vatAmount(it.Tax, it.Sum)
+		row("Ставка НДС", vatLabel)
+		total += it.Sum
+		tax = it.Tax
+		y += 6
+	}
+	p.Line(x, y-4, x+width, y-4, 0.5)
+	y += 12
+	p.Text(x, y, 11, true, "ИТОГ")
+	p.TextRight(x+width, y, 11, true, money(total))
+	y += 18
+	row("Безналичными", money(total))
+	if vat, label := vatAmount(tax, total); vat > 0 {
+		row(label, money(vat))
+	}
+	if sub.RefundedSum != "" {
+		y += 6
+		row("Возвращено", sub.RefundedSum)
+	}
+	y += 10
+	center(9, true, "Статус: "+statusText(sub))
+
+	return doc.Bytes()
+}
+
+// invoiceItems берет позиции из зафиксированного чека, а для старых платежей — строит из суммы
+func invoiceItems(sub *models.Subscription) []models.ReceiptItem {
+	if sub.Receipt != nil && len(sub.Receipt.Items) > 0 {
+		return sub.Receipt.Items
+	}
+	return NewReceipt("", "none", "Подписка Unicorn Premium", sub.Amount).Items
+}
+
+func statusText(sub *models.Subscription) string {
+	switch sub.Status {
+	case "paid":
+		return "оплачен " + sub.StartDate.In(msk).Format("02.01.2006")
+	case "refunded":
+		return "возврат " + sub.RefundedSum
+	case "partially_refunded":
+		return "частичный возврат " + sub.RefundedSum
+	case "cancelled":
+		return "отменен"
+	}
+	return "ожидает оплаты"
+}
+
+func snoName(s string) string {
+	if n, ok := snoNames[s]; ok {
+		return n
+	}
+	return s
+}
+
+func paymentMethodName(s string) string {
+	switch s {
+	case "full_prepayment":
+		return "Предоплата 100%"
+	case "full_payment":
+		return "Полный расчет"
+	}
+	return s
+}
+
+func paymentObjectName(s string) string {
+	if s == "service" {
+		return "Услуга"
+	}
+	return s
+}
+
+func currencyName(c string) string {
+	if c == "" || c == "RUB" {
+		return "руб."
+	}
+	return c
+}
@@ -0,0 +1,15 @@
+package billing
+
+// DigitsOnly — s состоит только из цифр и его длина от min до max
+// (ИНН, КПП, ОГРН, БИК, номера счетов)
+func DigitsOnly(s string, min, max int) bool {
+	if len(s) < min || len(s) > max {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -2,48 +2,37 @@ package cleanup
 
 import (
 	"context"
-	"log"
-	"time"
+	"log/slog"
 
 	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/telemetry"
 )
 
 // Cleaner выполняет периодическую очистку старых данных
 type Cleaner struct {
 	apps *repo.ApplicationRepo
+	log  *slog.Logger
 }
 
-func NewCleaner(apps *repo.ApplicationRepo) *Cleaner {
-	return &Cleaner{apps: apps}
+func NewCleaner(apps *repo.ApplicationRepo, log *slog.Logger) *Cleaner {
+	return &Cleaner{apps: apps, log: log.With("component", "cleanup")}
 }
 
-// Start запускает периодическую очистку
-func (c *Cleaner) Start(ctx context.Context, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// RunOnce удаляет устаревшие данные
+func (c *Cleaner) RunOnce(ctx context.Context) error {
+	job := telemetry.StartJob("cleanup")
+	defer job.Done()
 
-	// Первая очистка сразу при запуске
-	c.cleanup(ctx)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			c.cleanup(ctx)
-		}
-	}
-}
-
-func (c *Cleaner) cleanup(ctx context.Context) {
 	// Удаляем отклики, скрытые более месяца назад обеими сторонами
 	deleted, err := c.apps.DeleteOldHidden(ctx)
 	if err != nil {
-		log.Printf("Error cleaning old hidden applications: %v", err)
-		return
+		job.Fail(err)
+		c.log.Error("clean old hidden applications", "err", err)
+		return err
 	}
 
 	if deleted > 0 {
-		log.Printf("Cleaned up %d old hidden applications", deleted)
+		c.log.Info("old hidden applications deleted", "count", deleted)
 	}
+	return nil
 }
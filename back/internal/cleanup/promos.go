@@ -0,0 +1,37 @@
+package cleanup
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/telemetry"
+)
+
+// PromoReleaser возвращает кодам использования из неоплаченных резервов
+type PromoReleaser struct {
+	promos *repo.PromoRepo
+	log    *slog.Logger
+}
+
+func NewPromoReleaser(promos *repo.PromoRepo, log *slog.Logger) *PromoReleaser {
+	return &PromoReleaser{promos: promos, log: log.With("component", "promos")}
+}
+
+// RunOnce снимает резервы промокодов, не оплаченные за repo.PromoReservationTTL
+func (p *PromoReleaser) RunOnce(ctx context.Context) error {
+	job := telemetry.StartJob("promos")
+	defer job.Done()
+
+	released, err := p.promos.ReleaseExpired(ctx, time.Now().UTC())
+	if err != nil {
+		job.Fail(err)
+		p.log.Error("release expired promo reservations", "err", err)
+		return err
+	}
+	if released > 0 {
+		p.log.Info("expired promo reservations released", "count", released)
+	}
+	return nil
+}
@@ -11,6 +11,8 @@ import (
 type Config struct {
 	AppEnv   string
 	HTTPAddr string
+	// PublicURL — внешний адрес API (для callback/return URL)
+	PublicURL string
 
 	MongoURI string
 	MongoDB  string
@@ -32,8 +34,64 @@ type Config struct {
 	RobokassaPassword1     string
 	RobokassaPassword2     string
 	RobokassaTestMode      bool
+	RobokassaPassword3     string
+	RobokassaHashAlgo      string
 	SubscriptionPrice      string
 	SubscriptionDuration   int
+	SubscriptionPlan       string
+	SubscriptionTrialDays  int // 0 = пробный период выключен
+
+	// Payments: robokassa/yookassa/fake
+	PaymentProvider   string
+	YooKassaShopID    string
+	YooKassaSecretKey string
+	YooKassaReturnURL string
+	FakePaymentSecret string
+
+	// Реквизиты продавца для счетов и чеков
+	SellerName        string
+	SellerINN         string
+	SellerKPP         string
+	SellerOGRN        string
+	SellerAddress     string
+	SellerBank        string
+	SellerBIK         string
+	SellerAccount     string
+	SellerCorrAccount string
+	ReceiptSno        string // система налогообложения (usn_income, osn, ...)
+	ReceiptTax        string // ставка НДС (none, vat20, ...)
+
+	// Почта (приглашения в команду); без SMTP_HOST письма пишутся в лог
+	FrontendURL  string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUser     string
+	SMTPPassword string
+	MailFrom     string
+
+	// Telegram-бот; без TELEGRAM_BOT_TOKEN бот выключен.
+	// TELEGRAM_API_URL позволяет подставить локальную заглушку Bot API.
+	TelegramBotToken    string
+	TelegramBotUsername string
+	TelegramAPIURL      string
+
+	// Наблюдаемость: /metrics закрыт токеном, если METRICS_TOKEN задан;
+	// команды Mongo дольше MONGO_SLOW_MS пишутся в лог (0 — не писать).
+	// Трейсы экспортируются по OTLP, только если задан OTEL_EXPORTER_OTLP_ENDPOINT
+	// или OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+	MetricsToken   string
+	MongoSlow      time.Duration
+	TracingEnabled bool
+
+	// Сколько ждать завершения запросов и фоновых задач при остановке (SHUTDOWN_TIMEOUT_SEC)
+	ShutdownTimeout time.Duration
+	// Сколько после перевода /readyz в 503 принимать запросы, пока балансировщик
+	// не снимет инстанс (SHUTDOWN_DRAIN_SEC, 0 — не ждать)
+	ShutdownDrain time.Duration
+
+	// Периодические задачи по расписанию (SCHEDULER_ENABLED); на репликах
+	// только с API можно выключить — задачи выполнит другой инстанс
+	SchedulerEnabled bool
 }
 
 func MustLoad() Config {
@@ -46,9 +104,17 @@ func MustLoad() Config {
 		}
 	}
 
+	trialDays := 0
+	if d := get("SUBSCRIPTION_TRIAL_DAYS"); d != "" {
+		if parsed, err := strconv.Atoi(d); err == nil && parsed >= 0 {
+			trialDays = parsed
+		}
+	}
+
 	cfg := Config{
 		AppEnv:         def(get("APP_ENV"), "dev"),
 		HTTPAddr:       def(get("HTTP_ADDR"), ":8080"),
+		PublicURL:      strings.TrimRight(def(get("PUBLIC_URL"), "http://localhost:8080"), "/"),
 		MongoURI:       def(get("MONGO_URI"), "mongodb://localhost:27017"),
 		MongoDB:        def(get("MONGO_DB"), "unicorn"),
 		JWTHS256Secret: get("JWT_HS256_SECRET"),
@@ -63,17 +129,71 @@ func MustLoad() Config {
 		RobokassaPassword1:     get("ROBOKASSA_PASSWORD1"),
 		RobokassaPassword2:     get("ROBOKASSA_PASSWORD2"),
 		RobokassaTestMode:      strings.ToLower(def(get("ROBOKASSA_TEST_MODE"), "true")) == "true",
+		RobokassaPassword3:     get("ROBOKASSA_PASSWORD3"),
+		RobokassaHashAlgo:      def(get("ROBOKASSA_HASH_ALGO"), "md5"),
 		SubscriptionPrice:      def(get("SUBSCRIPTION_PRICE"), "990.00"),
 		SubscriptionDuration:   subsDuration,
+		SubscriptionPlan:       def(get("SUBSCRIPTION_PLAN"), "premium"),
+		SubscriptionTrialDays:  trialDays,
+
+		PaymentProvider:   strings.ToLower(def(get("PAYMENT_PROVIDER"), "robokassa")),
+		YooKassaShopID:    get("YOOKASSA_SHOP_ID"),
+		YooKassaSecretKey: get("YOOKASSA_SECRET_KEY"),
+		YooKassaReturnURL: get("YOOKASSA_RETURN_URL"),
+		FakePaymentSecret: get("FAKE_PAYMENT_SECRET"),
+
+		SellerName:        get("SELLER_NAME"),
+		SellerINN:         get("SELLER_INN"),
+		SellerKPP:         get("SELLER_KPP"),
+		SellerOGRN:        get("SELLER_OGRN"),
+		SellerAddress:     get("SELLER_ADDRESS"),
+		SellerBank:        get("SELLER_BANK"),
+		SellerBIK:         get("SELLER_BIK"),
+		SellerAccount:     get("SELLER_ACCOUNT"),
+		SellerCorrAccount: get("SELLER_CORR_ACCOUNT"),
+		ReceiptSno:        def(get("RECEIPT_SNO"), "usn_income"),
+		ReceiptTax:        def(get("RECEIPT_TAX"), "none"),
+
+		FrontendURL:  strings.TrimRight(def(get("FRONTEND_URL"), "http://localhost:3000"), "/"),
+		SMTPHost:     get("SMTP_HOST"),
+		SMTPPort:     def(get("SMTP_PORT"), "587"),
+		SMTPUser:     get("SMTP_USER"),
+		SMTPPassword: get("SMTP_PASSWORD"),
+		MailFrom:     def(get("MAIL_FROM"), "Unicorn <no-reply@localhost>"),
+
+		TelegramBotToken:    get("TELEGRAM_BOT_TOKEN"),
+		TelegramBotUsername: get("TELEGRAM_BOT_USERNAME"),
+		TelegramAPIURL:      get("TELEGRAM_API_URL"),
 	}
 	cfg.CookieSecure = strings.ToLower(def(get("COOKIE_SECURE"), "false")) == "true"
 
+	cfg.MetricsToken = get("METRICS_TOKEN")
+	cfg.MongoSlow = 500 * time.Millisecond
+	if ms, err := strconv.Atoi(get("MONGO_SLOW_MS")); err == nil && ms >= 0 {
+		cfg.MongoSlow = time.Duration(ms) * time.Millisecond
+	}
+	cfg.TracingEnabled = get("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || get("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+
+	cfg.ShutdownTimeout = 20 * time.Second
+	if sec, err := strconv.Atoi(get("SHUTDOWN_TIMEOUT_SEC")); err == nil && sec > 0 {
+		cfg.ShutdownTimeout = time.Duration(sec) * time.Second
+	}
+	cfg.ShutdownDrain = 5 * time.Second
+	if sec, err := strconv.Atoi(get("SHUTDOWN_DRAIN_SEC")); err == nil && sec >= 0 {
+		cfg.ShutdownDrain = time.Duration(sec) * time.Second
+	}
+
+	cfg.SchedulerEnabled = strings.ToLower(def(get("SCHEDULER_ENABLED"), "true")) == "true"
+
 	if cfg.JWTHS256Secret == "" || len(cfg.JWTHS256Secret) < 32 {
 		log.Fatal("missing/weak JWT_HS256_SECRET (min 32 chars)")
 	}
 	if cfg.TotpEncKeyB64 == "" {
 		log.Fatal("missing TOTP_ENC_KEY_B64")
 	}
+	if cfg.PaymentProvider == "fake" && cfg.AppEnv == "prod" {
+		log.Fatal("PAYMENT_PROVIDER=fake is not allowed in prod")
+	}
 	return cfg
 }
 
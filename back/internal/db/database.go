@@ -2,9 +2,11 @@ package db
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"os"
 	"time"
 
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -14,19 +16,26 @@ type Database struct {
 	DB     *mongo.Database
 }
 
-func Connect(ctx context.Context, uri, dbName string) *Database {
+// Connect подключается к MongoDB; monitor получает события команд (может быть nil)
+func Connect(ctx context.Context, uri, dbName string, monitor *event.CommandMonitor) *Database {
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetMonitor(monitor))
 	if err != nil {
-		log.Fatalf("mongo connect: %v", err)
+		fatal("mongo connect", err)
 	}
 	if err := client.Ping(ctx, nil); err != nil {
-		log.Fatalf("mongo ping: %v", err)
+		fatal("mongo ping", err)
 	}
 	return &Database{Client: client, DB: client.Database(dbName)}
 }
 
+// fatal — без базы сервис не запускается
+func fatal(msg string, err error) {
+	slog.Error(msg, "component", "db", "err", err)
+	os.Exit(1)
+}
+
 func (d *Database) Close(ctx context.Context) error { return d.Client.Disconnect(ctx) }
 
 func (d *Database) Users() *mongo.Collection         { return d.DB.Collection("users") }
@@ -38,3 +47,46 @@ func (d *Database) Applications() *mongo.Collection  { return d.DB.Collection("a
 func (d *Database) ChatMessages() *mongo.Collection  { return d.DB.Collection("chat_messages") }
 func (d *Database) Admins() *mongo.Collection        { return d.DB.Collection("admins") }
 func (d *Database) Subscriptions() *mongo.Collection { return d.DB.Collection("subscriptions") }
+func (d *Database) PromoCodes() *mongo.Collection    { return d.DB.Collection("promo_codes") }
+func (d *Database) PromoRedemptions() *mongo.Collection {
+	return d.DB.Collection("promo_redemptions")
+}
+func (d *Database) Organizations() *mongo.Collection { return d.DB.Collection("organizations") }
+func (d *Database) OrgMembers() *mongo.Collection    { return d.DB.Collection("org_members") }
+func (d *Database) OrgInvites() *mongo.Collection    { return d.DB.Collection("org_invites") }
+func (d *Database) CompanyVerifications() *mongo.Collection {
+	return d.DB.Collection("company_verifications")
+}
+func (d *Database) CompanyReviews() *mongo.Collection { return d.DB.Collection("company_reviews") }
+func (d *Database) SavedSearches() *mongo.Collection  { return d.DB.Collection("saved_searches") }
+func (d *Database) AlertsSent() *mongo.Collection     { return d.DB.Collection("alerts_sent") }
+func (d *Database) Notifications() *mongo.Collection  { return d.DB.Collection("notifications") }
+func (d *Database) NotificationPrefs() *mongo.Collection {
+	return d.DB.Collection("notification_prefs")
+}
+func (d *Database) TelegramLinks() *mongo.Collection { return d.DB.Collection("telegram_links") }
+func (d *Database) TelegramCodes() *mongo.Collection { return d.DB.Collection("telegram_codes") }
+func (d *Database) TelegramRefs() *mongo.Collection  { return d.DB.Collection("telegram_refs") }
+func (d *Database) Webhooks() *mongo.Collection      { return d.DB.Collection("webhooks") }
+func (d *Database) WebhookDeliveries() *mongo.Collection {
+	return d.DB.Collection("webhook_deliveries")
+}
+func (d *Database) APIKeys() *mongo.Collection      { return d.DB.Collection("api_keys") }
+func (d *Database) VacancyFeeds() *mongo.Collection { return d.DB.Collection("vacancy_feeds") }
+func (d *Database) ResumeSnapshots() *mongo.Collection {
+	return d.DB.Collection("resume_snapshots")
+}
+func (d *Database) Skills() *mongo.Collection     { return d.DB.Collection("skills") }
+func (d *Database) Migrations() *mongo.Collection { return d.DB.Collection("migrations") }
+func (d *Database) VacancyViews() *mongo.Collection {
+	return d.DB.Collection("vacancy_views")
+}
+func (d *Database) VacancyStatsDaily() *mongo.Collection {
+	return d.DB.Collection("vacancy_stats_daily")
+}
+func (d *Database) UserActivity() *mongo.Collection  { return d.DB.Collection("user_activity") }
+func (d *Database) PlatformStats() *mongo.Collection { return d.DB.Collection("platform_stats") }
+func (d *Database) ScheduledJobs() *mongo.Collection { return d.DB.Collection("scheduled_jobs") }
+func (d *Database) JobRuns() *mongo.Collection       { return d.DB.Collection("job_runs") }
+func (d *Database) Leases() *mongo.Collection        { return d.DB.Collection("leases") }
+func (d *Database) Counters() *mongo.Collection      { return d.DB.Collection("counters") }
@@ -2,8 +2,6 @@ package db
 
 import (
 	"context"
-	"log"
-
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -12,7 +10,7 @@ import (
 func (d *Database) EnsureIndexes(ctx context.Context) {
 	must := func(err error) {
 		if err != nil {
-			log.Fatalf("ensure indexes: %v", err)
+			fatal("ensure indexes", err)
 		}
 	}
 
@@ -34,13 +32,22 @@ func (d *Database) EnsureIndexes(ctx context.Context) {
 		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "displayName", Value: 1}}, Options: options.Index().SetName("profile_type_name")},
 		{Keys: bson.D{{Key: "industry", Value: 1}}, Options: options.Index().SetName("profile_industry")},
 		{Keys: bson.D{{Key: "location", Value: 1}}, Options: options.Index().SetName("profile_location")},
+		// Домен подтверждает только одна компания
+		{Keys: bson.D{{Key: "verifiedDomain", Value: 1}}, Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"verifiedDomain": bson.M{"$gt": ""}}).SetName("uniq_profile_verified_domain")},
 	})
 	must(err)
 
+	must(d.migrateVacancyLifecycle(ctx))
+
 	_, err = d.Vacancies().Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{Keys: bson.D{{Key: "vacancyId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_vacancyId")},
 		{Keys: bson.D{{Key: "companyId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("vac_company_created")},
-		{Keys: bson.D{{Key: "createdAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(60 * 60 * 24 * 30)).SetName("ttl_vacancies_30d")},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "isPremium", Value: -1}, {Key: "publishedAt", Value: -1}}, Options: options.Index().SetName("vac_status_published")},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}}, Options: options.Index().SetName("vac_status_expires")},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "publishAt", Value: 1}}, Options: options.Index().SetName("vac_status_publish_at")},
+		{Keys: bson.D{{Key: "companyId", Value: 1}, {Key: "externalId", Value: 1}}, Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"externalId": bson.M{"$type": "string"}}).SetName("uniq_vacancy_company_external")},
 	})
 	must(err)
 
@@ -54,6 +61,9 @@ func (d *Database) EnsureIndexes(ctx context.Context) {
 		{Keys: bson.D{{Key: "applicationId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_applicationId")},
 		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "vacancyId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_user_vacancy")},
 		{Keys: bson.D{{Key: "companyId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("app_company_created")},
+		{Keys: bson.D{{Key: "companyId", Value: 1}, {Key: "assigneeId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("app_company_assignee")},
+		{Keys: bson.D{{Key: "companyId", Value: 1}, {Key: "snapshotId", Value: 1}}, Options: options.Index().SetName("app_company_snapshot")},
+		{Keys: bson.D{{Key: "vacancyId", Value: 1}, {Key: "status", Value: 1}}, Options: options.Index().SetName("app_vacancy_status")},
 	})
 	must(err)
 
@@ -67,4 +77,223 @@ func (d *Database) EnsureIndexes(ctx context.Context) {
 		{Keys: bson.D{{Key: "adminId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_adminId")},
 	})
 	must(err)
+
+	_, err = d.PromoCodes().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "promoId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_promoId")},
+		{Keys: bson.D{{Key: "codeNorm", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_promo_code")},
+	})
+	must(err)
+
+	// Резерв заводится на каждый платеж, поэтому уникален только оплаченный:
+	// одним кодом пользователь оплачивает подписку один раз
+	if _, err := d.PromoRedemptions().Indexes().DropOne(ctx, "uniq_promo_user"); err != nil && !isIndexNotFound(err) {
+		must(err)
+	}
+	must(d.migratePromoDuplicates(ctx))
+	_, err = d.PromoRedemptions().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "promoId", Value: 1}, {Key: "userId", Value: 1}}, Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"status": "redeemed"}).SetName("uniq_promo_user_redeemed")},
+		{Keys: bson.D{{Key: "promoId", Value: 1}, {Key: "userId", Value: 1}, {Key: "status", Value: 1}}, Options: options.Index().SetName("promo_red_user_status")},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}}, Options: options.Index().SetName("promo_red_status_expires")},
+		{Keys: bson.D{{Key: "subscriptionId", Value: 1}}, Options: options.Index().SetName("promo_red_subscription")},
+		{Keys: bson.D{{Key: "promoId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("promo_red_created")},
+	})
+	must(err)
+
+	_, err = d.Organizations().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "orgId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_orgId")},
+	})
+	must(err)
+
+	_, err = d.OrgMembers().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_member_user")},
+		{Keys: bson.D{{Key: "orgId", Value: 1}, {Key: "joinedAt", Value: 1}}, Options: options.Index().SetName("member_org_joined")},
+	})
+	must(err)
+
+	_, err = d.OrgInvites().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "inviteId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_inviteId")},
+		{Keys: bson.D{{Key: "tokenHash", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_invite_token")},
+		{Keys: bson.D{{Key: "orgId", Value: 1}, {Key: "status", Value: 1}}, Options: options.Index().SetName("invite_org_status")},
+	})
+	must(err)
+
+	_, err = d.CompanyVerifications().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "verificationId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_verificationId")},
+		{Keys: bson.D{{Key: "companyId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("verif_company_created")},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "method", Value: 1}, {Key: "createdAt", Value: 1}}, Options: options.Index().SetName("verif_status_method")},
+	})
+	must(err)
+
+	_, err = d.CompanyReviews().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "reviewId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_reviewId")},
+		{Keys: bson.D{{Key: "companyId", Value: 1}, {Key: "userId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_review_company_user")},
+		{Keys: bson.D{{Key: "companyId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("review_company_created")},
+	})
+	must(err)
+
+	_, err = d.SavedSearches().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "searchId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_searchId")},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("search_user_created")},
+		{Keys: bson.D{{Key: "active", Value: 1}, {Key: "nextRunAt", Value: 1}}, Options: options.Index().SetName("search_active_next")},
+	})
+	must(err)
+
+	_, err = d.AlertsSent().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "vacancyId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_alert_user_vacancy")},
+		{Keys: bson.D{{Key: "searchId", Value: 1}, {Key: "sentAt", Value: -1}}, Options: options.Index().SetName("alert_search_sent")},
+	})
+	must(err)
+
+	_, err = d.Notifications().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "notificationId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_notificationId")},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("notif_user_created")},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "read", Value: 1}, {Key: "type", Value: 1}}, Options: options.Index().SetName("notif_user_unread")},
+	})
+	must(err)
+
+	_, err = d.NotificationPrefs().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_notif_prefs_user")},
+	})
+	must(err)
+
+	_, err = d.TelegramLinks().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_tg_link_user")},
+		{Keys: bson.D{{Key: "chatId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_tg_link_chat")},
+	})
+	must(err)
+
+	_, err = d.TelegramCodes().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "codeHash", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_tg_codeHash")},
+		{Keys: bson.D{{Key: "userId", Value: 1}}, Options: options.Index().SetName("tg_code_user")},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0).SetName("ttl_tg_codes")},
+	})
+	must(err)
+
+	_, err = d.TelegramRefs().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "chatId", Value: 1}, {Key: "messageId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_tg_ref")},
+		{Keys: bson.D{{Key: "createdAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(60 * 60 * 24 * 90)).SetName("ttl_tg_refs_90d")},
+	})
+	must(err)
+
+	_, err = d.Webhooks().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "webhookId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_webhookId")},
+		{Keys: bson.D{{Key: "companyId", Value: 1}, {Key: "active", Value: 1}}, Options: options.Index().SetName("webhook_company_active")},
+	})
+	must(err)
+
+	_, err = d.WebhookDeliveries().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "deliveryId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_deliveryId")},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}}, Options: options.Index().SetName("delivery_status_next")},
+		{Keys: bson.D{{Key: "webhookId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("delivery_webhook_created")},
+		{Keys: bson.D{{Key: "createdAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(60 * 60 * 24 * 30)).SetName("ttl_deliveries_30d")},
+	})
+	must(err)
+
+	_, err = d.APIKeys().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "keyId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_keyId")},
+		{Keys: bson.D{{Key: "keyHash", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_keyHash")},
+		{Keys: bson.D{{Key: "companyId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("apikey_company_created")},
+	})
+	must(err)
+
+	_, err = d.VacancyFeeds().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "companyId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_feed_company")},
+		{Keys: bson.D{{Key: "active", Value: 1}, {Key: "nextRunAt", Value: 1}}, Options: options.Index().SetName("feed_active_next")},
+	})
+	must(err)
+
+	_, err = d.ResumeSnapshots().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "snapshotId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_snapshotId")},
+		{Keys: bson.D{{Key: "resumeId", Value: 1}, {Key: "version", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_resume_version")},
+	})
+	must(err)
+
+	// Один ключ написания — один навык; по ключам же работает автодополнение
+	_, err = d.Skills().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "skillId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_skillId")},
+		{Keys: bson.D{{Key: "keys", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_skill_keys")},
+		{Keys: bson.D{{Key: "usage", Value: -1}, {Key: "name", Value: 1}}, Options: options.Index().SetName("skill_usage")},
+	})
+	must(err)
+
+	// Переименование тегов при слиянии навыков
+	_, err = d.Vacancies().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "tags", Value: 1}}, Options: options.Index().SetName("vac_tags"),
+	})
+	must(err)
+	_, err = d.Resumes().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "skills", Value: 1}}, Options: options.Index().SetName("res_skills"),
+	})
+	must(err)
+
+	// Просмотры вакансий: посетитель учитывается раз в сутки, сырые записи живут 90 дней
+	_, err = d.VacancyViews().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "vacancyId", Value: 1}, {Key: "day", Value: 1}, {Key: "visitor", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_view_vacancy_day_visitor")},
+		{Keys: bson.D{{Key: "day", Value: 1}}, Options: options.Index().SetName("view_day")},
+		{Keys: bson.D{{Key: "at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(60 * 60 * 24 * 90)).SetName("ttl_views_90d")},
+	})
+	must(err)
+
+	_, err = d.VacancyStatsDaily().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "vacancyId", Value: 1}, {Key: "day", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_stats_vacancy_day")},
+		{Keys: bson.D{{Key: "companyId", Value: 1}, {Key: "day", Value: 1}}, Options: options.Index().SetName("stats_company_day")},
+		{Keys: bson.D{{Key: "day", Value: 1}, {Key: "updatedAt", Value: 1}}, Options: options.Index().SetName("stats_day_updated")},
+	})
+	must(err)
+
+	// Пересчет дневной статистики идет по времени событий отклика
+	_, err = d.Applications().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "createdAt", Value: 1}}, Options: options.Index().SetName("app_created")},
+		{Keys: bson.D{{Key: "viewedAt", Value: 1}}, Options: options.Index().SetSparse(true).SetName("app_viewed_at")},
+		{Keys: bson.D{{Key: "decidedAt", Value: 1}}, Options: options.Index().SetSparse(true).SetName("app_decided_at")},
+		{Keys: bson.D{{Key: "acceptedAt", Value: 1}}, Options: options.Index().SetSparse(true).SetName("app_accepted_at")},
+	})
+	must(err)
+
+	// Активность для DAU/MAU: одна запись на пользователя в день, хранится 90 дней
+	_, err = d.UserActivity().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "day", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_activity_user_day")},
+		{Keys: bson.D{{Key: "date", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(60 * 60 * 24 * 90)).SetName("ttl_activity_90d")},
+	})
+	must(err)
+
+	_, err = d.PlatformStats().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "period", Value: 1}, {Key: "bucket", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_stats_period_bucket")},
+	})
+	must(err)
+
+	// Пересчет статистики платформы идет по датам создания и оплаты
+	_, err = d.Users().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "createdAt", Value: 1}}, Options: options.Index().SetName("user_created"),
+	})
+	must(err)
+	_, err = d.Vacancies().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "createdAt", Value: 1}}, Options: options.Index().SetName("vac_created"),
+	})
+	must(err)
+	// По InvId платеж находят колбэки провайдера; у пробных периодов номера нет
+	must(d.migrateCounters(ctx))
+	_, err = d.Subscriptions().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "invId", Value: 1}}, Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"invId": bson.M{"$gt": 0}}).SetName("uniq_sub_invId")},
+		{Keys: bson.D{{Key: "invoiceNo", Value: 1}}, Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"invoiceNo": bson.M{"$gt": ""}}).SetName("uniq_sub_invoiceNo")},
+		{Keys: bson.D{{Key: "createdAt", Value: 1}}, Options: options.Index().SetName("sub_created")},
+		{Keys: bson.D{{Key: "startDate", Value: 1}}, Options: options.Index().SetName("sub_start")},
+		{Keys: bson.D{{Key: "endDate", Value: 1}}, Options: options.Index().SetName("sub_end")},
+	})
+	must(err)
+
+	// Расписание задач: одна запись на задачу, история запусков хранится 30 дней
+	_, err = d.ScheduledJobs().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_job_name"),
+	})
+	must(err)
+	_, err = d.JobRuns().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "runId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_run_id")},
+		{Keys: bson.D{{Key: "job", Value: 1}, {Key: "startedAt", Value: -1}}, Options: options.Index().SetName("run_job_started")},
+		{Keys: bson.D{{Key: "startedAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(60 * 60 * 24 * 30)).SetName("ttl_runs_30d")},
+	})
+	must(err)
 }
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrateVacancyLifecycle переводит вакансии со старой схемы (active/closed и
+// TTL-индекс на 30 дней) на статусы с явным сроком публикации. Повторный запуск
+// ничего не меняет.
+func (d *Database) migrateVacancyLifecycle(ctx context.Context) error {
+	// TTL-индекс физически удалял вакансии вместе с историей откликов
+	if _, err := d.Vacancies().Indexes().DropOne(ctx, "ttl_vacancies_30d"); err != nil && !isIndexNotFound(err) {
+		return err
+	}
+
+	const term = 30 * 24 * 60 * 60 * 1000 // мс, models.VacancyTerm
+	res, err := d.Vacancies().UpdateMany(ctx, bson.M{"status": "active"}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"status":      "published",
+			"publishedAt": "$createdAt",
+			"expiresAt":   bson.M{"$add": bson.A{"$createdAt", term}},
+		}}},
+	})
+	if err != nil {
+		return err
+	}
+	if res.ModifiedCount > 0 {
+		slog.Info("vacancies moved from active to published", "component", "migrate", "count", res.ModifiedCount)
+	}
+	return nil
+}
+
+// migrateCounters заводит счетчики номеров платежей (InvId) и счетов. Счетчик
+// InvId поднимается выше номеров, которые раньше выдавались по времени, чтобы
+// новые с ними не совпали. Повторный запуск ничего не меняет.
+func (d *Database) migrateCounters(ctx context.Context) error {
+	var last struct {
+		InvID int64 `bson:"invId"`
+	}
+	err := d.Subscriptions().FindOne(ctx, bson.M{},
+		options.FindOne().SetSort(bson.M{"invId": -1}).SetProjection(bson.M{"invId": 1})).Decode(&last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	for name, seq := range map[string]int64{"invId": last.InvID, "invoice": 0} {
+		_, err = d.Counters().UpdateOne(ctx, bson.M{"_id": name},
+			bson.M{"$max": bson.M{"seq": seq}}, options.Update().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migratePromoDuplicates перед созданием уникального индекса оставляет
+// оплаченным только первое применение кода пользователем; остальные
+// помечаются duplicate, а их платежи — для проверки администратором
+func (d *Database) migratePromoDuplicates(ctx context.Context) error {
+	cur, err := d.PromoRedemptions().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": "redeemed"}}},
+		{{Key: "$sort", Value: bson.M{"createdAt": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":  bson.M{"promoId": "$promoId", "userId": "$userId"},
+			"subs": bson.M{"$push": "$subscriptionId"},
+		}}},
+		{{Key: "$match", Value: bson.M{"subs.1": bson.M{"$exists": true}}}},
+	})
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+	var groups []struct {
+		Subs []string `bson:"subs"`
+	}
+	if err := cur.All(ctx, &groups); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, g := range groups {
+		extra := g.Subs[1:]
+		if _, err := d.PromoRedemptions().UpdateMany(ctx, bson.M{"subscriptionId": bson.M{"$in": extra}, "status": "redeemed"},
+			bson.M{"$set": bson.M{"status": "duplicate", "updatedAt": now}}); err != nil {
+			return err
+		}
+		if _, err := d.Subscriptions().UpdateMany(ctx, bson.M{"subscriptionId": bson.M{"$in": extra}},
+			bson.M{"$set": bson.M{"review": "promo_already_used", "updatedAt": now}}); err != nil {
+			return err
+		}
+		slog.Info("repeated promo redemptions flagged", "component", "migrate", "count", len(extra))
+	}
+	return nil
+}
+
+// MigrationDone — разовая миграция name уже выполнена
+func (d *Database) MigrationDone(ctx context.Context, name string) (bool, error) {
+	n, err := d.Migrations().CountDocuments(ctx, bson.M{"_id": name})
+	return n > 0, err
+}
+
+// MarkMigration отмечает разовую миграцию выполненной
+func (d *Database) MarkMigration(ctx context.Context, name string) error {
+	_, err := d.Migrations().UpdateOne(ctx, bson.M{"_id": name},
+		bson.M{"$setOnInsert": bson.M{"doneAt": time.Now().UTC()}}, options.Update().SetUpsert(true))
+	return err
+}
+
+// isIndexNotFound — индекса (или всей коллекции на чистой базе) уже нет
+func isIndexNotFound(err error) bool {
+	var ce mongo.CommandError
+	return errors.As(err, &ce) && (ce.Code == 26 || ce.Code == 27)
+}
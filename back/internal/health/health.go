@@ -0,0 +1,63 @@
+// Package health — проверка готовности сервиса принимать трафик.
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/jobs"
+	"unicorn-auth/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const pingTimeout = 2 * time.Second
+
+// Probe отвечает на /readyz: сервис готов, пока доступна MongoDB и не
+// началась остановка. Состояние фоновых задач сообщается, но на готовность
+// не влияет: упавшая рассылка не повод снимать инстанс с балансировки
+type Probe struct {
+	d        *db.Database
+	sup      *jobs.Supervisor
+	draining atomic.Bool
+}
+
+func NewProbe(d *db.Database, sup *jobs.Supervisor) *Probe {
+	return &Probe{d: d, sup: sup}
+}
+
+// Drain переводит /readyz в 503 перед остановкой сервера
+func (p *Probe) Drain() {
+	p.draining.Store(true)
+}
+
+// Ready — обработчик GET /readyz
+func (p *Probe) Ready(c *gin.Context) {
+	if p.draining.Load() {
+		c.JSON(503, gin.H{"ok": false, "error": "shutting_down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), pingTimeout)
+	defer cancel()
+	mongo := "ok"
+	if err := p.d.Client.Ping(ctx, nil); err != nil {
+		logging.FromContext(c.Request.Context()).Warn("readiness: mongo ping failed", "err", err)
+		mongo = "unavailable"
+	}
+
+	jobStates, healthy := gin.H{}, true
+	for _, st := range p.sup.Statuses() {
+		jobStates[st.Name] = st.State
+		healthy = healthy && st.State == jobs.StateRunning
+	}
+	body := gin.H{"ok": mongo == "ok", "mongo": mongo, "jobsHealthy": healthy, "jobs": jobStates}
+	if mongo != "ok" {
+		body["error"] = "not_ready"
+		c.JSON(503, body)
+		return
+	}
+	c.JSON(200, body)
+}
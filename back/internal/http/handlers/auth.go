@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"unicorn-auth/internal/config"
+	"unicorn-auth/internal/logging"
 	"unicorn-auth/internal/models"
 	"unicorn-auth/internal/repo"
 	"unicorn-auth/internal/security"
@@ -351,6 +352,7 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 		return
 	}
+	h.markActive(c, u.UserID)
 	http.SetCookie(c.Writer, h.refreshCookie(newVal))
 	c.JSON(200, gin.H{"ok": true, "accessToken": access})
 }
@@ -387,10 +389,18 @@ func (h *AuthHandler) issueTokens(c *gin.Context, userID, typ string, amr []stri
 	if err != nil {
 		return "", nil, err
 	}
+	h.markActive(c, userID)
 	val := joinRefresh(s.SessionID, rtok)
 	return access, h.refreshCookie(val), nil
 }
 
+// markActive учитывает вход и обновление сессии в DAU/MAU; ошибка не мешает входу
+func (h *AuthHandler) markActive(c *gin.Context, userID string) {
+	if err := h.sessions.MarkActive(c.Request.Context(), userID); err != nil {
+		logging.FromContext(c.Request.Context()).Error("mark active", "user_id", userID, "err", err)
+	}
+}
+
 func (h *AuthHandler) refreshCookie(value string) *http.Cookie {
 	return &http.Cookie{
 		Name:     "refresh",
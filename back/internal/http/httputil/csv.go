@@ -0,0 +1,18 @@
+package httputil
+
+import (
+	"bytes"
+	"encoding/csv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WriteCSV отдает таблицу файлом; BOM нужен, чтобы Excel открыл UTF-8 без мусора
+func WriteCSV(c *gin.Context, filename string, rows [][]string) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	_ = w.WriteAll(rows)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(200, "text/csv; charset=utf-8", buf.Bytes())
+}
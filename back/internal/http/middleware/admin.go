@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"unicorn-auth/internal/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+const CtxAdminID = "adminId"
+
+// RequireAdmin пропускает только access-токены администратора (typ=admin)
+func RequireAdmin(sec *security.Security) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h := c.GetHeader("Authorization")
+		if !strings.HasPrefix(h, "Bearer ") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "unauthorized"})
+			return
+		}
+		claims, err := sec.Tokens.ParseAccess(strings.TrimSpace(strings.TrimPrefix(h, "Bearer ")))
+		if err != nil || claims.Type != "admin" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "unauthorized"})
+			return
+		}
+		c.Set(CtxAdminID, claims.UserID)
+		c.Next()
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/security"
+	"unicorn-auth/internal/telemetry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyRoutes — маршруты, открытые для ключей API, и нужная область.
+// Остальные маршруты ключ не пропускают.
+var apiKeyRoutes = map[string]string{
+	"GET /api/vacancies/my":                 models.ScopeVacanciesRead,
+	"GET /api/vacancies/export":             models.ScopeVacanciesRead,
+	"POST /api/vacancies/import":            models.ScopeVacanciesWrite,
+	"POST /api/vacancies":                   models.ScopeVacanciesWrite,
+	"PATCH /api/vacancies/:id":              models.ScopeVacanciesWrite,
+	"DELETE /api/vacancies/:id":             models.ScopeVacanciesWrite,
+	"POST /api/vacancies/:id/publish":       models.ScopeVacanciesWrite,
+	"POST /api/vacancies/:id/pause":         models.ScopeVacanciesWrite,
+	"POST /api/vacancies/:id/close":         models.ScopeVacanciesWrite,
+	"POST /api/vacancies/:id/extend":        models.ScopeVacanciesWrite,
+	"POST /api/vacancies/:id/republish":     models.ScopeVacanciesWrite,
+	"GET /api/applications/inbox":           models.ScopeApplicationsRead,
+	"GET /api/applications/export":          models.ScopeApplicationsRead,
+	"GET /api/resumes/snapshots/:id":        models.ScopeApplicationsRead,
+	"GET /api/resumes/snapshots/:id/export": models.ScopeApplicationsRead,
+	"GET /api/analytics/vacancies/:id":      models.ScopeApplicationsRead,
+	"GET /api/analytics/company":            models.ScopeApplicationsRead,
+}
+
+// authAPIKey — ветка RequireAuth для ключей API: проверяет маршрут, ключ, лимит и область
+func authAPIKey(c *gin.Context, sec *security.Security, raw string) {
+	scope, ok := apiKeyRoutes[c.Request.Method+" "+c.FullPath()]
+	if !ok || sec.APIKeys == nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"ok": false, "error": "api_key_not_allowed"})
+		return
+	}
+	p, err := sec.APIKeys.Verify(c.Request.Context(), raw, clientIP(c))
+	switch {
+	case errors.Is(err, security.ErrAPIKeyRateLimited):
+		telemetry.RateLimited("api_key")
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"ok": false, "error": "rate_limited"})
+		return
+	case errors.Is(err, security.ErrAPIKeyInvalid):
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "unauthorized"})
+		return
+	case err != nil:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "server_error"})
+		return
+	}
+	if !hasScope(p.Scopes, scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"ok": false, "error": "insufficient_scope", "scope": scope})
+		return
+	}
+	c.Set(CtxUserID, p.UserID)
+	c.Set(CtxUserType, p.UserType)
+	c.Set(CtxAMR, []string{"apikey"})
+	c.Set(CtxAPIKeyID, p.KeyID)
+	c.Next()
+}
+
+func hasScope(scopes []string, want string) bool {
+	for _, s := range scopes {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
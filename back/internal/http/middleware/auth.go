@@ -13,6 +13,8 @@ const (
 	CtxUserID   = "userId"
 	CtxUserType = "userType"
 	CtxAMR      = "amr"
+	// CtxAPIKeyID задан, если запрос пришел с ключом API, а не с JWT
+	CtxAPIKeyID = "apiKeyId"
 )
 
 func RequireAuth(sec *security.Security) gin.HandlerFunc {
@@ -23,6 +25,10 @@ func RequireAuth(sec *security.Security) gin.HandlerFunc {
 			return
 		}
 		token := strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+		if strings.HasPrefix(token, security.APIKeyPrefix) {
+			authAPIKey(c, sec, token)
+			return
+		}
 		claims, err := sec.Tokens.ParseAccess(token)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "unauthorized"})
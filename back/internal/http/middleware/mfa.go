@@ -11,6 +11,11 @@ import (
 
 func RequireMFAEnabled(_ *security.Security, users *repo.UserRepo) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Ключ API выпускается из кабинета с MFA; статус аккаунта проверен при разборе ключа
+		if c.GetString(CtxAPIKeyID) != "" {
+			c.Next()
+			return
+		}
 		uid := c.GetString(CtxUserID)
 		u, err := users.FindByUserID(c.Request.Context(), uid)
 		if err != nil || u == nil || u.Status.Blocked || u.Status.Deleted {
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+
+	"unicorn-auth/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	CtxOrgID   = "orgId"
+	CtxOrgRole = "orgRole"
+)
+
+// ResolveOrg определяет организацию и роль для пользователей-компаний.
+// Должен идти после RequireAuth; для соискателей ничего не делает.
+func ResolveOrg(orgs *repo.OrgRepo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(CtxUserType) != "company" {
+			c.Next()
+			return
+		}
+		orgID, role, err := orgs.Resolve(c.Request.Context(), c.GetString(CtxUserID))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.Set(CtxOrgID, orgID)
+		c.Set(CtxOrgRole, role)
+		c.Next()
+	}
+}
+
+// RequireOrgRole пропускает только участников организации с одной из ролей
+func RequireOrgRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(CtxOrgRole)
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"ok": false, "error": "forbidden"})
+	}
+}
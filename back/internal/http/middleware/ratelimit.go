@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"unicorn-auth/internal/telemetry"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -56,6 +58,7 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 		rl.mu.Unlock()
 
 		if !allowed {
+			telemetry.RateLimited("ip")
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"ok": false, "error": "rate_limited"})
 			return
 		}
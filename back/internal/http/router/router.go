@@ -1,29 +1,40 @@
 package router
 
 import (
+	"log/slog"
 	"net/http"
 
 	"unicorn-auth/internal/config"
+	"unicorn-auth/internal/health"
 	"unicorn-auth/internal/http/handlers"
 	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/logging"
 	"unicorn-auth/internal/repo"
 	"unicorn-auth/internal/security"
+	"unicorn-auth/internal/telemetry"
 
 	"github.com/gin-gonic/gin"
 )
 
-func New(cfg config.Config, sec *security.Security, users *repo.UserRepo, sessions *repo.SessionRepo, resumes *repo.ResumeRepo, vacancies *repo.VacancyRepo) *gin.Engine {
+func New(cfg config.Config, log *slog.Logger, probe *health.Probe, sec *security.Security, users *repo.UserRepo, sessions *repo.SessionRepo, resumes *repo.ResumeRepo, vacancies *repo.VacancyRepo) *gin.Engine {
 	if cfg.AppEnv == "prod" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	r := gin.New()
-	r.Use(gin.Recovery())
+	// Метрики и трейсы первыми: паника, пойманная Recovery, учитывается как 500.
+	// Логгер запроса с request_id — после трейса, чтобы взять trace_id
+	r.Use(telemetry.Middleware(), logging.Middleware(log), logging.Recovery())
 	//AVAupload
 	r.MaxMultipartMemory = 8 << 20 // 8MB (можешь поменять) ограничение на аватарку
 	r.Static("/uploads", "./uploads")
 
 	// simple health
 	r.GET("/healthz", func(c *gin.Context) { c.JSON(200, gin.H{"ok": true}) })
+	// Готовность: MongoDB и состояние фоновых задач
+	r.GET("/readyz", probe.Ready)
+
+	// Prometheus; вне CORS и ограничения частоты
+	r.GET("/metrics", telemetry.Handler(cfg.MetricsToken))
 
 	// CORS (simple)
 	r.Use(func(c *gin.Context) {
@@ -33,7 +44,8 @@ func New(cfg config.Config, sec *security.Security, users *repo.UserRepo, sessio
 				c.Header("Access-Control-Allow-Origin", origin)
 				c.Header("Vary", "Origin")
 				c.Header("Access-Control-Allow-Credentials", "true")
-				c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type")
+				c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Request-ID")
+				c.Header("Access-Control-Expose-Headers", "X-Request-ID")
 				c.Header("Access-Control-Allow-Methods", "GET,POST,PATCH,DELETE,OPTIONS")
 				break
 			}
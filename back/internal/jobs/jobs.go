@@ -0,0 +1,229 @@
+// Package jobs запускает фоновые задачи под присмотром: перезапускает
+// упавшие, останавливает их при завершении сервиса и хранит статус
+// последнего прохода каждой задачи.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"unicorn-auth/internal/logging"
+)
+
+// Состояния задачи
+const (
+	StateRunning    = "running"
+	StateRestarting = "restarting" // упала и ждет перезапуска
+	StateFailing    = "failing"    // последний проход завершился ошибкой
+	StateStale      = "stale"      // давно не было прохода
+	StateStopped    = "stopped"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+	// staleFactor — сколько интервалов задача может не отчитываться
+	staleFactor = 3
+)
+
+var ErrStopTimeout = errors.New("jobs did not stop in time")
+
+// Run — тело задачи; возвращается после отмены контекста
+type Run func(ctx context.Context)
+
+// Status — состояние задачи и ее последнего прохода
+type Status struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	StartedAt time.Time `json:"startedAt"`
+	Restarts  int       `json:"restarts"`
+	LastCrash string    `json:"lastCrash,omitempty"`
+
+	// Последний проход (для задач с RunOnce)
+	LastRunAt      *time.Time `json:"lastRunAt,omitempty"`
+	LastDurationMs int64      `json:"lastDurationMs,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	LastSuccessAt  *time.Time `json:"lastSuccessAt,omitempty"`
+}
+
+type job struct {
+	interval time.Duration
+	status   Status
+	running  bool
+	stopped  bool
+}
+
+// Supervisor запускает задачи и следит за ними
+type Supervisor struct {
+	log    *slog.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+func New(log *slog.Logger) *Supervisor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Supervisor{
+		log:    log.With("component", "jobs"),
+		ctx:    ctx,
+		cancel: cancel,
+		jobs:   map[string]*job{},
+	}
+}
+
+// Go запускает задачу name. Если run вернулся до остановки или упал с паникой,
+// задача перезапускается с растущей паузой. interval — как часто задача
+// проходит; без прохода дольше staleFactor интервалов она считается stale
+// (0 — не проверять)
+func (s *Supervisor) Go(name string, interval time.Duration, run Run) {
+	s.mu.Lock()
+	j := &job{interval: interval, status: Status{Name: name, StartedAt: time.Now().UTC()}}
+	s.jobs[name] = j
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		backoff := minBackoff
+		for {
+			s.setRunning(j, true, "")
+			started := time.Now()
+			crash := s.runSafe(name, run)
+			if s.ctx.Err() != nil {
+				s.setStopped(j)
+				return
+			}
+			if crash == "" {
+				crash = "returned before stop"
+			}
+			// Долго проработавшая задача начинает с короткой паузы
+			if time.Since(started) > maxBackoff {
+				backoff = minBackoff
+			}
+			s.setRunning(j, false, crash)
+			s.log.Error("job crashed, restarting", "job", name, "reason", crash, "backoff", backoff.String())
+			select {
+			case <-s.ctx.Done():
+				s.setStopped(j)
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+		}
+	}()
+}
+
+// Every запускает периодическую задачу с методом Start(ctx, interval)
+func (s *Supervisor) Every(name string, interval time.Duration, start func(context.Context, time.Duration)) {
+	s.Go(name, interval, func(ctx context.Context) { start(ctx, interval) })
+}
+
+// runSafe выполняет run и возвращает текст паники, если она была
+func (s *Supervisor) runSafe(name string, run Run) (crash string) {
+	defer func() {
+		if p := recover(); p != nil {
+			crash = fmt.Sprintf("panic: %v", p)
+			s.log.Error("job panic", "job", name, "panic", fmt.Sprint(p), "stack", string(debug.Stack()))
+		}
+	}()
+	run(s.ctx)
+	return ""
+}
+
+func (s *Supervisor) setRunning(j *job, running bool, crash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !running {
+		j.status.Restarts++
+		j.status.LastCrash = logging.Redact(crash)
+	}
+	j.running = running
+}
+
+func (s *Supervisor) setStopped(j *job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.running = false
+	j.stopped = true
+}
+
+// Observe записывает итог прохода задачи; подключается через telemetry.ObserveJobs
+func (s *Supervisor) Observe(name string, start time.Time, d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return
+	}
+	at := start.UTC()
+	j.status.LastRunAt = &at
+	j.status.LastDurationMs = d.Milliseconds()
+	j.status.LastError = ""
+	if err != nil {
+		j.status.LastError = logging.Redact(err.Error())
+		return
+	}
+	done := at.Add(d)
+	j.status.LastSuccessAt = &done
+}
+
+// Statuses — состояние всех задач по имени
+func (s *Supervisor) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	out := make([]Status, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		st := j.status
+		st.State = j.state(now)
+		out = append(out, st)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Name < out[b].Name })
+	return out
+}
+
+func (j *job) state(now time.Time) string {
+	switch {
+	case j.stopped:
+		return StateStopped
+	case !j.running:
+		return StateRestarting
+	case j.status.LastError != "":
+		return StateFailing
+	}
+	if j.interval > 0 {
+		last := j.status.StartedAt
+		if j.status.LastRunAt != nil {
+			last = *j.status.LastRunAt
+		}
+		if now.Sub(last) > staleFactor*j.interval+time.Minute {
+			return StateStale
+		}
+	}
+	return StateRunning
+}
+
+// Stop отменяет контекст задач и ждет их завершения не дольше timeout
+func (s *Supervisor) Stop(timeout time.Duration) error {
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return ErrStopTimeout
+	}
+}
@@ -0,0 +1,87 @@
+// Package lifecycle ведет вакансии по статусам: публикует запланированные
+// черновики, переводит просроченные в expired и сообщает о закрытии.
+package lifecycle
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/notify"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/telemetry"
+	"unicorn-auth/internal/webhooks"
+)
+
+// batchSize — сколько вакансий каждого вида обрабатывается за один проход
+const batchSize = 500
+
+type Service struct {
+	vac    *repo.VacancyRepo
+	apps   *repo.ApplicationRepo
+	events *notify.Emitter
+	hooks  *webhooks.Dispatcher
+	log    *slog.Logger
+}
+
+func NewService(vac *repo.VacancyRepo, apps *repo.ApplicationRepo, events *notify.Emitter, hooks *webhooks.Dispatcher, log *slog.Logger) *Service {
+	return &Service{vac: vac, apps: apps, events: events, hooks: hooks, log: log.With("component", "lifecycle")}
+}
+
+// Closed сообщает о закрытой или истекшей вакансии: вебхук vacancy.closed
+// и уведомления соискателям, чьи отклики еще не отклонены
+func (s *Service) Closed(ctx context.Context, v *models.Vacancy) {
+	s.hooks.Publish(ctx, v.CompanyID, webhooks.EventVacancyClosed, webhooks.VacancyData(v))
+
+	apps, err := s.apps.ListByVacancy(ctx, v.VacancyID, "pending", "accepted")
+	if err != nil {
+		s.log.Error("list applicants", "vacancy_id", v.VacancyID, "err", err)
+		return
+	}
+	title := "Вакансия закрыта"
+	if v.Status == models.VacancyExpired {
+		title = "Срок публикации вакансии истек"
+	}
+	for _, a := range apps {
+		s.events.Emit(notify.Event{
+			Type:  notify.EventVacancyClosed,
+			UserID: a.UserID,
+			Title: title,
+			Text:  "Вакансия: " + v.Title,
+			Link:  "/applications/" + a.ApplicationID,
+			Data:  map[string]string{"vacancyId": v.VacancyID, "applicationId": a.ApplicationID, "status": v.Status},
+		})
+	}
+}
+
+// RunOnce публикует запланированные черновики и закрывает просроченные вакансии
+func (s *Service) RunOnce(ctx context.Context) error {
+	job := telemetry.StartJob("lifecycle")
+	defer job.Done()
+	now := time.Now().UTC()
+	for i := 0; i < batchSize && ctx.Err() == nil; i++ {
+		v, err := s.vac.ClaimScheduled(ctx, now)
+		if err != nil {
+			job.Fail(err)
+			s.log.Error("publish scheduled", "err", err)
+			break
+		}
+		if v == nil {
+			break
+		}
+	}
+	for i := 0; i < batchSize && ctx.Err() == nil; i++ {
+		v, err := s.vac.ClaimExpired(ctx, now)
+		if err != nil {
+			job.Fail(err)
+			s.log.Error("expire", "err", err)
+			break
+		}
+		if v == nil {
+			break
+		}
+		s.Closed(ctx, v)
+	}
+	return job.Err()
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader — заголовок с идентификатором запроса в запросе и ответе
+const RequestIDHeader = "X-Request-ID"
+
+// CtxRequestID — ключ gin.Context с идентификатором запроса
+const CtxRequestID = "requestId"
+
+// Идентификатор от прокси принимается, если он короткий и без спецсимволов
+var reRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// Middleware назначает запросу идентификатор (или берет X-Request-ID от прокси),
+// возвращает его в ответе, кладет в контекст логгер с request_id и пишет
+// строку о завершенном запросе. Подключается до gin.Recovery
+func Middleware(base *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		id := c.GetHeader(RequestIDHeader)
+		if !reRequestID.MatchString(id) {
+			id = ulid.Make().String()
+		}
+		c.Set(CtxRequestID, id)
+		c.Header(RequestIDHeader, id)
+
+		l := base.With("request_id", id)
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.HasTraceID() {
+			l = l.With("trace_id", sc.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(WithContext(c.Request.Context(), l))
+
+		c.Next()
+
+		route := c.FullPath()
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case route == "/healthz" || route == "/readyz" || route == "/metrics":
+			// Проверки живости и сбор метрик забивали бы лог
+			level = slog.LevelDebug
+		}
+		// Путь без query: в параметрах бывают токены и подписи
+		l.Log(c.Request.Context(), level, "request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", route,
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"ip", c.ClientIP(),
+		)
+	}
+}
+
+// Recovery отвечает 500 на панику обработчика и пишет ее в лог запроса со стеком
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if p := recover(); p != nil {
+				FromContext(c.Request.Context()).Error("panic",
+					"panic", fmt.Sprint(p),
+					"stack", string(debug.Stack()),
+				)
+				c.AbortWithStatusJSON(500, gin.H{"ok": false, "error": "server_error"})
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,54 @@
+// Package logging — структурированные логи (slog): уровни по окружению,
+// вычищение секретов и идентификатор запроса.
+package logging
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+)
+
+// New создает логгер для окружения APP_ENV: prod — JSON с уровня info,
+// dev — текст с уровня debug, остальные — текст с уровня info.
+// Секреты в сообщениях и атрибутах заменяются на [REDACTED]
+func New(env string, w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: Level(env)}
+	var h slog.Handler
+	if env == "prod" {
+		h = slog.NewJSONHandler(w, opts)
+	} else {
+		h = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(&redactHandler{next: h})
+}
+
+// Level — минимальный уровень записей для окружения
+func Level(env string) slog.Level {
+	if env == "dev" {
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// SetDefault делает l логгером по умолчанию, в том числе для пакета log:
+// сторонние библиотеки тоже проходят через вычищение секретов
+func SetDefault(l *slog.Logger) {
+	slog.SetDefault(l)
+	log.SetFlags(0)
+}
+
+type ctxKey struct{}
+
+// WithContext кладет логгер в контекст
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext — логгер запроса с request_id; вне запроса — логгер по умолчанию
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
@@ -0,0 +1,83 @@
+// Package mail отправляет служебные письма (приглашения и т.п.).
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Sender — способ доставки писем
+type Sender interface {
+	Send(ctx context.Context, m Message) error
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string
+}
+
+// SMTP отправляет письма через SMTP-сервер (STARTTLS, если сервер его поддерживает)
+type SMTP struct{ cfg SMTPConfig }
+
+func NewSMTP(cfg SMTPConfig) *SMTP {
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	return &SMTP{cfg: cfg}
+}
+
+func (s *SMTP) Send(ctx context.Context, m Message) error {
+	if strings.ContainsAny(m.To, "\r\n") {
+		return fmt.Errorf("mail: bad recipient %q", m.To)
+	}
+	var auth smtp.Auth
+	if s.cfg.User != "" {
+		auth = smtp.PlainAuth("", s.cfg.User, s.cfg.Password, s.cfg.Host)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(m.Text, "\n", "\r\n"))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(s.cfg.Host, s.cfg.Port), auth, s.cfg.From, []string{m.To}, []byte(b.String()))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Log пишет письма в лог вместо отправки (локальная разработка).
+// Текст письма — только на уровне debug; ссылки с токенами вычищаются
+type Log struct {
+	Logger *slog.Logger
+}
+
+func (l Log) Send(ctx context.Context, m Message) error {
+	l.Logger.InfoContext(ctx, "email logged instead of sending", "component", "mail", "to", m.To, "subject", m.Subject)
+	l.Logger.DebugContext(ctx, "email text", "component", "mail", "to", m.To, "text", m.Text)
+	return nil
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VacancyView — просмотр карточки вакансии; один посетитель учитывается раз в сутки
+type VacancyView struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+
+	VacancyID string    `bson:"vacancyId" json:"vacancyId"`
+	CompanyID string    `bson:"companyId" json:"companyId"`
+	Day       string    `bson:"day" json:"day"`   // YYYY-MM-DD по UTC
+	Visitor   string    `bson:"visitor" json:"-"` // userId или хеш IP и User-Agent
+	At        time.Time `bson:"at" json:"at"`
+}
+
+// VacancyStats — счетчики воронки. События относятся к дню, когда произошли:
+// отклик — к дню подачи, ответ компании — к дню решения
+type VacancyStats struct {
+	Views        int64 `bson:"views" json:"views"`
+	Applications int64 `bson:"applications" json:"applications"`
+	KnockedOut   int64 `bson:"knockedOut" json:"knockedOut"` // отсечены анкетой
+	Viewed       int64 `bson:"viewed" json:"viewed"`         // компания открыла отклик
+	Responded    int64 `bson:"responded" json:"responded"`   // первое решение компании
+	Rejected     int64 `bson:"rejected" json:"rejected"`
+	Accepted     int64 `bson:"accepted" json:"accepted"`
+
+	// Суммы интервалов от отклика до первого решения и до приглашения, мс
+	ResponseSumMs int64 `bson:"responseSumMs" json:"-"`
+	HireSumMs     int64 `bson:"hireSumMs" json:"-"`
+}
+
+// Add прибавляет счетчики other
+func (s *VacancyStats) Add(other VacancyStats) {
+	s.Views += other.Views
+	s.Applications += other.Applications
+	s.KnockedOut += other.KnockedOut
+	s.Viewed += other.Viewed
+	s.Responded += other.Responded
+	s.Rejected += other.Rejected
+	s.Accepted += other.Accepted
+	s.ResponseSumMs += other.ResponseSumMs
+	s.HireSumMs += other.HireSumMs
+}
+
+// VacancyStatDay — дневной срез статистики вакансии (коллекция vacancy_stats_daily)
+type VacancyStatDay struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+
+	VacancyID    string `bson:"vacancyId" json:"vacancyId,omitempty"`
+	CompanyID    string `bson:"companyId" json:"-"`
+	Day          string `bson:"day" json:"day"`
+	VacancyStats `bson:",inline"`
+
+	UpdatedAt time.Time `bson:"updatedAt" json:"-"`
+}
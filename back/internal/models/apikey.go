@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Области доступа ключей API
+const (
+	ScopeVacanciesRead    = "vacancies:read"
+	ScopeVacanciesWrite   = "vacancies:write"
+	ScopeApplicationsRead = "applications:read"
+)
+
+// APIScopes — все области, которые можно выдать ключу
+var APIScopes = []string{ScopeVacanciesRead, ScopeVacanciesWrite, ScopeApplicationsRead}
+
+// APIKey — ключ для интеграции HR-системы компании. Ключ действует от имени
+// владельца организации в пределах областей; хранится только SHA-256.
+type APIKey struct {
+	KeyID     string   `bson:"keyId" json:"keyId"`
+	CompanyID string   `bson:"companyId" json:"-"` // orgId организации
+	Name      string   `bson:"name" json:"name"`
+	Prefix    string   `bson:"prefix" json:"prefix"` // начало ключа, чтобы узнать его в списке
+	KeyHash   string   `bson:"keyHash" json:"-"`
+	Scopes    []string `bson:"scopes" json:"scopes"`
+	RateLimit int      `bson:"rateLimit" json:"rateLimit"` // запросов в минуту
+
+	LastUsedAt *time.Time `bson:"lastUsedAt,omitempty" json:"lastUsedAt,omitempty"`
+	LastUsedIP string     `bson:"lastUsedIp,omitempty" json:"lastUsedIp,omitempty"`
+	ExpiresAt  *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	RevokedAt  *time.Time `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+
+	CreatedBy string    `bson:"createdBy" json:"-"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
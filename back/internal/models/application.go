@@ -13,12 +13,25 @@ type Application struct {
 	VacancyID     string `bson:"vacancyId" json:"vacancyId"`
 	ResumeID      string `bson:"resumeId" json:"resumeId"`
 
+	// Снимок резюме на момент отклика; у старых откликов пусто
+	SnapshotID string `bson:"snapshotId,omitempty" json:"snapshotId,omitempty"`
+
 	UserID    string `bson:"userId" json:"userId"`
-	CompanyID string `bson:"companyId" json:"companyId"`
+	CompanyID string `bson:"companyId" json:"companyId"` // orgId организации
+
+	// Рекрутер организации, ответственный за кандидата
+	AssigneeID string `bson:"assigneeId,omitempty" json:"assigneeId,omitempty"`
 
 	Status  string `bson:"status" json:"status"` // pending/accepted/rejected
 	Message string `bson:"message,omitempty" json:"message,omitempty"`
 
+	// Сопроводительное письмо
+	CoverLetter string `bson:"coverLetter,omitempty" json:"coverLetter,omitempty"`
+
+	// Ответы на анкету вакансии; KnockedOut — отклонен автоматически по отсекающему вопросу
+	Answers    []ScreeningAnswer `bson:"answers,omitempty" json:"answers,omitempty"`
+	KnockedOut bool              `bson:"knockedOut,omitempty" json:"knockedOut,omitempty"`
+
 	// Hidden state: скрыто ли для пользователя или компании
 	Hidden   Hidden    `bson:"hidden" json:"-"`
 	HiddenAt time.Time `bson:"hiddenAt,omitempty" json:"-"`
@@ -28,6 +41,10 @@ type Application struct {
 
 	Viewed   bool      `bson:"viewed" json:"viewed"`
 	ViewedAt time.Time `bson:"viewedAt,omitempty" json:"viewedAt,omitempty"`
+
+	// Первое решение компании и приглашение — для аналитики времени ответа и найма
+	DecidedAt  time.Time `bson:"decidedAt,omitempty" json:"decidedAt,omitempty"`
+	AcceptedAt time.Time `bson:"acceptedAt,omitempty" json:"acceptedAt,omitempty"`
 }
 
 // Hidden представляет состояние скрытия для пользователя и компании
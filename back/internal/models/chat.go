@@ -14,6 +14,8 @@ type ChatMessage struct {
 
 	SenderID   string `bson:"senderId" json:"senderId"`
 	SenderType string `bson:"senderType" json:"senderType"` // user/company
+	// Имя участника организации, отправившего сообщение от компании
+	SenderName string `bson:"senderName,omitempty" json:"senderName,omitempty"`
 	Text       string `bson:"text" json:"text"`
 
 	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Notification — уведомление в личном кабинете
+type Notification struct {
+	NotificationID string            `bson:"notificationId" json:"notificationId"`
+	UserID         string            `bson:"userId" json:"-"`
+	Type           string            `bson:"type" json:"type"`
+	Title          string            `bson:"title" json:"title"`
+	Text           string            `bson:"text,omitempty" json:"text,omitempty"`
+	Link           string            `bson:"link,omitempty" json:"link,omitempty"`
+	Data           map[string]string `bson:"data,omitempty" json:"data,omitempty"`
+	Read           bool              `bson:"read" json:"read"`
+	CreatedAt      time.Time         `bson:"createdAt" json:"createdAt"`
+}
+
+// NotificationPrefs — настройки уведомлений пользователя.
+// Events: тип события -> включенные каналы; тип без записи отправляется во все каналы.
+type NotificationPrefs struct {
+	UserID    string              `bson:"userId" json:"-"`
+	Email     string              `bson:"email,omitempty" json:"email,omitempty"`
+	Events    map[string][]string `bson:"events,omitempty" json:"events"`
+	UpdatedAt time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
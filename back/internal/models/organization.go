@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Роли участников организации
+const (
+	OrgRoleOwner     = "owner"     // управляет командой, все права
+	OrgRoleRecruiter = "recruiter" // вакансии, отклики, чаты
+	OrgRoleViewer    = "viewer"    // только просмотр
+)
+
+// Organization — компания-работодатель, которой принадлежат вакансии и отклики.
+// OrgID совпадает с userId владельца, поэтому companyId в вакансиях и откликах
+// и есть идентификатор организации.
+type Organization struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+
+	OrgID   string `bson:"orgId" json:"orgId"`
+	Name    string `bson:"name" json:"name"`
+	OwnerID string `bson:"ownerId" json:"ownerId"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"-"`
+}
+
+type OrgMember struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+
+	OrgID  string `bson:"orgId" json:"orgId"`
+	UserID string `bson:"userId" json:"userId"`
+	Role   string `bson:"role" json:"role"`
+	Email  string `bson:"email,omitempty" json:"email,omitempty"`
+
+	InvitedBy string    `bson:"invitedBy,omitempty" json:"invitedBy,omitempty"`
+	JoinedAt  time.Time `bson:"joinedAt" json:"joinedAt"`
+}
+
+type OrgInvite struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+
+	InviteID  string `bson:"inviteId" json:"inviteId"`
+	OrgID     string `bson:"orgId" json:"orgId"`
+	Email     string `bson:"email" json:"email"`
+	Role      string `bson:"role" json:"role"`
+	TokenHash string `bson:"tokenHash" json:"-"`
+
+	Status     string    `bson:"status" json:"status"` // pending/accepted/revoked
+	InvitedBy  string    `bson:"invitedBy" json:"invitedBy"`
+	AcceptedBy string    `bson:"acceptedBy,omitempty" json:"acceptedBy,omitempty"`
+	ExpiresAt  time.Time `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt  time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time `bson:"updatedAt" json:"-"`
+}
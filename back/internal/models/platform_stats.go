@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Периоды статистики платформы
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week" // ISO-неделя с понедельника
+	PeriodMonth = "month"
+)
+
+// PlatformCounters — показатели платформы за период
+type PlatformCounters struct {
+	UsersRegistered     int64 `bson:"usersRegistered" json:"usersRegistered"`
+	CompaniesRegistered int64 `bson:"companiesRegistered" json:"companiesRegistered"`
+	// Уникальные пользователи со входом или обновлением сессии за период;
+	// MAU — за 30 дней по последний день, только у дневных срезов
+	ActiveUsers int64 `bson:"activeUsers" json:"activeUsers"`
+	MAU         int64 `bson:"mau,omitempty" json:"mau,omitempty"`
+
+	VacanciesCreated int64 `bson:"vacanciesCreated" json:"vacanciesCreated"`
+	Applications     int64 `bson:"applications" json:"applications"`
+
+	// Платежи по дате создания: оплачены, отменены или еще ждут оплаты
+	Payments          int64 `bson:"payments" json:"payments"`
+	PaymentsSucceeded int64 `bson:"paymentsSucceeded" json:"paymentsSucceeded"`
+	PaymentsFailed    int64 `bson:"paymentsFailed" json:"paymentsFailed"`
+
+	// Выручка по дате оплаты и возвраты по дате возврата, рубли
+	PaidSubscriptions int64   `bson:"paidSubscriptions" json:"paidSubscriptions"`
+	Revenue           float64 `bson:"revenue" json:"revenue"`
+	Refunded          float64 `bson:"refunded" json:"refunded"`
+	TrialsStarted     int64   `bson:"trialsStarted" json:"trialsStarted"`
+
+	// Подписчики на начало периода и ушедшие: подписка закончилась в периоде и не продлена
+	SubscribersStart int64 `bson:"subscribersStart" json:"subscribersStart"`
+	Churned          int64 `bson:"churned" json:"churned"`
+
+	// Модерация заявок на проверку компании
+	VerificationsSubmitted int64 `bson:"verificationsSubmitted" json:"verificationsSubmitted"`
+	VerificationsApproved  int64 `bson:"verificationsApproved" json:"verificationsApproved"`
+	VerificationsRejected  int64 `bson:"verificationsRejected" json:"verificationsRejected"`
+}
+
+// Add прибавляет счетчики other
+func (p *PlatformCounters) Add(other PlatformCounters) {
+	p.UsersRegistered += other.UsersRegistered
+	p.CompaniesRegistered += other.CompaniesRegistered
+	p.ActiveUsers += other.ActiveUsers
+	p.MAU += other.MAU
+	p.VacanciesCreated += other.VacanciesCreated
+	p.Applications += other.Applications
+	p.Payments += other.Payments
+	p.PaymentsSucceeded += other.PaymentsSucceeded
+	p.PaymentsFailed += other.PaymentsFailed
+	p.PaidSubscriptions += other.PaidSubscriptions
+	p.Revenue += other.Revenue
+	p.Refunded += other.Refunded
+	p.TrialsStarted += other.TrialsStarted
+	p.SubscribersStart += other.SubscribersStart
+	p.Churned += other.Churned
+	p.VerificationsSubmitted += other.VerificationsSubmitted
+	p.VerificationsApproved += other.VerificationsApproved
+	p.VerificationsRejected += other.VerificationsRejected
+}
+
+// PlatformStat — срез статистики платформы (коллекция platform_stats).
+// Bucket: 2026-10-19 для дня, 2026-W42 для недели, 2026-10 для месяца
+type PlatformStat struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+
+	Period           string `bson:"period" json:"-"`
+	Bucket           string `bson:"bucket" json:"bucket"`
+	PlatformCounters `bson:",inline"`
+
+	UpdatedAt time.Time `bson:"updatedAt" json:"-"`
+}
+
+// UserActivity — пользователь входил или обновлял сессию в этот день
+type UserActivity struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+
+	UserID string    `bson:"userId"`
+	Day    string    `bson:"day"`  // YYYY-MM-DD по UTC
+	Date   time.Time `bson:"date"` // начало дня
+}
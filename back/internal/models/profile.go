@@ -24,4 +24,26 @@ type Profile struct {
 	UpdatedAt time.Time `bson:"updatedAt" json:"-"`
 
 	AvatarURL string `bson:"avatarUrl,omitempty" json:"avatarUrl,omitempty"`
+
+	// Значок проверенной компании; выставляется только через подтверждение
+	Verified       bool      `bson:"verified,omitempty" json:"verified"`
+	VerifiedMethod string    `bson:"verifiedMethod,omitempty" json:"verifiedMethod,omitempty"`
+	VerifiedDomain string    `bson:"verifiedDomain,omitempty" json:"verifiedDomain,omitempty"`
+	VerifiedAt     time.Time `bson:"verifiedAt,omitempty" json:"verifiedAt,omitempty"`
+
+	// Реквизиты для счетов и чеков; в публичном профиле не отдаются
+	Requisites *Requisites `bson:"requisites,omitempty" json:"requisites,omitempty"`
+}
+
+// Requisites — юридические и банковские реквизиты организации/ИП
+type Requisites struct {
+	LegalName   string `bson:"legalName" json:"legalName"`
+	INN         string `bson:"inn" json:"inn"`
+	KPP         string `bson:"kpp,omitempty" json:"kpp,omitempty"`
+	OGRN        string `bson:"ogrn,omitempty" json:"ogrn,omitempty"`
+	Address     string `bson:"address,omitempty" json:"address,omitempty"`
+	BankName    string `bson:"bankName,omitempty" json:"bankName,omitempty"`
+	BIK         string `bson:"bik,omitempty" json:"bik,omitempty"`
+	Account     string `bson:"account,omitempty" json:"account,omitempty"`
+	CorrAccount string `bson:"corrAccount,omitempty" json:"corrAccount,omitempty"`
 }
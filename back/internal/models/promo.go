@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PromoCode struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+
+	PromoID  string `bson:"promoId" json:"promoId"`
+	Code     string `bson:"code" json:"code"`
+	CodeNorm string `bson:"codeNorm" json:"-"`
+
+	Type  string  `bson:"type" json:"type"`   // percent/fixed
+	Value float64 `bson:"value" json:"value"` // проценты (1..100) или рубли
+
+	MaxUses   int64     `bson:"maxUses" json:"maxUses"` // 0 = без ограничения
+	UsedCount int64     `bson:"usedCount" json:"usedCount"`
+	ExpiresAt time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+
+	// Ограничения: пусто = для всех
+	UserType UserType `bson:"userType,omitempty" json:"userType,omitempty"`
+	Plans    []string `bson:"plans,omitempty" json:"plans,omitempty"`
+
+	Active    bool      `bson:"active" json:"active"`
+	CreatedBy string    `bson:"createdBy" json:"createdBy"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// PromoRedemption — применение промокода к платежу.
+// reserved — платеж создан, redeemed — оплачен, released — платеж не состоялся,
+// duplicate — оплачен, но пользователь уже оплатил с этим кодом другой платеж.
+type PromoRedemption struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+
+	RedemptionID   string `bson:"redemptionId" json:"redemptionId"`
+	PromoID        string `bson:"promoId" json:"promoId"`
+	Code           string `bson:"code" json:"code"`
+	UserID         string `bson:"userId" json:"userId"`
+	SubscriptionID string `bson:"subscriptionId" json:"subscriptionId"`
+
+	// Суммы в рублях, как Subscription.Amount
+	OriginalAmount float64 `bson:"originalAmount" json:"originalAmount"`
+	Discount       float64 `bson:"discount" json:"discount"`
+	Amount         float64 `bson:"amount" json:"amount"`
+
+	Status string `bson:"status" json:"status"` // reserved/redeemed/released/duplicate
+	// До этого времени резерв ждет оплаты
+	ExpiresAt *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time  `bson:"updatedAt" json:"updatedAt"`
+}
@@ -20,6 +20,9 @@ type Resume struct {
 	IsPremium bool   `bson:"isPremium" json:"isPremium"`
 	ColorCode string `bson:"colorCode,omitempty" json:"colorCode,omitempty"` // hex color for premium highlighting
 
+	// Растет при каждом изменении содержимого; отклик фиксирует снимок версии
+	Version int `bson:"version" json:"version"`
+
 	Status    string    `bson:"status" json:"status"` // active/hidden
 	CreatedAt time.Time `bson:"createdAt" json:"-"`
 	UpdatedAt time.Time `bson:"updatedAt" json:"-"`
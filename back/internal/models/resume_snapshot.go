@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// ResumeSnapshot — неизменяемая копия резюме в том виде, в каком его получила
+// компания при отклике. Одна версия резюме — один снимок, его делят все отклики.
+type ResumeSnapshot struct {
+	SnapshotID string `bson:"snapshotId" json:"snapshotId"`
+	ResumeID   string `bson:"resumeId" json:"resumeId"`
+	UserID     string `bson:"userId" json:"userId"`
+	Version    int    `bson:"version" json:"version"`
+
+	Title  string   `bson:"title" json:"title"`
+	About  string   `bson:"about" json:"about"`
+	Skills []string `bson:"skills,omitempty" json:"skills,omitempty"`
+	Links  []string `bson:"links,omitempty" json:"links,omitempty"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// FieldChange — старое и новое значение поля
+type FieldChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ResumeDiff — что изменилось в резюме после отклика
+type ResumeDiff struct {
+	Deleted       bool         `json:"deleted,omitempty"`
+	Version       int          `json:"version,omitempty"` // текущая версия резюме
+	Title         *FieldChange `json:"title,omitempty"`
+	About         *FieldChange `json:"about,omitempty"`
+	SkillsAdded   []string     `json:"skillsAdded,omitempty"`
+	SkillsRemoved []string     `json:"skillsRemoved,omitempty"`
+	LinksAdded    []string     `json:"linksAdded,omitempty"`
+	LinksRemoved  []string     `json:"linksRemoved,omitempty"`
+}
+
+// Diff сравнивает снимок с текущим резюме; cur == nil — резюме удалено
+func (s *ResumeSnapshot) Diff(cur *Resume) ResumeDiff {
+	if cur == nil {
+		return ResumeDiff{Deleted: true}
+	}
+	d := ResumeDiff{Version: cur.Version}
+	if s.Title != cur.Title {
+		d.Title = &FieldChange{From: s.Title, To: cur.Title}
+	}
+	if s.About != cur.About {
+		d.About = &FieldChange{From: s.About, To: cur.About}
+	}
+	d.SkillsAdded, d.SkillsRemoved = listDiff(s.Skills, cur.Skills)
+	d.LinksAdded, d.LinksRemoved = listDiff(s.Links, cur.Links)
+	return d
+}
+
+func listDiff(from, to []string) (added, removed []string) {
+	had := make(map[string]bool, len(from))
+	for _, v := range from {
+		had[v] = true
+	}
+	has := make(map[string]bool, len(to))
+	for _, v := range to {
+		has[v] = true
+		if !had[v] {
+			added = append(added, v)
+		}
+	}
+	for _, v := range from {
+		if !has[v] {
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// CompanyReview — отзыв соискателя о работодателе.
+// Оставить отзыв может только пользователь, откликавшийся в компанию.
+type CompanyReview struct {
+	ReviewID  string `bson:"reviewId" json:"reviewId"`
+	CompanyID string `bson:"companyId" json:"companyId"` // orgId организации
+	UserID    string `bson:"userId" json:"-"`
+
+	// Имя автора на момент публикации; у анонимных отзывов не отдается
+	AuthorName string `bson:"authorName,omitempty" json:"authorName,omitempty"`
+	Anonymous  bool   `bson:"anonymous" json:"anonymous"`
+	// Кандидата приняли по одному из откликов
+	WasAccepted bool `bson:"wasAccepted" json:"wasAccepted"`
+
+	Rating int    `bson:"rating" json:"rating"` // 1..5
+	Text   string `bson:"text" json:"text"`
+
+	Reply *ReviewReply `bson:"reply,omitempty" json:"reply,omitempty"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// ReviewReply — ответ компании на отзыв
+type ReviewReply struct {
+	Text       string    `bson:"text" json:"text"`
+	AuthorID   string    `bson:"authorId" json:"-"`
+	AuthorName string    `bson:"authorName,omitempty" json:"authorName,omitempty"`
+	CreatedAt  time.Time `bson:"createdAt" json:"createdAt"`
+}
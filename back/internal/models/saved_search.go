@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Частота рассылки по сохраненному поиску
+const (
+	AlertInstant = "instant"
+	AlertDaily   = "daily"
+	AlertWeekly  = "weekly"
+)
+
+// SavedSearch — сохраненный поиск вакансий с подпиской на новые результаты
+type SavedSearch struct {
+	SearchID string `bson:"searchId" json:"searchId"`
+	UserID   string `bson:"userId" json:"-"`
+	Name     string `bson:"name" json:"name"`
+
+	Query     string   `bson:"query,omitempty" json:"query,omitempty"`
+	Tags      []string `bson:"tags,omitempty" json:"tags,omitempty"`
+	Location  string   `bson:"location,omitempty" json:"location,omitempty"`
+	SalaryMin int64    `bson:"salaryMin,omitempty" json:"salaryMin,omitempty"`
+
+	Frequency string   `bson:"frequency" json:"frequency"` // instant/daily/weekly
+	Channels  []string `bson:"channels" json:"channels"`   // email, inapp
+	Email     string   `bson:"email,omitempty" json:"email,omitempty"`
+	Active    bool     `bson:"active" json:"active"`
+
+	// Вакансии, созданные до этого момента, уже просмотрены воркером
+	CheckedUntil time.Time `bson:"checkedUntil" json:"-"`
+	NextRunAt    time.Time `bson:"nextRunAt" json:"nextRunAt"`
+	LastSentAt   time.Time `bson:"lastSentAt,omitempty" json:"lastSentAt,omitempty"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// AlertSent — вакансия, уже отправленная пользователю по одному из его поисков
+type AlertSent struct {
+	UserID    string    `bson:"userId" json:"-"`
+	VacancyID string    `bson:"vacancyId" json:"vacancyId"`
+	SearchID  string    `bson:"searchId" json:"searchId"`
+	SentAt    time.Time `bson:"sentAt" json:"sentAt"`
+}
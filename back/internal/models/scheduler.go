@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Итоги запуска задачи по расписанию
+const (
+	JobRunRunning   = "running"
+	JobRunOK        = "ok"
+	JobRunError     = "error"
+	JobRunAbandoned = "abandoned" // инстанс пропал, не завершив запуск
+)
+
+// Причины запуска
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+// ScheduledJob — задача с cron-расписанием. Запись общая для всех инстансов:
+// запуск забирается атомарно с арендой (lockedUntil), поэтому задача
+// выполняется одним инстансом за раз
+type ScheduledJob struct {
+	Name            string    `bson:"name" json:"name"`
+	Schedule        string    `bson:"schedule" json:"schedule"`               // cron, UTC
+	DefaultSchedule string    `bson:"defaultSchedule" json:"defaultSchedule"` // из кода
+	Paused          bool      `bson:"paused" json:"paused"`
+	NextRunAt       time.Time `bson:"nextRunAt" json:"nextRunAt"`
+
+	// Ручной запуск из админки ждет, пока его заберет инстанс
+	RunRequestedAt *time.Time `bson:"runRequestedAt,omitempty" json:"runRequestedAt,omitempty"`
+	RunRequestedBy string     `bson:"runRequestedBy,omitempty" json:"runRequestedBy,omitempty"`
+
+	// Текущий запуск и его аренда; инстанс продлевает ее, пока работает
+	RunID       string     `bson:"runId,omitempty" json:"runId,omitempty"`
+	LockedBy    string     `bson:"lockedBy,omitempty" json:"lockedBy,omitempty"`
+	LockedUntil *time.Time `bson:"lockedUntil,omitempty" json:"lockedUntil,omitempty"`
+
+	LastRunAt      *time.Time `bson:"lastRunAt,omitempty" json:"lastRunAt,omitempty"`
+	LastStatus     string     `bson:"lastStatus,omitempty" json:"lastStatus,omitempty"`
+	LastError      string     `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	LastDurationMs int64      `bson:"lastDurationMs,omitempty" json:"lastDurationMs,omitempty"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// JobRun — один запуск задачи по расписанию
+type JobRun struct {
+	RunID       string     `bson:"runId" json:"runId"`
+	Job         string     `bson:"job" json:"job"`
+	Instance    string     `bson:"instance" json:"instance"`
+	Trigger     string     `bson:"trigger" json:"trigger"`
+	TriggeredBy string     `bson:"triggeredBy,omitempty" json:"triggeredBy,omitempty"` // userId админа
+	Status      string     `bson:"status" json:"status"`
+	Error       string     `bson:"error,omitempty" json:"error,omitempty"`
+	StartedAt   time.Time  `bson:"startedAt" json:"startedAt"`
+	FinishedAt  *time.Time `bson:"finishedAt,omitempty" json:"finishedAt,omitempty"`
+	DurationMs  int64      `bson:"durationMs,omitempty" json:"durationMs,omitempty"`
+}
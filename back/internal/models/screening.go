@@ -0,0 +1,56 @@
+package models
+
+// Типы вопросов анкеты вакансии
+const (
+	QuestionYesNo  = "yes_no"
+	QuestionChoice = "single_choice"
+	QuestionNumber = "number"
+	QuestionText   = "text"
+)
+
+// Question — вопрос анкеты, которую соискатель заполняет при отклике
+type Question struct {
+	ID       string   `bson:"id" json:"id"`
+	Type     string   `bson:"type" json:"type"`
+	Text     string   `bson:"text" json:"text"`
+	Required bool     `bson:"required" json:"required"`
+	Options  []string `bson:"options,omitempty" json:"options,omitempty"` // для single_choice
+
+	// Допустимый ввод для number
+	Min *float64 `bson:"min,omitempty" json:"min,omitempty"`
+	Max *float64 `bson:"max,omitempty" json:"max,omitempty"`
+
+	// Отсекающее условие; соискателям не показывается
+	Knockout *Knockout `bson:"knockout,omitempty" json:"knockout,omitempty"`
+}
+
+// Knockout — ответ вне условия отклоняет отклик автоматически
+type Knockout struct {
+	Yes     *bool    `bson:"yes,omitempty" json:"yes,omitempty"`         // yes_no: нужный ответ
+	Options []string `bson:"options,omitempty" json:"options,omitempty"` // single_choice: подходящие варианты
+	Min     *float64 `bson:"min,omitempty" json:"min,omitempty"`         // number: подходящий диапазон
+	Max     *float64 `bson:"max,omitempty" json:"max,omitempty"`
+}
+
+// ScreeningAnswer — ответ соискателя; текст вопроса копируется на момент отклика
+type ScreeningAnswer struct {
+	QuestionID string   `bson:"questionId" json:"questionId"`
+	Question   string   `bson:"question" json:"question"`
+	Type       string   `bson:"type" json:"type"`
+	Value      string   `bson:"value" json:"value"` // yes/no, вариант, число или текст
+	Number     *float64 `bson:"number,omitempty" json:"number,omitempty"`
+	KnockedOut bool     `bson:"knockedOut,omitempty" json:"knockedOut,omitempty"`
+}
+
+// HideKnockouts убирает отсекающие условия из вакансии перед показом соискателям
+func (v *Vacancy) HideKnockouts() {
+	if len(v.Questions) == 0 {
+		return
+	}
+	qs := make([]Question, len(v.Questions))
+	for i, q := range v.Questions {
+		q.Knockout = nil
+		qs[i] = q
+	}
+	v.Questions = qs
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Категории навыков
+var SkillCategories = []string{
+	"language", "framework", "database", "devops", "cloud", "data",
+	"mobile", "design", "tool", "management", "other",
+}
+
+// Skill — навык или тег из управляемого справочника. Теги вакансий и навыки
+// резюме при записи приводятся к Name по любому из написаний в Keys.
+type Skill struct {
+	SkillID  string   `bson:"skillId" json:"skillId"`
+	Name     string   `bson:"name" json:"name"`
+	Synonyms []string `bson:"synonyms" json:"synonyms"`
+	Category string   `bson:"category" json:"category"`
+
+	// Ключи нормализации имени и синонимов; каждый ключ принадлежит одному навыку
+	Keys []string `bson:"keys" json:"-"`
+
+	// Сколько вакансий и резюме используют навык; пересчитывается в фоне
+	Usage int64 `bson:"usage" json:"usage"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
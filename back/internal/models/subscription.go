@@ -15,7 +15,17 @@ type Subscription struct {
 	Amount   float64 `bson:"amount" json:"amount"`
 	Currency string  `bson:"currency" json:"currency"`
 
-	Status string `bson:"status" json:"status"` // pending/paid/cancelled
+	Status string `bson:"status" json:"status"` // pending/paid/trial/cancelled/refunded/partially_refunded
+	Plan   string `bson:"plan,omitempty" json:"plan,omitempty"`
+
+	// Промокод: OutSum — уже со скидкой, OriginalSum — цена до скидки
+	PromoCode   string `bson:"promoCode,omitempty" json:"promoCode,omitempty"`
+	OriginalSum string `bson:"originalSum,omitempty" json:"originalSum,omitempty"`
+	Discount    string `bson:"discount,omitempty" json:"discount,omitempty"`
+
+	// Платежный провайдер (robokassa/yookassa/fake); пусто у старых записей = robokassa
+	Provider   string `bson:"provider,omitempty" json:"provider,omitempty"`
+	ExternalID string `bson:"externalId,omitempty" json:"externalId,omitempty"`
 
 	// Robokassa fields
 	InvID  int64  `bson:"invId" json:"invId"`
@@ -24,6 +34,52 @@ type Subscription struct {
 	StartDate time.Time `bson:"startDate,omitempty" json:"startDate,omitempty"`
 	EndDate   time.Time `bson:"endDate,omitempty" json:"endDate,omitempty"`
 
+	// Счет и данные чека (54-ФЗ), зафиксированные при создании платежа
+	InvoiceNo string         `bson:"invoiceNo,omitempty" json:"invoiceNo,omitempty"`
+	Buyer     *BillingParty  `bson:"buyer,omitempty" json:"buyer,omitempty"`
+	Receipt   *FiscalReceipt `bson:"receipt,omitempty" json:"receipt,omitempty"`
+
+	// Платеж ждет решения администратора (возврат или проверка), например
+	// promo_already_used — скидка по коду, который пользователь уже использовал
+	Review string `bson:"review,omitempty" json:"review,omitempty"`
+
+	RefundedSum string               `bson:"refundedSum,omitempty" json:"refundedSum,omitempty"`
+	Refunds     []SubscriptionRefund `bson:"refunds,omitempty" json:"refunds,omitempty"`
+	// Сумма возврата, запрошенного у провайдера и еще не записанного;
+	// пока она задана, новый возврат не начинается
+	RefundPending   string     `bson:"refundPending,omitempty" json:"refundPending,omitempty"`
+	RefundPendingAt *time.Time `bson:"refundPendingAt,omitempty" json:"refundPendingAt,omitempty"`
+
 	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
 	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
 }
+
+// SubscriptionRefund — возврат (полный или частичный), выполненный администратором
+type SubscriptionRefund struct {
+	RefundID  string    `bson:"refundId" json:"refundId"`
+	Amount    string    `bson:"amount" json:"amount"`
+	AdminID   string    `bson:"adminId" json:"adminId"`
+	Reason    string    `bson:"reason,omitempty" json:"reason,omitempty"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// BillingParty — покупатель на момент выставления счета
+type BillingParty struct {
+	DisplayName string      `bson:"displayName" json:"displayName"`
+	Requisites  *Requisites `bson:"requisites,omitempty" json:"requisites,omitempty"`
+}
+
+// FiscalReceipt — данные чека в формате параметра Receipt Robokassa
+type FiscalReceipt struct {
+	Sno   string        `bson:"sno,omitempty" json:"sno,omitempty"` // система налогообложения
+	Items []ReceiptItem `bson:"items" json:"items"`
+}
+
+type ReceiptItem struct {
+	Name          string  `bson:"name" json:"name"`
+	Quantity      float64 `bson:"quantity" json:"quantity"`
+	Sum           float64 `bson:"sum" json:"sum"`
+	PaymentMethod string  `bson:"paymentMethod" json:"payment_method"`
+	PaymentObject string  `bson:"paymentObject" json:"payment_object"`
+	Tax           string  `bson:"tax" json:"tax"` // none/vat0/vat10/vat20/vat110/vat120
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// TelegramLink — привязка аккаунта к чату с ботом; один чат — один аккаунт
+type TelegramLink struct {
+	UserID   string    `bson:"userId" json:"-"`
+	ChatID   int64     `bson:"chatId" json:"-"`
+	Username string    `bson:"username,omitempty" json:"username,omitempty"`
+	LinkedAt time.Time `bson:"linkedAt" json:"linkedAt"`
+}
+
+// TelegramCode — одноразовый код привязки; хранится только хеш
+type TelegramCode struct {
+	CodeHash  string    `bson:"codeHash"`
+	UserID    string    `bson:"userId"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+// TelegramMessageRef связывает отправленное ботом сообщение с откликом,
+// чтобы ответ на него в Telegram попал в чат по этому отклику
+type TelegramMessageRef struct {
+	ChatID        int64     `bson:"chatId"`
+	MessageID     int64     `bson:"messageId"`
+	ApplicationID string    `bson:"applicationId"`
+	CreatedAt     time.Time `bson:"createdAt"`
+}
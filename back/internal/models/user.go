@@ -35,6 +35,8 @@ type User struct {
 	Subscription struct {
 		Active bool      `bson:"active" json:"-"`
 		Until  time.Time `bson:"until,omitempty" json:"-"`
+		// Пробный период выдается один раз
+		TrialUsed bool `bson:"trialUsed,omitempty" json:"-"`
 	} `bson:"subscription" json:"-"`
 
 	CreatedAt time.Time `bson:"createdAt" json:"-"`
@@ -10,17 +10,86 @@ type Vacancy struct {
 	ID primitive.ObjectID `bson:"_id,omitempty" json:"-"`
 
 	VacancyID string `bson:"vacancyId" json:"vacancyId"`
-	CompanyID string `bson:"companyId" json:"companyId"`
+	CompanyID string `bson:"companyId" json:"companyId"` // orgId организации
+	CreatedBy string `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	// Идентификатор вакансии в HR-системе компании; по нему импорт обновляет, а не дублирует
+	ExternalID string `bson:"externalId,omitempty" json:"externalId,omitempty"`
 
 	Title       string   `bson:"title" json:"title"`
 	Description string   `bson:"description" json:"description"`
 	Location    string   `bson:"location,omitempty" json:"location,omitempty"`
 	Tags        []string `bson:"tags,omitempty" json:"tags,omitempty"`
 
+	// Вилка зарплаты в рублях; 0 — не указано
+	SalaryFrom int64 `bson:"salaryFrom,omitempty" json:"salaryFrom,omitempty"`
+	SalaryTo   int64 `bson:"salaryTo,omitempty" json:"salaryTo,omitempty"`
+
 	IsPremium bool   `bson:"isPremium" json:"isPremium"`
 	ColorCode string `bson:"colorCode,omitempty" json:"colorCode,omitempty"` // hex color for premium highlighting
 
-	Status    string    `bson:"status" json:"status"` // active/closed
+	CompanyVerified bool `bson:"companyVerified,omitempty" json:"companyVerified"` // копия значка из профиля компании
+
+	// Анкета для откликов; см. screening.go
+	Questions []Question `bson:"questions,omitempty" json:"questions,omitempty"`
+
+	Status string `bson:"status" json:"status"` // draft/published/paused/closed/expired
+
+	// Черновик с publishAt публикуется автоматически в это время
+	PublishAt   *time.Time `bson:"publishAt,omitempty" json:"publishAt,omitempty"`
+	PublishedAt *time.Time `bson:"publishedAt,omitempty" json:"publishedAt,omitempty"`
+	// После expiresAt вакансия переходит в expired и пропадает из поиска, но не удаляется
+	ExpiresAt *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	ClosedAt  *time.Time `bson:"closedAt,omitempty" json:"closedAt,omitempty"`
+
 	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
 	UpdatedAt time.Time `bson:"updatedAt" json:"-"`
 }
+
+// Статусы вакансии
+const (
+	VacancyDraft     = "draft"
+	VacancyPublished = "published"
+	VacancyPaused    = "paused" // снята с поиска компанией, срок публикации идет
+	VacancyClosed    = "closed" // закрыта компанией
+	VacancyExpired   = "expired"
+)
+
+// VacancySlotStatuses — статусы, которые занимают место в лимите тарифа
+// (вместе с запланированными черновиками)
+var VacancySlotStatuses = []string{VacancyPublished, VacancyPaused}
+
+// Open — вакансия видна в поиске и принимает отклики
+func (v *Vacancy) Open(now time.Time) bool {
+	return v.Status == VacancyPublished && (v.ExpiresAt == nil || v.ExpiresAt.After(now))
+}
+
+// Сроки публикации: 30 дней; с подпиской продлевается до 90 дней вперед
+const (
+	VacancyTerm           = 30 * 24 * time.Hour
+	VacancyMaxTermPremium = 90 * 24 * time.Hour
+)
+
+func VacancyMaxTerm(premium bool) time.Duration {
+	if premium {
+		return VacancyMaxTermPremium
+	}
+	return VacancyTerm
+}
+
+// Лимиты активных вакансий организации по тарифу
+const (
+	VacancyLimitFree    = 2
+	VacancyLimitPremium = 16
+)
+
+func VacancyLimit(premium bool) int64 {
+	if premium {
+		return VacancyLimitPremium
+	}
+	return VacancyLimitFree
+}
+
+// ValidSalary — вилка неотрицательная, и «до» не меньше «от»
+func ValidSalary(from, to int64) bool {
+	return from >= 0 && to >= 0 && (to == 0 || from <= to)
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// VacancyFeed — XML-фид вакансий компании, который периодически импортируется
+type VacancyFeed struct {
+	CompanyID string `bson:"companyId" json:"-"` // orgId организации
+	URL       string `bson:"url" json:"url"`
+	Active    bool   `bson:"active" json:"active"`
+	CreatedBy string `bson:"createdBy" json:"-"` // от его имени создаются вакансии
+
+	NextRunAt  time.Time     `bson:"nextRunAt" json:"nextRunAt"`
+	LastRunAt  *time.Time    `bson:"lastRunAt,omitempty" json:"lastRunAt,omitempty"`
+	LastError  string        `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	LastResult *ImportResult `bson:"lastResult,omitempty" json:"lastResult,omitempty"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// ImportResult — итог импорта вакансий
+type ImportResult struct {
+	DryRun    bool              `bson:"dryRun" json:"dryRun"`
+	Total     int               `bson:"total" json:"total"`
+	Created   int               `bson:"created" json:"created"`
+	Updated   int               `bson:"updated" json:"updated"`
+	Unchanged int               `bson:"unchanged" json:"unchanged"`
+	Failed    int               `bson:"failed" json:"failed"`
+	Rows      []ImportRowResult `bson:"rows,omitempty" json:"rows"`
+}
+
+// ImportRowResult — что стало с одной строкой файла
+type ImportRowResult struct {
+	Row        int      `bson:"row" json:"row"`
+	ExternalID string   `bson:"externalId,omitempty" json:"externalId,omitempty"`
+	Action     string   `bson:"action" json:"action"` // created/updated/unchanged/error
+	VacancyID  string   `bson:"vacancyId,omitempty" json:"vacancyId,omitempty"`
+	Errors     []string `bson:"errors,omitempty" json:"errors,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Способы подтверждения компании
+const (
+	VerifyMethodDNS       = "dns"       // TXT-запись на домене
+	VerifyMethodFile      = "file"      // файл в /.well-known
+	VerifyMethodDocuments = "documents" // ИНН/ОГРН, проверяет администратор
+)
+
+// CompanyVerification — заявка компании на значок «Проверенная компания»
+type CompanyVerification struct {
+	VerificationID string `bson:"verificationId" json:"verificationId"`
+	CompanyID      string `bson:"companyId" json:"companyId"` // orgId организации
+	Method         string `bson:"method" json:"method"`
+	Status         string `bson:"status" json:"status"` // pending/verified/rejected
+
+	// Для dns/file
+	Domain string `bson:"domain,omitempty" json:"domain,omitempty"`
+	Token  string `bson:"token,omitempty" json:"token,omitempty"`
+
+	// Для documents
+	LegalName string `bson:"legalName,omitempty" json:"legalName,omitempty"`
+	INN       string `bson:"inn,omitempty" json:"inn,omitempty"`
+	OGRN      string `bson:"ogrn,omitempty" json:"ogrn,omitempty"`
+	Comment   string `bson:"comment,omitempty" json:"comment,omitempty"`
+
+	LastError   string    `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	LastCheckAt time.Time `bson:"lastCheckAt,omitempty" json:"lastCheckAt,omitempty"`
+
+	ReviewedBy string `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"` // adminId
+	ReviewNote string `bson:"reviewNote,omitempty" json:"reviewNote,omitempty"`
+
+	SubmittedBy string    `bson:"submittedBy" json:"submittedBy"`
+	CreatedAt   time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time `bson:"updatedAt" json:"updatedAt"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Статусы доставки вебхука
+const (
+	WebhookPending    = "pending"    // ждет отправки или повтора
+	WebhookDelivering = "delivering" // взята воркером
+	WebhookDelivered  = "delivered"
+	WebhookFailed     = "failed" // попытки исчерпаны или вебхук удален
+)
+
+// Webhook — адрес компании, куда отправляются события платформы (интеграция с ATS)
+type Webhook struct {
+	WebhookID   string   `bson:"webhookId" json:"webhookId"`
+	CompanyID   string   `bson:"companyId" json:"-"` // orgId организации
+	URL         string   `bson:"url" json:"url"`
+	Description string   `bson:"description,omitempty" json:"description,omitempty"`
+	Events      []string `bson:"events" json:"events"`
+	Active      bool     `bson:"active" json:"active"`
+
+	// Ключ подписи HMAC-SHA256; показывается только при создании и ротации
+	Secret string `bson:"secret" json:"-"`
+
+	CreatedBy string    `bson:"createdBy" json:"-"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// WebhookDelivery — событие в очереди на отправку и журнал попыток.
+// Очередь хранится в Mongo, поэтому рестарт сервера не теряет события.
+type WebhookDelivery struct {
+	DeliveryID string `bson:"deliveryId" json:"deliveryId"`
+	WebhookID  string `bson:"webhookId" json:"webhookId"`
+	CompanyID  string `bson:"companyId" json:"-"`
+	EventID    string `bson:"eventId" json:"eventId"`
+	EventType  string `bson:"eventType" json:"eventType"`
+	Payload    string `bson:"payload" json:"payload"` // тело запроса, JSON
+	ReplayOf   string `bson:"replayOf,omitempty" json:"replayOf,omitempty"`
+
+	Status        string           `bson:"status" json:"status"`
+	Attempts      int              `bson:"attempts" json:"attempts"`
+	AttemptLog    []WebhookAttempt `bson:"attemptLog,omitempty" json:"attemptLog"`
+	NextAttemptAt time.Time        `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	LockedUntil   time.Time        `bson:"lockedUntil,omitempty" json:"-"`
+
+	CreatedAt   time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time  `bson:"updatedAt" json:"updatedAt"`
+	DeliveredAt *time.Time `bson:"deliveredAt,omitempty" json:"deliveredAt,omitempty"`
+}
+
+// WebhookAttempt — одна попытка отправки
+type WebhookAttempt struct {
+	At         time.Time `bson:"at" json:"at"`
+	StatusCode int       `bson:"statusCode,omitempty" json:"statusCode,omitempty"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMs int64     `bson:"durationMs" json:"durationMs"`
+}
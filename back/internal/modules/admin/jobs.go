@@ -0,0 +1,15 @@
+package admin
+
+import (
+	"unicorn-auth/internal/jobs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerJobs — состояние фоновых задач и их последних проходов
+func registerJobs(api *gin.RouterGroup, requireAdmin gin.HandlerFunc, sup *jobs.Supervisor) {
+	// GET /api/admin/jobs
+	api.GET("/jobs", requireAdmin, func(c *gin.Context) {
+		c.JSON(200, gin.H{"ok": true, "items": sup.Statuses()})
+	})
+}
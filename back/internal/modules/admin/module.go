@@ -5,8 +5,13 @@ import (
 	"time"
 
 	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/jobs"
+	"unicorn-auth/internal/platformstats"
 	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/scheduler"
 	"unicorn-auth/internal/security"
+	"unicorn-auth/internal/taxonomy"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -17,7 +22,9 @@ type loginReq struct {
 	Password string `json:"password"`
 }
 
-func Register(r *gin.Engine, sec *security.Security, admins *repo.AdminRepo, users *repo.UserRepo) {
+func Register(r *gin.Engine, sec *security.Security, admins *repo.AdminRepo, users *repo.UserRepo,
+	profiles *repo.ProfileRepo, vac *repo.VacancyRepo, verifs *repo.VerificationRepo, skills *repo.SkillRepo, tax *taxonomy.Service,
+	platform *platformstats.Service, sup *jobs.Supervisor, sched *scheduler.Scheduler) {
 	api := r.Group("/api/admin")
 
 	api.POST("/login", func(c *gin.Context) {
@@ -44,20 +51,7 @@ func Register(r *gin.Engine, sec *security.Security, admins *repo.AdminRepo, use
 		c.JSON(200, gin.H{"ok": true, "accessToken": tok})
 	})
 
-	requireAdmin := func(c *gin.Context) {
-		h := c.GetHeader("Authorization")
-		if !strings.HasPrefix(h, "Bearer ") {
-			c.AbortWithStatusJSON(401, gin.H{"ok": false, "error": "unauthorized"})
-			return
-		}
-		claims, err := sec.Tokens.ParseAccess(strings.TrimSpace(strings.TrimPrefix(h, "Bearer ")))
-		if err != nil || claims.Type != "admin" {
-			c.AbortWithStatusJSON(401, gin.H{"ok": false, "error": "unauthorized"})
-			return
-		}
-		c.Set("adminId", claims.UserID)
-		c.Next()
-	}
+	requireAdmin := middleware.RequireAdmin(sec)
 
 	api.GET("/users", requireAdmin, func(c *gin.Context) {
 		typ := strings.TrimSpace(c.Query("type"))
@@ -229,6 +223,12 @@ func Register(r *gin.Engine, sec *security.Security, admins *repo.AdminRepo, use
 
 		c.JSON(200, gin.H{"ok": true})
 	})
+
+	registerVerification(api, requireAdmin, profiles, vac, verifs)
+	registerSkills(api, requireAdmin, skills, tax)
+	registerStats(api, requireAdmin, platform)
+	registerJobs(api, requireAdmin, sup)
+	registerSchedule(api, requireAdmin, sched)
 }
 
 func normLogin(login string) string {
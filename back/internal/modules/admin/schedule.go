@@ -0,0 +1,100 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+type scheduleReq struct {
+	Schedule string `json:"schedule"`
+}
+
+// scheduleError переводит ошибки планировщика в ответ
+func scheduleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownJob):
+		c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+	case errors.Is(err, scheduler.ErrBadSchedule):
+		c.JSON(400, gin.H{"ok": false, "error": "bad_schedule"})
+	default:
+		c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+	}
+}
+
+// registerSchedule — задачи по расписанию: история, ручной запуск, пауза
+func registerSchedule(api *gin.RouterGroup, requireAdmin gin.HandlerFunc, sched *scheduler.Scheduler) {
+	// GET /api/admin/schedule
+	api.GET("/schedule", requireAdmin, func(c *gin.Context) {
+		items, err := sched.List(c.Request.Context())
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items})
+	})
+
+	// GET /api/admin/schedule/:name/runs?limit=
+	api.GET("/schedule/:name/runs", requireAdmin, func(c *gin.Context) {
+		limit, _ := strconv.ParseInt(c.Query("limit"), 10, 64)
+		if limit <= 0 || limit > 100 {
+			limit = 20
+		}
+		items, err := sched.Runs(c.Request.Context(), c.Param("name"), limit)
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items})
+	})
+
+	// POST /api/admin/schedule/:name/run - запуск забирает первый свободный инстанс
+	api.POST("/schedule/:name/run", requireAdmin, func(c *gin.Context) {
+		job, err := sched.Trigger(c.Request.Context(), c.Param("name"), c.GetString(middleware.CtxAdminID))
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(202, gin.H{"ok": true, "job": job})
+	})
+
+	// POST /api/admin/schedule/:name/pause
+	api.POST("/schedule/:name/pause", requireAdmin, func(c *gin.Context) {
+		job, err := sched.SetPaused(c.Request.Context(), c.Param("name"), true)
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "job": job})
+	})
+
+	// POST /api/admin/schedule/:name/resume
+	api.POST("/schedule/:name/resume", requireAdmin, func(c *gin.Context) {
+		job, err := sched.SetPaused(c.Request.Context(), c.Param("name"), false)
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "job": job})
+	})
+
+	// PATCH /api/admin/schedule/:name - пустое расписание возвращает значение по умолчанию
+	api.PATCH("/schedule/:name", requireAdmin, func(c *gin.Context) {
+		var req scheduleReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+		job, err := sched.SetSchedule(c.Request.Context(), c.Param("name"), strings.TrimSpace(req.Schedule))
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "job": job})
+	})
+}
@@ -0,0 +1,146 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/taxonomy"
+
+	"github.com/gin-gonic/gin"
+)
+
+type skillReq struct {
+	Name     *string   `json:"name,omitempty"`
+	Synonyms *[]string `json:"synonyms,omitempty"`
+	Category *string   `json:"category,omitempty"`
+}
+
+type mergeReq struct {
+	SkillIDs []string `json:"skillIds,omitempty"`
+	Terms    []string `json:"terms,omitempty"`
+}
+
+type splitReq struct {
+	Name     string   `json:"name"`
+	Synonyms []string `json:"synonyms"`
+	Category string   `json:"category,omitempty"`
+}
+
+// skillError переводит ошибки справочника в ответ
+func skillError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, taxonomy.ErrInvalid):
+		c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+	case errors.Is(err, taxonomy.ErrNotFound):
+		c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+	case errors.Is(err, taxonomy.ErrExists):
+		c.JSON(409, gin.H{"ok": false, "error": "skill_exists"})
+	default:
+		c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+	}
+}
+
+// registerSkills — ведение справочника навыков и тегов
+func registerSkills(api *gin.RouterGroup, requireAdmin gin.HandlerFunc, skills *repo.SkillRepo, tax *taxonomy.Service) {
+	// GET /api/admin/skills?q=&category=&skip=
+	api.GET("/skills", requireAdmin, func(c *gin.Context) {
+		skip, _ := strconv.ParseInt(c.Query("skip"), 10, 64)
+		if skip < 0 {
+			skip = 0
+		}
+		items, err := skills.List(c.Request.Context(), taxonomy.Key(c.Query("q")), c.Query("category"), 100, skip)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items, "categories": models.SkillCategories})
+	})
+
+	// GET /api/admin/skills/unknown - теги вне справочника, частые сначала
+	api.GET("/skills/unknown", requireAdmin, func(c *gin.Context) {
+		items, err := tax.Unknown(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items})
+	})
+
+	// POST /api/admin/skills {name, synonyms, category}
+	api.POST("/skills", requireAdmin, func(c *gin.Context) {
+		var req skillReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+		if req.Name == nil {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		sk := &models.Skill{Name: *req.Name}
+		if req.Synonyms != nil {
+			sk.Synonyms = *req.Synonyms
+		}
+		if req.Category != nil {
+			sk.Category = *req.Category
+		}
+		if err := tax.Create(c.Request.Context(), sk); err != nil {
+			skillError(c, err)
+			return
+		}
+		c.JSON(201, gin.H{"ok": true, "skill": sk})
+	})
+
+	// PATCH /api/admin/skills/:skillId - смена названия переписывает вакансии и резюме
+	api.PATCH("/skills/:skillId", requireAdmin, func(c *gin.Context) {
+		var req skillReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+		sk, err := tax.Update(c.Request.Context(), c.Param("skillId"), req.Name, req.Synonyms, req.Category)
+		if err != nil {
+			skillError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "skill": sk})
+	})
+
+	// DELETE /api/admin/skills/:skillId - теги в документах остаются как есть
+	api.DELETE("/skills/:skillId", requireAdmin, func(c *gin.Context) {
+		if err := tax.Delete(c.Request.Context(), c.Param("skillId")); err != nil {
+			skillError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	// POST /api/admin/skills/:skillId/merge {skillIds, terms}
+	api.POST("/skills/:skillId/merge", requireAdmin, func(c *gin.Context) {
+		var req mergeReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+		sk, changed, err := tax.Merge(c.Request.Context(), c.Param("skillId"), req.SkillIDs, req.Terms)
+		if err != nil {
+			skillError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "skill": sk, "documentsUpdated": changed})
+	})
+
+	// POST /api/admin/skills/:skillId/split {name, synonyms, category}
+	api.POST("/skills/:skillId/split", requireAdmin, func(c *gin.Context) {
+		var req splitReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+		sk, err := tax.Split(c.Request.Context(), c.Param("skillId"), req.Name, req.Synonyms, req.Category)
+		if err != nil {
+			skillError(c, err)
+			return
+		}
+		c.JSON(201, gin.H{"ok": true, "skill": sk})
+	})
+}
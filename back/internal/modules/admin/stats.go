@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"strconv"
+	"time"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/platformstats"
+	"unicorn-auth/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statsRange — период по умолчанию и наибольший период в днях для каждой разбивки
+var statsRange = map[string]struct{ def, max int }{
+	models.PeriodDay:   {def: 30, max: 366},
+	models.PeriodWeek:  {def: 12 * 7, max: 3 * 366},
+	models.PeriodMonth: {def: 365, max: 10 * 366},
+}
+
+// registerStats — сводка платформы: рост, выручка, отток и модерация
+func registerStats(api *gin.RouterGroup, requireAdmin gin.HandlerFunc, platform *platformstats.Service) {
+	// GET /api/admin/stats?period=day|week|month&from=&to=&format=json|csv
+	api.GET("/stats", requireAdmin, func(c *gin.Context) {
+		period := c.DefaultQuery("period", models.PeriodDay)
+		lim, ok := statsRange[period]
+		if !ok {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_period"})
+			return
+		}
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "csv" {
+			c.JSON(400, gin.H{"ok": false, "error": "unsupported_format"})
+			return
+		}
+		to, okTo := parseDay(c.Query("to"), time.Now().UTC().Truncate(24*time.Hour))
+		from, okFrom := parseDay(c.Query("from"), to.AddDate(0, 0, -(lim.def-1)))
+		if !okTo || !okFrom || from.After(to) || to.Sub(from) >= time.Duration(lim.max)*24*time.Hour {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_range"})
+			return
+		}
+
+		items, totals, err := platform.Series(c.Request.Context(), period, from, to)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if format == "json" {
+			c.JSON(200, gin.H{"ok": true, "period": period, "from": from.Format(repo.DayLayout), "to": to.Format(repo.DayLayout),
+				"items": items, "totals": totals})
+			return
+		}
+		rows := [][]string{{"bucket", "usersRegistered", "companiesRegistered", "activeUsers", "mau", "vacanciesCreated", "applications",
+			"payments", "paymentsSucceeded", "paymentsFailed", "paymentFailureRate", "paidSubscriptions", "revenue", "refunded", "netRevenue",
+			"trialsStarted", "subscribersStart", "churned", "churnRate", "verificationsSubmitted", "verificationsApproved", "verificationsRejected"}}
+		for _, p := range items {
+			rows = append(rows, []string{p.Bucket,
+				itoa(p.UsersRegistered), itoa(p.CompaniesRegistered), itoa(p.ActiveUsers), itoa(p.MAU), itoa(p.VacanciesCreated), itoa(p.Applications),
+				itoa(p.Payments), itoa(p.PaymentsSucceeded), itoa(p.PaymentsFailed), ftoa(p.PaymentFailureRate), itoa(p.PaidSubscriptions),
+				ftoa(p.Revenue), ftoa(p.Refunded), ftoa(p.NetRevenue),
+				itoa(p.TrialsStarted), itoa(p.SubscribersStart), itoa(p.Churned), ftoa(p.ChurnRate),
+				itoa(p.VerificationsSubmitted), itoa(p.VerificationsApproved), itoa(p.VerificationsRejected)})
+		}
+		httputil.WriteCSV(c, "platform-"+period+"-"+from.Format(repo.DayLayout)+"-"+to.Format(repo.DayLayout)+".csv", rows)
+	})
+}
+
+// parseDay разбирает YYYY-MM-DD; пустая строка — def
+func parseDay(s string, def time.Time) (time.Time, bool) {
+	if s == "" {
+		return def, true
+	}
+	t, err := time.Parse(repo.DayLayout, s)
+	return t, err == nil
+}
+
+func itoa(n int64) string   { return strconv.FormatInt(n, 10) }
+func ftoa(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/verification"
+
+	"github.com/gin-gonic/gin"
+)
+
+type reviewReq struct {
+	Note string `json:"note,omitempty"`
+}
+
+// registerVerification — модерация заявок на значок проверенной компании
+func registerVerification(api *gin.RouterGroup, requireAdmin gin.HandlerFunc, profiles *repo.ProfileRepo,
+	vac *repo.VacancyRepo, verifs *repo.VerificationRepo) {
+
+	// GET /api/admin/verifications?status=pending&method=documents&skip=
+	api.GET("/verifications", requireAdmin, func(c *gin.Context) {
+		skip, _ := strconv.ParseInt(c.Query("skip"), 10, 64)
+		if skip < 0 {
+			skip = 0
+		}
+		items, err := verifs.List(c.Request.Context(), c.Query("status"), c.Query("method"), 100, skip)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+
+		out := make([]gin.H, 0, len(items))
+		names := map[string]string{}
+		for _, v := range items {
+			name, ok := names[v.CompanyID]
+			if !ok {
+				if p, _ := profiles.GetByUserID(c.Request.Context(), v.CompanyID); p != nil {
+					name = p.DisplayName
+				}
+				names[v.CompanyID] = name
+			}
+			out = append(out, gin.H{"verification": v, "companyName": name})
+		}
+		c.JSON(200, gin.H{"ok": true, "items": out})
+	})
+
+	// POST /api/admin/verifications/:verificationId/approve - тело {note} необязательно
+	api.POST("/verifications/:verificationId/approve", requireAdmin, func(c *gin.Context) {
+		var req reviewReq
+		if c.Request.ContentLength != 0 && !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+		v, err := verifs.GetByID(c.Request.Context(), c.Param("verificationId"))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if v == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		taken, err := verification.DomainTaken(c.Request.Context(), profiles, v.CompanyID, v.Domain)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if taken {
+			c.JSON(409, gin.H{"ok": false, "error": "domain_taken"})
+			return
+		}
+		ok, err := verifs.Review(c.Request.Context(), v.VerificationID, "verified",
+			c.GetString(middleware.CtxAdminID), strings.TrimSpace(req.Note))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(409, gin.H{"ok": false, "error": "not_pending"})
+			return
+		}
+		if err := verification.Grant(c.Request.Context(), profiles, vac, v.CompanyID, v.Method, v.Domain); err != nil {
+			if errors.Is(err, repo.ErrDomainTaken) {
+				c.JSON(409, gin.H{"ok": false, "error": "domain_taken"})
+				return
+			}
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "status": "verified"})
+	})
+
+	// POST /api/admin/verifications/:verificationId/reject - причина обязательна, ее видит компания
+	api.POST("/verifications/:verificationId/reject", requireAdmin, func(c *gin.Context) {
+		var req reviewReq
+		if !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+		note := strings.TrimSpace(req.Note)
+		if note == "" {
+			c.JSON(400, gin.H{"ok": false, "error": "note_required"})
+			return
+		}
+		ok, err := verifs.Review(c.Request.Context(), c.Param("verificationId"), "rejected",
+			c.GetString(middleware.CtxAdminID), note)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(409, gin.H{"ok": false, "error": "not_pending"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "status": "rejected"})
+	})
+
+	// DELETE /api/admin/companies/:companyId/verification - снять значок
+	api.DELETE("/companies/:companyId/verification", requireAdmin, func(c *gin.Context) {
+		if err := verification.Revoke(c.Request.Context(), profiles, vac, c.Param("companyId")); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+}
@@ -0,0 +1,196 @@
+package analytics
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	an "unicorn-auth/internal/analytics"
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/security"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultPeriod = 30  // дней, если from не указан
+	maxPeriod     = 366 // дней в одном отчете
+)
+
+// dayItem — счетчики за один день
+type dayItem struct {
+	Day string `json:"day"`
+	models.VacancyStats
+}
+
+type vacancyItem struct {
+	VacancyID string `json:"vacancyId"`
+	Title     string `json:"title"`
+	Status    string `json:"status,omitempty"`
+	an.Summary
+}
+
+// Register — аналитика откликов для компании: воронка, конверсии и время ответа.
+// Смотреть могут все участники организации, включая наблюдателей
+func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, orgs *repo.OrgRepo,
+	vac *repo.VacancyRepo, stats *repo.AnalyticsRepo) {
+
+	api := r.Group("/api/analytics")
+	api.Use(middleware.RequireAuth(sec))
+	api.Use(middleware.RequireType("company"))
+	api.Use(middleware.RequireMFAEnabled(sec, users))
+	api.Use(middleware.ResolveOrg(orgs))
+
+	// GET /api/analytics/vacancies/:id?from=&to=&format=json|csv - воронка вакансии и ряд по дням
+	api.GET("/vacancies/:id", func(c *gin.Context) {
+		from, to, format, ok := bindPeriod(c)
+		if !ok {
+			return
+		}
+		ctx := c.Request.Context()
+		v, err := vac.GetByID(ctx, c.Param("id"))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if v == nil || v.CompanyID != c.GetString(middleware.CtxOrgID) {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		rows, err := stats.SumStats(ctx, bson.M{"vacancyId": v.VacancyID}, from, to, "day")
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		daily, total := fillDays(rows, from, to)
+
+		if format == "csv" {
+			out := [][]string{{"day", "views", "applications", "knockedOut", "viewed", "responded", "rejected", "accepted"}}
+			for _, d := range daily {
+				out = append(out, append([]string{d.Day}, counters(d.VacancyStats)...))
+			}
+			httputil.WriteCSV(c, "vacancy-"+v.VacancyID+"-"+from+"-"+to+".csv", out)
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "vacancyId": v.VacancyID, "title": v.Title, "from": from, "to": to,
+			"summary": an.Summarize(total), "daily": daily})
+	})
+
+	// GET /api/analytics/company?from=&to=&format=json|csv - воронка организации и разбивка по вакансиям
+	api.GET("/company", func(c *gin.Context) {
+		from, to, format, ok := bindPeriod(c)
+		if !ok {
+			return
+		}
+		ctx := c.Request.Context()
+		orgID := c.GetString(middleware.CtxOrgID)
+		byVacancy, err := stats.SumStats(ctx, bson.M{"companyId": orgID}, from, to, "vacancyId")
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		byDay, err := stats.SumStats(ctx, bson.M{"companyId": orgID}, from, to, "day")
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		vacs, err := vac.ListByCompanyID(ctx, orgID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		known := make(map[string]*models.Vacancy, len(vacs))
+		for i := range vacs {
+			known[vacs[i].VacancyID] = &vacs[i]
+		}
+
+		items := make([]vacancyItem, 0, len(byVacancy))
+		for _, row := range byVacancy {
+			it := vacancyItem{VacancyID: row.Key, Summary: an.Summarize(row.VacancyStats)}
+			if v := known[row.Key]; v != nil {
+				it.Title, it.Status = v.Title, v.Status
+			}
+			items = append(items, it)
+		}
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Applications > items[j].Applications })
+		daily, total := fillDays(byDay, from, to)
+
+		if format == "csv" {
+			out := [][]string{{"vacancyId", "title", "status", "views", "applications", "knockedOut", "viewed", "responded", "rejected", "accepted",
+				"viewedRate", "responseRate", "acceptRate", "avgFirstResponseHours", "avgTimeToHireHours"}}
+			for _, it := range items {
+				row := append([]string{it.VacancyID, it.Title, it.Status}, counters(it.VacancyStats)...)
+				out = append(out, append(row, num(it.ViewedRate), num(it.ResponseRate), num(it.AcceptRate),
+					num(it.AvgFirstResponseHours), num(it.AvgTimeToHireHours)))
+			}
+			httputil.WriteCSV(c, "analytics-"+from+"-"+to+".csv", out)
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "from": from, "to": to, "summary": an.Summarize(total), "vacancies": items, "daily": daily})
+	})
+}
+
+// bindPeriod читает from, to (YYYY-MM-DD, включительно) и format;
+// ok == false — ответ с ошибкой уже записан
+func bindPeriod(c *gin.Context) (from, to, format string, ok bool) {
+	format = c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(400, gin.H{"ok": false, "error": "unsupported_format"})
+		return "", "", "", false
+	}
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	if s := c.Query("to"); s != "" {
+		t, err := time.Parse(repo.DayLayout, s)
+		if err != nil {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_period"})
+			return "", "", "", false
+		}
+		end = t
+	}
+	start := end.AddDate(0, 0, -(defaultPeriod - 1))
+	if s := c.Query("from"); s != "" {
+		t, err := time.Parse(repo.DayLayout, s)
+		if err != nil {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_period"})
+			return "", "", "", false
+		}
+		start = t
+	}
+	if start.After(end) || end.Sub(start) >= maxPeriod*24*time.Hour {
+		c.JSON(400, gin.H{"ok": false, "error": "bad_period"})
+		return "", "", "", false
+	}
+	return start.Format(repo.DayLayout), end.Format(repo.DayLayout), format, true
+}
+
+// fillDays раскладывает суммы по дням периода, дни без событий — нулями
+func fillDays(rows []repo.StatsTotal, from, to string) ([]dayItem, models.VacancyStats) {
+	byDay := make(map[string]models.VacancyStats, len(rows))
+	var total models.VacancyStats
+	for _, row := range rows {
+		byDay[row.Key] = row.VacancyStats
+		total.Add(row.VacancyStats)
+	}
+	start, _ := time.Parse(repo.DayLayout, from)
+	end, _ := time.Parse(repo.DayLayout, to)
+	out := []dayItem{}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		day := d.Format(repo.DayLayout)
+		out = append(out, dayItem{Day: day, VacancyStats: byDay[day]})
+	}
+	return out, total
+}
+
+func counters(s models.VacancyStats) []string {
+	out := make([]string, 0, 7)
+	for _, n := range []int64{s.Views, s.Applications, s.KnockedOut, s.Viewed, s.Responded, s.Rejected, s.Accepted} {
+		out = append(out, strconv.FormatInt(n, 10))
+	}
+	return out
+}
+
+func num(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
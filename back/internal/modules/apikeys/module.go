@@ -0,0 +1,138 @@
+package apikeys
+
+import (
+	"strings"
+	"time"
+
+	ak "unicorn-auth/internal/apikeys"
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxKeys = 10
+
+type createReq struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	RateLimit     int      `json:"rateLimit,omitempty"`     // запросов в минуту
+	ExpiresInDays int      `json:"expiresInDays,omitempty"` // 0 — бессрочно
+}
+
+// Register — ключи API компании; выпускает и отзывает только владелец организации
+func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, orgs *repo.OrgRepo, keys *repo.APIKeyRepo) {
+	api := r.Group("/api/company/api-keys")
+	api.Use(middleware.RequireAuth(sec))
+	api.Use(middleware.RequireType("company"))
+	api.Use(middleware.RequireMFAEnabled(sec, users))
+	api.Use(middleware.ResolveOrg(orgs))
+	api.Use(middleware.RequireOrgRole(models.OrgRoleOwner))
+
+	// GET /api/company/api-keys - список ключей (без самих ключей) и доступные области
+	api.GET("", func(c *gin.Context) {
+		items, err := keys.ListByCompany(c.Request.Context(), c.GetString(middleware.CtxOrgID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items, "scopes": models.APIScopes})
+	})
+
+	// POST /api/company/api-keys - выпустить ключ; значение возвращается один раз
+	api.POST("", func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		var req createReq
+		if !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" || len([]rune(req.Name)) > 100 {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		scopes, ok := validScopes(req.Scopes)
+		if !ok {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_scopes"})
+			return
+		}
+		if req.RateLimit == 0 {
+			req.RateLimit = ak.DefaultRateLimit
+		}
+		if req.RateLimit < 1 || req.RateLimit > ak.MaxRateLimit || req.ExpiresInDays < 0 || req.ExpiresInDays > 730 {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		n, err := keys.CountActive(c.Request.Context(), orgID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if n >= maxKeys {
+			c.JSON(409, gin.H{"ok": false, "error": "limit_reached"})
+			return
+		}
+
+		raw, prefix, hash := ak.Generate()
+		k := &models.APIKey{
+			CompanyID: orgID,
+			Name:      req.Name,
+			Prefix:    prefix,
+			KeyHash:   hash,
+			Scopes:    scopes,
+			RateLimit: req.RateLimit,
+			CreatedBy: c.GetString(middleware.CtxUserID),
+		}
+		if req.ExpiresInDays > 0 {
+			exp := time.Now().UTC().AddDate(0, 0, req.ExpiresInDays)
+			k.ExpiresAt = &exp
+		}
+		if err := keys.Create(c.Request.Context(), k); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "apiKey": k, "key": raw})
+	})
+
+	// DELETE /api/company/api-keys/:keyId - отозвать; действует сразу
+	api.DELETE("/:keyId", func(c *gin.Context) {
+		ok, err := keys.Revoke(c.Request.Context(), c.Param("keyId"), c.GetString(middleware.CtxOrgID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+}
+
+func validScopes(in []string) ([]string, bool) {
+	if len(in) == 0 {
+		return nil, false
+	}
+	out := make([]string, 0, len(in))
+	seen := map[string]bool{}
+	for _, s := range in {
+		known := false
+		for _, t := range models.APIScopes {
+			if s == t {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, false
+		}
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out, true
+}
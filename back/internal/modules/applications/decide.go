@@ -0,0 +1,57 @@
+package applications
+
+import (
+	"context"
+	"errors"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/notify"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/webhooks"
+)
+
+var ErrNotFound = errors.New("not_found")
+
+// Decide принимает или отклоняет отклик организации и уведомляет соискателя.
+// Общая логика для API и Telegram-бота; роль участника проверяет вызывающий.
+func Decide(ctx context.Context, apps *repo.ApplicationRepo, vac *repo.VacancyRepo, events *notify.Emitter,
+	hooks *webhooks.Dispatcher, orgID, actorID, appID, status string) (*models.Application, error) {
+
+	a, err := apps.GetByID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil || a.CompanyID != orgID {
+		return nil, ErrNotFound
+	}
+	if err := apps.UpdateStatus(ctx, appID, orgID, status); err != nil {
+		return nil, err
+	}
+	a.Status = status
+
+	title := "Отклик рассмотрен"
+	switch status {
+	case "accepted":
+		title = "Отклик принят"
+	case "rejected":
+		title = "Отклик отклонен"
+	}
+	v, _ := vac.GetByID(ctx, a.VacancyID)
+	text := ""
+	if v != nil {
+		text = "Вакансия: " + v.Title
+	}
+	events.Emit(notify.Event{
+		Type:    notify.EventApplicationStatus,
+		UserID:  a.UserID,
+		ActorID: actorID,
+		Title:   title,
+		Text:    text,
+		Link:    "/applications/" + a.ApplicationID,
+		Data:    map[string]string{"applicationId": a.ApplicationID, "status": status},
+	})
+	data := webhooks.ApplicationData(a, v)
+	data["changedBy"] = actorID
+	hooks.Publish(ctx, orgID, webhooks.EventApplicationStatus, data)
+	return a, nil
+}
@@ -0,0 +1,123 @@
+package applications
+
+import (
+	"strconv"
+	"time"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Сколько откликов попадает в одну выгрузку
+const exportLimit = 5000
+
+type exportItem struct {
+	ApplicationID     string    `json:"applicationId"`
+	VacancyID         string    `json:"vacancyId"`
+	VacancyExternalID string    `json:"vacancyExternalId,omitempty"`
+	VacancyTitle      string    `json:"vacancyTitle"`
+	CandidateID       string    `json:"candidateId"`
+	CandidateName     string    `json:"candidateName"`
+	ResumeID          string    `json:"resumeId"`
+	ResumeTitle       string    `json:"resumeTitle"`
+	SnapshotID        string    `json:"snapshotId,omitempty"`
+	Status            string    `json:"status"`
+	AssigneeID        string    `json:"assigneeId,omitempty"`
+	Message           string    `json:"message,omitempty"`
+	CoverLetter       string    `json:"coverLetter,omitempty"`
+	Viewed            bool      `json:"viewed"`
+	CreatedAt         time.Time `json:"createdAt"`
+
+	Answers    []models.ScreeningAnswer `json:"answers,omitempty"`
+	KnockedOut bool                     `json:"knockedOut"`
+}
+
+// registerExport — выгрузка откликов организации для HR-системы
+func registerExport(protected *gin.RouterGroup, users *repo.UserRepo, vac *repo.VacancyRepo,
+	resumes *repo.ResumeRepo, snapshots *repo.ResumeSnapshotRepo, apps *repo.ApplicationRepo) {
+
+	// GET /api/applications/export?format=csv|json - отклики организации, новые сверху;
+	// фильтры те же, что у инбокса
+	protected.GET("/applications/export", middleware.RequireType("company"), func(c *gin.Context) {
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "csv" {
+			c.JSON(400, gin.H{"ok": false, "error": "unsupported_format"})
+			return
+		}
+		filter, ok := bindInboxFilter(c)
+		if !ok {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		ctx := c.Request.Context()
+		items, err := apps.ListInbox(ctx, c.GetString(middleware.CtxOrgID), filter, exportLimit, 0)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+
+		type vacInfo struct{ title, externalID string }
+		vacs := map[string]vacInfo{}
+		names := map[string]string{}
+		rTitles := map[string]string{}
+		out := make([]exportItem, 0, len(items))
+		for _, a := range items {
+			vi, ok := vacs[a.VacancyID]
+			if !ok {
+				if v, _ := vac.GetByID(ctx, a.VacancyID); v != nil {
+					vi = vacInfo{title: v.Title, externalID: v.ExternalID}
+				}
+				vacs[a.VacancyID] = vi
+			}
+			name, ok := names[a.UserID]
+			if !ok {
+				if u, _ := users.FindByUserID(ctx, a.UserID); u != nil {
+					name = u.DisplayName
+				}
+				names[a.UserID] = name
+			}
+			rt := resumeTitle(ctx, resumes, snapshots, &a, rTitles)
+			out = append(out, exportItem{
+				ApplicationID:     a.ApplicationID,
+				VacancyID:         a.VacancyID,
+				VacancyExternalID: vi.externalID,
+				VacancyTitle:      vi.title,
+				CandidateID:       a.UserID,
+				CandidateName:     name,
+				ResumeID:          a.ResumeID,
+				ResumeTitle:       rt,
+				SnapshotID:        a.SnapshotID,
+				Status:            a.Status,
+				AssigneeID:        a.AssigneeID,
+				Message:           a.Message,
+				CoverLetter:       a.CoverLetter,
+				Viewed:            a.Viewed,
+				CreatedAt:         a.CreatedAt,
+				Answers:           a.Answers,
+				KnockedOut:        a.KnockedOut,
+			})
+		}
+
+		name := "applications-" + time.Now().UTC().Format("2006-01-02")
+		if format == "json" {
+			c.Header("Content-Disposition", `attachment; filename="`+name+`.json"`)
+			c.JSON(200, gin.H{"applications": out})
+			return
+		}
+		rows := [][]string{{"applicationId", "vacancyId", "vacancyExternalId", "vacancyTitle", "candidateId", "candidateName",
+			"resumeId", "resumeTitle", "status", "assigneeId", "message", "viewed", "createdAt", "knockedOut", "answers", "snapshotId", "coverLetter"}}
+		for _, it := range out {
+			rows = append(rows, []string{
+				it.ApplicationID, it.VacancyID, it.VacancyExternalID, it.VacancyTitle, it.CandidateID, it.CandidateName,
+				it.ResumeID, it.ResumeTitle, it.Status, it.AssigneeID, it.Message, strconv.FormatBool(it.Viewed),
+				it.CreatedAt.Format(time.RFC3339), strconv.FormatBool(it.KnockedOut), answersText(it.Answers),
+				it.SnapshotID, it.CoverLetter,
+			})
+		}
+		httputil.WriteCSV(c, name+".csv", rows)
+	})
+}
@@ -1,14 +1,20 @@
 package applications
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"unicorn-auth/internal/http/httputil"
 	"unicorn-auth/internal/http/middleware"
 	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/notify"
 	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/screening"
 	"unicorn-auth/internal/security"
+	"unicorn-auth/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,6 +26,9 @@ type applyReq struct {
 	ResumeID      string `json:"resumeId"`
 	Status        string `json:"status"`
 	Message       string `json:"message,omitempty"`
+	CoverLetter   string `json:"coverLetter,omitempty"`
+
+	Answers []screening.Answer `json:"answers,omitempty"` // ответы на анкету вакансии
 
 	// удобные поля для фронта
 	VacancyTitle string `json:"vacancyTitle,omitempty"`
@@ -33,13 +42,24 @@ type inboxItem struct {
 	VacancyTitle    string `json:"vacancyTitle,omitempty"`
 	ResumeID        string `json:"resumeId"`
 	ResumeTitle     string `json:"resumeTitle,omitempty"`
+	SnapshotID      string `json:"snapshotId,omitempty"`
 	UserID          string `json:"userId"`
 	UserDisplayName string `json:"userDisplayName,omitempty"`
 	UserIsPremium   bool   `json:"userIsPremium"`
 	CompanyID       string `json:"companyId"`
+	AssigneeID      string `json:"assigneeId,omitempty"`
+	AssigneeName    string `json:"assigneeName,omitempty"`
 	Status          string `json:"status"`
 	Message         string `json:"message,omitempty"`
+	CoverLetter     string `json:"coverLetter,omitempty"`
 	Viewed          bool   `json:"viewed"`
+
+	Answers    []models.ScreeningAnswer `json:"answers,omitempty"`
+	KnockedOut bool                     `json:"knockedOut"`
+}
+
+type assignReq struct {
+	AssigneeID string `json:"assigneeId"` // пусто — снять назначение
 }
 
 type myAppItem struct {
@@ -52,15 +72,23 @@ type myAppItem struct {
 
 	VacancyTitle       string `json:"vacancyTitle,omitempty"`
 	CompanyDisplayName string `json:"companyDisplayName,omitempty"`
+	CompanyVerified    bool   `json:"companyVerified"`
 }
 
-func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo,
-	vac *repo.VacancyRepo, resumes *repo.ResumeRepo, apps *repo.ApplicationRepo) {
+func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, orgs *repo.OrgRepo,
+	vac *repo.VacancyRepo, resumes *repo.ResumeRepo, snapshots *repo.ResumeSnapshotRepo, apps *repo.ApplicationRepo,
+	events *notify.Emitter, hooks *webhooks.Dispatcher) {
 
 	api := r.Group("/api")
 	protected := api.Group("")
 	protected.Use(middleware.RequireAuth(sec))
 	protected.Use(middleware.RequireMFAEnabled(sec, users))
+	protected.Use(middleware.ResolveOrg(orgs))
+
+	// Решения по откликам принимают владелец и рекрутеры организации
+	canManage := middleware.RequireOrgRole(models.OrgRoleOwner, models.OrgRoleRecruiter)
+
+	registerExport(protected, users, vac, resumes, snapshots, apps)
 
 	// user apply
 	protected.POST("/applications", middleware.RequireType("user"), func(c *gin.Context) {
@@ -71,13 +99,18 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo,
 			return
 		}
 		req.Message = strings.TrimSpace(req.Message)
+		req.CoverLetter = strings.TrimSpace(req.CoverLetter)
+		if utf8.RuneCountInString(req.CoverLetter) > maxCoverLetter {
+			c.JSON(400, gin.H{"ok": false, "error": "cover_letter_too_long", "max": maxCoverLetter})
+			return
+		}
 
 		v, err := vac.GetByID(c.Request.Context(), req.VacancyID)
 		if err != nil {
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
-		if v == nil || v.Status != "active" {
+		if v == nil || !v.Open(time.Now().UTC()) {
 			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
 			return
 		}
@@ -90,18 +123,69 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo,
 			c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
 			return
 		}
+		answers, knockedOut, err := screening.Evaluate(v.Questions, req.Answers)
+		if err != nil {
+			var ae *screening.AnswerError
+			if errors.As(err, &ae) {
+				c.JSON(400, gin.H{"ok": false, "error": ae.Code, "questionId": ae.QuestionID})
+				return
+			}
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+
+		// Компания видит резюме таким, каким оно было в момент отклика
+		snap, err := snapshots.Capture(c.Request.Context(), rr)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
 
 		a := &models.Application{
-			VacancyID: v.VacancyID,
-			ResumeID:  rr.ResumeID,
-			UserID:    uid,
-			CompanyID: v.CompanyID,
-			Message:   req.Message,
+			VacancyID:   v.VacancyID,
+			ResumeID:    rr.ResumeID,
+			SnapshotID:  snap.SnapshotID,
+			UserID:      uid,
+			CompanyID:   v.CompanyID,
+			Message:     req.Message,
+			CoverLetter: req.CoverLetter,
+			Answers:     answers,
+			KnockedOut:  knockedOut,
+		}
+		// Не прошедший отсекающий вопрос отклик сразу отклоняется
+		if knockedOut {
+			a.Status = "rejected"
 		}
 		if err := apps.Create(c.Request.Context(), a); err != nil {
 			c.JSON(http.StatusConflict, gin.H{"ok": false, "error": "conflict"})
 			return
 		}
+
+		if knockedOut {
+			events.Emit(notify.Event{
+				Type:   notify.EventApplicationStatus,
+				UserID: uid,
+				Title:  "Отклик отклонен",
+				Text:   "Вакансия: " + v.Title,
+				Link:   "/applications/" + a.ApplicationID,
+				Data:   map[string]string{"applicationId": a.ApplicationID, "status": a.Status},
+			})
+		} else {
+			text := ""
+			if u, _ := users.FindByUserID(c.Request.Context(), uid); u != nil {
+				text = u.DisplayName + " откликнулся на вакансию"
+			}
+			events.Emit(notify.Event{
+				Type:    notify.EventApplicationNew,
+				OrgID:   a.CompanyID,
+				ActorID: uid,
+				Title:   "Новый отклик: " + v.Title,
+				Text:    text,
+				Link:    "/applications/" + a.ApplicationID,
+				Data:    map[string]string{"applicationId": a.ApplicationID, "vacancyId": v.VacancyID},
+			})
+		}
+		hooks.Publish(c.Request.Context(), a.CompanyID, webhooks.EventApplicationCreated, webhooks.ApplicationData(a, v))
 		c.JSON(200, gin.H{"ok": true, "applicationId": a.ApplicationID, "status": a.Status})
 	})
 	// user: my applications
@@ -138,6 +222,7 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo,
 			// enrich vacancy title (optional)
 			if v, _ := vac.GetByID(c.Request.Context(), a.VacancyID); v != nil {
 				it.VacancyTitle = v.Title
+				it.CompanyVerified = v.CompanyVerified
 			}
 
 			out = append(out, it)
@@ -148,11 +233,17 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo,
 
 	// новый инбокс соотвктвующий inboxItem
 	// company inbox
+	// ?assignee=me|none|<userId> - фильтр по ответственному рекрутеру
+	// ?vacancyId=, ?knockedOut=, ?answer= - фильтры по вакансии и ответам анкеты
 	protected.GET("/applications/inbox", middleware.RequireType("company"), func(c *gin.Context) {
-		companyID := c.GetString(middleware.CtxUserID)
-		status := c.Query("status")
+		orgID := c.GetString(middleware.CtxOrgID)
+		filter, ok := bindInboxFilter(c)
+		if !ok {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
 
-		items, err := apps.ListInbox(c.Request.Context(), companyID, status, 50, 0)
+		items, err := apps.ListInbox(c.Request.Context(), orgID, filter, 50, 0)
 		if err != nil {
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
@@ -170,11 +261,16 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo,
 				ApplicationID: a.ApplicationID,
 				VacancyID:     a.VacancyID,
 				ResumeID:      a.ResumeID,
+				SnapshotID:    a.SnapshotID,
 				UserID:        a.UserID,
 				CompanyID:     a.CompanyID,
+				AssigneeID:    a.AssigneeID,
 				Status:        a.Status,
 				Message:       a.Message,
+				CoverLetter:   a.CoverLetter,
 				Viewed:        a.Viewed,
+				Answers:       a.Answers,
+				KnockedOut:    a.KnockedOut,
 			}
 
 			// vacancy title
@@ -187,13 +283,17 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo,
 				}
 			}
 
-			// resume title
-			if t, ok := rTitle[a.ResumeID]; ok {
-				it.ResumeTitle = t
-			} else {
-				if rr, _ := resumes.GetByID(c.Request.Context(), a.ResumeID); rr != nil {
-					rTitle[a.ResumeID] = rr.Title
-					it.ResumeTitle = rr.Title
+			// resume title: из снимка, у старых откликов — из текущего резюме
+			it.ResumeTitle = resumeTitle(c.Request.Context(), resumes, snapshots, &a, rTitle)
+
+			// assignee displayName
+			if a.AssigneeID != "" {
+				if n, ok := uName[a.AssigneeID]; ok {
+					it.AssigneeName = n
+				} else if u, _ := users.FindByUserID(c.Request.Context(), a.AssigneeID); u != nil {
+					uName[a.AssigneeID] = u.DisplayName
+					uPremium[a.AssigneeID] = u.Subscription.Active
+					it.AssigneeName = u.DisplayName
 				}
 			}
 
@@ -217,27 +317,37 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo,
 		c.JSON(200, gin.H{"ok": true, "items": out})
 	})
 
-	protected.POST("/applications/:id/accept", middleware.RequireType("company"), func(c *gin.Context) {
-		companyID := c.GetString(middleware.CtxUserID)
-		if err := apps.UpdateStatus(c.Request.Context(), c.Param("id"), companyID, "accepted"); err != nil {
+	protected.POST("/applications/:id/accept", middleware.RequireType("company"), canManage, func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		_, err := Decide(c.Request.Context(), apps, vac, events, hooks, orgID, c.GetString(middleware.CtxUserID), c.Param("id"), "accepted")
+		if errors.Is(err, ErrNotFound) {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		if err != nil {
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
 		c.JSON(200, gin.H{"ok": true, "status": "accepted"})
 	})
 
-	protected.POST("/applications/:id/reject", middleware.RequireType("company"), func(c *gin.Context) {
-		companyID := c.GetString(middleware.CtxUserID)
-		if err := apps.UpdateStatus(c.Request.Context(), c.Param("id"), companyID, "rejected"); err != nil {
+	protected.POST("/applications/:id/reject", middleware.RequireType("company"), canManage, func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		_, err := Decide(c.Request.Context(), apps, vac, events, hooks, orgID, c.GetString(middleware.CtxUserID), c.Param("id"), "rejected")
+		if errors.Is(err, ErrNotFound) {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		if err != nil {
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
 		c.JSON(200, gin.H{"ok": true, "status": "rejected"})
 	})
-	protected.POST("/applications/:id/viewed", middleware.RequireType("company"), func(c *gin.Context) {
-		companyID := c.GetString(middleware.CtxUserID)
+	protected.POST("/applications/:id/viewed", middleware.RequireType("company"), canManage, func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
 
-		if err := apps.MarkViewed(c.Request.Context(), c.Param("id"), companyID); err != nil {
+		if err := apps.MarkViewed(c.Request.Context(), c.Param("id"), orgID); err != nil {
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
@@ -247,16 +357,63 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo,
 
 	// Скрыть отклик для пользователя или компании
 	protected.POST("/applications/:id/hide", func(c *gin.Context) {
-		userID := c.GetString(middleware.CtxUserID)
+		ownerID := c.GetString(middleware.CtxUserID)
 		userType := c.GetString(middleware.CtxUserType)
 
 		isCompany := userType == "company"
+		if isCompany {
+			role := c.GetString(middleware.CtxOrgRole)
+			if role != models.OrgRoleOwner && role != models.OrgRoleRecruiter {
+				c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
+				return
+			}
+			ownerID = c.GetString(middleware.CtxOrgID)
+		}
 
-		if err := apps.Hide(c.Request.Context(), c.Param("id"), userID, isCompany); err != nil {
+		if err := apps.Hide(c.Request.Context(), c.Param("id"), ownerID, isCompany); err != nil {
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
 
 		c.JSON(200, gin.H{"ok": true})
 	})
+
+	// Назначить ответственного рекрутера
+	protected.POST("/applications/:id/assign", middleware.RequireType("company"), canManage, func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+
+		var req assignReq
+		if !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+		assignee := strings.TrimSpace(req.AssigneeID)
+
+		if assignee != "" {
+			orgOf, role, err := orgs.Resolve(c.Request.Context(), assignee)
+			if err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+			u, err := users.FindByUserID(c.Request.Context(), assignee)
+			if err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+			if u == nil || u.Type != models.UserTypeCompany || orgOf != orgID || role == models.OrgRoleViewer {
+				c.JSON(400, gin.H{"ok": false, "error": "invalid_assignee"})
+				return
+			}
+		}
+
+		ok, err := apps.Assign(c.Request.Context(), c.Param("id"), orgID, assignee)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "assigneeId": assignee})
+	})
 }
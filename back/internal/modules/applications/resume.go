@@ -0,0 +1,35 @@
+package applications
+
+import (
+	"context"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+)
+
+// Максимальная длина сопроводительного письма, символов
+const maxCoverLetter = 5000
+
+// resumeTitle — название резюме, которое получила компания: из снимка,
+// у откликов без снимка — из текущего резюме. cache — по snapshotId или resumeId.
+func resumeTitle(ctx context.Context, resumes *repo.ResumeRepo, snapshots *repo.ResumeSnapshotRepo,
+	a *models.Application, cache map[string]string) string {
+
+	key := a.SnapshotID
+	if key == "" {
+		key = a.ResumeID
+	}
+	if t, ok := cache[key]; ok {
+		return t
+	}
+	t := ""
+	if a.SnapshotID != "" {
+		if s, _ := snapshots.GetByID(ctx, a.SnapshotID); s != nil {
+			t = s.Title
+		}
+	} else if rr, _ := resumes.GetByID(ctx, a.ResumeID); rr != nil {
+		t = rr.Title
+	}
+	cache[key] = t
+	return t
+}
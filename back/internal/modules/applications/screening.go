@@ -0,0 +1,86 @@
+package applications
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Сколько условий на ответы анкеты принимает инбокс
+const maxAnswerFilters = 5
+
+// bindInboxFilter разбирает фильтры инбокса:
+// ?status=, ?assignee=me|none|<userId>, ?vacancyId=, ?knockedOut=true|false
+// и повторяемый ?answer=qid:значение | qid~текст | qid>=n | qid<=n
+func bindInboxFilter(c *gin.Context) (repo.InboxFilter, bool) {
+	f := repo.InboxFilter{
+		Status:    c.Query("status"),
+		Assignee:  strings.TrimSpace(c.Query("assignee")),
+		VacancyID: strings.TrimSpace(c.Query("vacancyId")),
+	}
+	if f.Assignee == "me" {
+		f.Assignee = c.GetString(middleware.CtxUserID)
+	}
+	if s := c.Query("knockedOut"); s != "" {
+		ko, err := strconv.ParseBool(s)
+		if err != nil {
+			return f, false
+		}
+		f.KnockedOut = &ko
+	}
+	raw := c.QueryArray("answer")
+	if len(raw) > maxAnswerFilters {
+		return f, false
+	}
+	for _, s := range raw {
+		af, ok := parseAnswerFilter(s)
+		if !ok {
+			return f, false
+		}
+		f.Answers = append(f.Answers, af)
+	}
+	return f, true
+}
+
+func parseAnswerFilter(s string) (repo.AnswerFilter, bool) {
+	i := strings.IndexAny(s, ":~<>")
+	if i <= 0 || i > 32 {
+		return repo.AnswerFilter{}, false
+	}
+	af := repo.AnswerFilter{QuestionID: s[:i]}
+	rest := s[i:]
+	switch {
+	case strings.HasPrefix(rest, ">="), strings.HasPrefix(rest, "<="):
+		n, err := strconv.ParseFloat(strings.TrimSpace(rest[2:]), 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return af, false
+		}
+		af.Op, af.Number = "gte", n
+		if rest[0] == '<' {
+			af.Op = "lte"
+		}
+		return af, true
+	case rest[0] == ':':
+		af.Op, af.Value = "eq", strings.TrimSpace(rest[1:])
+	case rest[0] == '~':
+		af.Op, af.Value = "contains", strings.TrimSpace(rest[1:])
+	default:
+		return af, false
+	}
+	return af, af.Value != "" && len(af.Value) <= 200
+}
+
+// answersText — ответы анкеты одной строкой для CSV
+func answersText(answers []models.ScreeningAnswer) string {
+	parts := make([]string, 0, len(answers))
+	for _, a := range answers {
+		parts = append(parts, a.Question+": "+a.Value)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -1,13 +1,15 @@
 package chat
 
 import (
-	"strings"
+	"errors"
 
 	"unicorn-auth/internal/http/httputil"
 	"unicorn-auth/internal/http/middleware"
 	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/notify"
 	"unicorn-auth/internal/repo"
 	"unicorn-auth/internal/security"
+	"unicorn-auth/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,36 +19,33 @@ type sendReq struct {
 }
 
 type chatItem struct {
-	ApplicationID string `json:"applicationId"`
-	VacancyID     string `json:"vacancyId"`
-	VacancyTitle  string `json:"vacancyTitle,omitempty"`
-	ResumeID      string `json:"resumeId"`
-	UserID        string `json:"userId"`
-	CompanyID     string `json:"companyId"`
-	CompanyName   string `json:"companyName,omitempty"`
-	Status        string `json:"status"`
-	Message       string `json:"message,omitempty"`
-	Viewed        bool   `json:"viewed"`
-	CreatedAt     string `json:"createdAt"`
-	UpdatedAt     string `json:"updatedAt"`
+	ApplicationID   string `json:"applicationId"`
+	VacancyID       string `json:"vacancyId"`
+	VacancyTitle    string `json:"vacancyTitle,omitempty"`
+	ResumeID        string `json:"resumeId"`
+	UserID          string `json:"userId"`
+	CompanyID       string `json:"companyId"`
+	CompanyName     string `json:"companyName,omitempty"`
+	CompanyVerified bool   `json:"companyVerified"`
+	Status          string `json:"status"`
+	Message         string `json:"message,omitempty"`
+	Viewed          bool   `json:"viewed"`
+	CreatedAt       string `json:"createdAt"`
+	UpdatedAt       string `json:"updatedAt"`
 }
 
-func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, apps *repo.ApplicationRepo, chatRepo *repo.ChatRepo, vac *repo.VacancyRepo, profiles *repo.ProfileRepo) {
+func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, orgs *repo.OrgRepo, apps *repo.ApplicationRepo, chatRepo *repo.ChatRepo, vac *repo.VacancyRepo, profiles *repo.ProfileRepo,
+	events *notify.Emitter, hooks *webhooks.Dispatcher) {
 	api := r.Group("/api")
 	protected := api.Group("")
 	protected.Use(middleware.RequireAuth(sec))
 	protected.Use(middleware.RequireMFAEnabled(sec, users))
+	protected.Use(middleware.ResolveOrg(orgs))
+
+	svc := NewService(users, apps, chatRepo, events, hooks)
 
 	canAccess := func(c *gin.Context, a *models.Application) bool {
-		uid := c.GetString(middleware.CtxUserID)
-		ut := c.GetString(middleware.CtxUserType)
-		if ut == "user" && a.UserID == uid {
-			return true
-		}
-		if ut == "company" && a.CompanyID == uid {
-			return true
-		}
-		return false
+		return CanAccess(actorOf(c), a)
 	}
 
 	// GET /api/chats/my - получить список чатов (applications) пользователя
@@ -60,7 +59,7 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, apps
 		if ut == "user" {
 			items, err = apps.ListByUserID(c.Request.Context(), uid)
 		} else if ut == "company" {
-			items, err = apps.ListByCompanyID(c.Request.Context(), uid)
+			items, err = apps.ListByCompanyID(c.Request.Context(), c.GetString(middleware.CtxOrgID))
 		} else {
 			c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
 			return
@@ -85,7 +84,7 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, apps
 		if ut == "user" {
 			items, err = apps.ListByUserID(c.Request.Context(), uid)
 		} else if ut == "company" {
-			items, err = apps.ListByCompanyID(c.Request.Context(), uid)
+			items, err = apps.ListByCompanyID(c.Request.Context(), c.GetString(middleware.CtxOrgID))
 		} else {
 			c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
 			return
@@ -99,6 +98,7 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, apps
 		// Обогащаем данные названиями вакансий и компаний
 		vTitle := map[string]string{}
 		cName := map[string]string{}
+		cVerified := map[string]bool{}
 
 		out := make([]chatItem, 0, len(items))
 
@@ -129,10 +129,13 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, apps
 			// Название компании
 			if n, ok := cName[a.CompanyID]; ok {
 				it.CompanyName = n
+				it.CompanyVerified = cVerified[a.CompanyID]
 			} else {
 				if p, _ := profiles.GetByUserID(c.Request.Context(), a.CompanyID); p != nil {
 					cName[a.CompanyID] = p.DisplayName
+					cVerified[a.CompanyID] = p.Verified
 					it.CompanyName = p.DisplayName
+					it.CompanyVerified = p.Verified
 				}
 			}
 
@@ -161,50 +164,85 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, apps
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
+
+		// У старых сообщений компании имени отправителя нет — подставляем текущее
+		names := map[string]string{}
+		for i := range items {
+			m := &items[i]
+			if m.SenderType != "company" || m.SenderName != "" {
+				continue
+			}
+			if n, ok := names[m.SenderID]; ok {
+				m.SenderName = n
+				continue
+			}
+			if u, _ := users.FindByUserID(c.Request.Context(), m.SenderID); u != nil {
+				names[m.SenderID] = u.DisplayName
+				m.SenderName = u.DisplayName
+			}
+		}
 		c.JSON(200, gin.H{"ok": true, "items": items})
 	})
 
 	protected.POST("/chat/:applicationId/messages", func(c *gin.Context) {
-		a, err := apps.GetByID(c.Request.Context(), c.Param("applicationId"))
-		if err != nil {
-			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+		var req sendReq
+		if !httputil.BindJSONStrict(c, &req, 32<<10) {
 			return
 		}
-		if a == nil {
+
+		m, err := svc.Send(c.Request.Context(), actorOf(c), c.Param("applicationId"), req.Text)
+		switch {
+		case errors.Is(err, ErrNotFound):
 			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
 			return
-		}
-		if !canAccess(c, a) {
+		case errors.Is(err, ErrForbidden):
 			c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
 			return
-		}
-
-		var req sendReq
-		if !httputil.BindJSONStrict(c, &req, 32<<10) {
-			return
-		}
-		txt := strings.TrimSpace(req.Text)
-		if txt == "" || len(txt) > 2000 {
+		case errors.Is(err, ErrBadText):
 			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
 			return
-		}
-
-		m := &models.ChatMessage{
-			ApplicationID: a.ApplicationID,
-			SenderID:      c.GetString(middleware.CtxUserID),
-			SenderType:    c.GetString(middleware.CtxUserType),
-			Text:          txt,
-		}
-		if err := chatRepo.Create(c.Request.Context(), m); err != nil {
+		case err != nil:
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
 
-		// При отправке сообщения снимаем скрытие
-		if err := apps.UnhideOnNewMessage(c.Request.Context(), a.ApplicationID); err != nil {
-			// Логируем ошибку, но не прерываем процесс
-		}
-
 		c.JSON(200, gin.H{"ok": true, "messageId": m.MessageID, "createdAt": m.CreatedAt})
 	})
 }
+
+func actorOf(c *gin.Context) Actor {
+	return Actor{
+		UserID:   c.GetString(middleware.CtxUserID),
+		UserType: c.GetString(middleware.CtxUserType),
+		OrgID:    c.GetString(middleware.CtxOrgID),
+		OrgRole:  c.GetString(middleware.CtxOrgRole),
+	}
+}
+
+// notifyMessage уведомляет другую сторону переписки: компании — ответственного
+// рекрутера или всю команду, соискателю — его самого
+func notifyMessage(events *notify.Emitter, a *models.Application, m *models.ChatMessage) {
+	ev := notify.Event{
+		Type:    notify.EventChatMessage,
+		ActorID: m.SenderID,
+		Title:   "Новое сообщение",
+		Link:    "/chat/" + a.ApplicationID,
+		Data:    map[string]string{"applicationId": a.ApplicationID, "messageId": m.MessageID},
+	}
+	if m.SenderType == "company" {
+		ev.UserID = a.UserID
+		if m.SenderName != "" {
+			ev.Title = "Новое сообщение от " + m.SenderName
+		}
+	} else if a.AssigneeID != "" {
+		ev.UserID = a.AssigneeID
+	} else {
+		ev.OrgID = a.CompanyID
+	}
+	text := []rune(m.Text)
+	if len(text) > 200 {
+		text = append(text[:200], '…')
+	}
+	ev.Text = string(text)
+	events.Emit(ev)
+}
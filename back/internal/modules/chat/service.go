@@ -0,0 +1,98 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/notify"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/webhooks"
+)
+
+var (
+	ErrNotFound  = errors.New("not_found")
+	ErrForbidden = errors.New("forbidden")
+	ErrBadText   = errors.New("bad_request")
+)
+
+// Actor — от чьего имени пишут: соискатель или участник организации
+type Actor struct {
+	UserID   string
+	UserType string
+	OrgID    string
+	OrgRole  string
+}
+
+// CanAccess: соискатель — свой отклик, компания — любой участник организации-получателя
+func CanAccess(actor Actor, a *models.Application) bool {
+	if actor.UserType == "user" && a.UserID == actor.UserID {
+		return true
+	}
+	if actor.UserType == "company" && a.CompanyID == actor.OrgID {
+		return true
+	}
+	return false
+}
+
+// Service — отправка сообщений в чат по отклику; общая для API и Telegram-бота
+type Service struct {
+	users    *repo.UserRepo
+	apps     *repo.ApplicationRepo
+	chatRepo *repo.ChatRepo
+	events   *notify.Emitter
+	hooks    *webhooks.Dispatcher
+}
+
+func NewService(users *repo.UserRepo, apps *repo.ApplicationRepo, chatRepo *repo.ChatRepo, events *notify.Emitter,
+	hooks *webhooks.Dispatcher) *Service {
+	return &Service{users: users, apps: apps, chatRepo: chatRepo, events: events, hooks: hooks}
+}
+
+// Send проверяет доступ, сохраняет сообщение, снимает скрытие и уведомляет собеседника
+func (s *Service) Send(ctx context.Context, actor Actor, appID, text string) (*models.ChatMessage, error) {
+	a, err := s.apps.GetByID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrNotFound
+	}
+	if !CanAccess(actor, a) || actor.OrgRole == models.OrgRoleViewer {
+		return nil, ErrForbidden
+	}
+
+	txt := strings.TrimSpace(text)
+	if txt == "" || len(txt) > 2000 {
+		return nil, ErrBadText
+	}
+
+	m := &models.ChatMessage{
+		ApplicationID: a.ApplicationID,
+		SenderID:      actor.UserID,
+		SenderType:    actor.UserType,
+		Text:          txt,
+	}
+	// От компании пишет конкретный участник — сохраняем его имя
+	if m.SenderType == "company" {
+		if u, _ := s.users.FindByUserID(ctx, m.SenderID); u != nil {
+			m.SenderName = u.DisplayName
+		}
+	}
+	if err := s.chatRepo.Create(ctx, m); err != nil {
+		return nil, err
+	}
+
+	// При отправке сообщения снимаем скрытие
+	if err := s.apps.UnhideOnNewMessage(ctx, a.ApplicationID); err != nil {
+		// Логируем ошибку, но не прерываем процесс
+	}
+
+	notifyMessage(s.events, a, m)
+	// В ATS компании уходят только сообщения кандидата
+	if m.SenderType == "user" {
+		s.hooks.Publish(ctx, a.CompanyID, webhooks.EventMessageReceived, webhooks.MessageData(m))
+	}
+	return m, nil
+}
@@ -5,14 +5,19 @@ import (
 	"regexp"
 	"strings"
 
+	"unicorn-auth/internal/http/middleware"
 	"unicorn-auth/internal/models"
 	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/security"
+	"unicorn-auth/internal/verification"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
-func Register(r *gin.Engine, profiles *repo.ProfileRepo) {
+func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, orgs *repo.OrgRepo, profiles *repo.ProfileRepo,
+	vac *repo.VacancyRepo, apps *repo.ApplicationRepo, reviews *repo.ReviewRepo,
+	verifs *repo.VerificationRepo, checker *verification.Checker) {
 	api := r.Group("/api")
 
 	api.GET("/companies/search", func(c *gin.Context) {
@@ -36,12 +41,27 @@ func Register(r *gin.Engine, profiles *repo.ProfileRepo) {
 		if industry != "" {
 			filter["industry"] = industry
 		}
+		if c.Query("verified") == "true" {
+			filter["verified"] = true
+		}
 
 		items, err := profiles.SearchCompanies(c.Request.Context(), filter, 50)
 		if err != nil {
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
+		for i := range items {
+			items[i].Requisites = nil
+		}
 		c.JSON(200, gin.H{"ok": true, "items": items})
 	})
+
+	protected := api.Group("/company")
+	protected.Use(middleware.RequireAuth(sec))
+	protected.Use(middleware.RequireType("company"))
+	protected.Use(middleware.RequireMFAEnabled(sec, users))
+	protected.Use(middleware.ResolveOrg(orgs))
+
+	registerVerification(protected, profiles, vac, verifs, checker)
+	registerPage(api, protected, sec, users, profiles, vac, apps, reviews)
 }
@@ -0,0 +1,270 @@
+package company
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/security"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	statsWindow = 180 * 24 * time.Hour // статистика по откликам за полгода
+	statsLimit  = 1000                 // и не больше стольких последних откликов
+	statsTTL    = 10 * time.Minute
+)
+
+type reviewReq struct {
+	Rating    int    `json:"rating"`
+	Text      string `json:"text"`
+	Anonymous bool   `json:"anonymous,omitempty"`
+}
+
+type replyReq struct {
+	Text string `json:"text"`
+}
+
+// statsCache — статистика ответов считается агрегацией, поэтому кэшируется
+type statsCache struct {
+	mu    sync.Mutex
+	items map[string]cachedStats
+}
+
+type cachedStats struct {
+	stats repo.ResponseStats
+	at    time.Time
+}
+
+func (sc *statsCache) get(ctx context.Context, apps *repo.ApplicationRepo, orgID string) (repo.ResponseStats, error) {
+	sc.mu.Lock()
+	cs, ok := sc.items[orgID]
+	sc.mu.Unlock()
+	if ok && time.Since(cs.at) < statsTTL {
+		return cs.stats, nil
+	}
+
+	st, err := apps.ResponseStats(ctx, orgID, time.Now().UTC().Add(-statsWindow), statsLimit)
+	if err != nil {
+		return st, err
+	}
+	sc.mu.Lock()
+	sc.items[orgID] = cachedStats{stats: st, at: time.Now()}
+	sc.mu.Unlock()
+	return st, nil
+}
+
+// registerPage — публичная страница компании и отзывы соискателей
+func registerPage(api, companyGroup *gin.RouterGroup, sec *security.Security, users *repo.UserRepo, profiles *repo.ProfileRepo,
+	vac *repo.VacancyRepo, apps *repo.ApplicationRepo, reviews *repo.ReviewRepo) {
+
+	cache := &statsCache{items: map[string]cachedStats{}}
+
+	// loadCompany отдает публичный профиль компании или пишет 404
+	loadCompany := func(c *gin.Context, companyID string) *models.Profile {
+		p, err := profiles.GetByUserID(c.Request.Context(), companyID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return nil
+		}
+		if p != nil && p.Type == models.UserTypeCompany {
+			u, err := users.FindByUserID(c.Request.Context(), companyID)
+			if err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return nil
+			}
+			if u != nil && !u.Status.Deleted && !u.Status.Blocked {
+				p.Requisites = nil
+				return p
+			}
+		}
+		c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+		return nil
+	}
+
+	// GET /api/companies/:companyId - профиль, открытые вакансии, статистика и отзывы
+	api.GET("/companies/:companyId", func(c *gin.Context) {
+		p := loadCompany(c, c.Param("companyId"))
+		if p == nil {
+			return
+		}
+		ctx := c.Request.Context()
+
+		all, err := vac.ListByCompanyID(ctx, p.UserID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		now := time.Now().UTC()
+		active := make([]models.Vacancy, 0, len(all))
+		for _, v := range all {
+			if v.Open(now) {
+				v.HideKnockouts()
+				active = append(active, v)
+			}
+		}
+
+		stats, err := cache.get(ctx, apps, p.UserID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		summary, err := reviews.Summary(ctx, p.UserID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		latest, err := reviews.ListByCompany(ctx, p.UserID, 5, 0)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"ok":        true,
+			"profile":   p,
+			"vacancies": active,
+			"stats":     stats,
+			"reviews":   gin.H{"summary": summary, "items": latest},
+		})
+	})
+
+	// GET /api/companies/:companyId/reviews?skip=
+	api.GET("/companies/:companyId/reviews", func(c *gin.Context) {
+		skip, _ := strconv.ParseInt(c.Query("skip"), 10, 64)
+		if skip < 0 {
+			skip = 0
+		}
+		items, err := reviews.ListByCompany(c.Request.Context(), c.Param("companyId"), 20, skip)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items})
+	})
+
+	applicant := api.Group("/companies/:companyId/reviews")
+	applicant.Use(middleware.RequireAuth(sec))
+	applicant.Use(middleware.RequireType("user"))
+	applicant.Use(middleware.RequireMFAEnabled(sec, users))
+
+	// POST /api/companies/:companyId/reviews - оставить или изменить свой отзыв
+	applicant.POST("", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+		var req reviewReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+		text := strings.TrimSpace(req.Text)
+		if req.Rating < 1 || req.Rating > 5 || utf8.RuneCountInString(text) > 4000 {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_review"})
+			return
+		}
+		p := loadCompany(c, c.Param("companyId"))
+		if p == nil {
+			return
+		}
+
+		applied, accepted, err := apps.ApplicantOf(c.Request.Context(), uid, p.UserID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !applied {
+			c.JSON(403, gin.H{"ok": false, "error": "not_an_applicant"})
+			return
+		}
+
+		rv := &models.CompanyReview{
+			CompanyID:   p.UserID,
+			UserID:      uid,
+			Anonymous:   req.Anonymous,
+			WasAccepted: accepted,
+			Rating:      req.Rating,
+			Text:        text,
+		}
+		if !req.Anonymous {
+			if u, _ := users.FindByUserID(c.Request.Context(), uid); u != nil {
+				rv.AuthorName = u.DisplayName
+			}
+		}
+		saved, err := reviews.Upsert(c.Request.Context(), rv)
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(409, gin.H{"ok": false, "error": "conflict"})
+				return
+			}
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "review": saved})
+	})
+
+	// DELETE /api/companies/:companyId/reviews/:reviewId - удалить свой отзыв
+	applicant.DELETE("/:reviewId", func(c *gin.Context) {
+		ok, err := reviews.DeleteOwn(c.Request.Context(), c.Param("reviewId"), c.GetString(middleware.CtxUserID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	canReply := middleware.RequireOrgRole(models.OrgRoleOwner, models.OrgRoleRecruiter)
+
+	// PUT /api/company/reviews/:reviewId/reply - ответ компании на отзыв
+	companyGroup.PUT("/reviews/:reviewId/reply", canReply, func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		uid := c.GetString(middleware.CtxUserID)
+		var req replyReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+		text := strings.TrimSpace(req.Text)
+		if text == "" || utf8.RuneCountInString(text) > 4000 {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_reply"})
+			return
+		}
+		reply := &models.ReviewReply{Text: text, AuthorID: uid, CreatedAt: time.Now().UTC()}
+		if u, _ := users.FindByUserID(c.Request.Context(), uid); u != nil {
+			reply.AuthorName = u.DisplayName
+		}
+		ok, err := reviews.SetReply(c.Request.Context(), c.Param("reviewId"), orgID, reply)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "reply": reply})
+	})
+
+	// DELETE /api/company/reviews/:reviewId/reply
+	companyGroup.DELETE("/reviews/:reviewId/reply", canReply, func(c *gin.Context) {
+		ok, err := reviews.SetReply(c.Request.Context(), c.Param("reviewId"), c.GetString(middleware.CtxOrgID), nil)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+}
@@ -0,0 +1,259 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/billing"
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/telemetry"
+	"unicorn-auth/internal/verification"
+
+	"github.com/gin-gonic/gin"
+)
+
+// минимальный интервал между проверками одной заявки
+const checkInterval = 10 * time.Second
+
+type domainReq struct {
+	Domain string `json:"domain"`
+	Method string `json:"method"` // dns | file
+}
+
+type documentsReq struct {
+	LegalName string `json:"legalName"`
+	INN       string `json:"inn"`
+	OGRN      string `json:"ogrn"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// registerVerification — подтверждение компании: домен проверяется автоматически,
+// документы уходят на модерацию в админку
+func registerVerification(protected *gin.RouterGroup, profiles *repo.ProfileRepo, vac *repo.VacancyRepo,
+	verifs *repo.VerificationRepo, checker *verification.Checker) {
+
+	ownerOnly := middleware.RequireOrgRole(models.OrgRoleOwner)
+
+	// GET /api/company/verification - текущий значок и история заявок
+	protected.GET("/verification", func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		p, err := profiles.GetByUserID(c.Request.Context(), orgID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		items, err := verifs.ListByCompany(c.Request.Context(), orgID, 20)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		resp := gin.H{"ok": true, "verified": false, "items": items}
+		if p != nil && p.Verified {
+			resp["verified"] = true
+			resp["method"] = p.VerifiedMethod
+			resp["domain"] = p.VerifiedDomain
+			resp["verifiedAt"] = p.VerifiedAt
+		}
+		c.JSON(200, resp)
+	})
+
+	// POST /api/company/verification/domain - выдать токен для TXT-записи или файла
+	protected.POST("/verification/domain", ownerOnly, func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		var req domainReq
+		if !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+		if req.Method != models.VerifyMethodDNS && req.Method != models.VerifyMethodFile {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_method"})
+			return
+		}
+		domain, ok := verification.NormalizeDomain(req.Domain)
+		if !ok {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_domain"})
+			return
+		}
+		if !hasProfile(c, profiles, orgID) {
+			return
+		}
+		if !domainFree(c, profiles, orgID, domain) {
+			return
+		}
+
+		// Повторный запрос на тот же домен возвращает прежний токен
+		v, err := verifs.FindPending(c.Request.Context(), orgID, req.Method, domain)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if v == nil {
+			v = &models.CompanyVerification{
+				CompanyID:   orgID,
+				Method:      req.Method,
+				Domain:      domain,
+				Token:       verification.NewToken(),
+				SubmittedBy: c.GetString(middleware.CtxUserID),
+			}
+			if err := verifs.Create(c.Request.Context(), v); err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+		}
+		c.JSON(200, gin.H{"ok": true, "verification": v, "instructions": instructions(v)})
+	})
+
+	// POST /api/company/verification/:verificationId/check - проверить домен сейчас
+	protected.POST("/verification/:verificationId/check", ownerOnly, func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		v, err := verifs.GetByID(c.Request.Context(), c.Param("verificationId"))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if v == nil || v.CompanyID != orgID || v.Method == models.VerifyMethodDocuments {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		if v.Status != "pending" {
+			c.JSON(409, gin.H{"ok": false, "error": "not_pending", "status": v.Status})
+			return
+		}
+		// Время проверки занимается атомарно: из параллельных запросов проходит один
+		claimed, err := verifs.ClaimCheck(c.Request.Context(), v.VerificationID, checkInterval)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if claimed == nil {
+			telemetry.RateLimited("verification_check")
+			c.JSON(429, gin.H{"ok": false, "error": "too_many_checks"})
+			return
+		}
+		v = claimed
+		if !domainFree(c, profiles, orgID, v.Domain) {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 15*time.Second)
+		defer cancel()
+		if v.Method == models.VerifyMethodDNS {
+			err = checker.CheckDNS(ctx, v.Domain, v.Token)
+		} else {
+			err = checker.CheckFile(ctx, v.Domain, v.Token)
+		}
+
+		lastErr := ""
+		if err != nil {
+			lastErr = err.Error()
+		}
+		// Значок выдается до отметки заявки: уникальный индекс не даст
+		// подтвердить домен двум компаниям одновременно
+		if lastErr == "" {
+			err = verification.Grant(c.Request.Context(), profiles, vac, orgID, v.Method, v.Domain)
+			if errors.Is(err, repo.ErrDomainTaken) {
+				_ = verifs.MarkChecked(c.Request.Context(), v.VerificationID, false, "domain_taken")
+				c.JSON(409, gin.H{"ok": false, "error": "domain_taken"})
+				return
+			}
+			if err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+		}
+		if err := verifs.MarkChecked(c.Request.Context(), v.VerificationID, lastErr == "", lastErr); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if lastErr != "" {
+			c.JSON(200, gin.H{"ok": true, "verified": false, "reason": lastErr, "instructions": instructions(v)})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "verified": true, "domain": v.Domain})
+	})
+
+	// POST /api/company/verification/documents - ИНН/ОГРН на проверку администратором
+	protected.POST("/verification/documents", ownerOnly, func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		var req documentsReq
+		if !httputil.BindJSONStrict(c, &req, 8<<10) {
+			return
+		}
+		req.LegalName = strings.TrimSpace(req.LegalName)
+		req.INN = strings.TrimSpace(req.INN)
+		req.OGRN = strings.TrimSpace(req.OGRN)
+		req.Comment = strings.TrimSpace(req.Comment)
+		if req.LegalName == "" || len(req.LegalName) > 256 || len(req.Comment) > 2000 ||
+			!billing.DigitsOnly(req.INN, 10, 12) || !billing.DigitsOnly(req.OGRN, 13, 15) {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_documents"})
+			return
+		}
+		if !hasProfile(c, profiles, orgID) {
+			return
+		}
+
+		pending, err := verifs.FindPending(c.Request.Context(), orgID, models.VerifyMethodDocuments, "")
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if pending != nil {
+			c.JSON(409, gin.H{"ok": false, "error": "already_pending", "verificationId": pending.VerificationID})
+			return
+		}
+
+		v := &models.CompanyVerification{
+			CompanyID:   orgID,
+			Method:      models.VerifyMethodDocuments,
+			LegalName:   req.LegalName,
+			INN:         req.INN,
+			OGRN:        req.OGRN,
+			Comment:     req.Comment,
+			SubmittedBy: c.GetString(middleware.CtxUserID),
+		}
+		if err := verifs.Create(c.Request.Context(), v); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(201, gin.H{"ok": true, "verification": v})
+	})
+}
+
+// instructions — что компании нужно разместить у себя
+func instructions(v *models.CompanyVerification) gin.H {
+	if v.Method == models.VerifyMethodDNS {
+		return gin.H{"type": "TXT", "host": verification.TXTHost(v.Domain), "value": verification.Value(v.Token)}
+	}
+	return gin.H{"url": verification.FileURL(v.Domain), "content": verification.Value(v.Token)}
+}
+
+func hasProfile(c *gin.Context, profiles *repo.ProfileRepo, orgID string) bool {
+	p, err := profiles.GetByUserID(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+		return false
+	}
+	if p == nil {
+		c.JSON(409, gin.H{"ok": false, "error": "profile_not_found"})
+		return false
+	}
+	return true
+}
+
+// domainFree отвечает 409, если домен уже подтвердила другая компания
+func domainFree(c *gin.Context, profiles *repo.ProfileRepo, orgID, domain string) bool {
+	taken, err := verification.DomainTaken(c.Request.Context(), profiles, orgID, domain)
+	if err != nil {
+		c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+		return false
+	}
+	if taken {
+		c.JSON(409, gin.H{"ok": false, "error": "domain_taken"})
+		return false
+	}
+	return true
+}
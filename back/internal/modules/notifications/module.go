@@ -0,0 +1,183 @@
+package notifications
+
+import (
+	netmail "net/mail"
+	"strconv"
+	"strings"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/notify"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+type prefsReq struct {
+	Email  *string             `json:"email,omitempty"`
+	Events map[string][]string `json:"events,omitempty"`
+}
+
+type readAllReq struct {
+	Type string `json:"type,omitempty"`
+}
+
+func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, notifications *repo.NotificationRepo) {
+	api := r.Group("/api/notifications")
+	api.Use(middleware.RequireAuth(sec))
+	api.Use(middleware.RequireMFAEnabled(sec, users))
+
+	// GET /api/notifications?unread=true&type=&skip= - лента и счетчики непрочитанных
+	api.GET("", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+		skip, _ := strconv.ParseInt(c.Query("skip"), 10, 64)
+		if skip < 0 {
+			skip = 0
+		}
+		items, err := notifications.List(c.Request.Context(), uid, c.Query("type"), c.Query("unread") == "true", 50, skip)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		total, byType, err := notifications.UnreadCounts(c.Request.Context(), uid)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items, "unread": total, "unreadByType": byType})
+	})
+
+	// GET /api/notifications/unread - только счетчики, для бейджа в шапке
+	api.GET("/unread", func(c *gin.Context) {
+		total, byType, err := notifications.UnreadCounts(c.Request.Context(), c.GetString(middleware.CtxUserID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "unread": total, "unreadByType": byType})
+	})
+
+	// POST /api/notifications/read-all - {type} необязателен
+	api.POST("/read-all", func(c *gin.Context) {
+		var req readAllReq
+		if c.Request.ContentLength != 0 && !httputil.BindJSONStrict(c, &req, 1<<10) {
+			return
+		}
+		n, err := notifications.MarkAllRead(c.Request.Context(), c.GetString(middleware.CtxUserID), req.Type)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "updated": n})
+	})
+
+	// POST /api/notifications/:notificationId/read
+	api.POST("/:notificationId/read", func(c *gin.Context) {
+		ok, err := notifications.MarkRead(c.Request.Context(), c.GetString(middleware.CtxUserID), c.Param("notificationId"))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	// GET /api/notifications/preferences - настройки с подставленными значениями по умолчанию
+	api.GET("/preferences", func(c *gin.Context) {
+		p, err := notifications.GetPrefs(c.Request.Context(), c.GetString(middleware.CtxUserID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "preferences": effective(p), "channels": notify.Channels, "eventTypes": notify.EventTypes})
+	})
+
+	// PUT /api/notifications/preferences - email и каналы по типам событий; неуказанные типы не меняются
+	api.PUT("/preferences", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+		var req prefsReq
+		if !httputil.BindJSONStrict(c, &req, 8<<10) {
+			return
+		}
+
+		p, err := notifications.GetPrefs(c.Request.Context(), uid)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if p == nil {
+			p = &models.NotificationPrefs{UserID: uid}
+		}
+		if p.Events == nil {
+			p.Events = map[string][]string{}
+		}
+
+		if req.Email != nil {
+			email := strings.TrimSpace(*req.Email)
+			if email != "" {
+				addr, err := netmail.ParseAddress(email)
+				if err != nil || addr.Address != email {
+					c.JSON(400, gin.H{"ok": false, "error": "invalid_email"})
+					return
+				}
+			}
+			p.Email = email
+		}
+		for typ, channels := range req.Events {
+			if !contains(notify.EventTypes, typ) {
+				c.JSON(400, gin.H{"ok": false, "error": "invalid_event_type", "type": typ})
+				return
+			}
+			clean := []string{}
+			for _, ch := range channels {
+				if !contains(notify.Channels, ch) {
+					c.JSON(400, gin.H{"ok": false, "error": "invalid_channel", "channel": ch})
+					return
+				}
+				if !contains(clean, ch) {
+					clean = append(clean, ch)
+				}
+			}
+			p.Events[typ] = clean
+		}
+
+		if err := notifications.SavePrefs(c.Request.Context(), p); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "preferences": effective(p)})
+	})
+}
+
+// effective раскрывает настройки: для типов без записи включены все каналы
+func effective(p *models.NotificationPrefs) gin.H {
+	events := map[string][]string{}
+	for _, typ := range notify.EventTypes {
+		list := []string{}
+		for _, ch := range notify.Channels {
+			if notify.Enabled(p, typ, ch) {
+				list = append(list, ch)
+			}
+		}
+		events[typ] = list
+	}
+	email := ""
+	if p != nil {
+		email = p.Email
+	}
+	return gin.H{"email": email, "events": events}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,413 @@
+package org
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/logging"
+	"unicorn-auth/internal/mail"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Config struct {
+	FrontendURL string        // адрес фронта для ссылки в приглашении
+	InviteTTL   time.Duration // срок действия приглашения
+}
+
+type renameReq struct {
+	Name string `json:"name"`
+}
+
+type inviteReq struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type joinReq struct {
+	Token string `json:"token"`
+}
+
+type roleReq struct {
+	Role string `json:"role"`
+}
+
+type memberItem struct {
+	UserID      string    `json:"userId"`
+	DisplayName string    `json:"displayName,omitempty"`
+	Login       string    `json:"login,omitempty"`
+	Email       string    `json:"email,omitempty"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joinedAt,omitempty"`
+}
+
+func Register(r *gin.Engine, cfg Config, sec *security.Security, users *repo.UserRepo, orgs *repo.OrgRepo,
+	profiles *repo.ProfileRepo, vac *repo.VacancyRepo, apps *repo.ApplicationRepo, mailer mail.Sender) {
+
+	if cfg.InviteTTL <= 0 {
+		cfg.InviteTTL = 7 * 24 * time.Hour
+	}
+
+	api := r.Group("/api")
+	protected := api.Group("/org")
+	protected.Use(middleware.RequireAuth(sec))
+	protected.Use(middleware.RequireType("company"))
+	protected.Use(middleware.RequireMFAEnabled(sec, users))
+	protected.Use(middleware.ResolveOrg(orgs))
+
+	ownerOnly := middleware.RequireOrgRole(models.OrgRoleOwner)
+
+	// GET /api/org - организация, роль текущего пользователя и участники
+	protected.GET("", func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+
+		owner, err := users.FindByUserID(c.Request.Context(), orgID)
+		if err != nil || owner == nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		o, err := orgs.GetOrg(c.Request.Context(), orgID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if o == nil {
+			// Команда еще не создавалась: организация из одного владельца
+			o = &models.Organization{OrgID: orgID, OwnerID: orgID, Name: owner.DisplayName, CreatedAt: owner.CreatedAt}
+		}
+
+		members, err := orgs.ListMembers(c.Request.Context(), orgID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if len(members) == 0 {
+			members = []models.OrgMember{{OrgID: orgID, UserID: orgID, Role: models.OrgRoleOwner, JoinedAt: owner.CreatedAt}}
+		}
+
+		out := make([]memberItem, 0, len(members))
+		for _, m := range members {
+			it := memberItem{UserID: m.UserID, Email: m.Email, Role: m.Role, JoinedAt: m.JoinedAt}
+			if u, _ := users.FindByUserID(c.Request.Context(), m.UserID); u != nil {
+				it.DisplayName = u.DisplayName
+				it.Login = u.Login
+			}
+			out = append(out, it)
+		}
+
+		c.JSON(200, gin.H{
+			"ok":           true,
+			"organization": o,
+			"role":         c.GetString(middleware.CtxOrgRole),
+			"members":      out,
+		})
+	})
+
+	// PATCH /api/org - переименовать организацию
+	protected.PATCH("", ownerOnly, func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+
+		var req renameReq
+		if !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+		name := strings.TrimSpace(req.Name)
+		if name == "" || len(name) > 128 {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		if _, err := orgs.EnsureOrg(c.Request.Context(), orgID, name); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if err := orgs.UpdateName(c.Request.Context(), orgID, name); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	// GET /api/org/invites - действующие приглашения
+	protected.GET("/invites", ownerOnly, func(c *gin.Context) {
+		items, err := orgs.ListInvites(c.Request.Context(), c.GetString(middleware.CtxOrgID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items})
+	})
+
+	// POST /api/org/invites - пригласить участника по email
+	protected.POST("/invites", ownerOnly, func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+		orgID := c.GetString(middleware.CtxOrgID)
+
+		var req inviteReq
+		if !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+		addr, err := netmail.ParseAddress(strings.TrimSpace(req.Email))
+		if err != nil || len(addr.Address) > 254 {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_email"})
+			return
+		}
+		if req.Role != models.OrgRoleRecruiter && req.Role != models.OrgRoleViewer {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_role"})
+			return
+		}
+
+		owner, err := users.FindByUserID(c.Request.Context(), orgID)
+		if err != nil || owner == nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		o, err := orgs.EnsureOrg(c.Request.Context(), orgID, owner.DisplayName)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+
+		token, hash := newInviteToken()
+		inv := &models.OrgInvite{
+			OrgID:     orgID,
+			Email:     strings.ToLower(addr.Address),
+			Role:      req.Role,
+			TokenHash: hash,
+			InvitedBy: uid,
+			ExpiresAt: time.Now().UTC().Add(cfg.InviteTTL),
+		}
+		if err := orgs.CreateInvite(c.Request.Context(), inv); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+
+		link := strings.TrimRight(cfg.FrontendURL, "/") + "/team/join?token=" + token
+		err = mailer.Send(c.Request.Context(), mail.Message{
+			To:      inv.Email,
+			Subject: "Приглашение в команду " + o.Name + " на Unicorn",
+			Text: fmt.Sprintf("Вас пригласили в команду «%s» на Unicorn (роль: %s).\n\n"+
+				"Войдите или зарегистрируйтесь как компания и перейдите по ссылке:\n%s\n\n"+
+				"Ссылка действует до %s.",
+				o.Name, roleName(inv.Role), link, inv.ExpiresAt.Format("02.01.2006 15:04 MST")),
+		})
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("send invite", "invite_id", inv.InviteID, "err", err)
+		}
+
+		c.JSON(201, gin.H{"ok": true, "invite": inv, "emailSent": err == nil})
+	})
+
+	// DELETE /api/org/invites/:inviteId - отозвать приглашение
+	protected.DELETE("/invites/:inviteId", ownerOnly, func(c *gin.Context) {
+		ok, err := orgs.RevokeInvite(c.Request.Context(), c.GetString(middleware.CtxOrgID), c.Param("inviteId"))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	// POST /api/org/join - принять приглашение по токену из письма
+	protected.POST("/join", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+
+		var req joinReq
+		if !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+		if strings.TrimSpace(req.Token) == "" {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		hash := hashToken(strings.TrimSpace(req.Token))
+
+		// Приглашение принимает только аккаунт, чей логин — email из приглашения
+		u, err := users.FindByUserID(c.Request.Context(), uid)
+		if err != nil || u == nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		pending, err := orgs.FindInvite(c.Request.Context(), hash)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if pending == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "invite_not_found"})
+			return
+		}
+		email := strings.ToLower(strings.TrimSpace(u.Login))
+		if email != pending.Email {
+			c.JSON(403, gin.H{"ok": false, "error": "invite_email_mismatch"})
+			return
+		}
+
+		// Вступить можно только без своей команды и без своих активных вакансий
+		m, err := orgs.MemberOf(c.Request.Context(), uid)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if m != nil {
+			c.JSON(409, gin.H{"ok": false, "error": "already_member"})
+			return
+		}
+		cnt, err := vac.CountActiveByCompany(c.Request.Context(), uid)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if cnt > 0 {
+			c.JSON(409, gin.H{"ok": false, "error": "has_own_vacancies"})
+			return
+		}
+
+		inv, err := orgs.ClaimInvite(c.Request.Context(), hash, uid)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if inv == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "invite_not_found"})
+			return
+		}
+		if inv.OrgID == uid {
+			_ = orgs.ReopenInvite(c.Request.Context(), inv.InviteID)
+			c.JSON(409, gin.H{"ok": false, "error": "already_member"})
+			return
+		}
+
+		err = orgs.AddMember(c.Request.Context(), &models.OrgMember{
+			OrgID:     inv.OrgID,
+			UserID:    uid,
+			Role:      inv.Role,
+			Email:     email,
+			InvitedBy: inv.InvitedBy,
+		})
+		if err != nil {
+			_ = orgs.ReopenInvite(c.Request.Context(), inv.InviteID)
+			if errors.Is(err, repo.ErrAlreadyMember) {
+				c.JSON(409, gin.H{"ok": false, "error": "already_member"})
+				return
+			}
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+
+		// Черновики, архив и отклики аккаунта переходят в организацию, иначе
+		// они недоступны, пока пользователь в команде. Если перенос не удался,
+		// вступление откатывается: повторный запрос перенесет оставшееся
+		verified := false
+		if p, _ := profiles.GetByUserID(c.Request.Context(), inv.OrgID); p != nil {
+			verified = p.Verified
+		}
+		movedVac, err := vac.MoveToOrg(c.Request.Context(), uid, inv.OrgID, verified)
+		var movedApps int64
+		if err == nil {
+			movedApps, err = apps.MoveToOrg(c.Request.Context(), uid, inv.OrgID)
+		}
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("move account data to org", "user_id", uid, "org_id", inv.OrgID, "err", err)
+			_, _ = orgs.RemoveMember(c.Request.Context(), inv.OrgID, uid)
+			_ = orgs.ReopenInvite(c.Request.Context(), inv.InviteID)
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+
+		logging.FromContext(c.Request.Context()).Info("member joined", "user_id", uid, "org_id", inv.OrgID, "role", inv.Role,
+			"moved_vacancies", movedVac, "moved_applications", movedApps)
+		c.JSON(200, gin.H{"ok": true, "orgId": inv.OrgID, "role": inv.Role,
+			"movedVacancies": movedVac, "movedApplications": movedApps})
+	})
+
+	// PATCH /api/org/members/:userId - сменить роль участника
+	protected.PATCH("/members/:userId", ownerOnly, func(c *gin.Context) {
+		var req roleReq
+		if !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+		if req.Role != models.OrgRoleRecruiter && req.Role != models.OrgRoleViewer {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_role"})
+			return
+		}
+		ok, err := orgs.UpdateMemberRole(c.Request.Context(), c.GetString(middleware.CtxOrgID), c.Param("userId"), req.Role)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "member_not_found"})
+			return
+		}
+		// Наблюдатель не может вести кандидатов
+		if req.Role == models.OrgRoleViewer {
+			if err := apps.UnassignMember(c.Request.Context(), c.GetString(middleware.CtxOrgID), c.Param("userId")); err != nil {
+				logging.FromContext(c.Request.Context()).Error("unassign applications", "user_id", c.Param("userId"), "err", err)
+			}
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	// DELETE /api/org/members/:userId - исключить участника (владелец) или выйти из команды (сам участник)
+	protected.DELETE("/members/:userId", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+		orgID := c.GetString(middleware.CtxOrgID)
+		target := c.Param("userId")
+
+		if target != uid && c.GetString(middleware.CtxOrgRole) != models.OrgRoleOwner {
+			c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
+			return
+		}
+		ok, err := orgs.RemoveMember(c.Request.Context(), orgID, target)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			// Владельца нельзя исключить
+			c.JSON(404, gin.H{"ok": false, "error": "member_not_found"})
+			return
+		}
+		if err := apps.UnassignMember(c.Request.Context(), orgID, target); err != nil {
+			logging.FromContext(c.Request.Context()).Error("unassign applications", "user_id", target, "err", err)
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+}
+
+func roleName(role string) string {
+	switch role {
+	case models.OrgRoleRecruiter:
+		return "рекрутер"
+	case models.OrgRoleViewer:
+		return "наблюдатель"
+	}
+	return role
+}
+
+func newInviteToken() (token string, hash string) {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/oklog/ulid/v2"
 
+	"unicorn-auth/internal/billing"
 	"unicorn-auth/internal/http/httputil"
 	"unicorn-auth/internal/http/middleware"
 	"unicorn-auth/internal/models"
@@ -41,6 +42,7 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, profi
 			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
 			return
 		}
+		p.Requisites = nil // реквизиты видит только владелец
 		c.JSON(200, gin.H{"ok": true, "profile": p})
 	})
 
@@ -217,6 +219,49 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, profi
 		c.JSON(200, gin.H{"ok": true})
 	})
 
+	// PUT /api/profile/me/requisites - реквизиты для счетов и чеков
+	protected.PUT("/profile/me/requisites", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+
+		var req models.Requisites
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+		fields := []*string{&req.LegalName, &req.INN, &req.KPP, &req.OGRN, &req.Address,
+			&req.BankName, &req.BIK, &req.Account, &req.CorrAccount}
+		for _, f := range fields {
+			*f = strings.TrimSpace(*f)
+			if len(*f) > 256 {
+				c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+				return
+			}
+		}
+		if req.LegalName == "" || !billing.DigitsOnly(req.INN, 10, 12) ||
+			(req.KPP != "" && !billing.DigitsOnly(req.KPP, 9, 9)) ||
+			(req.OGRN != "" && !billing.DigitsOnly(req.OGRN, 13, 15)) ||
+			(req.BIK != "" && !billing.DigitsOnly(req.BIK, 9, 9)) ||
+			(req.Account != "" && !billing.DigitsOnly(req.Account, 20, 20)) ||
+			(req.CorrAccount != "" && !billing.DigitsOnly(req.CorrAccount, 20, 20)) {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_requisites"})
+			return
+		}
+
+		p, err := profiles.GetByUserID(c.Request.Context(), uid)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if p == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "profile_not_found"})
+			return
+		}
+		if err := profiles.UpdateByUserID(c.Request.Context(), uid, bson.M{"requisites": req}); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "requisites": req})
+	})
+
 	// ✅ Upload avatar to /uploads/avatars (inside container)
 	protected.POST("/profile/me/avatar", func(c *gin.Context) {
 		uid := c.GetString(middleware.CtxUserID)
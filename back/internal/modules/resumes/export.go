@@ -0,0 +1,170 @@
+package resumes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"unicorn-auth/internal/logging"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/resumedoc"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// avatarDir — куда профиль сохраняет аватары (отдает их nginx)
+	avatarDir     = "/uploads/avatars"
+	maxAvatarSize = 8 << 20
+
+	docCacheTTL = 24 * time.Hour
+	maxDocCache = 128
+)
+
+var docFormats = map[string]string{
+	"pdf":  "application/pdf",
+	"docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
+// docCache — готовые документы по версии резюме, шаблону и формату
+type docCache struct {
+	mu    sync.Mutex
+	items map[string]cachedDoc
+}
+
+type cachedDoc struct {
+	body []byte
+	at   time.Time
+}
+
+func (dc *docCache) get(key string) ([]byte, bool) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	d, ok := dc.items[key]
+	return d.body, ok && time.Since(d.at) < docCacheTTL
+}
+
+func (dc *docCache) put(key string, body []byte) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	if len(dc.items) >= maxDocCache {
+		for k, v := range dc.items {
+			if time.Since(v.at) >= docCacheTTL {
+				delete(dc.items, k)
+			}
+		}
+		if len(dc.items) >= maxDocCache {
+			dc.items = map[string]cachedDoc{}
+		}
+	}
+	dc.items[key] = cachedDoc{body: body, at: time.Now()}
+}
+
+// readAvatar читает загруженный аватар профиля; чужие URL не загружаются
+func readAvatar(p *models.Profile) []byte {
+	if p == nil || !strings.HasPrefix(p.AvatarURL, "/uploads/avatars/") {
+		return nil
+	}
+	name := path.Base(p.AvatarURL)
+	fi, err := os.Stat(filepath.Join(avatarDir, name))
+	if err != nil || fi.Size() > maxAvatarSize {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(avatarDir, name))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// registerExport — скачивание резюме в PDF и DOCX
+func registerExport(shared *gin.RouterGroup, profiles *repo.ProfileRepo, resumes *repo.ResumeRepo,
+	snapshots *repo.ResumeSnapshotRepo, apps *repo.ApplicationRepo) {
+
+	cache := &docCache{items: map[string]cachedDoc{}}
+
+	// render отдает документ из кэша или собирает его; ключ включает версию резюме
+	// и время изменения профиля, так что правки сразу дают новый файл
+	render := func(c *gin.Context, id string, version int, userID string, build func(p *models.Profile, avatar []byte) resumedoc.Input) {
+		format := c.DefaultQuery("format", "pdf")
+		contentType, ok := docFormats[format]
+		template := c.DefaultQuery("template", resumedoc.TemplateClassic)
+		if !ok || !resumedoc.ValidTemplate(template) {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		p, err := profiles.GetByUserID(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		var profileAt int64
+		if p != nil {
+			profileAt = p.UpdatedAt.UnixNano()
+		}
+		key := fmt.Sprintf("%s:%d:%d:%s:%s", id, version, profileAt, template, format)
+		sum := sha256.Sum256([]byte(key))
+		etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+		c.Header("Cache-Control", "private, no-cache")
+		c.Header("ETag", etag)
+		if c.GetHeader("If-None-Match") == etag {
+			c.Status(304)
+			return
+		}
+
+		body, ok := cache.get(key)
+		if !ok {
+			in := build(p, readAvatar(p))
+			if format == "docx" {
+				body, err = resumedoc.DOCX(in, template)
+			} else {
+				body, err = resumedoc.PDF(in, template)
+			}
+			if err != nil {
+				logging.FromContext(c.Request.Context()).Error("render resume", "resume_id", id, "template", template, "format", format, "err", err)
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+			cache.put(key, body)
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="resume-%s-v%d.%s"`, id, version, format))
+		c.Data(200, contentType, body)
+	}
+
+	// GET /api/resumes/:id/export?format=pdf|docx&template=classic|modern|compact
+	// доступ как у GET /api/resumes/:id: компания получает снимок из отклика
+	shared.GET("/resumes/:id/export", func(c *gin.Context) {
+		rr, snap, ok := visibleResume(c, resumes, snapshots, apps)
+		if !ok {
+			return
+		}
+		if snap != nil {
+			render(c, snap.SnapshotID, snap.Version, snap.UserID, func(p *models.Profile, avatar []byte) resumedoc.Input {
+				return resumedoc.FromSnapshot(snap, p, avatar)
+			})
+			return
+		}
+		render(c, rr.ResumeID, rr.Version, rr.UserID, func(p *models.Profile, avatar []byte) resumedoc.Input {
+			return resumedoc.FromResume(rr, p, avatar)
+		})
+	})
+
+	// GET /api/resumes/snapshots/:id/export - то же для конкретного снимка
+	shared.GET("/resumes/snapshots/:id/export", func(c *gin.Context) {
+		snap, ok := visibleSnapshot(c, snapshots, apps)
+		if !ok {
+			return
+		}
+		render(c, snap.SnapshotID, snap.Version, snap.UserID, func(p *models.Profile, avatar []byte) resumedoc.Input {
+			return resumedoc.FromSnapshot(snap, p, avatar)
+		})
+	})
+}
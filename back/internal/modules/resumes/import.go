@@ -0,0 +1,117 @@
+package resumes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"unicorn-auth/internal/logging"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/resumeimport"
+	"unicorn-auth/internal/taxonomy"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxImportSize = 5 << 20
+	dictTTL       = time.Hour
+)
+
+// skillDict — словарь навыков: справочник плюс теги вакансий вне него,
+// пересобирается раз в час
+type skillDict struct {
+	mu   sync.Mutex
+	tax  *taxonomy.Service
+	vac  *repo.VacancyRepo
+	dict *resumeimport.Dictionary
+	at   time.Time
+}
+
+func (sd *skillDict) get(ctx context.Context) *resumeimport.Dictionary {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	if sd.dict != nil && time.Since(sd.at) < dictTTL {
+		return sd.dict
+	}
+	skills, err := sd.tax.Skills(ctx)
+	tags, terr := sd.vac.DistinctTags(ctx)
+	if err = errors.Join(err, terr); err != nil {
+		logging.FromContext(ctx).Error("load skills dictionary", "err", err)
+		if sd.dict != nil {
+			return sd.dict // старый словарь лучше неполного
+		}
+	} else {
+		sd.at = time.Now()
+	}
+	sd.dict = resumeimport.NewDictionary(skills, tags)
+	return sd.dict
+}
+
+// registerImport — черновик резюме из загруженного PDF или DOCX.
+// Ничего не сохраняется: кандидат правит черновик и отправляет POST /api/resumes
+func registerImport(protected *gin.RouterGroup, tax *taxonomy.Service, vac *repo.VacancyRepo) {
+	dict := &skillDict{tax: tax, vac: vac}
+
+	// POST /api/resumes/import (multipart, поле file)
+	protected.POST("/resumes/import", func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize+1<<10)
+		fh, err := c.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(413, gin.H{"ok": false, "error": "file_too_large"})
+				return
+			}
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		if fh.Size > maxImportSize {
+			c.JSON(413, gin.H{"ok": false, "error": "file_too_large"})
+			return
+		}
+		f, err := fh.Open()
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		defer f.Close()
+		data, err := io.ReadAll(io.LimitReader(f, maxImportSize))
+		if err != nil {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+
+		// Тип определяем по содержимому: PDF или zip-контейнер DOCX
+		switch http.DetectContentType(data) {
+		case "application/pdf", "application/zip":
+		default:
+			c.JSON(400, gin.H{"ok": false, "error": "unsupported_file_type"})
+			return
+		}
+
+		res, err := resumeimport.Parse(data, dict.get(c.Request.Context()))
+		switch {
+		case errors.Is(err, resumeimport.ErrUnsupported):
+			c.JSON(400, gin.H{"ok": false, "error": "unsupported_file_type"})
+			return
+		case errors.Is(err, resumeimport.ErrUnreadable):
+			c.JSON(422, gin.H{"ok": false, "error": "unreadable_file"})
+			return
+		case errors.Is(err, resumeimport.ErrNoText):
+			c.JSON(422, gin.H{"ok": false, "error": "no_text"})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		unmatched := res.Unmatched
+		if unmatched == nil {
+			unmatched = []string{}
+		}
+		c.JSON(200, gin.H{"ok": true, "draft": res.Draft, "unmatchedSkills": unmatched})
+	})
+}
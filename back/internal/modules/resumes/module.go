@@ -8,6 +8,7 @@ import (
 	"unicorn-auth/internal/models"
 	"unicorn-auth/internal/repo"
 	"unicorn-auth/internal/security"
+	"unicorn-auth/internal/taxonomy"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -20,64 +21,43 @@ type createReq struct {
 	Links  []string `json:"links,omitempty"`
 }
 
-func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, resumes *repo.ResumeRepo, apps *repo.ApplicationRepo) {
+func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, orgs *repo.OrgRepo, profiles *repo.ProfileRepo, resumes *repo.ResumeRepo,
+	snapshots *repo.ResumeSnapshotRepo, apps *repo.ApplicationRepo, vac *repo.VacancyRepo, tax *taxonomy.Service) {
 	api := r.Group("/api")
 
 	// shared auth group for both user/company (but MFA required)
 	shared := api.Group("")
 	shared.Use(middleware.RequireAuth(sec))
 	shared.Use(middleware.RequireMFAEnabled(sec, users))
+	shared.Use(middleware.ResolveOrg(orgs))
 
 	// ✅ GET /api/resumes/:id
 	// user: только своё
-	// company: только если есть application на эту company с таким resumeId
+	// company: снимок из последнего отклика в организацию с таким resumeId;
+	// для откликов без снимка — текущее резюме
 	shared.GET("/resumes/:id", func(c *gin.Context) {
-		uid := c.GetString(middleware.CtxUserID)
-		ut := c.GetString(middleware.CtxUserType)
-		id := c.Param("id")
-
-		rr, err := resumes.GetByID(c.Request.Context(), id)
-		if err != nil {
-			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+		rr, snap, ok := visibleResume(c, resumes, snapshots, apps)
+		if !ok {
 			return
 		}
-		if rr == nil {
-			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
-			return
-		}
-
-		if ut == "user" {
-			if rr.UserID != uid {
-				c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
-				return
-			}
-			c.JSON(200, gin.H{"ok": true, "resume": rr})
+		if snap != nil {
+			writeSnapshot(c, resumes, snap)
 			return
 		}
-
-		if ut == "company" {
-			ok, err := apps.ExistsCompanyResume(c.Request.Context(), uid, id)
-			if err != nil {
-				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
-				return
-			}
-			if !ok {
-				c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
-				return
-			}
-			c.JSON(200, gin.H{"ok": true, "resume": rr})
-			return
-		}
-
-		c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
+		c.JSON(200, gin.H{"ok": true, "resume": rr})
 	})
 
+	registerSnapshots(shared, resumes, snapshots, apps)
+	registerExport(shared, profiles, resumes, snapshots, apps)
+
 	// my resumes
 	protected := api.Group("")
 	protected.Use(middleware.RequireAuth(sec))
 	protected.Use(middleware.RequireType("user"))
 	protected.Use(middleware.RequireMFAEnabled(sec, users))
 
+	registerImport(protected, tax, vac)
+
 	protected.GET("/resumes/my", func(c *gin.Context) {
 		uid := c.GetString(middleware.CtxUserID)
 		items, err := resumes.ListMine(c.Request.Context(), uid)
@@ -121,7 +101,7 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, resum
 			UserID:    uid,
 			Title:     strings.TrimSpace(req.Title),
 			About:     strings.TrimSpace(req.About),
-			Skills:    req.Skills,
+			Skills:    tax.Normalize(c.Request.Context(), req.Skills),
 			Links:     req.Links,
 			IsPremium: u.Subscription.Active,
 			ColorCode: "",
@@ -152,7 +132,7 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, resum
 		set := bson.M{
 			"title":  strings.TrimSpace(req.Title),
 			"about":  strings.TrimSpace(req.About),
-			"skills": req.Skills,
+			"skills": tax.Normalize(c.Request.Context(), req.Skills),
 			"links":  req.Links,
 		}
 		if err := resumes.Update(c.Request.Context(), c.Param("id"), uid, set); err != nil {
@@ -0,0 +1,126 @@
+package resumes
+
+import (
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// visibleResume применяет правило доступа GET /api/resumes/:id: соискатель видит
+// свое резюме, компания — снимок из последнего отклика в организацию (у старых
+// откликов — текущее резюме). Ровно одно из rr, snap не nil; ok == false — ответ
+// с ошибкой уже записан.
+func visibleResume(c *gin.Context, resumes *repo.ResumeRepo, snapshots *repo.ResumeSnapshotRepo,
+	apps *repo.ApplicationRepo) (rr *models.Resume, snap *models.ResumeSnapshot, ok bool) {
+
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	ut := c.GetString(middleware.CtxUserType)
+	if ut != "user" && ut != "company" {
+		c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
+		return nil, nil, false
+	}
+
+	if ut == "company" {
+		a, err := apps.LatestCompanyResume(ctx, c.GetString(middleware.CtxOrgID), id)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return nil, nil, false
+		}
+		if a == nil {
+			c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
+			return nil, nil, false
+		}
+		if a.SnapshotID != "" {
+			snap, err := snapshots.GetByID(ctx, a.SnapshotID)
+			if err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return nil, nil, false
+			}
+			if snap != nil {
+				return nil, snap, true
+			}
+		}
+	}
+
+	rr, err := resumes.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+		return nil, nil, false
+	}
+	if rr == nil {
+		c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+		return nil, nil, false
+	}
+	if ut == "user" && rr.UserID != c.GetString(middleware.CtxUserID) {
+		c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
+		return nil, nil, false
+	}
+	return rr, nil, true
+}
+
+// visibleSnapshot — снимок по id для владельца резюме или компании, получившей его с откликом
+func visibleSnapshot(c *gin.Context, snapshots *repo.ResumeSnapshotRepo, apps *repo.ApplicationRepo) (*models.ResumeSnapshot, bool) {
+	snap, err := snapshots.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+		return nil, false
+	}
+	if snap == nil {
+		c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+		return nil, false
+	}
+
+	switch c.GetString(middleware.CtxUserType) {
+	case "user":
+		if snap.UserID != c.GetString(middleware.CtxUserID) {
+			c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
+			return nil, false
+		}
+	case "company":
+		ok, err := apps.ExistsCompanySnapshot(c.Request.Context(), c.GetString(middleware.CtxOrgID), snap.SnapshotID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return nil, false
+		}
+		if !ok {
+			c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
+			return nil, false
+		}
+	default:
+		c.JSON(403, gin.H{"ok": false, "error": "forbidden"})
+		return nil, false
+	}
+	return snap, true
+}
+
+// writeSnapshot отвечает снимком резюме. updated — кандидат с тех пор изменил
+// или удалил резюме; ?diff=1 добавляет, что именно изменилось.
+func writeSnapshot(c *gin.Context, resumes *repo.ResumeRepo, snap *models.ResumeSnapshot) {
+	cur, err := resumes.GetByID(c.Request.Context(), snap.ResumeID)
+	if err != nil {
+		c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+		return
+	}
+	updated := cur == nil || cur.Version != snap.Version
+	resp := gin.H{"ok": true, "resume": snap, "snapshotId": snap.SnapshotID, "version": snap.Version, "updated": updated}
+	if updated && c.Query("diff") == "1" {
+		resp["diff"] = snap.Diff(cur)
+	}
+	c.JSON(200, resp)
+}
+
+// registerSnapshots — снимки резюме, полученные компаниями с откликами
+func registerSnapshots(shared *gin.RouterGroup, resumes *repo.ResumeRepo, snapshots *repo.ResumeSnapshotRepo, apps *repo.ApplicationRepo) {
+	// GET /api/resumes/snapshots/:id?diff=1
+	// user: снимки своих резюме; company: только снимки из откликов в организацию
+	shared.GET("/resumes/snapshots/:id", func(c *gin.Context) {
+		snap, ok := visibleSnapshot(c, snapshots, apps)
+		if !ok {
+			return
+		}
+		writeSnapshot(c, resumes, snap)
+	})
+}
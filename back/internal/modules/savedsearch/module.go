@@ -0,0 +1,302 @@
+package savedsearch
+
+import (
+	netmail "net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"unicorn-auth/internal/alerts"
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/notify"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/security"
+	"unicorn-auth/internal/taxonomy"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// максимум сохраненных поисков у одного пользователя
+const maxSearches = 20
+
+type searchReq struct {
+	Name      *string   `json:"name,omitempty"`
+	Query     *string   `json:"query,omitempty"`
+	Tags      *[]string `json:"tags,omitempty"`
+	Location  *string   `json:"location,omitempty"`
+	SalaryMin *int64    `json:"salaryMin,omitempty"`
+	Frequency *string   `json:"frequency,omitempty"`
+	Channels  *[]string `json:"channels,omitempty"`
+	Email     *string   `json:"email,omitempty"`
+	Active    *bool     `json:"active,omitempty"`
+}
+
+type matchItem struct {
+	VacancyID    string    `json:"vacancyId"`
+	VacancyTitle string    `json:"vacancyTitle,omitempty"`
+	Location     string    `json:"location,omitempty"`
+	SentAt       time.Time `json:"sentAt"`
+}
+
+func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, searches *repo.SavedSearchRepo, vac *repo.VacancyRepo,
+	tax *taxonomy.Service) {
+	api := r.Group("/api/saved-searches")
+	api.Use(middleware.RequireAuth(sec))
+	api.Use(middleware.RequireType("user"))
+	api.Use(middleware.RequireMFAEnabled(sec, users))
+
+	// GET /api/saved-searches
+	api.GET("", func(c *gin.Context) {
+		items, err := searches.ListByUser(c.Request.Context(), c.GetString(middleware.CtxUserID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items})
+	})
+
+	// POST /api/saved-searches - сохранить поиск и подписаться на новые вакансии
+	api.POST("", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+		var req searchReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+
+		s := &models.SavedSearch{
+			UserID:    uid,
+			Frequency: models.AlertDaily,
+			Channels:  []string{notify.ChannelInApp},
+			Active:    true,
+		}
+		if req.Tags != nil {
+			// Теги в справочных названиях — как у вакансий
+			tags := tax.Normalize(c.Request.Context(), *req.Tags)
+			req.Tags = &tags
+		}
+		if code := apply(s, &req); code != "" {
+			c.JSON(400, gin.H{"ok": false, "error": code})
+			return
+		}
+
+		cnt, err := searches.CountByUser(c.Request.Context(), uid)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if cnt >= maxSearches {
+			c.JSON(403, gin.H{"ok": false, "error": "limit_reached", "limit": maxSearches})
+			return
+		}
+
+		s.NextRunAt = alerts.NextRun(s.Frequency, time.Now().UTC())
+		if err := searches.Create(c.Request.Context(), s); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(201, gin.H{"ok": true, "search": s})
+	})
+
+	// PATCH /api/saved-searches/:searchId
+	api.PATCH("/:searchId", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+		var req searchReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+		s, err := searches.GetByID(c.Request.Context(), c.Param("searchId"), uid)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if s == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		wasActive, oldFreq := s.Active, s.Frequency
+		if req.Tags != nil {
+			tags := tax.Normalize(c.Request.Context(), *req.Tags)
+			req.Tags = &tags
+		}
+		if code := apply(s, &req); code != "" {
+			c.JSON(400, gin.H{"ok": false, "error": code})
+			return
+		}
+
+		set := bson.M{
+			"name":      s.Name,
+			"query":     s.Query,
+			"tags":      s.Tags,
+			"location":  s.Location,
+			"salaryMin": s.SalaryMin,
+			"frequency": s.Frequency,
+			"channels":  s.Channels,
+			"email":     s.Email,
+			"active":    s.Active,
+		}
+		now := time.Now().UTC()
+		if s.Frequency != oldFreq {
+			set["nextRunAt"] = alerts.NextRun(s.Frequency, now)
+		}
+		// После паузы не присылаем все, что накопилось за время паузы
+		if s.Active && !wasActive {
+			set["checkedUntil"] = now
+			set["nextRunAt"] = alerts.NextRun(s.Frequency, now)
+		}
+		if _, err := searches.Update(c.Request.Context(), s.SearchID, uid, set); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	// DELETE /api/saved-searches/:searchId
+	api.DELETE("/:searchId", func(c *gin.Context) {
+		ok, err := searches.Delete(c.Request.Context(), c.Param("searchId"), c.GetString(middleware.CtxUserID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	// GET /api/saved-searches/:searchId/matches - вакансии, уже отправленные по поиску
+	api.GET("/:searchId/matches", func(c *gin.Context) {
+		s, err := searches.GetByID(c.Request.Context(), c.Param("searchId"), c.GetString(middleware.CtxUserID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if s == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		sent, err := searches.ListSent(c.Request.Context(), s.SearchID, 50)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		out := make([]matchItem, 0, len(sent))
+		for _, m := range sent {
+			it := matchItem{VacancyID: m.VacancyID, SentAt: m.SentAt}
+			if v, _ := vac.GetByID(c.Request.Context(), m.VacancyID); v != nil {
+				it.VacancyTitle = v.Title
+				it.Location = v.Location
+			}
+			out = append(out, it)
+		}
+		c.JSON(200, gin.H{"ok": true, "items": out})
+	})
+}
+
+// apply переносит поля запроса в поиск и проверяет результат; возвращает код ошибки
+func apply(s *models.SavedSearch, req *searchReq) string {
+	if req.Name != nil {
+		s.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Query != nil {
+		s.Query = strings.TrimSpace(*req.Query)
+	}
+	if req.Tags != nil {
+		s.Tags = s.Tags[:0]
+		for _, t := range *req.Tags {
+			if t = strings.TrimSpace(t); t != "" {
+				s.Tags = append(s.Tags, t)
+			}
+		}
+	}
+	if req.Location != nil {
+		s.Location = strings.TrimSpace(*req.Location)
+	}
+	if req.SalaryMin != nil {
+		s.SalaryMin = *req.SalaryMin
+	}
+	if req.Frequency != nil {
+		s.Frequency = *req.Frequency
+	}
+	if req.Channels != nil {
+		s.Channels = *req.Channels
+	}
+	if req.Email != nil {
+		s.Email = strings.TrimSpace(*req.Email)
+	}
+	if req.Active != nil {
+		s.Active = *req.Active
+	}
+
+	if s.Query == "" && len(s.Tags) == 0 && s.Location == "" && s.SalaryMin == 0 {
+		return "empty_search"
+	}
+	if utf8.RuneCountInString(s.Query) > 100 || utf8.RuneCountInString(s.Location) > 100 ||
+		len(s.Tags) > 10 || s.SalaryMin < 0 {
+		return "invalid_search"
+	}
+	for _, t := range s.Tags {
+		if utf8.RuneCountInString(t) > 50 {
+			return "invalid_search"
+		}
+	}
+	if s.Name == "" {
+		s.Name = defaultName(s)
+	}
+	if utf8.RuneCountInString(s.Name) > 100 {
+		return "invalid_search"
+	}
+
+	switch s.Frequency {
+	case models.AlertInstant, models.AlertDaily, models.AlertWeekly:
+	default:
+		return "invalid_frequency"
+	}
+
+	if len(s.Channels) == 0 {
+		return "invalid_channels"
+	}
+	seen := map[string]bool{}
+	channels := make([]string, 0, len(s.Channels))
+	for _, ch := range s.Channels {
+		if ch != notify.ChannelInApp && ch != notify.ChannelEmail {
+			return "invalid_channels"
+		}
+		if !seen[ch] {
+			seen[ch] = true
+			channels = append(channels, ch)
+		}
+	}
+	s.Channels = channels
+	if seen[notify.ChannelEmail] {
+		addr, err := netmail.ParseAddress(s.Email)
+		if err != nil || addr.Address != s.Email {
+			return "invalid_email"
+		}
+	}
+	return ""
+}
+
+func defaultName(s *models.SavedSearch) string {
+	parts := []string{}
+	if s.Query != "" {
+		parts = append(parts, s.Query)
+	}
+	if len(s.Tags) > 0 {
+		parts = append(parts, strings.Join(s.Tags, ", "))
+	}
+	if s.Location != "" {
+		parts = append(parts, s.Location)
+	}
+	name := strings.Join(parts, " · ")
+	if name == "" {
+		name = "Поиск вакансий"
+	}
+	if r := []rune(name); len(r) > 100 {
+		name = string(r[:100])
+	}
+	return name
+}
@@ -0,0 +1,46 @@
+package skills
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	"unicorn-auth/internal/taxonomy"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 50
+)
+
+type suggestion struct {
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Synonyms []string `json:"synonyms,omitempty"`
+	Usage    int64    `json:"usage"`
+}
+
+// Register — публичное автодополнение навыков и тегов
+func Register(r *gin.Engine, tax *taxonomy.Service) {
+	// GET /api/skills?q=gol&limit=10 - по началу названия или синонима, популярные сначала
+	r.GET("/api/skills", func(c *gin.Context) {
+		q := c.Query("q")
+		limit, err := strconv.ParseInt(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)), 10, 64)
+		if err != nil || limit < 1 || limit > maxLimit || utf8.RuneCountInString(q) > 50 {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		items, err := tax.Suggest(c.Request.Context(), q, limit)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		out := make([]suggestion, 0, len(items))
+		for _, sk := range items {
+			out = append(out, suggestion{Name: sk.Name, Category: sk.Category, Synonyms: sk.Synonyms, Usage: sk.Usage})
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(200, gin.H{"ok": true, "items": out})
+	})
+}
@@ -2,29 +2,66 @@ package subscription
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
 	"time"
 
+	"unicorn-auth/internal/billing"
+	"unicorn-auth/internal/http/httputil"
 	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/logging"
 	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/notify"
+	"unicorn-auth/internal/payments"
 	"unicorn-auth/internal/repo"
 	"unicorn-auth/internal/security"
+	"unicorn-auth/internal/telemetry"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
 type Config struct {
-	Price            string
-	DurationDays     int
-	RobokassaEnabled bool
+	Price           string
+	DurationDays    int
+	PaymentsEnabled bool
+
+	// Plan — идентификатор тарифа для ограничений промокодов,
+	// TrialDays — длительность пробного периода (0 = выключен)
+	Plan      string
+	TrialDays int
+
+	// Продавец и параметры чека (54-ФЗ)
+	Seller     models.Requisites
+	ReceiptSno string
+	ReceiptTax string
 }
 
-func Register(r *gin.Engine, cfg Config, sec *security.Security, robokassa *security.Robokassa,
-	users *repo.UserRepo, subs *repo.SubscriptionRepo, vacancies *repo.VacancyRepo, resumes *repo.ResumeRepo) {
+type billingItem struct {
+	SubscriptionID string    `json:"subscriptionId"`
+	InvoiceNo      string    `json:"invoiceNo"`
+	InvID          int64     `json:"invId"`
+	Amount         string    `json:"amount"`
+	Currency       string    `json:"currency"`
+	Status         string    `json:"status"`
+	Provider       string    `json:"provider,omitempty"`
+	RefundedSum    string    `json:"refundedSum,omitempty"`
+	StartDate      time.Time `json:"startDate,omitempty"`
+	EndDate        time.Time `json:"endDate,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	Documents      gin.H     `json:"documents"`
+}
+
+type refundReq struct {
+	Amount string `json:"amount,omitempty"` // пусто = полный возврат остатка
+	Reason string `json:"reason,omitempty"`
+}
+
+func Register(r *gin.Engine, cfg Config, sec *security.Security, pay *payments.Registry,
+	users *repo.UserRepo, subs *repo.SubscriptionRepo, promos *repo.PromoRepo, profiles *repo.ProfileRepo, vacancies *repo.VacancyRepo, resumes *repo.ResumeRepo,
+	events *notify.Emitter) {
 
 	api := r.Group("/api")
 
@@ -45,42 +82,192 @@ func Register(r *gin.Engine, cfg Config, sec *security.Security, robokassa *secu
 		activeSub, _ := subs.GetActiveByUserID(c.Request.Context(), uid)
 
 		response := gin.H{
-			"ok":     true,
-			"active": u.Subscription.Active,
+			"ok":             true,
+			"active":         u.Subscription.Active,
+			"trialAvailable": cfg.TrialDays > 0 && !u.Subscription.TrialUsed,
+			"trialDays":      cfg.TrialDays,
 		}
 
 		if activeSub != nil {
 			response["endDate"] = activeSub.EndDate
 			response["daysLeft"] = int(time.Until(activeSub.EndDate).Hours() / 24)
+			response["trial"] = activeSub.Status == "trial"
 		}
 
 		c.JSON(200, response)
 	})
 
-	// POST /api/subscription/create-payment - Создать ссылку на оплату
+	// POST /api/subscription/promo/check - проверить промокод и посчитать цену
+	protected.POST("/subscription/promo/check", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+
+		var req promoReq
+		if !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+
+		u, err := users.FindByUserID(c.Request.Context(), uid)
+		if err != nil || u == nil {
+			c.JSON(401, gin.H{"ok": false, "error": "unauthorized"})
+			return
+		}
+
+		price, err := payments.ParseAmount(cfg.Price)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		promo, err := promos.FindByCode(c.Request.Context(), req.PromoCode)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		discount, code := quotePromo(promo, u, cfg.Plan, price)
+		if code != "" {
+			c.JSON(400, gin.H{"ok": false, "error": code})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"ok":             true,
+			"promoCode":      promo.Code,
+			"originalAmount": cfg.Price,
+			"discount":       payments.FormatAmount(discount),
+			"amount":         payments.FormatAmount(price - discount),
+		})
+	})
+
+	// POST /api/subscription/trial - активировать пробный период (один раз на пользователя)
+	protected.POST("/subscription/trial", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+
+		if cfg.TrialDays <= 0 {
+			c.JSON(403, gin.H{"ok": false, "error": "trial_disabled"})
+			return
+		}
+
+		u, err := users.FindByUserID(c.Request.Context(), uid)
+		if err != nil || u == nil {
+			c.JSON(401, gin.H{"ok": false, "error": "unauthorized"})
+			return
+		}
+		if u.Subscription.Active {
+			c.JSON(409, gin.H{"ok": false, "error": "already_active"})
+			return
+		}
+
+		claimed, err := users.ClaimTrial(c.Request.Context(), uid)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !claimed {
+			c.JSON(409, gin.H{"ok": false, "error": "trial_already_used"})
+			return
+		}
+
+		sub, err := startTrial(c.Request.Context(), cfg, u, users, subs, vacancies, resumes)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("start trial", "user_id", uid, "err", err)
+			// Пробный период не выдан — пользователь может повторить
+			if err := users.UnclaimTrial(c.Request.Context(), uid); err != nil {
+				logging.FromContext(c.Request.Context()).Error("unclaim trial", "user_id", uid, "err", err)
+			}
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+
+		logging.FromContext(c.Request.Context()).Info("trial started", "user_id", uid, "until", sub.EndDate)
+		c.JSON(200, gin.H{"ok": true, "endDate": sub.EndDate, "daysLeft": cfg.TrialDays})
+	})
+
+	// POST /api/subscription/create-payment - Создать ссылку на оплату.
+	// Тело необязательно: {"promoCode": "..."}
 	protected.POST("/subscription/create-payment", func(c *gin.Context) {
 		uid := c.GetString(middleware.CtxUserID)
 
+		var req promoReq
+		if c.Request.ContentLength != 0 && !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+
 		u, err := users.FindByUserID(c.Request.Context(), uid)
 		if err != nil || u == nil {
 			c.JSON(401, gin.H{"ok": false, "error": "unauthorized"})
 			return
 		}
 
-		if !cfg.RobokassaEnabled {
-			c.JSON(503, gin.H{"ok": false, "error": "payment_disabled"})
+		price, err := payments.ParseAmount(cfg.Price)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
 
+		// Промокод
+		var promo *models.PromoCode
+		var discount int64
+		if strings.TrimSpace(req.PromoCode) != "" {
+			promo, err = promos.FindByCode(c.Request.Context(), req.PromoCode)
+			if err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+			var code string
+			if discount, code = quotePromo(promo, u, cfg.Plan, price); code != "" {
+				c.JSON(400, gin.H{"ok": false, "error": code})
+				return
+			}
+		}
+		amount := price - discount
+		outSum := payments.FormatAmount(amount)
+
+		// Скидка 100% оформляется без платежного провайдера
+		provider := pay.Default()
+		providerName := "promo"
+		if amount > 0 {
+			if !cfg.PaymentsEnabled || provider == nil {
+				c.JSON(503, gin.H{"ok": false, "error": "payment_disabled"})
+				return
+			}
+			providerName = provider.Name()
+		}
+
 		// Создаем запись подписки в статусе pending
-		invID := time.Now().Unix() // Используем timestamp как InvID
+		invID, err := subs.NextInvID(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		invoiceSeq, err := subs.NextInvoiceNo(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		description := fmt.Sprintf("Подписка на %d дней", cfg.DurationDays)
+
+		// Фиксируем покупателя и чек на момент выставления счета
+		buyer := &models.BillingParty{DisplayName: u.DisplayName}
+		if p, _ := profiles.GetByUserID(c.Request.Context(), uid); p != nil {
+			buyer.Requisites = p.Requisites
+		}
+
 		sub := &models.Subscription{
-			UserID:   uid,
-			Amount:   parseFloat(cfg.Price),
-			Currency: "RUB",
-			Status:   "pending",
-			InvID:    invID,
-			OutSum:   cfg.Price,
+			UserID:    uid,
+			Amount:    float64(amount) / 100,
+			Currency:  "RUB",
+			Status:    "pending",
+			Plan:      cfg.Plan,
+			Provider:  providerName,
+			InvID:     invID,
+			OutSum:    outSum,
+			InvoiceNo: billing.InvoiceNo(invoiceSeq),
+			Buyer:     buyer,
+			Receipt:   billing.NewReceipt(cfg.ReceiptSno, cfg.ReceiptTax, "Unicorn Premium: "+description, float64(amount)/100),
+		}
+		if promo != nil {
+			sub.PromoCode = promo.Code
+			sub.OriginalSum = cfg.Price
+			sub.Discount = payments.FormatAmount(discount)
 		}
 
 		if err := subs.Create(c.Request.Context(), sub); err != nil {
@@ -88,92 +275,278 @@ func Register(r *gin.Engine, cfg Config, sec *security.Security, robokassa *secu
 			return
 		}
 
-		// Генерируем ссылку на оплату Robokassa
-		description := fmt.Sprintf("Подписка на %d дней", cfg.DurationDays)
-		paymentURL := robokassa.GeneratePaymentURL(cfg.Price, invID, description, uid)
+		if promo != nil {
+			err := promos.Reserve(c.Request.Context(), promo, &models.PromoRedemption{
+				UserID:         uid,
+				SubscriptionID: sub.SubscriptionID,
+				OriginalAmount: float64(price) / 100,
+				Discount:       float64(discount) / 100,
+				Amount:         sub.Amount,
+			})
+			if err != nil {
+				_ = subs.UpdateStatus(c.Request.Context(), invID, "cancelled", time.Time{}, time.Time{})
+				switch {
+				case errors.Is(err, repo.ErrPromoExhausted):
+					c.JSON(409, gin.H{"ok": false, "error": "promo_exhausted"})
+				case errors.Is(err, repo.ErrPromoAlreadyUsed):
+					c.JSON(409, gin.H{"ok": false, "error": "promo_already_used"})
+				default:
+					c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				}
+				return
+			}
+		}
 
-		log.Printf("subscription: payment created for user=%s, invID=%d, amount=%s, url=%s", uid, invID, cfg.Price, paymentURL)
+		if amount == 0 {
+			if err := activateSubscription(c.Request.Context(), cfg, sub, users, subs, promos, vacancies, resumes, events); err != nil {
+				logging.FromContext(c.Request.Context()).Error("activate free subscription", "inv_id", invID, "err", err)
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+			c.JSON(200, gin.H{
+				"ok":        true,
+				"provider":  providerName,
+				"activated": true,
+				"invId":     invID,
+				"amount":    outSum,
+				"discount":  sub.Discount,
+			})
+			return
+		}
 
-		c.JSON(200, gin.H{
+		payment, err := provider.CreatePayment(c.Request.Context(), payments.PaymentRequest{
+			InvID:       invID,
+			UserID:      uid,
+			Amount:      outSum,
+			Currency:    sub.Currency,
+			Description: description,
+			Receipt:     sub.Receipt,
+		})
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("create payment", "provider", provider.Name(), "inv_id", invID, "err", err)
+			_ = promos.Release(c.Request.Context(), sub.SubscriptionID)
+			c.JSON(502, gin.H{"ok": false, "error": "payment_provider_error"})
+			return
+		}
+		if payment.ExternalID != "" {
+			if err := subs.SetExternal(c.Request.Context(), invID, provider.Name(), payment.ExternalID); err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+		}
+
+		logging.FromContext(c.Request.Context()).Info("payment created", "user_id", uid, "provider", provider.Name(), "inv_id", invID, "amount", outSum, "promo", sub.PromoCode)
+
+		resp := gin.H{
 			"ok":         true,
-			"paymentUrl": paymentURL,
+			"provider":   provider.Name(),
+			"paymentUrl": payment.URL,
 			"invId":      invID,
-			"amount":     cfg.Price,
-		})
+			"amount":     outSum,
+		}
+		if promo != nil {
+			resp["originalAmount"] = sub.OriginalSum
+			resp["discount"] = sub.Discount
+		}
+		c.JSON(200, resp)
 	})
 
-	// POST /api/subscription/robokassa/result - Callback от Robokassa (Result URL)
-	api.POST("/subscription/robokassa/result", func(c *gin.Context) {
-		outSum := c.PostForm("OutSum")
-		invIDStr := c.PostForm("InvId")
-		signatureValue := c.PostForm("SignatureValue")
-		userID := c.PostForm("Shp_userId")
+	// GET /api/subscription/billing - история платежей с документами
+	protected.GET("/subscription/billing", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
 
-		log.Printf("robokassa result: received callback - OutSum=%s, InvId=%s, Signature=%s, UserId=%s",
-			outSum, invIDStr, signatureValue, userID)
+		items, err := subs.ListByUserID(c.Request.Context(), uid)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
 
-		invID, err := strconv.ParseInt(invIDStr, 10, 64)
+		out := make([]billingItem, 0, len(items))
+		for _, s := range items {
+			if s.Status == "pending" || s.Status == "cancelled" || s.Status == "trial" {
+				continue
+			}
+			invoiceNo := s.InvoiceNo
+			if invoiceNo == "" {
+				invoiceNo = billing.InvoiceNo(s.InvID)
+			}
+			base := "/api/subscription/billing/" + s.SubscriptionID
+			out = append(out, billingItem{
+				SubscriptionID: s.SubscriptionID,
+				InvoiceNo:      invoiceNo,
+				InvID:          s.InvID,
+				Amount:         s.OutSum,
+				Currency:       s.Currency,
+				Status:         s.Status,
+				Provider:       s.Provider,
+				RefundedSum:    s.RefundedSum,
+				StartDate:      s.StartDate,
+				EndDate:        s.EndDate,
+				CreatedAt:      s.CreatedAt,
+				Documents: gin.H{
+					"invoice": base + "/invoice.pdf",
+					"receipt": base + "/receipt.pdf",
+				},
+			})
+		}
+
+		c.JSON(200, gin.H{"ok": true, "items": out})
+	})
+
+	// GET /api/subscription/billing/:subscriptionId/:document - PDF счета или квитанции
+	protected.GET("/subscription/billing/:subscriptionId/:document", func(c *gin.Context) {
+		uid := c.GetString(middleware.CtxUserID)
+
+		sub, err := subs.GetByID(c.Request.Context(), c.Param("subscriptionId"))
 		if err != nil {
-			log.Printf("robokassa result: invalid invID format: %v", err)
-			c.String(400, "bad request")
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
+		if sub == nil || sub.UserID != uid || sub.Status == "pending" || sub.Status == "cancelled" || sub.Status == "trial" {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		if sub.InvoiceNo == "" {
+			sub.InvoiceNo = billing.InvoiceNo(sub.InvID)
+		}
+		if sub.Buyer == nil {
+			sub.Buyer = &models.BillingParty{}
+			if u, _ := users.FindByUserID(c.Request.Context(), uid); u != nil {
+				sub.Buyer.DisplayName = u.DisplayName
+			}
+		}
 
-		// Проверяем подпись
-		if !robokassa.VerifyResultSignature(outSum, invID, signatureValue, userID) {
-			log.Printf("robokassa result: invalid signature for invID=%d, expected signature calculation based on OutSum=%s", invID, outSum)
-			c.String(400, "invalid signature")
+		var data []byte
+		switch c.Param("document") {
+		case "invoice.pdf":
+			data, err = billing.Invoice(cfg.Seller, sub)
+		case "receipt.pdf":
+			data, err = billing.Receipt(cfg.Seller, sub)
+		default:
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("render billing document", "document", c.Param("document"), "subscription_id", sub.SubscriptionID, "err", err)
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s"`, sub.InvoiceNo, c.Param("document")))
+		c.Data(200, "application/pdf", data)
+	})
+
+	// handleResult обрабатывает уведомление об оплате от любого провайдера
+	handleResult := func(c *gin.Context, provider payments.Provider) {
+		name := provider.Name()
+		log := logging.FromContext(c.Request.Context()).With("provider", name)
+
+		cb, err := provider.VerifyCallback(c.Request)
+		if err != nil {
+			log.Warn("payment callback rejected", "err", err)
+			telemetry.PaymentCallback(name, telemetry.PaymentRejected)
+			if errors.Is(err, payments.ErrInvalidSignature) {
+				c.String(400, "invalid signature")
+				return
+			}
+			c.String(400, "bad request")
 			return
 		}
 
-		log.Printf("robokassa result: signature verified successfully for invID=%d", invID)
+		log.Debug("payment callback verified", "inv_id", cb.InvID, "status", cb.Status)
 
 		// Находим подписку
-		sub, err := subs.FindByInvID(c.Request.Context(), invID)
+		sub, err := subs.FindByInvID(c.Request.Context(), cb.InvID)
 		if err != nil || sub == nil {
-			log.Printf("robokassa result: subscription not found invID=%d, error=%v", invID, err)
+			log.Warn("payment callback for unknown subscription", "inv_id", cb.InvID, "err", err)
+			telemetry.PaymentCallback(name, telemetry.PaymentNotFound)
 			c.String(404, "subscription not found")
 			return
 		}
 
-		log.Printf("robokassa result: found subscription %s for user %s", sub.SubscriptionID, userID)
+		switch payments.Decide(cb, name, sub.Provider, sub.Status, sub.OutSum) {
+		case payments.ActionProviderMismatch:
+			// Уведомление должно прийти от провайдера, через которого создан платеж
+			log.Warn("payment callback from another provider", "inv_id", cb.InvID, "subscription_provider", sub.Provider)
+			telemetry.PaymentCallback(name, telemetry.PaymentRejected)
+			c.String(400, "provider mismatch")
+			return
 
-		// Обновляем статус подписки
-		startDate := time.Now().UTC()
-		endDate := startDate.AddDate(0, 0, cfg.DurationDays)
+		case payments.ActionCancel:
+			// Платеж отменен — возвращаем использование промокода
+			cancelled, err := subs.CancelPending(c.Request.Context(), cb.InvID)
+			if err != nil {
+				telemetry.PaymentCallback(name, telemetry.PaymentError)
+				c.String(500, "server error")
+				return
+			}
+			if cancelled {
+				if err := promos.Release(c.Request.Context(), sub.SubscriptionID); err != nil {
+					log.Error("release promo", "inv_id", cb.InvID, "err", err)
+				}
+				log.Info("payment cancelled", "inv_id", cb.InvID)
+				telemetry.PaymentCallback(name, telemetry.PaymentCancelled)
+			} else {
+				telemetry.PaymentCallback(name, telemetry.PaymentDuplicate)
+			}
+			c.String(200, cb.Ack)
+			return
 
-		if err := subs.UpdateStatus(c.Request.Context(), invID, "paid", startDate, endDate); err != nil {
-			log.Printf("robokassa result: failed to update subscription: %v", err)
-			c.String(500, "server error")
+		case payments.ActionIgnore:
+			// Повторное уведомление по уже оплаченной подписке — просто подтверждаем
+			log.Info("payment callback ignored", "inv_id", cb.InvID, "callback_status", cb.Status, "subscription_status", sub.Status)
+			telemetry.PaymentCallback(name, telemetry.PaymentDuplicate)
+			c.String(200, cb.Ack)
+			return
+
+		case payments.ActionAmountMismatch:
+			// Оплаченная сумма должна совпасть с выставленной
+			log.Warn("payment callback amount mismatch", "inv_id", cb.InvID, "amount", cb.Amount, "expected", sub.OutSum)
+			telemetry.PaymentCallback(name, telemetry.PaymentRejected)
+			c.String(400, "amount mismatch")
 			return
 		}
 
-		log.Printf("robokassa result: subscription status updated to paid, endDate=%s", endDate)
+		log.Debug("payment callback matched", "inv_id", cb.InvID, "subscription_id", sub.SubscriptionID, "user_id", sub.UserID)
 
-		// Обновляем пользователя
-		if err := users.UpdateByUserID(c.Request.Context(), userID, bson.M{
-			"subscription.active": true,
-			"subscription.until":  endDate,
-		}); err != nil {
-			log.Printf("robokassa result: failed to update user: %v", err)
+		err = activateSubscription(c.Request.Context(), cfg, sub, users, subs, promos, vacancies, resumes, events)
+		if errors.Is(err, errAlreadyProcessed) {
+			// Параллельное или повторное уведомление уже активировало подписку
+			log.Info("payment callback ignored", "inv_id", cb.InvID, "subscription_status", "paid")
+			telemetry.PaymentCallback(name, telemetry.PaymentDuplicate)
+			c.String(200, cb.Ack)
+			return
+		}
+		if err != nil {
+			log.Error("activate subscription", "inv_id", cb.InvID, "err", err)
+			telemetry.PaymentCallback(name, telemetry.PaymentError)
 			c.String(500, "server error")
 			return
 		}
 
-		log.Printf("robokassa result: user subscription activated for user=%s until=%s", userID, endDate)
+		log.Info("payment processed", "inv_id", cb.InvID, "user_id", sub.UserID)
+		telemetry.PaymentCallback(name, telemetry.PaymentPaid)
+		c.String(200, cb.Ack)
+	}
 
-		// Обновляем все вакансии и резюме пользователя - добавляем премиум статус
-		colorCode := "#FFD700" // Gold color for premium
-		if err := updateUserContentToPremium(c.Request.Context(), userID, colorCode, vacancies, resumes); err != nil {
-			log.Printf("robokassa result: failed to update content: %v", err)
-		} else {
-			log.Printf("robokassa result: content updated to premium for user=%s", userID)
+	// POST /api/subscription/:provider/result - Callback от провайдера (для Robokassa — Result URL)
+	api.POST("/subscription/:provider/result", func(c *gin.Context) {
+		provider := pay.Get(c.Param("provider"))
+		if provider == nil {
+			telemetry.PaymentCallback("unknown", telemetry.PaymentRejected)
+			c.String(404, "unknown provider")
+			return
 		}
-
-		log.Printf("robokassa result: payment processing completed successfully for user=%s, invID=%d", userID, invID)
-		c.String(200, fmt.Sprintf("OK%d", invID))
+		handleResult(c, provider)
 	})
 
+	// GET /api/subscription/fake/result - «оплата» по ссылке fake-провайдера (только для разработки)
+	if fake := pay.Get("fake"); fake != nil {
+		api.GET("/subscription/fake/result", func(c *gin.Context) {
+			handleResult(c, fake)
+		})
+	}
+
 	// GET /api/subscription/robokassa/success - Success URL (переадресация после оплаты)
 	api.GET("/subscription/robokassa/success", func(c *gin.Context) {
 		outSum := c.Query("OutSum")
@@ -187,6 +560,12 @@ func Register(r *gin.Engine, cfg Config, sec *security.Security, robokassa *secu
 			return
 		}
 
+		robokassa, ok := pay.Get("robokassa").(*payments.Robokassa)
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+
 		// Проверяем подпись
 		if !robokassa.VerifySuccessSignature(outSum, invID, signatureValue, userID) {
 			c.JSON(400, gin.H{"ok": false, "error": "invalid_signature"})
@@ -210,6 +589,244 @@ func Register(r *gin.Engine, cfg Config, sec *security.Security, robokassa *secu
 			"invId":   invIDStr,
 		})
 	})
+
+	// Админские эндпоинты: платежи пользователя и возвраты
+	admin := api.Group("/admin")
+	admin.Use(middleware.RequireAdmin(sec))
+
+	// GET /api/admin/subscriptions?userId= - платежи пользователя
+	admin.GET("/subscriptions", func(c *gin.Context) {
+		userID := strings.TrimSpace(c.Query("userId"))
+		if userID == "" {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		items, err := subs.ListByUserID(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items})
+	})
+
+	registerPromoAdmin(admin, promos, subs)
+
+	// POST /api/admin/subscriptions/:subscriptionId/refund - полный или частичный возврат.
+	// Премиум снимает только полный возврат подписки, которая действует сейчас.
+	admin.POST("/subscriptions/:subscriptionId/refund", func(c *gin.Context) {
+		var req refundReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+
+		sub, err := subs.GetByID(c.Request.Context(), c.Param("subscriptionId"))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if sub == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		if sub.Status != "paid" && sub.Status != "partially_refunded" {
+			c.JSON(409, gin.H{"ok": false, "error": "not_refundable"})
+			return
+		}
+
+		paid, err := payments.ParseAmount(sub.OutSum)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		var refunded int64
+		if sub.RefundedSum != "" {
+			if refunded, err = payments.ParseAmount(sub.RefundedSum); err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+		}
+		amount := paid - refunded
+		if strings.TrimSpace(req.Amount) != "" {
+			amount, err = payments.ParseAmount(req.Amount)
+			if err != nil {
+				c.JSON(400, gin.H{"ok": false, "error": "bad_amount"})
+				return
+			}
+		}
+		if amount <= 0 || refunded+amount > paid {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_amount"})
+			return
+		}
+
+		providerName := sub.Provider
+		if providerName == "" {
+			providerName = "robokassa"
+		}
+		provider := pay.Get(providerName)
+		if provider == nil {
+			c.JSON(503, gin.H{"ok": false, "error": "payment_disabled"})
+			return
+		}
+
+		// Сумма резервируется до запроса к провайдеру: параллельный возврат
+		// по той же подписке получит refund_conflict и не превысит оплату
+		log := logging.FromContext(c.Request.Context())
+		amountStr := payments.FormatAmount(amount)
+		reserved, err := subs.ReserveRefund(c.Request.Context(), sub.SubscriptionID, sub.RefundedSum, amountStr)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !reserved {
+			c.JSON(409, gin.H{"ok": false, "error": "refund_conflict"})
+			return
+		}
+
+		res, err := provider.Refund(c.Request.Context(), payments.RefundRequest{
+			InvID:      sub.InvID,
+			ExternalID: sub.ExternalID,
+			Amount:     amountStr,
+			Currency:   sub.Currency,
+		})
+		if err != nil {
+			if rerr := subs.ReleaseRefund(c.Request.Context(), sub.SubscriptionID, amountStr); rerr != nil {
+				log.Error("release refund reservation", "inv_id", sub.InvID, "err", rerr)
+			}
+			if errors.Is(err, payments.ErrNotSupported) {
+				c.JSON(501, gin.H{"ok": false, "error": "refund_not_supported"})
+				return
+			}
+			log.Error("refund", "provider", providerName, "inv_id", sub.InvID, "err", err)
+			c.JSON(502, gin.H{"ok": false, "error": "payment_provider_error"})
+			return
+		}
+
+		status := "partially_refunded"
+		if refunded+amount == paid {
+			status = "refunded"
+		}
+		refund := models.SubscriptionRefund{
+			RefundID:  res.RefundID,
+			Amount:    amountStr,
+			AdminID:   c.GetString(middleware.CtxAdminID),
+			Reason:    strings.TrimSpace(req.Reason),
+			CreatedAt: time.Now().UTC(),
+		}
+		// Возврат у провайдера уже прошел: если записать не удалось, резерв
+		// остается и блокирует новые возвраты до ручной сверки
+		ok, err := subs.AddRefund(c.Request.Context(), sub.SubscriptionID, sub.RefundedSum, status, payments.FormatAmount(refunded+amount), refund)
+		if err != nil || !ok {
+			log.Error("record refund", "refund_id", refund.RefundID, "amount", refund.Amount, "inv_id", sub.InvID, "err", err)
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+
+		if status == "refunded" && sub.EndDate.After(refund.CreatedAt) {
+			if err := syncPremium(c.Request.Context(), sub.UserID, users, subs, vacancies, resumes); err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+		}
+
+		log.Info("refund created", "refund_id", refund.RefundID, "amount", refund.Amount, "inv_id", sub.InvID, "admin_id", refund.AdminID)
+		c.JSON(200, gin.H{"ok": true, "status": status, "refund": refund})
+	})
+}
+
+// errAlreadyProcessed — платеж уже не ждет оплаты
+var errAlreadyProcessed = errors.New("subscription already processed")
+
+// activateSubscription переводит ожидающую оплаты подписку в paid и включает
+// премиум пользователю; errAlreadyProcessed — ее уже активировали
+func activateSubscription(ctx context.Context, cfg Config, sub *models.Subscription, users *repo.UserRepo,
+	subs *repo.SubscriptionRepo, promos *repo.PromoRepo, vacancies *repo.VacancyRepo, resumes *repo.ResumeRepo,
+	events *notify.Emitter) error {
+
+	startDate := time.Now().UTC()
+	endDate := startDate.AddDate(0, 0, cfg.DurationDays)
+
+	paid, err := subs.Activate(ctx, sub.InvID, startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("update subscription: %w", err)
+	}
+	if paid == nil {
+		return errAlreadyProcessed
+	}
+
+	if sub.PromoCode != "" {
+		err := promos.MarkRedeemed(ctx, sub.SubscriptionID)
+		switch {
+		case errors.Is(err, repo.ErrPromoAlreadyUsed):
+			// Оплата уже прошла: премиум включаем, скидку разбирает администратор
+			logging.FromContext(ctx).Warn("promo already redeemed by user", "promo", sub.PromoCode, "subscription_id", sub.SubscriptionID, "user_id", sub.UserID)
+			if err := subs.FlagReview(ctx, sub.SubscriptionID, "promo_already_used"); err != nil {
+				logging.FromContext(ctx).Error("flag subscription for review", "subscription_id", sub.SubscriptionID, "err", err)
+			}
+		case err != nil:
+			logging.FromContext(ctx).Error("mark promo redeemed", "promo", sub.PromoCode, "subscription_id", sub.SubscriptionID, "err", err)
+		}
+	}
+
+	if err := grantPremium(ctx, sub.UserID, endDate, users, vacancies, resumes); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Info("subscription activated", "user_id", sub.UserID, "until", endDate)
+
+	events.Emit(notify.Event{
+		Type:   notify.EventPaymentSuccess,
+		UserID: sub.UserID,
+		Title:  "Подписка оплачена",
+		Text:   fmt.Sprintf("Премиум активен до %s", endDate.Format("02.01.2006")),
+		Link:   "/subscription",
+		Data:   map[string]string{"subscriptionId": sub.SubscriptionID},
+	})
+	return nil
+}
+
+// grantPremium включает премиум пользователю до endDate и подсвечивает его контент
+func grantPremium(ctx context.Context, userID string, endDate time.Time, users *repo.UserRepo,
+	vacancies *repo.VacancyRepo, resumes *repo.ResumeRepo) error {
+
+	if err := users.UpdateByUserID(ctx, userID, bson.M{
+		"subscription.active": true,
+		"subscription.until":  endDate,
+	}); err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+
+	// Обновляем все вакансии и резюме пользователя - добавляем премиум статус
+	colorCode := "#FFD700" // Gold color for premium
+	if err := updateUserContentToPremium(ctx, userID, colorCode, vacancies, resumes); err != nil {
+		logging.FromContext(ctx).Error("update premium content", "user_id", userID, "err", err)
+	}
+	return nil
+}
+
+// syncPremium приводит премиум пользователя к его действующим подпискам:
+// продлевает до конца самой поздней или выключает, если таких не осталось
+func syncPremium(ctx context.Context, userID string, users *repo.UserRepo, subs *repo.SubscriptionRepo,
+	vacancies *repo.VacancyRepo, resumes *repo.ResumeRepo) error {
+
+	active, err := subs.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if active != nil {
+		return grantPremium(ctx, userID, active.EndDate, users, vacancies, resumes)
+	}
+	return deactivateSubscription(ctx, userID, users, vacancies, resumes)
+}
+
+// deactivateSubscription выключает премиум пользователю и снимает подсветку контента
+func deactivateSubscription(ctx context.Context, userID string, users *repo.UserRepo, vacancies *repo.VacancyRepo, resumes *repo.ResumeRepo) error {
+	if err := users.UpdateByUserID(ctx, userID, bson.M{"subscription.active": false}); err != nil {
+		return err
+	}
+	if err := vacancies.UpdateAllByCompanyID(ctx, userID, bson.M{"isPremium": false, "colorCode": ""}); err != nil {
+		return err
+	}
+	return resumes.UpdateAllByUserID(ctx, userID, bson.M{"isPremium": false, "colorCode": ""})
 }
 
 func parseFloat(s string) float64 {
@@ -0,0 +1,347 @@
+package subscription
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/logging"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type promoReq struct {
+	PromoCode string `json:"promoCode,omitempty"`
+}
+
+type promoCreateReq struct {
+	Code      string          `json:"code"`
+	Type      string          `json:"type"`
+	Value     float64         `json:"value"`
+	MaxUses   int64           `json:"maxUses,omitempty"`
+	ExpiresAt *time.Time      `json:"expiresAt,omitempty"`
+	UserType  models.UserType `json:"userType,omitempty"`
+	Plans     []string        `json:"plans,omitempty"`
+	Active    *bool           `json:"active,omitempty"`
+}
+
+type promoPatchReq struct {
+	Value     *float64         `json:"value,omitempty"`
+	MaxUses   *int64           `json:"maxUses,omitempty"`
+	ExpiresAt *time.Time       `json:"expiresAt,omitempty"` // нулевое время снимает ограничение
+	UserType  *models.UserType `json:"userType,omitempty"`
+	Plans     *[]string        `json:"plans,omitempty"`
+	Active    *bool            `json:"active,omitempty"`
+}
+
+// quotePromo проверяет, подходит ли промокод пользователю и тарифу, и считает скидку в копейках.
+// Второе значение — код ошибки для ответа API.
+func quotePromo(p *models.PromoCode, u *models.User, plan string, price int64) (int64, string) {
+	if p == nil || !p.Active {
+		return 0, "promo_not_found"
+	}
+	if !p.ExpiresAt.IsZero() && time.Now().After(p.ExpiresAt) {
+		return 0, "promo_expired"
+	}
+	if p.MaxUses > 0 && p.UsedCount >= p.MaxUses {
+		return 0, "promo_exhausted"
+	}
+	if p.UserType != "" && p.UserType != u.Type {
+		return 0, "promo_not_applicable"
+	}
+	if len(p.Plans) > 0 && !containsString(p.Plans, plan) {
+		return 0, "promo_not_applicable"
+	}
+
+	var discount int64
+	switch p.Type {
+	case "percent":
+		discount = int64(math.Round(float64(price) * p.Value / 100))
+	case "fixed":
+		discount = int64(math.Round(p.Value * 100))
+	}
+	if discount > price {
+		discount = price
+	}
+	return discount, ""
+}
+
+func validPromo(typ string, value float64, userType models.UserType) bool {
+	switch typ {
+	case "percent":
+		if value <= 0 || value > 100 {
+			return false
+		}
+	case "fixed":
+		if value <= 0 {
+			return false
+		}
+	default:
+		return false
+	}
+	return userType == "" || userType == models.UserTypeUser || userType == models.UserTypeCompany
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func cleanPlans(plans []string) []string {
+	out := make([]string, 0, len(plans))
+	for _, p := range plans {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// parseDay разбирает дату YYYY-MM-DD из query; пустая строка — нулевое время
+func parseDay(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse("2006-01-02", s)
+	return t, err == nil
+}
+
+func querySkip(c *gin.Context) int64 {
+	n, err := strconv.ParseInt(c.Query("skip"), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// registerPromoAdmin — управление промокодами и отчеты по промокодам и пробным периодам
+func registerPromoAdmin(admin *gin.RouterGroup, promos *repo.PromoRepo, subs *repo.SubscriptionRepo) {
+	// GET /api/admin/promo-codes?active=&skip= - список промокодов
+	admin.GET("/promo-codes", func(c *gin.Context) {
+		items, err := promos.List(c.Request.Context(), c.Query("active"), 100, querySkip(c))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items})
+	})
+
+	// POST /api/admin/promo-codes - создать промокод
+	admin.POST("/promo-codes", func(c *gin.Context) {
+		var req promoCreateReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+		code := strings.TrimSpace(req.Code)
+		if len(code) < 3 || len(code) > 32 || strings.ContainsAny(code, " \t\n") {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_code"})
+			return
+		}
+		if !validPromo(req.Type, req.Value, req.UserType) || req.MaxUses < 0 {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_promo"})
+			return
+		}
+
+		p := &models.PromoCode{
+			Code:      code,
+			Type:      req.Type,
+			Value:     req.Value,
+			MaxUses:   req.MaxUses,
+			UserType:  req.UserType,
+			Plans:     cleanPlans(req.Plans),
+			Active:    req.Active == nil || *req.Active,
+			CreatedBy: c.GetString(middleware.CtxAdminID),
+		}
+		if req.ExpiresAt != nil {
+			p.ExpiresAt = req.ExpiresAt.UTC()
+		}
+		if err := promos.Create(c.Request.Context(), p); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				c.JSON(409, gin.H{"ok": false, "error": "code_already_exists"})
+				return
+			}
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(201, gin.H{"ok": true, "promo": p})
+	})
+
+	// GET /api/admin/promo-codes/report?from=&to= - применения и выручка по промокодам
+	admin.GET("/promo-codes/report", func(c *gin.Context) {
+		from, ok1 := parseDay(c.Query("from"))
+		to, ok2 := parseDay(c.Query("to"))
+		if !ok1 || !ok2 {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		if !to.IsZero() {
+			to = to.AddDate(0, 0, 1) // включительно
+		}
+		rows, err := promos.Report(c.Request.Context(), from, to)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": rows})
+	})
+
+	// GET /api/admin/promo-codes/:promoId
+	admin.GET("/promo-codes/:promoId", func(c *gin.Context) {
+		p, err := promos.GetByID(c.Request.Context(), c.Param("promoId"))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if p == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "promo": p})
+	})
+
+	// PATCH /api/admin/promo-codes/:promoId - код и тип скидки не меняются
+	admin.PATCH("/promo-codes/:promoId", func(c *gin.Context) {
+		var req promoPatchReq
+		if !httputil.BindJSONStrict(c, &req, 16<<10) {
+			return
+		}
+		p, err := promos.GetByID(c.Request.Context(), c.Param("promoId"))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if p == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+
+		set := bson.M{}
+		if req.Value != nil {
+			p.Value = *req.Value
+			set["value"] = p.Value
+		}
+		if req.MaxUses != nil {
+			if *req.MaxUses < 0 {
+				c.JSON(400, gin.H{"ok": false, "error": "invalid_promo"})
+				return
+			}
+			set["maxUses"] = *req.MaxUses
+		}
+		if req.ExpiresAt != nil {
+			set["expiresAt"] = req.ExpiresAt.UTC()
+		}
+		if req.UserType != nil {
+			p.UserType = *req.UserType
+			set["userType"] = p.UserType
+		}
+		if req.Plans != nil {
+			set["plans"] = cleanPlans(*req.Plans)
+		}
+		if req.Active != nil {
+			set["active"] = *req.Active
+		}
+		if len(set) == 0 {
+			c.JSON(400, gin.H{"ok": false, "error": "no_fields_to_update"})
+			return
+		}
+		if !validPromo(p.Type, p.Value, p.UserType) {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_promo"})
+			return
+		}
+
+		if err := promos.Update(c.Request.Context(), p.PromoID, set); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	// DELETE /api/admin/promo-codes/:promoId - неиспользованный удаляется, иначе выключается
+	admin.DELETE("/promo-codes/:promoId", func(c *gin.Context) {
+		deleted, err := promos.Delete(c.Request.Context(), c.Param("promoId"))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !deleted {
+			p, err := promos.GetByID(c.Request.Context(), c.Param("promoId"))
+			if err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+			if p == nil {
+				c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+				return
+			}
+			if err := promos.Update(c.Request.Context(), p.PromoID, bson.M{"active": false}); err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+		}
+		c.JSON(200, gin.H{"ok": true, "deleted": deleted})
+	})
+
+	// GET /api/admin/promo-codes/:promoId/redemptions?status=&skip=
+	admin.GET("/promo-codes/:promoId/redemptions", func(c *gin.Context) {
+		items, err := promos.ListRedemptions(c.Request.Context(), c.Param("promoId"), c.Query("status"), 100, querySkip(c))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items})
+	})
+
+	// GET /api/admin/subscriptions/trials - сколько пробных периодов выдано и сколько из них оплатили
+	admin.GET("/subscriptions/trials", func(c *gin.Context) {
+		started, converted, err := subs.TrialReport(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		rate := 0.0
+		if started > 0 {
+			rate = math.Round(float64(converted)/float64(started)*1000) / 10
+		}
+		c.JSON(200, gin.H{"ok": true, "started": started, "converted": converted, "conversionPercent": rate})
+	})
+}
+
+// startTrial выдает пробный период без оплаты; при ошибке запись о нем не остается
+func startTrial(ctx context.Context, cfg Config, u *models.User, users *repo.UserRepo,
+	subs *repo.SubscriptionRepo, vacancies *repo.VacancyRepo, resumes *repo.ResumeRepo) (*models.Subscription, error) {
+
+	start := time.Now().UTC()
+	sub := &models.Subscription{
+		UserID:    u.UserID,
+		Currency:  "RUB",
+		Status:    "trial",
+		Plan:      cfg.Plan,
+		Provider:  "trial",
+		OutSum:    "0.00",
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, cfg.TrialDays),
+	}
+	if err := subs.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+	if err := grantPremium(ctx, u.UserID, sub.EndDate, users, vacancies, resumes); err != nil {
+		if derr := subs.DeleteTrial(ctx, sub.SubscriptionID); derr != nil {
+			logging.FromContext(ctx).Error("delete failed trial", "subscription_id", sub.SubscriptionID, "err", derr)
+		}
+		return nil, err
+	}
+	return sub, nil
+}
@@ -0,0 +1,219 @@
+package syndication
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+)
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Self          atomLink  `xml:"atom:link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	TTL           int       `xml:"ttl"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomAuthor     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+func buildRSS(s site, self string, f repo.VacancyFilter, items []models.Vacancy, companies map[string]models.Profile) ([]byte, error) {
+	ch := rssChannel{
+		Title:         feedTitle(f),
+		Link:          s.jobsURL(),
+		Self:          atomLink{Href: self, Rel: "self", Type: "application/rss+xml"},
+		Description:   "Новые вакансии на " + siteName,
+		Language:      "ru",
+		LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+		TTL:           int(feedTTL / time.Minute),
+	}
+	for _, v := range items {
+		company := companyName(companies, v.CompanyID)
+		ch.Items = append(ch.Items, rssItem{
+			Title:       v.Title + " — " + company,
+			Link:        s.vacancyURL(v.VacancyID),
+			GUID:        rssGUID{IsPermaLink: true, Value: s.vacancyURL(v.VacancyID)},
+			PubDate:     posted(&v).Format(time.RFC1123Z),
+			Categories:  v.Tags,
+			Description: summary(v),
+		})
+	}
+	doc := rss{Version: "2.0", Atom: "http://www.w3.org/2005/Atom", Channel: ch}
+	return marshalXML(doc)
+}
+
+func buildAtom(s site, self string, f repo.VacancyFilter, items []models.Vacancy, companies map[string]models.Profile) ([]byte, error) {
+	feed := atomFeed{
+		Title: feedTitle(f),
+		ID:    self,
+		Links: []atomLink{
+			{Href: self, Rel: "self", Type: "application/atom+xml"},
+			{Href: s.jobsURL(), Rel: "alternate", Type: "text/html"},
+		},
+	}
+	updated := time.Time{}
+	for _, v := range items {
+		if v.UpdatedAt.After(updated) {
+			updated = v.UpdatedAt
+		}
+		e := atomEntry{
+			Title:     v.Title,
+			ID:        s.vacancyURL(v.VacancyID),
+			Link:      atomLink{Href: s.vacancyURL(v.VacancyID), Rel: "alternate", Type: "text/html"},
+			Published: posted(&v).Format(time.RFC3339),
+			Updated:   latest(posted(&v), v.UpdatedAt).UTC().Format(time.RFC3339),
+			Author:    atomAuthor{Name: companyName(companies, v.CompanyID), URI: s.companyURL(v.CompanyID)},
+			Summary:   summary(v),
+		}
+		for _, t := range v.Tags {
+			e.Categories = append(e.Categories, atomCategory{Term: t})
+		}
+		feed.Entries = append(feed.Entries, e)
+	}
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+	return marshalXML(feed)
+}
+
+func feedTitle(f repo.VacancyFilter) string {
+	parts := []string{"Вакансии"}
+	if len(f.Tags) > 0 {
+		parts = append(parts, f.Tags[0])
+	}
+	if f.Location != "" {
+		parts = append(parts, f.Location)
+	}
+	return strings.Join(parts, " · ") + " — " + siteName
+}
+
+// summary — город, зарплата и описание вакансии одним текстом
+func summary(v models.Vacancy) string {
+	var head []string
+	if v.Location != "" {
+		head = append(head, v.Location)
+	}
+	if sal := salaryText(v); sal != "" {
+		head = append(head, sal)
+	}
+	if len(head) == 0 {
+		return v.Description
+	}
+	return strings.Join(head, ", ") + "\n\n" + v.Description
+}
+
+func salaryText(v models.Vacancy) string {
+	switch {
+	case v.SalaryFrom > 0 && v.SalaryTo > 0:
+		return "от " + rub(v.SalaryFrom) + " до " + rub(v.SalaryTo) + " ₽"
+	case v.SalaryFrom > 0:
+		return "от " + rub(v.SalaryFrom) + " ₽"
+	case v.SalaryTo > 0:
+		return "до " + rub(v.SalaryTo) + " ₽"
+	}
+	return ""
+}
+
+// rub разбивает сумму на разряды: 150000 -> 150 000
+func rub(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func companyName(companies map[string]models.Profile, id string) string {
+	if p, ok := companies[id]; ok && p.DisplayName != "" {
+		return p.DisplayName
+	}
+	return siteName
+}
+
+// posted — дата публикации, а если ее нет — создания
+func posted(v *models.Vacancy) time.Time {
+	if v.PublishedAt != nil {
+		return v.PublishedAt.UTC()
+	}
+	return v.CreatedAt.UTC()
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func marshalXML(v any) ([]byte, error) {
+	out, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,93 @@
+package syndication
+
+import (
+	"encoding/json"
+	"html"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/models"
+)
+
+// buildJobPosting — разметка schema.org JobPosting для поисковиков (Google for Jobs, Яндекс)
+func buildJobPosting(s site, v *models.Vacancy, company *models.Profile) ([]byte, error) {
+	org := map[string]any{"@type": "Organization", "name": siteName}
+	if company != nil {
+		if company.DisplayName != "" {
+			org["name"] = company.DisplayName
+		}
+		org["sameAs"] = s.companyURL(company.UserID)
+		if company.Website != "" {
+			org["sameAs"] = company.Website
+		}
+		if company.AvatarURL != "" {
+			org["logo"] = company.AvatarURL
+		}
+	}
+
+	doc := map[string]any{
+		"@context":           "https://schema.org",
+		"@type":              "JobPosting",
+		"title":              v.Title,
+		"description":        descriptionHTML(v.Description),
+		"datePosted":         posted(v).Format(time.RFC3339),
+		"url":                s.vacancyURL(v.VacancyID),
+		"identifier":         map[string]any{"@type": "PropertyValue", "name": org["name"], "value": v.VacancyID},
+		"hiringOrganization": org,
+		"directApply":        true,
+	}
+	if v.ExpiresAt != nil {
+		doc["validThrough"] = v.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if len(v.Tags) > 0 {
+		doc["skills"] = strings.Join(v.Tags, ", ")
+	}
+
+	switch {
+	case isRemote(v.Location):
+		doc["jobLocationType"] = "TELECOMMUTE"
+		doc["applicantLocationRequirements"] = map[string]any{"@type": "Country", "name": "RU"}
+	case v.Location != "":
+		doc["jobLocation"] = map[string]any{
+			"@type": "Place",
+			"address": map[string]any{
+				"@type":           "PostalAddress",
+				"addressLocality": v.Location,
+				"addressCountry":  "RU",
+			},
+		}
+	default:
+		doc["jobLocation"] = map[string]any{
+			"@type":   "Place",
+			"address": map[string]any{"@type": "PostalAddress", "addressCountry": "RU"},
+		}
+	}
+
+	if v.SalaryFrom > 0 || v.SalaryTo > 0 {
+		value := map[string]any{"@type": "QuantitativeValue", "unitText": "MONTH"}
+		if v.SalaryFrom > 0 {
+			value["minValue"] = v.SalaryFrom
+		}
+		if v.SalaryTo > 0 {
+			value["maxValue"] = v.SalaryTo
+		}
+		doc["baseSalary"] = map[string]any{"@type": "MonetaryAmount", "currency": "RUB", "value": value}
+	}
+
+	return json.Marshal(doc)
+}
+
+// descriptionHTML — описание хранится простым текстом; абзацы переводим в <p>, строки в <br>
+func descriptionHTML(text string) string {
+	var b strings.Builder
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>"))
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
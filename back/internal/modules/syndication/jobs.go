@@ -0,0 +1,129 @@
+package syndication
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/models"
+)
+
+// Выгрузка в общем формате агрегаторов вакансий (<source><job>…</job></source>):
+// его принимают Indeed, Jooble, Adzuna, Careerjet и другие.
+type jobsSource struct {
+	XMLName       xml.Name `xml:"source"`
+	Publisher     string   `xml:"publisher"`
+	PublisherURL  string   `xml:"publisherurl"`
+	LastBuildDate string   `xml:"lastBuildDate"`
+	Jobs          []jobsJob
+}
+
+type jobsJob struct {
+	XMLName         xml.Name `xml:"job"`
+	Title           cdata    `xml:"title"`
+	Date            string   `xml:"date"`
+	ReferenceNumber string   `xml:"referencenumber"`
+	URL             string   `xml:"url"`
+	Company         cdata    `xml:"company"`
+	City            *cdata   `xml:"city,omitempty"`
+	Country         string   `xml:"country"`
+	Remote          string   `xml:"remotetype,omitempty"`
+	Description     cdata    `xml:"description"`
+	Salary          string   `xml:"salary,omitempty"`
+	Category        *cdata   `xml:"category,omitempty"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+// remoteMarkers — так в поле «город» обычно пишут удаленную работу
+var remoteMarkers = []string{"удален", "удалён", "remote"}
+
+func isRemote(location string) bool {
+	l := strings.ToLower(location)
+	for _, m := range remoteMarkers {
+		if strings.Contains(l, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildJobsXML(s site, items []models.Vacancy, companies map[string]models.Profile) ([]byte, error) {
+	src := jobsSource{
+		Publisher:     siteName,
+		PublisherURL:  s.frontendURL,
+		LastBuildDate: time.Now().UTC().Format(time.RFC1123Z),
+	}
+	for _, v := range items {
+		j := jobsJob{
+			Title:           cdata{v.Title},
+			Date:            posted(&v).Format(time.RFC1123Z),
+			ReferenceNumber: v.VacancyID,
+			URL:             s.vacancyURL(v.VacancyID),
+			Company:         cdata{companyName(companies, v.CompanyID)},
+			Country:         "RU",
+			Description:     cdata{v.Description},
+			Salary:          salaryText(v),
+		}
+		if len(v.Tags) > 0 {
+			j.Category = &cdata{strings.Join(v.Tags, ", ")}
+		}
+		if isRemote(v.Location) {
+			j.Remote = "Fully remote"
+		} else if v.Location != "" {
+			j.City = &cdata{v.Location}
+		}
+		src.Jobs = append(src.Jobs, j)
+	}
+	return marshalXML(src)
+}
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod"`
+	ChangeFreq string `xml:"changefreq"`
+	Priority   string `xml:"priority"`
+}
+
+// buildSitemap — страницы активных вакансий и компаний, у которых они есть;
+// lastmod компании — самое свежее изменение ее вакансий
+func buildSitemap(s site, items []models.Vacancy) ([]byte, error) {
+	set := urlSet{}
+	companies := map[string]time.Time{}
+	var order []string
+	for _, v := range items {
+		mod := latest(posted(&v), v.UpdatedAt)
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:        s.vacancyURL(v.VacancyID),
+			LastMod:    mod.UTC().Format("2006-01-02"),
+			ChangeFreq: "daily",
+			Priority:   "0.8",
+		})
+		prev, ok := companies[v.CompanyID]
+		if !ok {
+			order = append(order, v.CompanyID)
+		}
+		if mod.After(prev) {
+			companies[v.CompanyID] = mod
+		}
+	}
+	for _, id := range order {
+		if len(set.URLs) >= sitemapMax {
+			break
+		}
+		set.URLs = append(set.URLs, sitemapURL{
+			Loc:        s.companyURL(id),
+			LastMod:    companies[id].UTC().Format("2006-01-02"),
+			ChangeFreq: "weekly",
+			Priority:   "0.6",
+		})
+	}
+	return marshalXML(set)
+}
@@ -0,0 +1,239 @@
+package syndication
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	siteName = "Unicornstar"
+
+	feedTTL     = 10 * time.Minute
+	maxCached   = 256  // разных комбинаций фильтров и вакансий в кэше
+	feedLimit   = 100  // записей в RSS/Atom
+	exportLimit = 5000 // вакансий в выгрузке для агрегаторов
+	sitemapMax  = 50000
+)
+
+var errNotFound = errors.New("not_found")
+
+type Config struct {
+	PublicURL   string // адрес API, на нем лежат сами фиды
+	FrontendURL string // адрес фронта, на него ведут ссылки на вакансии и компании
+}
+
+// site строит ссылки на страницы фронтенда и на сами фиды
+type site struct {
+	publicURL   string
+	frontendURL string
+}
+
+func (s site) jobsURL() string             { return s.frontendURL + "/jobs" }
+func (s site) vacancyURL(id string) string { return s.frontendURL + "/jobs/" + id }
+func (s site) companyURL(id string) string { return s.frontendURL + "/company/" + id }
+
+// feedURL — адрес фида с фильтром, для rel="self"
+func (s site) feedURL(path string, f repo.VacancyFilter) string {
+	q := url.Values{}
+	if len(f.Tags) > 0 {
+		q.Set("tag", f.Tags[0])
+	}
+	if f.Location != "" {
+		q.Set("location", f.Location)
+	}
+	if len(q) == 0 {
+		return s.publicURL + path
+	}
+	return s.publicURL + path + "?" + q.Encode()
+}
+
+// feedCache — фиды собираются из базы целиком, поэтому готовый ответ кэшируется
+type feedCache struct {
+	mu    sync.Mutex
+	items map[string]cachedFeed
+}
+
+type cachedFeed struct {
+	body []byte
+	etag string
+	at   time.Time
+}
+
+func (fc *feedCache) get(key string) (cachedFeed, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	cf, ok := fc.items[key]
+	return cf, ok && time.Since(cf.at) < feedTTL
+}
+
+func (fc *feedCache) put(key string, body []byte) cachedFeed {
+	sum := sha256.Sum256(body)
+	cf := cachedFeed{body: body, etag: `"` + hex.EncodeToString(sum[:8]) + `"`, at: time.Now()}
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if len(fc.items) >= maxCached {
+		for k, v := range fc.items {
+			if time.Since(v.at) >= feedTTL {
+				delete(fc.items, k)
+			}
+		}
+		if len(fc.items) >= maxCached {
+			fc.items = map[string]cachedFeed{}
+		}
+	}
+	fc.items[key] = cf
+	return cf
+}
+
+// Register — публичные фиды вакансий для агрегаторов и поисковиков
+func Register(r *gin.Engine, cfg Config, vac *repo.VacancyRepo, profiles *repo.ProfileRepo) {
+	s := site{publicURL: cfg.PublicURL, frontendURL: cfg.FrontendURL}
+	cache := &feedCache{items: map[string]cachedFeed{}}
+
+	// serve отдает ответ из кэша или собирает его; поддерживает If-None-Match
+	serve := func(c *gin.Context, key, contentType string, build func(ctx context.Context) ([]byte, error)) {
+		cf, ok := cache.get(key)
+		if !ok {
+			body, err := build(c.Request.Context())
+			if errors.Is(err, errNotFound) {
+				c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+				return
+			}
+			if err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+			cf = cache.put(key, body)
+		}
+		c.Header("Cache-Control", "public, max-age=600")
+		c.Header("ETag", cf.etag)
+		if c.GetHeader("If-None-Match") == cf.etag {
+			c.Status(304)
+			return
+		}
+		c.Data(200, contentType, cf.body)
+	}
+
+	// list — активные вакансии и профили их компаний
+	list := func(ctx context.Context, f repo.VacancyFilter, limit int64) ([]models.Vacancy, map[string]models.Profile, error) {
+		items, err := vac.ListActive(ctx, f, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids := make([]string, 0, len(items))
+		seen := map[string]bool{}
+		for _, v := range items {
+			if !seen[v.CompanyID] {
+				seen[v.CompanyID] = true
+				ids = append(ids, v.CompanyID)
+			}
+		}
+		companies, err := profiles.MapByUserIDs(ctx, ids)
+		if err != nil {
+			return nil, nil, err
+		}
+		return items, companies, nil
+	}
+
+	api := r.Group("/api")
+
+	// GET /api/feeds/vacancies.rss?tag=&location= - RSS 2.0
+	api.GET("/feeds/vacancies.rss", func(c *gin.Context) {
+		f, key, ok := bindFilter(c)
+		if !ok {
+			return
+		}
+		serve(c, key, "application/rss+xml; charset=utf-8", func(ctx context.Context) ([]byte, error) {
+			items, companies, err := list(ctx, f, feedLimit)
+			if err != nil {
+				return nil, err
+			}
+			return buildRSS(s, s.feedURL(c.Request.URL.Path, f), f, items, companies)
+		})
+	})
+
+	// GET /api/feeds/vacancies.atom?tag=&location= - Atom 1.0
+	api.GET("/feeds/vacancies.atom", func(c *gin.Context) {
+		f, key, ok := bindFilter(c)
+		if !ok {
+			return
+		}
+		serve(c, key, "application/atom+xml; charset=utf-8", func(ctx context.Context) ([]byte, error) {
+			items, companies, err := list(ctx, f, feedLimit)
+			if err != nil {
+				return nil, err
+			}
+			return buildAtom(s, s.feedURL(c.Request.URL.Path, f), f, items, companies)
+		})
+	})
+
+	// GET /api/feeds/jobs.xml - выгрузка для агрегаторов вакансий
+	api.GET("/feeds/jobs.xml", func(c *gin.Context) {
+		serve(c, c.Request.URL.Path, "application/xml; charset=utf-8", func(ctx context.Context) ([]byte, error) {
+			items, companies, err := list(ctx, repo.VacancyFilter{}, exportLimit)
+			if err != nil {
+				return nil, err
+			}
+			return buildJobsXML(s, items, companies)
+		})
+	})
+
+	// GET /api/feeds/sitemap.xml - страницы вакансий и компаний
+	api.GET("/feeds/sitemap.xml", func(c *gin.Context) {
+		serve(c, c.Request.URL.Path, "application/xml; charset=utf-8", func(ctx context.Context) ([]byte, error) {
+			items, err := vac.ListActive(ctx, repo.VacancyFilter{}, sitemapMax)
+			if err != nil {
+				return nil, err
+			}
+			return buildSitemap(s, items)
+		})
+	})
+
+	// GET /api/vacancies/:id/jsonld - schema.org JobPosting для страницы вакансии
+	api.GET("/vacancies/:id/jsonld", func(c *gin.Context) {
+		id := c.Param("id")
+		serve(c, c.Request.URL.Path, "application/ld+json; charset=utf-8", func(ctx context.Context) ([]byte, error) {
+			v, err := vac.GetByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil || !v.Open(time.Now().UTC()) {
+				return nil, errNotFound
+			}
+			p, err := profiles.GetByUserID(ctx, v.CompanyID)
+			if err != nil {
+				return nil, err
+			}
+			return buildJobPosting(s, v, p)
+		})
+	})
+}
+
+// bindFilter читает ?tag= и ?location=; ключ кэша не зависит от порядка параметров
+// и от регистра города (город сравнивается без учета регистра)
+func bindFilter(c *gin.Context) (repo.VacancyFilter, string, bool) {
+	tag := strings.TrimSpace(c.Query("tag"))
+	location := strings.TrimSpace(c.Query("location"))
+	if utf8.RuneCountInString(tag) > 100 || utf8.RuneCountInString(location) > 100 {
+		c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+		return repo.VacancyFilter{}, "", false
+	}
+	var f repo.VacancyFilter
+	if tag != "" {
+		f.Tags = []string{tag}
+	}
+	f.Location = location
+	return f, c.Request.URL.Path + "?tag=" + tag + "&location=" + strings.ToLower(location), true
+}
@@ -0,0 +1,308 @@
+package telegram
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/modules/applications"
+	"unicorn-auth/internal/modules/chat"
+	"unicorn-auth/internal/notify"
+	"unicorn-auth/internal/repo"
+	tg "unicorn-auth/internal/telegram"
+	"unicorn-auth/internal/webhooks"
+)
+
+const (
+	pollTimeout = 25 * time.Second
+	retryDelay  = 5 * time.Second
+
+	// префиксы callback_data inline-кнопок: acc:<applicationId>, rej:<applicationId>
+	cbAccept = "acc:"
+	cbReject = "rej:"
+)
+
+const helpText = "Бот Unicorn присылает новые отклики, сообщения чата и решения по откликам.\n\n" +
+	"Чтобы привязать аккаунт, получите код в настройках профиля и отправьте /start <код>.\n" +
+	"Чтобы ответить в чат, ответьте (reply) на уведомление.\n" +
+	"/unlink — отвязать аккаунт."
+
+var errUnavailable = errors.New("account_unavailable")
+
+// Bot разбирает обновления Telegram: привязка аккаунта, ответы в чат,
+// решения по откликам из inline-кнопок
+type Bot struct {
+	client   tg.Client
+	links    *repo.TelegramRepo
+	users    *repo.UserRepo
+	orgs     *repo.OrgRepo
+	apps     *repo.ApplicationRepo
+	vac      *repo.VacancyRepo
+	chat     *chat.Service
+	events   *notify.Emitter
+	hooks    *webhooks.Dispatcher
+	username string
+	offsets  *repo.TelegramRepo
+	log      *slog.Logger
+}
+
+func NewBot(client tg.Client, username string, links *repo.TelegramRepo, users *repo.UserRepo, orgs *repo.OrgRepo,
+	apps *repo.ApplicationRepo, vac *repo.VacancyRepo, chatRepo *repo.ChatRepo, events *notify.Emitter, hooks *webhooks.Dispatcher, log *slog.Logger) *Bot {
+	return &Bot{
+		client:   client,
+		links:    links,
+		users:    users,
+		orgs:     orgs,
+		apps:     apps,
+		vac:      vac,
+		chat:     chat.NewService(users, apps, chatRepo, events, hooks),
+		events:   events,
+		hooks:    hooks,
+		username: strings.TrimPrefix(username, "@"),
+		log:      log.With("component", "telegram"),
+	}
+}
+
+// Channel — канал доставки уведомлений через этого бота
+func (b *Bot) Channel() notify.Channel { return &Channel{bot: b} }
+
+// StoreOffsets включает хранение номера обновления в Mongo; вызывать до Start.
+// Без него номер живет в памяти, и новый владелец аренды начнет сначала.
+func (b *Bot) StoreOffsets(r *repo.TelegramRepo) {
+	b.offsets = r
+}
+
+// Start опрашивает getUpdates до отмены контекста
+func (b *Bot) Start(ctx context.Context) {
+	offset, ok := b.loadOffset(ctx)
+	if !ok {
+		return
+	}
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		updates, err := b.client.GetUpdates(ctx, offset, pollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			b.log.Error("get updates", "err", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			hctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			b.handle(hctx, u)
+			cancel()
+		}
+		if len(updates) > 0 && b.offsets != nil {
+			if err := b.offsets.SaveUpdateOffset(ctx, offset); err != nil {
+				b.log.Error("save update offset", "offset", offset, "err", err)
+			}
+		}
+	}
+}
+
+// loadOffset читает сохраненный номер обновления, повторяя при ошибке;
+// false — контекст отменен
+func (b *Bot) loadOffset(ctx context.Context) (int64, bool) {
+	if b.offsets == nil {
+		return 0, true
+	}
+	for {
+		offset, err := b.offsets.UpdateOffset(ctx)
+		if err == nil {
+			return offset, true
+		}
+		b.log.Error("load update offset", "err", err)
+		select {
+		case <-ctx.Done():
+			return 0, false
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+func (b *Bot) handle(ctx context.Context, u tg.Update) {
+	switch {
+	case u.CallbackQuery != nil:
+		b.handleCallback(ctx, u.CallbackQuery)
+	case u.Message != nil && u.Message.Chat.Type == "private":
+		b.handleMessage(ctx, u.Message)
+	}
+}
+
+func (b *Bot) handleMessage(ctx context.Context, m *tg.Message) {
+	text := strings.TrimSpace(m.Text)
+	cmd, arg, _ := strings.Cut(text, " ")
+	cmd, _, _ = strings.Cut(cmd, "@") // /start@unicorn_bot
+	switch {
+	case cmd == "/start" && strings.TrimSpace(arg) != "":
+		b.reply(ctx, m.Chat.ID, b.link(ctx, m, strings.TrimSpace(arg)))
+	case cmd == "/start" || cmd == "/help":
+		b.reply(ctx, m.Chat.ID, helpText)
+	case cmd == "/unlink":
+		ok, err := b.links.UnlinkChat(ctx, m.Chat.ID)
+		switch {
+		case err != nil:
+			b.reply(ctx, m.Chat.ID, "Не удалось отвязать аккаунт, попробуйте позже.")
+		case !ok:
+			b.reply(ctx, m.Chat.ID, "Этот чат не привязан к аккаунту.")
+		default:
+			b.reply(ctx, m.Chat.ID, "Аккаунт отвязан, уведомления больше не придут.")
+		}
+	case m.ReplyToMessage != nil && text != "":
+		b.reply(ctx, m.Chat.ID, b.replyToChat(ctx, m, text))
+	default:
+		b.reply(ctx, m.Chat.ID, "Чтобы написать в чат, ответьте (reply) на уведомление. /help — справка.")
+	}
+}
+
+// link привязывает чат по одноразовому коду из кабинета
+func (b *Bot) link(ctx context.Context, m *tg.Message, code string) string {
+	uid, err := b.links.ClaimCode(ctx, HashCode(code))
+	if err != nil {
+		return "Не удалось привязать аккаунт, попробуйте позже."
+	}
+	if uid == "" {
+		return "Код недействителен или истек. Получите новый в настройках профиля."
+	}
+	if _, err := b.actor(ctx, uid); err != nil {
+		return "Аккаунт недоступен."
+	}
+	username := ""
+	if m.From != nil {
+		username = m.From.Username
+	}
+	if err := b.links.Link(ctx, uid, m.Chat.ID, username); err != nil {
+		return "Не удалось привязать аккаунт, попробуйте позже."
+	}
+	return "Аккаунт привязан. Сюда будут приходить новые отклики, сообщения и решения по откликам."
+}
+
+// replyToChat отправляет ответ на уведомление в чат отклика — как POST /api/chat/:applicationId/messages
+func (b *Bot) replyToChat(ctx context.Context, m *tg.Message, text string) string {
+	link, err := b.links.GetByChat(ctx, m.Chat.ID)
+	if err != nil {
+		return "Ошибка сервера, попробуйте позже."
+	}
+	if link == nil {
+		return "Аккаунт не привязан. /help — справка."
+	}
+	ref, err := b.links.FindRef(ctx, m.Chat.ID, m.ReplyToMessage.MessageID)
+	if err != nil {
+		return "Ошибка сервера, попробуйте позже."
+	}
+	if ref == nil {
+		return "Это сообщение не относится к отклику. Ответьте на уведомление о сообщении или отклике."
+	}
+	actor, err := b.actor(ctx, link.UserID)
+	if err != nil {
+		return "Аккаунт недоступен."
+	}
+	_, err = b.chat.Send(ctx, actor, ref.ApplicationID, text)
+	switch {
+	case err == nil:
+		return "Отправлено."
+	case errors.Is(err, chat.ErrNotFound):
+		return "Отклик не найден."
+	case errors.Is(err, chat.ErrForbidden):
+		return "Нет доступа к этому чату."
+	case errors.Is(err, chat.ErrBadText):
+		return "Сообщение должно быть от 1 до 2000 символов."
+	default:
+		return "Ошибка сервера, попробуйте позже."
+	}
+}
+
+// handleCallback принимает или отклоняет отклик по inline-кнопке; нужна роль owner или recruiter
+func (b *Bot) handleCallback(ctx context.Context, q *tg.CallbackQuery) {
+	status, appID := "", ""
+	switch {
+	case strings.HasPrefix(q.Data, cbAccept):
+		status, appID = "accepted", strings.TrimPrefix(q.Data, cbAccept)
+	case strings.HasPrefix(q.Data, cbReject):
+		status, appID = "rejected", strings.TrimPrefix(q.Data, cbReject)
+	default:
+		b.answer(ctx, q.ID, "")
+		return
+	}
+	if q.Message == nil {
+		b.answer(ctx, q.ID, "Сообщение устарело")
+		return
+	}
+	link, err := b.links.GetByChat(ctx, q.Message.Chat.ID)
+	if err != nil || link == nil {
+		b.answer(ctx, q.ID, "Аккаунт не привязан")
+		return
+	}
+	actor, err := b.actor(ctx, link.UserID)
+	if err != nil || actor.UserType != "company" {
+		b.answer(ctx, q.ID, "Нет доступа")
+		return
+	}
+	if actor.OrgRole != models.OrgRoleOwner && actor.OrgRole != models.OrgRoleRecruiter {
+		b.answer(ctx, q.ID, "Недостаточно прав")
+		return
+	}
+	_, err = applications.Decide(ctx, b.apps, b.vac, b.events, b.hooks, actor.OrgID, actor.UserID, appID, status)
+	switch {
+	case errors.Is(err, applications.ErrNotFound):
+		b.answer(ctx, q.ID, "Отклик не найден")
+	case err != nil:
+		b.answer(ctx, q.ID, "Ошибка сервера")
+	case status == "accepted":
+		b.answer(ctx, q.ID, "Отклик принят")
+	default:
+		b.answer(ctx, q.ID, "Отклик отклонен")
+	}
+}
+
+// actor собирает участника чата так же, как middleware API: активный аккаунт с MFA,
+// у компании — организация и роль
+func (b *Bot) actor(ctx context.Context, userID string) (chat.Actor, error) {
+	u, err := b.users.FindByUserID(ctx, userID)
+	if err != nil {
+		return chat.Actor{}, err
+	}
+	if u == nil || u.Status.Blocked || u.Status.Deleted || !u.MFA.TOTP.Enabled {
+		return chat.Actor{}, errUnavailable
+	}
+	a := chat.Actor{UserID: u.UserID, UserType: string(u.Type)}
+	if a.UserType == "company" {
+		a.OrgID, a.OrgRole, err = b.orgs.Resolve(ctx, u.UserID)
+		if err != nil {
+			return chat.Actor{}, err
+		}
+	}
+	return a, nil
+}
+
+func (b *Bot) reply(ctx context.Context, chatID int64, text string) {
+	if _, err := b.client.SendMessage(ctx, chatID, text, nil); err != nil {
+		b.log.Error("reply", "chat_id", chatID, "err", err)
+	}
+}
+
+func (b *Bot) answer(ctx context.Context, callbackID, text string) {
+	if err := b.client.AnswerCallback(ctx, callbackID, text); err != nil {
+		b.log.Error("answer callback", "err", err)
+	}
+}
+
+// HashCode — хеш кода привязки для хранения; регистр не важен
+func HashCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToUpper(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,415 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/notify"
+	"unicorn-auth/internal/repo"
+	tg "unicorn-auth/internal/telegram"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// sent — вызов sendMessage или answerCallbackQuery, который получила заглушка
+type sent struct {
+	method string
+	chatID int64
+	text   string
+	markup *tg.InlineKeyboard
+	msgID  int64
+}
+
+// botAPI — заглушка Bot API: отдает обновления первым getUpdates,
+// дальше держит long polling пустым и запоминает ответы бота
+type botAPI struct {
+	srv *httptest.Server
+
+	mu      sync.Mutex
+	updates []tg.Update
+	offsets []int64
+	calls   []sent
+	nextID  int64
+}
+
+func newBotAPI(t *testing.T) *botAPI {
+	t.Helper()
+	a := &botAPI{nextID: 1000}
+	a.srv = httptest.NewServer(http.HandlerFunc(a.serve))
+	t.Cleanup(a.srv.Close)
+	return a
+}
+
+func (a *botAPI) serve(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Offset          int64              `json:"offset"`
+		ChatID          int64              `json:"chat_id"`
+		Text            string             `json:"text"`
+		ReplyMarkup     *tg.InlineKeyboard `json:"reply_markup"`
+		CallbackQueryID string             `json:"callback_query_id"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	a.mu.Lock()
+	switch method {
+	case "getUpdates":
+		a.offsets = append(a.offsets, body.Offset)
+		updates := a.updates
+		a.updates = nil
+		a.mu.Unlock()
+		if len(updates) == 0 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(100 * time.Millisecond):
+			}
+			updates = []tg.Update{}
+		}
+		writeResult(w, updates)
+		return
+	case "sendMessage":
+		a.nextID++
+		a.calls = append(a.calls, sent{method: method, chatID: body.ChatID, text: body.Text, markup: body.ReplyMarkup, msgID: a.nextID})
+		id := a.nextID
+		a.mu.Unlock()
+		writeResult(w, tg.Message{MessageID: id, Chat: tg.Chat{ID: body.ChatID, Type: "private"}})
+		return
+	case "answerCallbackQuery":
+		a.calls = append(a.calls, sent{method: method, text: body.Text})
+		a.mu.Unlock()
+		writeResult(w, true)
+		return
+	}
+	a.mu.Unlock()
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = w.Write([]byte(`{"ok":false,"description":"Not Found"}`))
+}
+
+func writeResult(w http.ResponseWriter, result any) {
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func (a *botAPI) polls() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.offsets)
+}
+
+func (a *botAPI) sent() []sent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]sent(nil), a.calls...)
+}
+
+// newTestBot собирает бота на заглушке; d == nil — без базы, для команд, которые в нее не ходят
+func newTestBot(api *botAPI, d *db.Database) *Bot {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewBot(tg.NewHTTPClient(api.srv.URL, "123:test"), "@unicorn_bot", repo.NewTelegramRepo(d), repo.NewUserRepo(d),
+		repo.NewOrgRepo(d), repo.NewApplicationRepo(d), repo.NewVacancyRepo(d), repo.NewChatRepo(d), nil, nil, log)
+}
+
+// poll отдает боту обновления через getUpdates и ждет want ответов
+func poll(t *testing.T, api *botAPI, b *Bot, want int, updates ...tg.Update) []sent {
+	t.Helper()
+	api.mu.Lock()
+	api.updates = updates
+	polled := len(api.offsets)
+	api.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Start(ctx)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	// Ответы отправлены, и бот снова пришел за обновлениями — пачка обработана
+	for (len(api.sent()) < want || api.polls() < polled+2) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return after cancel")
+	}
+	got := api.sent()
+	if len(got) != want {
+		t.Fatalf("bot sent %d calls, want %d: %+v", len(got), want, got)
+	}
+	return got
+}
+
+var nextUpdate int64 = 100
+
+func message(chatID int64, text string) tg.Update {
+	nextUpdate++
+	return tg.Update{UpdateID: nextUpdate, Message: &tg.Message{
+		MessageID: nextUpdate, From: &tg.User{ID: chatID, Username: "anna"},
+		Chat: tg.Chat{ID: chatID, Type: "private"}, Text: text,
+	}}
+}
+
+func replyTo(chatID, messageID int64, text string) tg.Update {
+	u := message(chatID, text)
+	u.Message.ReplyToMessage = &tg.Message{MessageID: messageID, Chat: tg.Chat{ID: chatID, Type: "private"}}
+	return u
+}
+
+func callback(chatID int64, data string) tg.Update {
+	nextUpdate++
+	return tg.Update{UpdateID: nextUpdate, CallbackQuery: &tg.CallbackQuery{
+		ID: "cb", From: tg.User{ID: chatID}, Data: data,
+		Message: &tg.Message{MessageID: 1, Chat: tg.Chat{ID: chatID, Type: "private"}},
+	}}
+}
+
+func expect(t *testing.T, got []sent, want ...string) {
+	t.Helper()
+	for i, w := range want {
+		if !strings.HasPrefix(got[i].text, w) {
+			t.Errorf("call %d (%s): %q, want prefix %q", i, got[i].method, got[i].text, w)
+		}
+	}
+}
+
+func TestBotCommands(t *testing.T) {
+	api := newBotAPI(t)
+	b := newTestBot(api, nil)
+
+	updates := []tg.Update{
+		message(7, "/help"),
+		message(7, "/start"),
+		message(7, "/start@unicorn_bot"),
+		message(-5, "/help"), // в группах бот молчит
+		message(7, "привет"),
+		callback(7, "unknown"),
+		callback(7, "acc:01H"),
+	}
+	updates[3].Message.Chat.Type = "group"
+	updates[6].CallbackQuery.Message = nil
+	last := updates[len(updates)-1].UpdateID
+
+	got := poll(t, api, b, 6, updates...)
+	expect(t, got,
+		"Бот Unicorn присылает",
+		"Бот Unicorn присылает",
+		"Бот Unicorn присылает",
+		"Чтобы написать в чат, ответьте (reply)",
+		"",
+		"Сообщение устарело",
+	)
+	if got[0].chatID != 7 || got[4].method != "answerCallbackQuery" {
+		t.Fatalf("calls = %+v", got)
+	}
+
+	// Следующий getUpdates подтверждает обработанные обновления
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.offsets) < 2 || api.offsets[0] != 0 || api.offsets[1] != last+1 {
+		t.Fatalf("getUpdates offsets = %v, want 0 then %d", api.offsets, last+1)
+	}
+}
+
+// Тесты с базой идут против настоящей MongoDB: MONGO_TEST_URI=mongodb://127.0.0.1:27017
+func testDB(t *testing.T) *db.Database {
+	t.Helper()
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+	d := db.Connect(context.Background(), uri, "telegram_test_"+strings.ToLower(ulid.Make().String()), nil)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = d.DB.Drop(ctx)
+		_ = d.Close(ctx)
+	})
+	return d
+}
+
+// account создает активный аккаунт с MFA
+func account(t *testing.T, d *db.Database, typ models.UserType) string {
+	t.Helper()
+	u := &models.User{UserID: ulid.Make().String(), Login: ulid.Make().String(), Type: typ}
+	u.MFA.TOTP.Enabled = true
+	if err := repo.NewUserRepo(d).Create(context.Background(), u); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return u.UserID
+}
+
+func link(t *testing.T, d *db.Database, userID string, chatID int64) {
+	t.Helper()
+	if err := repo.NewTelegramRepo(d).Link(context.Background(), userID, chatID, ""); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+}
+
+func TestBotLinkAndUnlink(t *testing.T) {
+	d := testDB(t)
+	api := newBotAPI(t)
+	b := newTestBot(api, d)
+	ctx := context.Background()
+	links := repo.NewTelegramRepo(d)
+
+	uid := account(t, d, models.UserTypeUser)
+	if err := links.SaveCode(ctx, uid, HashCode("AB12CD"), time.Now().UTC().Add(10*time.Minute)); err != nil {
+		t.Fatalf("save code: %v", err)
+	}
+
+	got := poll(t, api, b, 2, message(7, "/start ab12cd"), message(7, "/start ab12cd"))
+	expect(t, got, "Аккаунт привязан", "Код недействителен или истек")
+	l, err := links.GetByUser(ctx, uid)
+	if err != nil || l == nil || l.ChatID != 7 || l.Username != "anna" {
+		t.Fatalf("link = %+v, %v", l, err)
+	}
+
+	got = poll(t, api, b, 4, message(7, "/unlink"), message(7, "/unlink"))
+	expect(t, got[2:], "Аккаунт отвязан", "Этот чат не привязан")
+	if l, _ := links.GetByUser(ctx, uid); l != nil {
+		t.Fatalf("link after /unlink = %+v", l)
+	}
+}
+
+// Новый владелец аренды продолжает с сохраненного номера обновления
+func TestBotResumesOffset(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	links := repo.NewTelegramRepo(d)
+	if ok, err := repo.NewSchedulerRepo(d).AcquireLease(ctx, "telegram", "a", time.Now().UTC().Add(time.Minute)); err != nil || !ok {
+		t.Fatalf("acquire lease: %v, %v", ok, err)
+	}
+
+	api := newBotAPI(t)
+	b := newTestBot(api, d)
+	b.StoreOffsets(links)
+	u := message(7, "/help")
+	poll(t, api, b, 1, u)
+	if offset, err := links.UpdateOffset(ctx); err != nil || offset != u.UpdateID+1 {
+		t.Fatalf("saved offset = %d, %v; want %d", offset, err, u.UpdateID+1)
+	}
+	// Запоздавший прежний владелец не откатывает номер назад
+	if err := links.SaveUpdateOffset(ctx, 1); err != nil {
+		t.Fatalf("save offset: %v", err)
+	}
+
+	next := newBotAPI(t)
+	b = newTestBot(next, d)
+	b.StoreOffsets(links)
+	poll(t, next, b, 0)
+	next.mu.Lock()
+	defer next.mu.Unlock()
+	if next.offsets[0] != u.UpdateID+1 {
+		t.Fatalf("getUpdates offsets = %v, want start from %d", next.offsets, u.UpdateID+1)
+	}
+}
+
+func TestBotRepliesAndDecisions(t *testing.T) {
+	d := testDB(t)
+	api := newBotAPI(t)
+	b := newTestBot(api, d)
+
+	owner := account(t, d, models.UserTypeCompany)
+	viewer := account(t, d, models.UserTypeCompany)
+	if err := repo.NewOrgRepo(d).AddMember(context.Background(), &models.OrgMember{
+		OrgID: owner, UserID: viewer, Role: models.OrgRoleViewer,
+	}); err != nil {
+		t.Fatalf("add member: %v", err)
+	}
+	link(t, d, viewer, 8)
+
+	got := poll(t, api, b, 4,
+		replyTo(9, 1, "Здравствуйте"), // чат не привязан
+		replyTo(8, 1, "Здравствуйте"), // не уведомление об отклике
+		callback(9, "acc:01H"),
+		callback(8, "rej:01H"), // viewer не принимает решений
+	)
+	expect(t, got,
+		"Аккаунт не привязан",
+		"Это сообщение не относится к отклику",
+		"Аккаунт не привязан",
+		"Недостаточно прав",
+	)
+}
+
+func notifyMessage(userID, typ, appID string) notify.Message {
+	m := notify.Message{UserID: userID, Type: typ, Title: "Заголовок", Text: "Текст"}
+	if appID != "" {
+		m.Data = map[string]string{"applicationId": appID}
+	}
+	return m
+}
+
+func TestChannelDeliver(t *testing.T) {
+	d := testDB(t)
+	api := newBotAPI(t)
+	b := newTestBot(api, d)
+	ch := b.Channel()
+	ctx := context.Background()
+
+	candidate := account(t, d, models.UserTypeUser)
+	owner := account(t, d, models.UserTypeCompany)
+	link(t, d, candidate, 11)
+	link(t, d, owner, 12)
+
+	// Не привязан — ничего не отправляется
+	if err := ch.Deliver(ctx, notifyMessage(account(t, d, models.UserTypeUser), notify.EventApplicationStatus, "app1")); err != nil {
+		t.Fatalf("deliver to unlinked: %v", err)
+	}
+	if n := len(api.sent()); n != 0 {
+		t.Fatalf("sent %d messages to unlinked user", n)
+	}
+
+	if err := ch.Deliver(ctx, notifyMessage(candidate, notify.EventApplicationStatus, "app1")); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if err := ch.Deliver(ctx, notifyMessage(owner, notify.EventApplicationNew, "app2")); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if err := ch.Deliver(ctx, notifyMessage(owner, notify.EventPaymentSuccess, "")); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+
+	got := api.sent()
+	if len(got) != 3 {
+		t.Fatalf("sent %d messages, want 3", len(got))
+	}
+	if got[0].chatID != 11 || got[0].text != "Заголовок\n\nТекст\n\nОтветьте на это сообщение, чтобы написать в чат." || got[0].markup != nil {
+		t.Fatalf("candidate message = %+v", got[0])
+	}
+	kb := got[1].markup
+	if got[1].chatID != 12 || kb == nil || len(kb.InlineKeyboard) != 1 || len(kb.InlineKeyboard[0]) != 2 ||
+		kb.InlineKeyboard[0][0].CallbackData != "acc:app2" || kb.InlineKeyboard[0][1].CallbackData != "rej:app2" {
+		t.Fatalf("owner message = %+v", got[1])
+	}
+	if got[2].text != "Заголовок\n\nТекст" || got[2].markup != nil {
+		t.Fatalf("message without application = %+v", got[2])
+	}
+
+	// Ответ на уведомление найдет отклик
+	links := repo.NewTelegramRepo(d)
+	for _, c := range []struct {
+		chatID, msgID int64
+		app           string
+	}{{11, got[0].msgID, "app1"}, {12, got[1].msgID, "app2"}} {
+		ref, err := links.FindRef(ctx, c.chatID, c.msgID)
+		if err != nil || ref == nil || ref.ApplicationID != c.app {
+			t.Fatalf("ref for chat %d message %d = %+v, %v; want %s", c.chatID, c.msgID, ref, err, c.app)
+		}
+	}
+	if ref, _ := links.FindRef(ctx, 12, got[2].msgID); ref != nil {
+		t.Fatalf("ref saved for message without application: %+v", ref)
+	}
+}
@@ -0,0 +1,56 @@
+package telegram
+
+import (
+	"context"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/notify"
+	tg "unicorn-auth/internal/telegram"
+)
+
+// Channel доставляет уведомления в привязанный чат Telegram.
+// Сообщения по откликам запоминаются, чтобы ответ на них ушел в чат отклика.
+type Channel struct {
+	bot *Bot
+}
+
+func (c *Channel) Name() string { return notify.ChannelTelegram }
+
+func (c *Channel) Deliver(ctx context.Context, m notify.Message) error {
+	link, err := c.bot.links.GetByUser(ctx, m.UserID)
+	if err != nil || link == nil {
+		return err
+	}
+	text := m.Title
+	if m.Text != "" {
+		text += "\n\n" + m.Text
+	}
+	appID := m.Data["applicationId"]
+	var kb *tg.InlineKeyboard
+	if appID != "" {
+		text += "\n\nОтветьте на это сообщение, чтобы написать в чат."
+		if m.Type == notify.EventApplicationNew && c.canDecide(ctx, m.UserID) {
+			kb = &tg.InlineKeyboard{InlineKeyboard: [][]tg.InlineButton{{
+				{Text: "Принять", CallbackData: cbAccept + appID},
+				{Text: "Отклонить", CallbackData: cbReject + appID},
+			}}}
+		}
+	}
+	msgID, err := c.bot.client.SendMessage(ctx, link.ChatID, text, kb)
+	if err != nil {
+		return err
+	}
+	if appID != "" {
+		return c.bot.links.SaveRef(ctx, link.ChatID, msgID, appID)
+	}
+	return nil
+}
+
+// canDecide: кнопки решения показываем только тем, кому их разрешит бот
+func (c *Channel) canDecide(ctx context.Context, userID string) bool {
+	a, err := c.bot.actor(ctx, userID)
+	if err != nil {
+		return false
+	}
+	return a.UserType == "company" && (a.OrgRole == models.OrgRoleOwner || a.OrgRole == models.OrgRoleRecruiter)
+}
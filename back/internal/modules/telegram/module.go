@@ -0,0 +1,91 @@
+package telegram
+
+import (
+	"crypto/rand"
+	"time"
+
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	codeTTL = 10 * time.Minute
+	// без похожих символов (0/O, 1/I), код удобно набрать вручную
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	codeLen      = 8
+)
+
+// Register подключает привязку Telegram; bot == nil — бот не настроен (нет TELEGRAM_BOT_TOKEN)
+func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, links *repo.TelegramRepo, bot *Bot) {
+	api := r.Group("/api/telegram")
+	api.Use(middleware.RequireAuth(sec))
+	api.Use(middleware.RequireMFAEnabled(sec, users))
+
+	// POST /api/telegram/link-code - одноразовый код и ссылка t.me для привязки
+	api.POST("/link-code", func(c *gin.Context) {
+		if bot == nil {
+			c.JSON(503, gin.H{"ok": false, "error": "telegram_disabled"})
+			return
+		}
+		code, err := newCode()
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		expiresAt := time.Now().UTC().Add(codeTTL)
+		if err := links.SaveCode(c.Request.Context(), c.GetString(middleware.CtxUserID), HashCode(code), expiresAt); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		out := gin.H{"ok": true, "code": code, "expiresAt": expiresAt}
+		if bot.username != "" {
+			out["link"] = "https://t.me/" + bot.username + "?start=" + code
+		}
+		c.JSON(200, out)
+	})
+
+	// GET /api/telegram/status - привязан ли аккаунт
+	api.GET("/status", func(c *gin.Context) {
+		link, err := links.GetByUser(c.Request.Context(), c.GetString(middleware.CtxUserID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		out := gin.H{"ok": true, "enabled": bot != nil, "linked": link != nil}
+		if link != nil {
+			out["link"] = link
+		}
+		if bot != nil && bot.username != "" {
+			out["bot"] = bot.username
+		}
+		c.JSON(200, out)
+	})
+
+	// DELETE /api/telegram/link - отвязать аккаунт
+	api.DELETE("/link", func(c *gin.Context) {
+		ok, err := links.Unlink(c.Request.Context(), c.GetString(middleware.CtxUserID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_linked"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+}
+
+func newCode() (string, error) {
+	b := make([]byte, codeLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = codeAlphabet[int(b[i])%len(codeAlphabet)]
+	}
+	return string(b), nil
+}
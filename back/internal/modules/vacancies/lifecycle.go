@@ -0,0 +1,229 @@
+package vacancies
+
+import (
+	"time"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/lifecycle"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// maxScheduleAhead — насколько вперед можно запланировать публикацию
+const maxScheduleAhead = 90 * 24 * time.Hour
+
+type publishReq struct {
+	PublishAt *time.Time `json:"publishAt,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// plan проверяет даты публикации. publishAt != nil — публикация отложена;
+// expiresAt == nil — срок по умолчанию от момента публикации.
+func (p publishReq) plan(now time.Time, premium bool) (publishAt, expiresAt *time.Time, ok bool) {
+	start := now
+	if p.PublishAt != nil && p.PublishAt.After(now) {
+		if p.PublishAt.After(now.Add(maxScheduleAhead)) {
+			return nil, nil, false
+		}
+		at := p.PublishAt.UTC()
+		start, publishAt = at, &at
+	}
+	if p.ExpiresAt != nil {
+		if !p.ExpiresAt.After(start) || p.ExpiresAt.After(start.Add(models.VacancyMaxTerm(premium))) {
+			return nil, nil, false
+		}
+		exp := p.ExpiresAt.UTC()
+		expiresAt = &exp
+	}
+	return publishAt, expiresAt, true
+}
+
+// registerLifecycle — публикация, пауза, закрытие, продление и перевыпуск вакансий
+func registerLifecycle(protected *gin.RouterGroup, canEdit gin.HandlerFunc, users *repo.UserRepo, vac *repo.VacancyRepo, life *lifecycle.Service) {
+	// load отдает вакансию организации и подписку владельца или пишет ошибку
+	load := func(c *gin.Context) (*models.Vacancy, bool, bool) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		v, err := vac.GetByID(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return nil, false, false
+		}
+		if v == nil || v.CompanyID != orgID {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return nil, false, false
+		}
+		u, err := users.FindByUserID(c.Request.Context(), orgID)
+		if err != nil || u == nil {
+			c.JSON(401, gin.H{"ok": false, "error": "unauthorized"})
+			return nil, false, false
+		}
+		return v, u.Subscription.Active, true
+	}
+
+	// hasSlot проверяет лимит тарифа перед тем, как вакансия займет место
+	hasSlot := func(c *gin.Context, premium bool) bool {
+		limit := models.VacancyLimit(premium)
+		cnt, err := vac.CountActiveByCompany(c.Request.Context(), c.GetString(middleware.CtxOrgID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return false
+		}
+		if cnt >= limit {
+			c.JSON(403, gin.H{"ok": false, "error": "limit_reached", "limit": limit})
+			return false
+		}
+		return true
+	}
+
+	// transition применяет переход и отвечает вакансией; 409, если статус успели сменить
+	transition := func(c *gin.Context, v *models.Vacancy, from []string, set bson.M, unset ...string) *models.Vacancy {
+		out, err := vac.Transition(c.Request.Context(), v.VacancyID, v.CompanyID, from, set, unset...)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return nil
+		}
+		if out == nil {
+			c.JSON(409, gin.H{"ok": false, "error": "invalid_status"})
+			return nil
+		}
+		c.JSON(200, gin.H{"ok": true, "vacancy": out})
+		return out
+	}
+
+	// POST /api/vacancies/:id/publish - черновик или пауза -> published; черновик можно запланировать
+	protected.POST("/vacancies/:id/publish", canEdit, func(c *gin.Context) {
+		var req publishReq
+		if !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+		v, premium, ok := load(c)
+		if !ok {
+			return
+		}
+		now := time.Now().UTC()
+
+		switch v.Status {
+		case models.VacancyPaused:
+			// Срок публикации на паузе не останавливается
+			if req.PublishAt != nil || req.ExpiresAt != nil {
+				c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+				return
+			}
+			if v.ExpiresAt != nil && !v.ExpiresAt.After(now) {
+				c.JSON(409, gin.H{"ok": false, "error": "invalid_status"})
+				return
+			}
+			transition(c, v, []string{models.VacancyPaused}, bson.M{"status": models.VacancyPublished})
+
+		case models.VacancyDraft:
+			publishAt, expiresAt, ok := req.plan(now, premium)
+			if !ok {
+				c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+				return
+			}
+			// Запланированный черновик уже занимает место в лимите
+			if v.PublishAt == nil && !hasSlot(c, premium) {
+				return
+			}
+			if publishAt != nil {
+				set := bson.M{"publishAt": publishAt}
+				if expiresAt != nil {
+					set["expiresAt"] = expiresAt
+					transition(c, v, []string{models.VacancyDraft}, set)
+				} else {
+					transition(c, v, []string{models.VacancyDraft}, set, "expiresAt")
+				}
+				return
+			}
+			if expiresAt == nil {
+				exp := now.Add(models.VacancyTerm)
+				expiresAt = &exp
+			}
+			transition(c, v, []string{models.VacancyDraft},
+				bson.M{"status": models.VacancyPublished, "publishedAt": now, "expiresAt": expiresAt}, "publishAt")
+
+		default:
+			c.JSON(409, gin.H{"ok": false, "error": "invalid_status"})
+		}
+	})
+
+	// POST /api/vacancies/:id/pause - снять с поиска, не закрывая
+	protected.POST("/vacancies/:id/pause", canEdit, func(c *gin.Context) {
+		v, _, ok := load(c)
+		if !ok {
+			return
+		}
+		transition(c, v, []string{models.VacancyPublished}, bson.M{"status": models.VacancyPaused})
+	})
+
+	// POST /api/vacancies/:id/close - закрыть; откликнувшиеся получат уведомление
+	protected.POST("/vacancies/:id/close", canEdit, func(c *gin.Context) {
+		v, _, ok := load(c)
+		if !ok {
+			return
+		}
+		if out := transition(c, v, models.VacancySlotStatuses,
+			bson.M{"status": models.VacancyClosed, "closedAt": time.Now().UTC()}); out != nil {
+			life.Closed(c.Request.Context(), out)
+		}
+	})
+
+	// POST /api/vacancies/:id/extend - продлить срок на 30 дней (подписка), не дальше 90 дней вперед
+	protected.POST("/vacancies/:id/extend", canEdit, func(c *gin.Context) {
+		v, premium, ok := load(c)
+		if !ok {
+			return
+		}
+		if !premium {
+			c.JSON(403, gin.H{"ok": false, "error": "premium_required"})
+			return
+		}
+		now := time.Now().UTC()
+		from := now
+		if v.ExpiresAt != nil && v.ExpiresAt.After(now) {
+			from = *v.ExpiresAt
+		}
+		exp := from.Add(models.VacancyTerm)
+		if limit := now.Add(models.VacancyMaxTermPremium); exp.After(limit) {
+			exp = limit
+		}
+		if v.ExpiresAt != nil && !exp.After(v.ExpiresAt.Add(time.Hour)) {
+			c.JSON(409, gin.H{"ok": false, "error": "max_term_reached"})
+			return
+		}
+		transition(c, v, models.VacancySlotStatuses, bson.M{"expiresAt": exp})
+	})
+
+	// POST /api/vacancies/:id/republish - опубликовать заново (подписка): новая дата
+	// публикации поднимает вакансию в выдаче, срок — 30 дней
+	protected.POST("/vacancies/:id/republish", canEdit, func(c *gin.Context) {
+		v, premium, ok := load(c)
+		if !ok {
+			return
+		}
+		if !premium {
+			c.JSON(403, gin.H{"ok": false, "error": "premium_required"})
+			return
+		}
+		from := []string{models.VacancyPublished, models.VacancyPaused}
+		if v.Status == models.VacancyClosed || v.Status == models.VacancyExpired {
+			// Закрытая вакансия снова займет место в лимите
+			if !hasSlot(c, premium) {
+				return
+			}
+			from = []string{models.VacancyClosed, models.VacancyExpired}
+		}
+		now := time.Now().UTC()
+		transition(c, v, from, bson.M{
+			"status":      models.VacancyPublished,
+			"publishedAt": now,
+			"expiresAt":   now.Add(models.VacancyTerm),
+			"isPremium":   true,
+			"colorCode":   "#FFD700",
+		}, "closedAt")
+	})
+}
@@ -2,12 +2,18 @@ package vacancies
 
 import (
 	"strings"
+	"time"
 
+	"unicorn-auth/internal/analytics"
 	"unicorn-auth/internal/http/httputil"
 	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/lifecycle"
 	"unicorn-auth/internal/models"
 	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/screening"
 	"unicorn-auth/internal/security"
+	"unicorn-auth/internal/taxonomy"
+	"unicorn-auth/internal/vacimport"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -18,9 +24,60 @@ type createReq struct {
 	Description string   `json:"description"`
 	Location    string   `json:"location,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
+	SalaryFrom  int64    `json:"salaryFrom,omitempty"`
+	SalaryTo    int64    `json:"salaryTo,omitempty"`
+
+	// Анкета для откликов; nil — не менять, пустой список — убрать
+	Questions *[]models.Question `json:"questions,omitempty"`
+}
+
+// questions проверяет анкету из запроса; ok == false — ответ с ошибкой уже записан
+func (req createReq) questions(c *gin.Context) ([]models.Question, bool) {
+	if req.Questions == nil {
+		return nil, true
+	}
+	qs, err := screening.Normalize(*req.Questions)
+	if err != nil {
+		c.JSON(400, gin.H{"ok": false, "error": err.Error()})
+		return nil, false
+	}
+	return qs, true
+}
+
+// viewer — ключ посетителя для счетчика просмотров. Компании, ключи API
+// и клиенты без User-Agent не учитываются
+func viewer(c *gin.Context, sec *security.Security) (string, bool) {
+	ua := c.GetHeader("User-Agent")
+	if ua == "" {
+		return "", false
+	}
+	h := c.GetHeader("Authorization")
+	if !strings.HasPrefix(h, "Bearer ") {
+		return analytics.Visitor("", c.ClientIP(), ua), true
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+	if strings.HasPrefix(token, security.APIKeyPrefix) {
+		return "", false
+	}
+	claims, err := sec.Tokens.ParseAccess(token)
+	if err != nil {
+		return analytics.Visitor("", c.ClientIP(), ua), true
+	}
+	if claims.Type == "company" {
+		return "", false
+	}
+	return analytics.Visitor(claims.UserID, "", ""), true
+}
+
+// postReq — новая вакансия: сразу в поиск, черновиком или с отложенной публикацией
+type postReq struct {
+	createReq
+	publishReq
+	Draft bool `json:"draft,omitempty"`
 }
 
-func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, vac *repo.VacancyRepo) {
+func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, orgs *repo.OrgRepo, profiles *repo.ProfileRepo, vac *repo.VacancyRepo,
+	life *lifecycle.Service, feeds *repo.VacancyFeedRepo, poller *vacimport.Poller, tax *taxonomy.Service, views *analytics.Service) {
 	api := r.Group("/api")
 
 	api.GET("/vacancies", func(c *gin.Context) {
@@ -29,6 +86,10 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, vac *
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
+		// Отсекающие условия анкеты знает только компания
+		for i := range items {
+			items[i].HideKnockouts()
+		}
 		c.JSON(200, gin.H{"ok": true, "items": items})
 	})
 
@@ -38,10 +99,15 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, vac *
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
-		if v == nil {
+		// Черновики видны только компании через /vacancies/my
+		if v == nil || v.Status == models.VacancyDraft {
 			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
 			return
 		}
+		if visitor, ok := viewer(c, sec); ok {
+			views.RecordView(v, visitor)
+		}
+		v.HideKnockouts()
 		c.JSON(200, gin.H{"ok": true, "vacancy": v})
 	})
 
@@ -49,11 +115,18 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, vac *
 	protected.Use(middleware.RequireAuth(sec))
 	protected.Use(middleware.RequireType("company"))
 	protected.Use(middleware.RequireMFAEnabled(sec, users))
+	protected.Use(middleware.ResolveOrg(orgs))
 
-	// GET /api/vacancies/my - получить свои вакансии
+	// Вакансии ведут владелец и рекрутеры, наблюдатель только смотрит
+	canEdit := middleware.RequireOrgRole(models.OrgRoleOwner, models.OrgRoleRecruiter)
+
+	registerTransfer(protected, canEdit, vac, feeds, vacimport.NewImporter(users, profiles, vac, tax), poller)
+	registerLifecycle(protected, canEdit, users, vac, life)
+
+	// GET /api/vacancies/my - получить вакансии своей организации
 	protected.GET("/vacancies/my", func(c *gin.Context) {
-		uid := c.GetString(middleware.CtxUserID)
-		items, err := vac.ListByCompanyID(c.Request.Context(), uid)
+		orgID := c.GetString(middleware.CtxOrgID)
+		items, err := vac.ListByCompanyID(c.Request.Context(), orgID)
 		if err != nil {
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
@@ -61,43 +134,72 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, vac *
 		c.JSON(200, gin.H{"ok": true, "items": items})
 	})
 
-	protected.POST("/vacancies", func(c *gin.Context) {
+	// POST /api/vacancies - новая вакансия; draft и publishAt откладывают публикацию
+	protected.POST("/vacancies", canEdit, func(c *gin.Context) {
 		uid := c.GetString(middleware.CtxUserID)
+		orgID := c.GetString(middleware.CtxOrgID)
 
-		u, err := users.FindByUserID(c.Request.Context(), uid)
-		if err != nil || u == nil {
-			c.JSON(401, gin.H{"ok": false, "error": "unauthorized"})
+		var req postReq
+		if !httputil.BindJSONStrict(c, &req, 64<<10) {
 			return
 		}
 
-		// Проверяем лимиты: 16 для премиум, 2 для обычных
-		maxLimit := int64(2)
-		if u.Subscription.Active {
-			maxLimit = 16
+		// Лимиты и премиум определяются подпиской владельца организации
+		u, err := users.FindByUserID(c.Request.Context(), orgID)
+		if err != nil || u == nil {
+			c.JSON(401, gin.H{"ok": false, "error": "unauthorized"})
+			return
 		}
 
-		cnt, err := vac.CountActiveByCompany(c.Request.Context(), uid)
-		if err != nil {
-			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+		now := time.Now().UTC()
+		publishAt, expiresAt, ok := req.plan(now, u.Subscription.Active)
+		if !ok {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
 			return
 		}
-		if cnt >= maxLimit {
-			c.JSON(403, gin.H{"ok": false, "error": "limit_reached", "limit": maxLimit})
+		questions, ok := req.questions(c)
+		if !ok {
 			return
 		}
+		status := models.VacancyPublished
+		if req.Draft || publishAt != nil {
+			status = models.VacancyDraft
+		}
 
-		var req createReq
-		if !httputil.BindJSONStrict(c, &req, 64<<10) {
-			return
+		// Проверяем лимиты: 16 для премиум, 2 для обычных; черновик без даты публикации не считается
+		if !req.Draft || publishAt != nil {
+			maxLimit := models.VacancyLimit(u.Subscription.Active)
+			cnt, err := vac.CountActiveByCompany(c.Request.Context(), orgID)
+			if err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+			if cnt >= maxLimit {
+				c.JSON(403, gin.H{"ok": false, "error": "limit_reached", "limit": maxLimit})
+				return
+			}
 		}
+
 		v := &models.Vacancy{
-			CompanyID:   uid,
+			CompanyID:   orgID,
+			CreatedBy:   uid,
 			Title:       strings.TrimSpace(req.Title),
 			Description: strings.TrimSpace(req.Description),
 			Location:    strings.TrimSpace(req.Location),
-			Tags:        req.Tags,
+			Tags:        tax.Normalize(c.Request.Context(), req.Tags),
+			SalaryFrom:  req.SalaryFrom,
+			SalaryTo:    req.SalaryTo,
 			IsPremium:   u.Subscription.Active,
 			ColorCode:   "",
+			Status:      status,
+			PublishAt:   publishAt,
+			ExpiresAt:   expiresAt,
+			Questions:   questions,
+		}
+
+		// Значок проверенной компании копируется из профиля
+		if p, _ := profiles.GetByUserID(c.Request.Context(), orgID); p != nil {
+			v.CompanyVerified = p.Verified
 		}
 
 		// Если подписка активна, добавляем цветовой код
@@ -105,7 +207,7 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, vac *
 			v.ColorCode = "#FFD700" // Gold color for premium
 		}
 
-		if v.Title == "" || v.Description == "" {
+		if v.Title == "" || v.Description == "" || !models.ValidSalary(v.SalaryFrom, v.SalaryTo) {
 			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
 			return
 		}
@@ -113,34 +215,72 @@ func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, vac *
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
-		c.JSON(200, gin.H{"ok": true, "vacancyId": v.VacancyID})
+		c.JSON(200, gin.H{"ok": true, "vacancyId": v.VacancyID, "status": v.Status})
 	})
 
-	protected.PATCH("/vacancies/:id", func(c *gin.Context) {
-		uid := c.GetString(middleware.CtxUserID)
+	protected.PATCH("/vacancies/:id", canEdit, func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
 		var req createReq
 		if !httputil.BindJSONStrict(c, &req, 64<<10) {
 			return
 		}
+		if !models.ValidSalary(req.SalaryFrom, req.SalaryTo) {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
 		set := bson.M{
 			"title":       strings.TrimSpace(req.Title),
 			"description": strings.TrimSpace(req.Description),
 			"location":    strings.TrimSpace(req.Location),
-			"tags":        req.Tags,
+			"tags":        tax.Normalize(c.Request.Context(), req.Tags),
+			"salaryFrom":  req.SalaryFrom,
+			"salaryTo":    req.SalaryTo,
+		}
+		if req.Questions != nil {
+			questions, ok := req.questions(c)
+			if !ok {
+				return
+			}
+			set["questions"] = questions
 		}
-		if err := vac.Update(c.Request.Context(), c.Param("id"), uid, set); err != nil {
+		if err := vac.Update(c.Request.Context(), c.Param("id"), orgID, set); err != nil {
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
 		c.JSON(200, gin.H{"ok": true})
 	})
 
-	protected.DELETE("/vacancies/:id", func(c *gin.Context) {
-		uid := c.GetString(middleware.CtxUserID)
-		if err := vac.Delete(c.Request.Context(), c.Param("id"), uid); err != nil {
+	// DELETE /api/vacancies/:id - черновик удаляется, остальные вакансии закрываются:
+	// на них ссылаются отклики
+	protected.DELETE("/vacancies/:id", canEdit, func(c *gin.Context) {
+		ctx := c.Request.Context()
+		orgID := c.GetString(middleware.CtxOrgID)
+		v, err := vac.GetByID(ctx, c.Param("id"))
+		if err != nil {
 			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
 			return
 		}
+		if v == nil || v.CompanyID != orgID {
+			c.JSON(200, gin.H{"ok": true})
+			return
+		}
+		if v.Status == models.VacancyDraft {
+			if err := vac.Delete(ctx, v.VacancyID, orgID); err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+				return
+			}
+			c.JSON(200, gin.H{"ok": true})
+			return
+		}
+		closed, err := vac.Transition(ctx, v.VacancyID, orgID, models.VacancySlotStatuses,
+			bson.M{"status": models.VacancyClosed, "closedAt": time.Now().UTC()})
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if closed != nil {
+			life.Closed(ctx, closed)
+		}
 		c.JSON(200, gin.H{"ok": true})
 	})
 }
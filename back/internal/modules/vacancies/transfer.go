@@ -0,0 +1,192 @@
+package vacancies
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/safehttp"
+	"unicorn-auth/internal/vacimport"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxImportBytes = 2 << 20
+
+type feedReq struct {
+	URL    string `json:"url"`
+	Active *bool  `json:"active,omitempty"`
+}
+
+// registerTransfer — импорт вакансий из файла и фида, выгрузка вакансий организации
+func registerTransfer(protected *gin.RouterGroup, canEdit gin.HandlerFunc, vac *repo.VacancyRepo,
+	feeds *repo.VacancyFeedRepo, importer *vacimport.Importer, poller *vacimport.Poller) {
+
+	// POST /api/vacancies/import?format=csv|json|xml&dryRun=true - файл в теле или multipart-поле file
+	protected.POST("/vacancies/import", canEdit, func(c *gin.Context) {
+		body, format, ok := readImport(c)
+		if !ok {
+			return
+		}
+		rows, err := vacimport.Parse(format, bytes.NewReader(body))
+		if err != nil {
+			c.JSON(400, gin.H{"ok": false, "error": err.Error(), "maxRows": vacimport.MaxRows})
+			return
+		}
+		res, err := importer.Apply(c.Request.Context(), c.GetString(middleware.CtxOrgID), c.GetString(middleware.CtxUserID),
+			rows, c.Query("dryRun") == "true")
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "result": res})
+	})
+
+	// GET /api/vacancies/export?format=csv|json - все вакансии организации
+	protected.GET("/vacancies/export", func(c *gin.Context) {
+		items, err := vac.ListByCompanyID(c.Request.Context(), c.GetString(middleware.CtxOrgID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		name := "vacancies-" + time.Now().UTC().Format("2006-01-02")
+		switch c.DefaultQuery("format", vacimport.FormatJSON) {
+		case vacimport.FormatJSON:
+			c.Header("Content-Disposition", `attachment; filename="`+name+`.json"`)
+			c.JSON(200, gin.H{"vacancies": items})
+		case vacimport.FormatCSV:
+			rows := [][]string{{"externalId", "vacancyId", "title", "description", "location", "tags", "salaryFrom", "salaryTo", "status", "createdAt"}}
+			for _, v := range items {
+				rows = append(rows, []string{
+					v.ExternalID, v.VacancyID, v.Title, v.Description, v.Location, strings.Join(v.Tags, ";"),
+					strconv.FormatInt(v.SalaryFrom, 10), strconv.FormatInt(v.SalaryTo, 10), v.Status,
+					v.CreatedAt.Format(time.RFC3339),
+				})
+			}
+			httputil.WriteCSV(c, name+".csv", rows)
+		default:
+			c.JSON(400, gin.H{"ok": false, "error": "unsupported_format"})
+		}
+	})
+
+	// GET /api/vacancies/import/feed - настройки фида и итог последнего опроса
+	protected.GET("/vacancies/import/feed", func(c *gin.Context) {
+		f, err := feeds.Get(c.Request.Context(), c.GetString(middleware.CtxOrgID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "feed": f})
+	})
+
+	// PUT /api/vacancies/import/feed - адрес XML-фида; первый опрос — в ближайшую минуту
+	protected.PUT("/vacancies/import/feed", canEdit, func(c *gin.Context) {
+		var req feedReq
+		if !httputil.BindJSONStrict(c, &req, 4<<10) {
+			return
+		}
+		u, ok := safehttp.ValidURL(req.URL)
+		if !ok {
+			c.JSON(400, gin.H{"ok": false, "error": "invalid_url"})
+			return
+		}
+		active := req.Active == nil || *req.Active
+		f, err := feeds.Save(c.Request.Context(), c.GetString(middleware.CtxOrgID), u, c.GetString(middleware.CtxUserID), active)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "feed": f})
+	})
+
+	// DELETE /api/vacancies/import/feed - перестать опрашивать; вакансии остаются
+	protected.DELETE("/vacancies/import/feed", canEdit, func(c *gin.Context) {
+		ok, err := feeds.Delete(c.Request.Context(), c.GetString(middleware.CtxOrgID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	// POST /api/vacancies/import/feed/run?dryRun=true - опросить фид сейчас
+	protected.POST("/vacancies/import/feed/run", canEdit, func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		f, err := feeds.Get(c.Request.Context(), orgID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if f == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		dryRun := c.Query("dryRun") == "true"
+		res, err := poller.Run(c.Request.Context(), f, dryRun)
+		if !dryRun {
+			lastErr := ""
+			if err != nil {
+				lastErr = err.Error()
+			}
+			_ = feeds.MarkRun(c.Request.Context(), orgID, res, lastErr, time.Now().UTC().Add(vacimport.FeedInterval))
+		}
+		if err != nil {
+			c.JSON(502, gin.H{"ok": false, "error": "feed_failed", "detail": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "result": res})
+	})
+}
+
+// readImport достает файл из multipart-поля file или из тела запроса
+func readImport(c *gin.Context) ([]byte, string, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes+1<<10)
+	var (
+		src      io.Reader
+		filename string
+		ct       = c.ContentType()
+	)
+	if strings.HasPrefix(ct, "multipart/") {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return nil, "", false
+		}
+		f, err := fh.Open()
+		if err != nil {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return nil, "", false
+		}
+		defer f.Close()
+		src, filename, ct = f, fh.Filename, fh.Header.Get("Content-Type")
+	} else {
+		src = c.Request.Body
+	}
+	format := vacimport.DetectFormat(c.Query("format"), ct, filename)
+	if format == "" {
+		c.JSON(400, gin.H{"ok": false, "error": vacimport.ErrBadFormat.Error()})
+		return nil, "", false
+	}
+	body, err := io.ReadAll(io.LimitReader(src, maxImportBytes+1))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) || len(body) > maxImportBytes {
+		c.JSON(413, gin.H{"ok": false, "error": "file_too_large"})
+		return nil, "", false
+	}
+	if err != nil {
+		c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+		return nil, "", false
+	}
+	return body, format, true
+}
@@ -0,0 +1,288 @@
+package webhooks
+
+import (
+	"strconv"
+	"strings"
+
+	"unicorn-auth/internal/http/httputil"
+	"unicorn-auth/internal/http/middleware"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/safehttp"
+	"unicorn-auth/internal/security"
+	wh "unicorn-auth/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const maxWebhooks = 10
+
+type webhookReq struct {
+	URL         *string   `json:"url,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Events      *[]string `json:"events,omitempty"`
+	Active      *bool     `json:"active,omitempty"`
+}
+
+// Register — вебхуки компании для интеграции с ATS; управляет только владелец организации
+func Register(r *gin.Engine, sec *security.Security, users *repo.UserRepo, orgs *repo.OrgRepo,
+	hooks *repo.WebhookRepo, dispatcher *wh.Dispatcher) {
+
+	api := r.Group("/api/company/webhooks")
+	api.Use(middleware.RequireAuth(sec))
+	api.Use(middleware.RequireType("company"))
+	api.Use(middleware.RequireMFAEnabled(sec, users))
+	api.Use(middleware.ResolveOrg(orgs))
+	api.Use(middleware.RequireOrgRole(models.OrgRoleOwner))
+
+	// GET /api/company/webhooks - список вебхуков и доступные события
+	api.GET("", func(c *gin.Context) {
+		items, err := hooks.ListByCompany(c.Request.Context(), c.GetString(middleware.CtxOrgID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items, "events": wh.EventTypes})
+	})
+
+	// POST /api/company/webhooks - создать; секрет подписи возвращается один раз
+	api.POST("", func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		var req webhookReq
+		if !httputil.BindJSONStrict(c, &req, 8<<10) {
+			return
+		}
+		if req.URL == nil || req.Events == nil {
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		w := &models.Webhook{CompanyID: orgID, Active: true, CreatedBy: c.GetString(middleware.CtxUserID)}
+		if code := apply(w, &req); code != "" {
+			c.JSON(400, gin.H{"ok": false, "error": code})
+			return
+		}
+		n, err := hooks.CountByCompany(c.Request.Context(), orgID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if n >= maxWebhooks {
+			c.JSON(409, gin.H{"ok": false, "error": "limit_reached"})
+			return
+		}
+		w.Secret = wh.NewSecret()
+		if err := hooks.Create(c.Request.Context(), w); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "webhook": w, "secret": w.Secret})
+	})
+
+	// PATCH /api/company/webhooks/:webhookId - адрес, события, описание, вкл/выкл
+	api.PATCH("/:webhookId", func(c *gin.Context) {
+		orgID := c.GetString(middleware.CtxOrgID)
+		var req webhookReq
+		if !httputil.BindJSONStrict(c, &req, 8<<10) {
+			return
+		}
+		w, ok := load(c, hooks)
+		if !ok {
+			return
+		}
+		if code := apply(w, &req); code != "" {
+			c.JSON(400, gin.H{"ok": false, "error": code})
+			return
+		}
+		set := bson.M{"url": w.URL, "description": w.Description, "events": w.Events, "active": w.Active}
+		if _, err := hooks.Update(c.Request.Context(), w.WebhookID, orgID, set); err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "webhook": w})
+	})
+
+	// DELETE /api/company/webhooks/:webhookId
+	api.DELETE("/:webhookId", func(c *gin.Context) {
+		ok, err := hooks.Delete(c.Request.Context(), c.Param("webhookId"), c.GetString(middleware.CtxOrgID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	// POST /api/company/webhooks/:webhookId/rotate-secret - новый секрет, старый перестает действовать сразу
+	api.POST("/:webhookId/rotate-secret", func(c *gin.Context) {
+		secret := wh.NewSecret()
+		ok, err := hooks.Update(c.Request.Context(), c.Param("webhookId"), c.GetString(middleware.CtxOrgID), bson.M{"secret": secret})
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if !ok {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "secret": secret})
+	})
+
+	// POST /api/company/webhooks/:webhookId/ping - тестовое событие
+	api.POST("/:webhookId/ping", func(c *gin.Context) {
+		w, ok := load(c, hooks)
+		if !ok {
+			return
+		}
+		if !w.Active {
+			c.JSON(409, gin.H{"ok": false, "error": "webhook_inactive"})
+			return
+		}
+		del, err := dispatcher.Ping(c.Request.Context(), w)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "deliveryId": del.DeliveryID})
+	})
+
+	// GET /api/company/webhooks/:webhookId/deliveries?status=&skip= - журнал доставок
+	api.GET("/:webhookId/deliveries", func(c *gin.Context) {
+		w, ok := load(c, hooks)
+		if !ok {
+			return
+		}
+		status := c.Query("status")
+		switch status {
+		case "", models.WebhookPending, models.WebhookDelivering, models.WebhookDelivered, models.WebhookFailed:
+		default:
+			c.JSON(400, gin.H{"ok": false, "error": "bad_request"})
+			return
+		}
+		skip, _ := strconv.ParseInt(c.Query("skip"), 10, 64)
+		if skip < 0 {
+			skip = 0
+		}
+		items, err := hooks.ListDeliveries(c.Request.Context(), w.WebhookID, w.CompanyID, status, 50, skip)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "items": items})
+	})
+
+	// GET /api/company/webhooks/:webhookId/deliveries/:deliveryId - доставка с телом события
+	api.GET("/:webhookId/deliveries/:deliveryId", func(c *gin.Context) {
+		d, err := hooks.GetDelivery(c.Request.Context(), c.Param("deliveryId"), c.Param("webhookId"), c.GetString(middleware.CtxOrgID))
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if d == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "delivery": d})
+	})
+
+	// POST /api/company/webhooks/:webhookId/deliveries/:deliveryId/replay - отправить событие еще раз
+	api.POST("/:webhookId/deliveries/:deliveryId/replay", func(c *gin.Context) {
+		w, ok := load(c, hooks)
+		if !ok {
+			return
+		}
+		if !w.Active {
+			c.JSON(409, gin.H{"ok": false, "error": "webhook_inactive"})
+			return
+		}
+		d, err := hooks.GetDelivery(c.Request.Context(), c.Param("deliveryId"), w.WebhookID, w.CompanyID)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		if d == nil {
+			c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+			return
+		}
+		if d.Status == models.WebhookPending || d.Status == models.WebhookDelivering {
+			c.JSON(409, gin.H{"ok": false, "error": "delivery_in_progress"})
+			return
+		}
+		replay, err := dispatcher.Replay(c.Request.Context(), d)
+		if err != nil {
+			c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+			return
+		}
+		c.JSON(200, gin.H{"ok": true, "deliveryId": replay.DeliveryID})
+	})
+}
+
+func load(c *gin.Context, hooks *repo.WebhookRepo) (*models.Webhook, bool) {
+	w, err := hooks.GetByID(c.Request.Context(), c.Param("webhookId"), c.GetString(middleware.CtxOrgID))
+	if err != nil {
+		c.JSON(500, gin.H{"ok": false, "error": "server_error"})
+		return nil, false
+	}
+	if w == nil {
+		c.JSON(404, gin.H{"ok": false, "error": "not_found"})
+		return nil, false
+	}
+	return w, true
+}
+
+// apply переносит поля запроса в вебхук; возвращает код ошибки или ""
+func apply(w *models.Webhook, req *webhookReq) string {
+	if req.URL != nil {
+		u, ok := safehttp.ValidURL(*req.URL)
+		if !ok {
+			return "invalid_url"
+		}
+		w.URL = u
+	}
+	if req.Description != nil {
+		d := strings.TrimSpace(*req.Description)
+		if len([]rune(d)) > 200 {
+			return "bad_request"
+		}
+		w.Description = d
+	}
+	if req.Events != nil {
+		events, ok := validEvents(*req.Events)
+		if !ok {
+			return "invalid_events"
+		}
+		w.Events = events
+	}
+	if req.Active != nil {
+		w.Active = *req.Active
+	}
+	return ""
+}
+
+func validEvents(in []string) ([]string, bool) {
+	if len(in) == 0 {
+		return nil, false
+	}
+	out := make([]string, 0, len(in))
+	seen := map[string]bool{}
+	for _, e := range in {
+		known := false
+		for _, t := range wh.EventTypes {
+			if e == t {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, false
+		}
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+	return out, true
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+)
+
+// Типы событий
+const (
+	EventApplicationNew    = "application_new"    // компании: новый отклик
+	EventApplicationStatus = "application_status" // соискателю: решение по отклику
+	EventChatMessage       = "chat_message"       // собеседнику: новое сообщение
+	EventPaymentSuccess    = "payment_success"    // плательщику: подписка оплачена
+	EventJobAlert          = "job_alert"          // соискателю: вакансии по сохраненному поиску
+	EventVacancyClosed     = "vacancy_closed"     // откликнувшимся: вакансия закрыта или истекла
+)
+
+// EventTypes — типы, для которых настраиваются каналы
+var EventTypes = []string{EventApplicationNew, EventApplicationStatus, EventChatMessage, EventPaymentSuccess, EventJobAlert, EventVacancyClosed}
+
+// Event — событие для рассылки. Получатель — UserID либо все участники OrgID.
+type Event struct {
+	Type    string
+	UserID  string
+	OrgID   string
+	ActorID string // инициатор события; ему самому уведомление не уходит
+
+	Title string
+	Text  string
+	Link  string
+	Data  map[string]string
+}
+
+// Emitter принимает события из обработчиков и доставляет их в фоне,
+// чтобы медленный канал (почта, Telegram) не задерживал ответ API.
+type Emitter struct {
+	notifications *repo.NotificationRepo
+	orgs          *repo.OrgRepo
+	channels      []Channel
+	queue         chan Event
+	log           *slog.Logger
+}
+
+func NewEmitter(notifications *repo.NotificationRepo, orgs *repo.OrgRepo, log *slog.Logger, channels ...Channel) *Emitter {
+	return &Emitter{
+		notifications: notifications,
+		orgs:          orgs,
+		channels:      channels,
+		queue:         make(chan Event, 1024),
+		log:           log.With("component", "notify"),
+	}
+}
+
+// AddChannel подключает канал доставки; вызывать до Start
+func (e *Emitter) AddChannel(ch Channel) {
+	e.channels = append(e.channels, ch)
+}
+
+// Emit ставит событие в очередь; при переполнении событие теряется с записью в лог
+func (e *Emitter) Emit(ev Event) {
+	if e == nil {
+		return
+	}
+	select {
+	case e.queue <- ev:
+	default:
+		e.log.Warn("queue is full, event dropped", "event", ev.Type)
+	}
+}
+
+// Start разбирает очередь до отмены контекста
+func (e *Emitter) Start(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-e.queue:
+			dctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			e.dispatch(dctx, ev)
+			cancel()
+		}
+	}
+}
+
+func (e *Emitter) dispatch(ctx context.Context, ev Event) {
+	recipients, err := e.recipients(ctx, ev)
+	if err != nil {
+		e.log.Error("resolve recipients", "event", ev.Type, "err", err)
+		return
+	}
+	for _, uid := range recipients {
+		prefs, err := e.notifications.GetPrefs(ctx, uid)
+		if err != nil {
+			e.log.Error("load prefs", "user_id", uid, "err", err)
+			continue
+		}
+		msg := Message{UserID: uid, Type: ev.Type, Title: ev.Title, Text: ev.Text, Link: ev.Link, Data: ev.Data}
+		if prefs != nil {
+			msg.Email = prefs.Email
+		}
+		for _, ch := range e.channels {
+			if !Enabled(prefs, ev.Type, ch.Name()) {
+				continue
+			}
+			if err := ch.Deliver(ctx, msg); err != nil {
+				e.log.Error("deliver", "event", ev.Type, "channel", ch.Name(), "user_id", uid, "err", err)
+			}
+		}
+	}
+}
+
+func (e *Emitter) recipients(ctx context.Context, ev Event) ([]string, error) {
+	var ids []string
+	if ev.UserID != "" {
+		ids = []string{ev.UserID}
+	} else if ev.OrgID != "" {
+		members, err := e.orgs.ListMembers(ctx, ev.OrgID)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			ids = append(ids, m.UserID)
+		}
+		// Компания без команды: записи участника у владельца еще нет
+		if len(ids) == 0 {
+			ids = []string{ev.OrgID}
+		}
+	}
+	out := ids[:0]
+	for _, id := range ids {
+		if id != ev.ActorID {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+// Enabled сообщает, включен ли канал для типа события; без настроек включено все
+func Enabled(prefs *models.NotificationPrefs, eventType, channel string) bool {
+	if prefs == nil {
+		return true
+	}
+	list, ok := prefs.Events[eventType]
+	if !ok {
+		return true
+	}
+	for _, ch := range list {
+		if ch == channel {
+			return true
+		}
+	}
+	return false
+}
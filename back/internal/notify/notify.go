@@ -0,0 +1,89 @@
+// Package notify доставляет уведомления пользователям по каналам (в кабинет, на почту).
+package notify
+
+import (
+	"context"
+	"strings"
+
+	"unicorn-auth/internal/mail"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+)
+
+const (
+	ChannelInApp    = "inapp"
+	ChannelEmail    = "email"
+	ChannelWebPush  = "webpush"
+	ChannelTelegram = "telegram"
+)
+
+// Channels — все известные каналы; в настройках можно выбирать только из них
+var Channels = []string{ChannelInApp, ChannelEmail, ChannelWebPush, ChannelTelegram}
+
+// Message — уведомление, не зависящее от канала
+type Message struct {
+	UserID string
+	Type   string // тип события, например job_alert
+	Title  string
+	Text   string
+	Link   string // путь на фронте, например /vacancies/01H...
+	Data   map[string]string
+
+	// Адрес для почтового канала; пусто — письмо не отправляется
+	Email string
+}
+
+// Channel — способ доставки уведомления
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, m Message) error
+}
+
+// InApp сохраняет уведомление в кабинет пользователя
+type InApp struct {
+	notifications *repo.NotificationRepo
+}
+
+func NewInApp(notifications *repo.NotificationRepo) *InApp {
+	return &InApp{notifications: notifications}
+}
+
+func (c *InApp) Name() string { return ChannelInApp }
+
+func (c *InApp) Deliver(ctx context.Context, m Message) error {
+	return c.notifications.Create(ctx, &models.Notification{
+		UserID: m.UserID,
+		Type:   m.Type,
+		Title:  m.Title,
+		Text:   m.Text,
+		Link:   m.Link,
+		Data:   m.Data,
+	})
+}
+
+// Email отправляет уведомление письмом; относительные ссылки дополняются адресом фронта
+type Email struct {
+	sender  mail.Sender
+	baseURL string
+}
+
+func NewEmail(sender mail.Sender, frontendURL string) *Email {
+	return &Email{sender: sender, baseURL: strings.TrimRight(frontendURL, "/")}
+}
+
+func (c *Email) Name() string { return ChannelEmail }
+
+func (c *Email) Deliver(ctx context.Context, m Message) error {
+	if m.Email == "" {
+		return nil
+	}
+	text := m.Text
+	if m.Link != "" {
+		link := m.Link
+		if strings.HasPrefix(link, "/") {
+			link = c.baseURL + link
+		}
+		text += "\n\n" + link
+	}
+	return c.sender.Send(ctx, mail.Message{To: m.Email, Subject: m.Title, Text: text})
+}
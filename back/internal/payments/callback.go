@@ -0,0 +1,56 @@
+package payments
+
+import "strings"
+
+// Action — что сделать с платежом по проверенному уведомлению провайдера
+type Action string
+
+const (
+	ActionActivate         Action = "activate"          // оплачен: перевести в paid
+	ActionCancel           Action = "cancel"            // отменен: снять ожидание оплаты
+	ActionIgnore           Action = "ignore"            // повтор или промежуточный статус: только подтвердить
+	ActionProviderMismatch Action = "provider_mismatch" // пришло не от провайдера платежа
+	ActionAmountMismatch   Action = "amount_mismatch"   // сумма не совпала с выставленной
+)
+
+// Decide выбирает действие по уведомлению cb, пришедшему от провайдера
+// provider, для платежа в статусе status, созданного через subProvider на
+// сумму outSum. Пустой subProvider — старые платежи Robokassa.
+func Decide(cb *Callback, provider, subProvider, status, outSum string) Action {
+	if subProvider == "" {
+		subProvider = "robokassa"
+	}
+	switch {
+	case subProvider != provider:
+		return ActionProviderMismatch
+	case status != "pending":
+		return ActionIgnore
+	case cb.Status == StatusCancelled:
+		return ActionCancel
+	case cb.Status != StatusPaid:
+		return ActionIgnore
+	case !SameAmount(cb.Amount, outSum):
+		return ActionAmountMismatch
+	}
+	return ActionActivate
+}
+
+// SameAmount сравнивает суммы в копейках: "990", "990.00" и "990.000000"
+// (так OutSum присылает Robokassa) совпадают
+func SameAmount(a, b string) bool {
+	x, err := parseCallbackAmount(a)
+	if err != nil {
+		return false
+	}
+	y, err := parseCallbackAmount(b)
+	return err == nil && x == y
+}
+
+func parseCallbackAmount(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if whole, frac, ok := strings.Cut(s, "."); ok && len(frac) > 2 {
+		s = whole + "." + strings.TrimRight(frac, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	return ParseAmount(s)
+}
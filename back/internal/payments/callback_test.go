@@ -0,0 +1,118 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"990.00", 99000, true},
+		{"990", 99000, true},
+		{" 990.5 ", 99050, true},
+		{"0.01", 1, true},
+		{"0", 0, true},
+		{"990.000", 0, false},
+		{"-1.00", 0, false},
+		{".50", 0, false},
+		{"1,50", 0, false},
+		{"abc", 0, false},
+		{"", 0, false},
+		{"99999999999999999999", 0, false},
+	}
+	for _, tc := range cases {
+		got, err := ParseAmount(tc.in)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("ParseAmount(%q) = %d, %v; want %d, ok=%v", tc.in, got, err, tc.want, tc.ok)
+		}
+	}
+	if s := FormatAmount(99050); s != "990.50" {
+		t.Errorf("FormatAmount = %s", s)
+	}
+}
+
+func TestSameAmount(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"990.000000", "990.00", true},
+		{"990", "990.00", true},
+		{"990.50", "990.500000", true},
+		{"990.01", "990.00", false},
+		{"990.001", "990.00", false},
+		{"1990.00", "990.00", false},
+		{"", "990.00", false},
+		{"990.00", "", false},
+		{"abc", "abc", false},
+	}
+	for _, tc := range cases {
+		if got := SameAmount(tc.a, tc.b); got != tc.want {
+			t.Errorf("SameAmount(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	paid := &Callback{Status: StatusPaid, Amount: "990.000000"}
+	cases := []struct {
+		name        string
+		cb          *Callback
+		provider    string
+		subProvider string
+		status      string
+		want        Action
+	}{
+		{"paid", paid, "robokassa", "robokassa", "pending", ActionActivate},
+		{"legacy payment without provider", paid, "robokassa", "", "pending", ActionActivate},
+		{"another provider", paid, "yookassa", "robokassa", "pending", ActionProviderMismatch},
+		{"legacy payment from another provider", paid, "fake", "", "pending", ActionProviderMismatch},
+		{"repeat after activation", paid, "robokassa", "robokassa", "paid", ActionIgnore},
+		{"paid after cancel", paid, "robokassa", "robokassa", "cancelled", ActionIgnore},
+		{"paid after refund", paid, "robokassa", "robokassa", "refunded", ActionIgnore},
+		{"amount mismatch", &Callback{Status: StatusPaid, Amount: "1.00"}, "robokassa", "robokassa", "pending", ActionAmountMismatch},
+		{"cancelled", &Callback{Status: StatusCancelled}, "yookassa", "yookassa", "pending", ActionCancel},
+		{"cancel after payment", &Callback{Status: StatusCancelled}, "yookassa", "yookassa", "paid", ActionIgnore},
+		{"still pending", &Callback{Status: StatusPending}, "yookassa", "yookassa", "pending", ActionIgnore},
+		{"refund notification", &Callback{Status: StatusRefunded}, "yookassa", "yookassa", "paid", ActionIgnore},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Decide(tc.cb, tc.provider, tc.subProvider, tc.status, "990.00"); got != tc.want {
+				t.Fatalf("Decide = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFakeCallback(t *testing.T) {
+	f := NewFake("http://localhost/api/subscription/fake/result", "")
+	p, err := f.CreatePayment(context.Background(), PaymentRequest{InvID: 7, UserID: "u1", Amount: "990.00"})
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+	if st, _ := f.Status(context.Background(), 7, ""); st != StatusPending {
+		t.Fatalf("status after create = %s", st)
+	}
+
+	cb, err := f.VerifyCallback(httptest.NewRequest(http.MethodGet, p.URL, nil))
+	if err != nil || cb.InvID != 7 || cb.Amount != "990.00" || cb.ExternalID != "fake-7" || cb.Ack != "OK7" {
+		t.Fatalf("VerifyCallback = %+v, %v", cb, err)
+	}
+	if st, _ := f.Status(context.Background(), 7, ""); st != StatusPaid {
+		t.Fatalf("status after callback = %s", st)
+	}
+
+	tampered := strings.Replace(p.URL, "OutSum=990.00", "OutSum=1.00", 1)
+	if _, err := f.VerifyCallback(httptest.NewRequest(http.MethodGet, tampered, nil)); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("tampered callback error = %v", err)
+	}
+}
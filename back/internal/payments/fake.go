@@ -0,0 +1,97 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// Fake — детерминированный провайдер для локальной разработки.
+// Ссылка на оплату ведет прямо на callback-эндпоинт с уже подписанными
+// параметрами, поэтому «оплата» — это просто переход по ссылке.
+type Fake struct {
+	CallbackURL string
+	Secret      string
+
+	mu       sync.Mutex
+	statuses map[int64]Status
+}
+
+func NewFake(callbackURL, secret string) *Fake {
+	if secret == "" {
+		secret = "fake-provider-secret"
+	}
+	return &Fake{CallbackURL: callbackURL, Secret: secret, statuses: map[int64]Status{}}
+}
+
+func (f *Fake) Name() string { return "fake" }
+
+func (f *Fake) sign(outSum string, invID int64, userID string) string {
+	m := hmac.New(sha256.New, []byte(f.Secret))
+	fmt.Fprintf(m, "%s:%d:%s", outSum, invID, userID)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+func (f *Fake) setStatus(invID int64, st Status) {
+	f.mu.Lock()
+	f.statuses[invID] = st
+	f.mu.Unlock()
+}
+
+func (f *Fake) CreatePayment(_ context.Context, req PaymentRequest) (*Payment, error) {
+	params := url.Values{}
+	params.Set("OutSum", req.Amount)
+	params.Set("InvId", strconv.FormatInt(req.InvID, 10))
+	params.Set("Shp_userId", req.UserID)
+	params.Set("SignatureValue", f.sign(req.Amount, req.InvID, req.UserID))
+	f.setStatus(req.InvID, StatusPending)
+	return &Payment{
+		URL:        f.CallbackURL + "?" + params.Encode(),
+		ExternalID: fmt.Sprintf("fake-%d", req.InvID),
+	}, nil
+}
+
+// VerifyCallback принимает параметры и из query, и из формы
+func (f *Fake) VerifyCallback(r *http.Request) (*Callback, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, ErrBadCallback
+	}
+	outSum := r.Form.Get("OutSum")
+	userID := r.Form.Get("Shp_userId")
+	invID, err := strconv.ParseInt(r.Form.Get("InvId"), 10, 64)
+	if err != nil {
+		return nil, ErrBadCallback
+	}
+	if !hmac.Equal([]byte(r.Form.Get("SignatureValue")), []byte(f.sign(outSum, invID, userID))) {
+		return nil, ErrInvalidSignature
+	}
+	f.setStatus(invID, StatusPaid)
+	return &Callback{
+		InvID:      invID,
+		ExternalID: fmt.Sprintf("fake-%d", invID),
+		UserID:     userID,
+		Amount:     outSum,
+		Status:     StatusPaid,
+		Ack:        fmt.Sprintf("OK%d", invID),
+	}, nil
+}
+
+func (f *Fake) Refund(_ context.Context, req RefundRequest) (*Refund, error) {
+	f.setStatus(req.InvID, StatusRefunded)
+	return &Refund{RefundID: fmt.Sprintf("fake-refund-%d-%s", req.InvID, req.Amount), Status: StatusRefunded}, nil
+}
+
+func (f *Fake) Status(_ context.Context, invID int64, _ string) (Status, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if st, ok := f.statuses[invID]; ok {
+		return st, nil
+	}
+	return StatusUnknown, nil
+}
@@ -0,0 +1,131 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"unicorn-auth/internal/models"
+)
+
+// Status — статус платежа, приведённый к общему виду для всех провайдеров
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusPaid      Status = "paid"
+	StatusCancelled Status = "cancelled"
+	StatusRefunded  Status = "refunded"
+	StatusUnknown   Status = "unknown"
+)
+
+var (
+	ErrInvalidSignature = errors.New("payments: invalid signature")
+	ErrBadCallback      = errors.New("payments: malformed callback")
+	ErrNotSupported     = errors.New("payments: operation not supported")
+	ErrUnknownProvider  = errors.New("payments: unknown provider")
+)
+
+// PaymentRequest описывает платеж, который нужно создать у провайдера
+type PaymentRequest struct {
+	InvID       int64
+	UserID      string
+	Amount      string // сумма в формате "990.00"
+	Currency    string
+	Description string
+	// Receipt — данные чека для онлайн-кассы (может быть nil)
+	Receipt *models.FiscalReceipt
+}
+
+// Payment — результат создания платежа
+type Payment struct {
+	URL        string // куда отправить пользователя для оплаты
+	ExternalID string // ID платежа на стороне провайдера (если есть)
+}
+
+// Callback — проверенное уведомление от провайдера
+type Callback struct {
+	InvID      int64
+	ExternalID string
+	UserID     string
+	Amount     string
+	Status     Status
+	// Ack — тело ответа, которое ожидает провайдер при успешной обработке
+	Ack string
+}
+
+// RefundRequest описывает полный или частичный возврат
+type RefundRequest struct {
+	InvID      int64
+	ExternalID string
+	Amount     string
+	Currency   string
+}
+
+// Refund — результат возврата
+type Refund struct {
+	RefundID string
+	Status   Status
+}
+
+// Provider — платежный провайдер (Robokassa, YooKassa, fake для разработки)
+type Provider interface {
+	Name() string
+	CreatePayment(ctx context.Context, req PaymentRequest) (*Payment, error)
+	VerifyCallback(r *http.Request) (*Callback, error)
+	Refund(ctx context.Context, req RefundRequest) (*Refund, error)
+	Status(ctx context.Context, invID int64, externalID string) (Status, error)
+}
+
+// Registry хранит подключенные провайдеры и провайдер по умолчанию
+type Registry struct {
+	providers map[string]Provider
+	def       string
+}
+
+func NewRegistry(def string, providers ...Provider) *Registry {
+	r := &Registry{providers: map[string]Provider{}, def: def}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+	return r
+}
+
+// Get возвращает провайдер по имени или nil
+func (r *Registry) Get(name string) Provider {
+	return r.providers[name]
+}
+
+// Default возвращает провайдер, через который создаются новые платежи
+func (r *Registry) Default() Provider {
+	return r.providers[r.def]
+}
+
+// ParseAmount переводит сумму "990.00" в копейки
+func ParseAmount(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" || len(frac) > 2 {
+		return 0, fmt.Errorf("payments: bad amount %q", s)
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	rub, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || rub < 0 {
+		return 0, fmt.Errorf("payments: bad amount %q", s)
+	}
+	kop, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("payments: bad amount %q", s)
+	}
+	return rub*100 + kop, nil
+}
+
+// FormatAmount переводит копейки в строку "990.00"
+func FormatAmount(kop int64) string {
+	return fmt.Sprintf("%d.%02d", kop/100, kop%100)
+}
@@ -0,0 +1,287 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	robokassaPaymentURL = "https://auth.robokassa.ru/Merchant/Index.aspx"
+	robokassaOpStateURL = "https://auth.robokassa.ru/Merchant/WebService/Service.asmx/OpStateExt"
+	robokassaRefundURL  = "https://services.robokassa.ru/RefundService/Refund/Create"
+)
+
+type RobokassaConfig struct {
+	MerchantLogin string
+	Password1     string
+	Password2     string
+	Password3     string // нужен только для API возвратов
+	TestMode      bool
+	// HashAlgo — алгоритм подписи из настроек магазина: md5/sha1/sha256/sha384/sha512
+	HashAlgo string
+}
+
+type Robokassa struct {
+	MerchantLogin string
+	Password1     string
+	Password2     string
+	Password3     string
+	TestMode      bool
+	HashAlgo      string
+
+	HTTPClient *http.Client
+}
+
+func NewRobokassa(cfg RobokassaConfig) *Robokassa {
+	algo := strings.ToLower(strings.TrimSpace(cfg.HashAlgo))
+	if algo == "" {
+		algo = "md5"
+	}
+	return &Robokassa{
+		MerchantLogin: cfg.MerchantLogin,
+		Password1:     cfg.Password1,
+		Password2:     cfg.Password2,
+		Password3:     cfg.Password3,
+		TestMode:      cfg.TestMode,
+		HashAlgo:      algo,
+		HTTPClient:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (r *Robokassa) Name() string { return "robokassa" }
+
+// sign считает подпись выбранным в магазине алгоритмом (hex, нижний регистр)
+func (r *Robokassa) sign(s string) string {
+	var h hash.Hash
+	switch r.HashAlgo {
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	case "sha384":
+		h = sha512.New384()
+	case "sha512":
+		h = sha512.New()
+	default:
+		h = md5.New()
+	}
+	h.Write([]byte(s))
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// GeneratePaymentURL создает URL для оплаты через Robokassa.
+// receipt — JSON чека (пустая строка, если фискализация не нужна).
+func (r *Robokassa) GeneratePaymentURL(outSum string, invID int64, description string, userID string, receipt string) string {
+	// Генерируем подпись: HASH(MerchantLogin:OutSum:InvId[:Receipt]:Password1:Shp_userId=value).
+	// Receipt входит в подпись в URL-кодированном виде и в таком же виде
+	// передается значением параметра (т.е. в ссылке кодируется дважды).
+	signStr := fmt.Sprintf("%s:%s:%d", r.MerchantLogin, outSum, invID)
+	encReceipt := ""
+	if receipt != "" {
+		encReceipt = url.QueryEscape(receipt)
+		signStr += ":" + encReceipt
+	}
+	signStr += fmt.Sprintf(":%s:Shp_userId=%s", r.Password1, userID)
+	signature := r.sign(signStr)
+
+	params := url.Values{}
+	params.Set("MerchantLogin", r.MerchantLogin)
+	params.Set("OutSum", outSum)
+	params.Set("InvId", strconv.FormatInt(invID, 10))
+	params.Set("Description", description)
+	params.Set("SignatureValue", signature)
+	params.Set("Shp_userId", userID)
+	if encReceipt != "" {
+		params.Set("Receipt", encReceipt)
+	}
+
+	if r.TestMode {
+		params.Set("IsTest", "1")
+	}
+
+	return robokassaPaymentURL + "?" + params.Encode()
+}
+
+// VerifyResultSignature проверяет подпись от ResultURL (когда платеж проведен)
+func (r *Robokassa) VerifyResultSignature(outSum string, invID int64, signatureValue string, userID string) bool {
+	// Подпись от Result: HASH(OutSum:InvId:Password2:Shp_userId=value)
+	signStr := fmt.Sprintf("%s:%d:%s:Shp_userId=%s",
+		outSum, invID, r.Password2, userID)
+	// Строка подписи содержит Password2 — в лог ее не пишем
+	return sameSignature(r.sign(signStr), signatureValue)
+}
+
+// VerifySuccessSignature проверяет подпись от SuccessURL (страница успеха)
+func (r *Robokassa) VerifySuccessSignature(outSum string, invID int64, signatureValue string, userID string) bool {
+	// Подпись от Success: HASH(MerchantLogin:OutSum:InvId:Password1:Shp_userId)
+	signStr := fmt.Sprintf("%s:%s:%d:%s:Shp_userId=%s",
+		r.MerchantLogin, outSum, invID, r.Password1, userID)
+	return sameSignature(r.sign(signStr), signatureValue)
+}
+
+// sameSignature сравнивает hex-подписи без учета регистра за постоянное время
+func sameSignature(expected, received string) bool {
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(received))) == 1
+}
+
+func (r *Robokassa) CreatePayment(_ context.Context, req PaymentRequest) (*Payment, error) {
+	receipt := ""
+	if req.Receipt != nil {
+		b, err := json.Marshal(req.Receipt)
+		if err != nil {
+			return nil, err
+		}
+		receipt = string(b)
+	}
+	return &Payment{URL: r.GeneratePaymentURL(req.Amount, req.InvID, req.Description, req.UserID, receipt)}, nil
+}
+
+// VerifyCallback разбирает ResultURL (form POST) и проверяет подпись Password2
+func (r *Robokassa) VerifyCallback(req *http.Request) (*Callback, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, ErrBadCallback
+	}
+	outSum := req.PostForm.Get("OutSum")
+	userID := req.PostForm.Get("Shp_userId")
+	invID, err := strconv.ParseInt(req.PostForm.Get("InvId"), 10, 64)
+	if err != nil {
+		return nil, ErrBadCallback
+	}
+	if !r.VerifyResultSignature(outSum, invID, req.PostForm.Get("SignatureValue"), userID) {
+		return nil, ErrInvalidSignature
+	}
+	return &Callback{
+		InvID:  invID,
+		UserID: userID,
+		Amount: outSum,
+		Status: StatusPaid,
+		Ack:    fmt.Sprintf("OK%d", invID),
+	}, nil
+}
+
+type robokassaOpState struct {
+	Result struct {
+		Code        int    `xml:"Code"`
+		Description string `xml:"Description"`
+	} `xml:"Result"`
+	State struct {
+		Code int `xml:"Code"`
+	} `xml:"State"`
+	Info struct {
+		OpKey string `xml:"OpKey"`
+	} `xml:"Info"`
+}
+
+// opState запрашивает состояние операции через XML-интерфейс OpStateExt
+func (r *Robokassa) opState(ctx context.Context, invID int64) (*robokassaOpState, error) {
+	params := url.Values{}
+	params.Set("MerchantLogin", r.MerchantLogin)
+	params.Set("InvoiceID", strconv.FormatInt(invID, 10))
+	params.Set("Signature", r.sign(fmt.Sprintf("%s:%d:%s", r.MerchantLogin, invID, r.Password2)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robokassaOpStateURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("robokassa: opstate http %d", resp.StatusCode)
+	}
+
+	var out robokassaOpState
+	if err := xml.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if out.Result.Code != 0 {
+		return nil, fmt.Errorf("robokassa: opstate code %d: %s", out.Result.Code, out.Result.Description)
+	}
+	return &out, nil
+}
+
+func (r *Robokassa) Status(ctx context.Context, invID int64, _ string) (Status, error) {
+	st, err := r.opState(ctx, invID)
+	if err != nil {
+		return StatusUnknown, err
+	}
+	switch st.State.Code {
+	case 100:
+		return StatusPaid, nil
+	case 5, 50, 80:
+		return StatusPending, nil
+	case 10:
+		return StatusCancelled, nil
+	case 60:
+		return StatusRefunded, nil
+	}
+	return StatusUnknown, nil
+}
+
+// Refund создает возврат через Refund API: тело — JWT, подписанный Password3
+func (r *Robokassa) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	if r.Password3 == "" {
+		return nil, ErrNotSupported
+	}
+	st, err := r.opState(ctx, req.InvID)
+	if err != nil {
+		return nil, err
+	}
+	if st.Info.OpKey == "" {
+		return nil, fmt.Errorf("robokassa: no OpKey for invId=%d", req.InvID)
+	}
+	kop, err := ParseAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"OpKey":     st.Info.OpKey,
+		"RefundSum": float64(kop) / 100,
+	}).SignedString([]byte(r.Password3))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, robokassaRefundURL, bytes.NewBufferString(tok))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := r.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Success   bool   `json:"success"`
+		Message   string `json:"message"`
+		RequestID string `json:"requestId"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if !out.Success {
+		return nil, fmt.Errorf("robokassa: refund rejected: %s", out.Message)
+	}
+	return &Refund{RefundID: out.RequestID, Status: StatusRefunded}, nil
+}
@@ -0,0 +1,139 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"unicorn-auth/internal/models"
+)
+
+func testRobokassa(algo string) *Robokassa {
+	return NewRobokassa(RobokassaConfig{MerchantLogin: "shop", Password1: "p1", Password2: "p2", HashAlgo: algo})
+}
+
+func TestRobokassaSign(t *testing.T) {
+	cases := []struct{ algo, want string }{
+		{"", "900150983cd24fb0d6963f7d28e17f72"},
+		{"md5", "900150983cd24fb0d6963f7d28e17f72"},
+		{"sha1", "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{" SHA256 ", "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"sha384", "cb00753f45a35e8bb5a03d699ac65007272c32ab0eded1631a8b605a43ff5bed8086072ba1e7cc2358baeca134c825a7"},
+		{"sha512", "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+	}
+	for _, tc := range cases {
+		if got := testRobokassa(tc.algo).sign("abc"); got != tc.want {
+			t.Errorf("sign(%q) = %s, want %s", tc.algo, got, tc.want)
+		}
+	}
+}
+
+func TestRobokassaCreatePayment(t *testing.T) {
+	receipt := &models.FiscalReceipt{Sno: "usn_income", Items: []models.ReceiptItem{{
+		Name: "Unicorn Premium", Quantity: 1, Sum: 990,
+		PaymentMethod: "full_payment", PaymentObject: "service", Tax: "none",
+	}}}
+	const encReceipt = "%7B%22sno%22%3A%22usn_income%22%2C%22items%22%3A%5B%7B%22name%22%3A%22Unicorn+Premium%22%2C%22quantity%22%3A1%2C%22sum%22%3A990%2C%22payment_method%22%3A%22full_payment%22%2C%22payment_object%22%3A%22service%22%2C%22tax%22%3A%22none%22%7D%5D%7D"
+
+	cases := []struct {
+		name    string
+		receipt *models.FiscalReceipt
+		test    bool
+		sig     string // md5(shop:990.00:42[:Receipt]:p1:Shp_userId=u1)
+	}{
+		{"without receipt", nil, false, "0173efc7dbd164a47e26144463b9b9c2"},
+		{"with receipt", receipt, true, "9b6a8cfeb5d14d059815b0dd77e6b0ff"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := testRobokassa("md5")
+			r.TestMode = tc.test
+			p, err := r.CreatePayment(context.Background(), PaymentRequest{
+				InvID: 42, UserID: "u1", Amount: "990.00", Description: "Подписка", Receipt: tc.receipt,
+			})
+			if err != nil {
+				t.Fatalf("CreatePayment: %v", err)
+			}
+			u, err := url.Parse(p.URL)
+			if err != nil || !strings.HasPrefix(p.URL, robokassaPaymentURL+"?") {
+				t.Fatalf("URL = %s", p.URL)
+			}
+			q := u.Query()
+			if q.Get("MerchantLogin") != "shop" || q.Get("OutSum") != "990.00" || q.Get("InvId") != "42" ||
+				q.Get("Shp_userId") != "u1" || q.Get("Description") != "Подписка" || q.Get("SignatureValue") != tc.sig {
+				t.Fatalf("query = %v", q)
+			}
+			// Receipt в ссылке закодирован дважды: значение параметра — уже URL-кодированный JSON
+			wantReceipt := ""
+			if tc.receipt != nil {
+				wantReceipt = encReceipt
+			}
+			if q.Get("Receipt") != wantReceipt {
+				t.Fatalf("Receipt = %q, want %q", q.Get("Receipt"), wantReceipt)
+			}
+			if (q.Get("IsTest") == "1") != tc.test {
+				t.Fatalf("IsTest = %q", q.Get("IsTest"))
+			}
+		})
+	}
+}
+
+func resultRequest(form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/subscription/robokassa/result", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestRobokassaVerifyCallback(t *testing.T) {
+	// md5(990.000000:42:p2:Shp_userId=u1)
+	const sig = "baabf821c917356a6fd458bc50f916d0"
+	form := func(outSum, invID, sig, user string) url.Values {
+		return url.Values{"OutSum": {outSum}, "InvId": {invID}, "SignatureValue": {sig}, "Shp_userId": {user}}
+	}
+	cases := []struct {
+		name string
+		algo string
+		form url.Values
+		want error
+	}{
+		{"valid", "md5", form("990.000000", "42", sig, "u1"), nil},
+		{"upper case signature", "md5", form("990.000000", "42", strings.ToUpper(sig), "u1"), nil},
+		{"sha256", "sha256", form("990.000000", "42", "656bdb9eca32347b9b605ab4e2bc8fa1a533946790f4b80c05b78cf570268ca6", "u1"), nil},
+		{"other algorithm", "sha256", form("990.000000", "42", sig, "u1"), ErrInvalidSignature},
+		{"tampered amount", "md5", form("1.000000", "42", sig, "u1"), ErrInvalidSignature},
+		{"tampered invoice", "md5", form("990.000000", "43", sig, "u1"), ErrInvalidSignature},
+		{"tampered user", "md5", form("990.000000", "42", sig, "u2"), ErrInvalidSignature},
+		{"truncated signature", "md5", form("990.000000", "42", sig[:31], "u1"), ErrInvalidSignature},
+		{"empty signature", "md5", form("990.000000", "42", "", "u1"), ErrInvalidSignature},
+		{"bad invoice", "md5", form("990.000000", "x", sig, "u1"), ErrBadCallback},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cb, err := testRobokassa(tc.algo).VerifyCallback(resultRequest(tc.form))
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("VerifyCallback error = %v, want %v", err, tc.want)
+			}
+			if err != nil {
+				return
+			}
+			if cb.InvID != 42 || cb.UserID != "u1" || cb.Amount != "990.000000" || cb.Status != StatusPaid || cb.Ack != "OK42" {
+				t.Fatalf("callback = %+v", cb)
+			}
+		})
+	}
+}
+
+func TestRobokassaVerifySuccessSignature(t *testing.T) {
+	r := testRobokassa("md5")
+	// Success подписывается Password1 с логином магазина, как ссылка на оплату без чека
+	if !r.VerifySuccessSignature("990.00", 42, "0173EFC7DBD164A47E26144463B9B9C2", "u1") {
+		t.Fatal("valid success signature rejected")
+	}
+	if r.VerifySuccessSignature("990.00", 42, "baabf821c917356a6fd458bc50f916d0", "u1") {
+		t.Fatal("result signature accepted as success signature")
+	}
+}
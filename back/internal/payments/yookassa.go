@@ -0,0 +1,199 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+const yookassaAPIURL = "https://api.yookassa.ru/v3"
+
+type YooKassaConfig struct {
+	ShopID    string
+	SecretKey string
+	ReturnURL string // куда YooKassa вернет пользователя после оплаты
+}
+
+// YooKassa — провайдер с JSON API и JSON-вебхуками.
+// Вебхуки YooKassa не подписываются, поэтому VerifyCallback перепроверяет
+// платеж запросом к API и доверяет только ответу API.
+type YooKassa struct {
+	ShopID    string
+	SecretKey string
+	ReturnURL string
+	BaseURL   string
+
+	HTTPClient *http.Client
+}
+
+func NewYooKassa(cfg YooKassaConfig) *YooKassa {
+	return &YooKassa{
+		ShopID:     cfg.ShopID,
+		SecretKey:  cfg.SecretKey,
+		ReturnURL:  cfg.ReturnURL,
+		BaseURL:    yookassaAPIURL,
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (y *YooKassa) Name() string { return "yookassa" }
+
+type yookassaAmount struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+type yookassaPayment struct {
+	ID           string            `json:"id"`
+	Status       string            `json:"status"`
+	Paid         bool              `json:"paid"`
+	Amount       yookassaAmount    `json:"amount"`
+	Metadata     map[string]string `json:"metadata"`
+	Confirmation struct {
+		Type            string `json:"type"`
+		ConfirmationURL string `json:"confirmation_url"`
+	} `json:"confirmation"`
+}
+
+// do выполняет запрос к API с Basic-авторизацией и ключом идемпотентности
+func (y *YooKassa) do(ctx context.Context, method, path string, body any, out any) error {
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, y.BaseURL+path, rd)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(y.ShopID, y.SecretKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotence-Key", ulid.Make().String())
+	}
+	resp, err := y.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("yookassa: http %d: %s %s", resp.StatusCode, e.Code, e.Description)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (y *YooKassa) CreatePayment(ctx context.Context, req PaymentRequest) (*Payment, error) {
+	body := map[string]any{
+		"amount":  yookassaAmount{Value: req.Amount, Currency: req.Currency},
+		"capture": true,
+		"confirmation": map[string]string{
+			"type":       "redirect",
+			"return_url": y.ReturnURL,
+		},
+		"description": req.Description,
+		"metadata": map[string]string{
+			"invId":  strconv.FormatInt(req.InvID, 10),
+			"userId": req.UserID,
+		},
+	}
+	var p yookassaPayment
+	if err := y.do(ctx, http.MethodPost, "/payments", body, &p); err != nil {
+		return nil, err
+	}
+	return &Payment{URL: p.Confirmation.ConfirmationURL, ExternalID: p.ID}, nil
+}
+
+// VerifyCallback принимает уведомление payment.* и сверяет его с API
+func (y *YooKassa) VerifyCallback(r *http.Request) (*Callback, error) {
+	var n struct {
+		Type   string          `json:"type"`
+		Event  string          `json:"event"`
+		Object yookassaPayment `json:"object"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&n); err != nil {
+		return nil, ErrBadCallback
+	}
+	if n.Type != "notification" || n.Object.ID == "" {
+		return nil, ErrBadCallback
+	}
+
+	// Данным из тела не доверяем — берем платеж из API
+	var p yookassaPayment
+	if err := y.do(r.Context(), http.MethodGet, "/payments/"+n.Object.ID, nil, &p); err != nil {
+		return nil, ErrInvalidSignature
+	}
+	invID, err := strconv.ParseInt(p.Metadata["invId"], 10, 64)
+	if err != nil {
+		return nil, ErrBadCallback
+	}
+	return &Callback{
+		InvID:      invID,
+		ExternalID: p.ID,
+		UserID:     p.Metadata["userId"],
+		Amount:     p.Amount.Value,
+		Status:     yookassaStatus(p.Status),
+	}, nil
+}
+
+func (y *YooKassa) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	if req.ExternalID == "" {
+		return nil, fmt.Errorf("yookassa: payment id is empty for invId=%d", req.InvID)
+	}
+	body := map[string]any{
+		"payment_id": req.ExternalID,
+		"amount":     yookassaAmount{Value: req.Amount, Currency: req.Currency},
+	}
+	var out struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	if err := y.do(ctx, http.MethodPost, "/refunds", body, &out); err != nil {
+		return nil, err
+	}
+	st := StatusPending
+	switch out.Status {
+	case "succeeded":
+		st = StatusRefunded
+	case "canceled":
+		st = StatusCancelled
+	}
+	return &Refund{RefundID: out.ID, Status: st}, nil
+}
+
+func (y *YooKassa) Status(ctx context.Context, _ int64, externalID string) (Status, error) {
+	if externalID == "" {
+		return StatusUnknown, nil
+	}
+	var p yookassaPayment
+	if err := y.do(ctx, http.MethodGet, "/payments/"+externalID, nil, &p); err != nil {
+		return StatusUnknown, err
+	}
+	return yookassaStatus(p.Status), nil
+}
+
+func yookassaStatus(s string) Status {
+	switch s {
+	case "pending", "waiting_for_capture":
+		return StatusPending
+	case "succeeded":
+		return StatusPaid
+	case "canceled":
+		return StatusCancelled
+	}
+	return StatusUnknown
+}
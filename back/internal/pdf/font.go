@@ -0,0 +1,176 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// ttfFont — TrueType-шрифт, встраиваемый в PDF как CIDFontType2 (Identity-H).
+// Go fonts покрывают WGL4, т.е. латиницу и кириллицу.
+type ttfFont struct {
+	name string
+	data []byte
+	f    *sfnt.Font
+	upem float64
+
+	mu     sync.Mutex
+	buf    sfnt.Buffer
+	glyphs map[rune]sfnt.GlyphIndex
+	widths map[sfnt.GlyphIndex]int // ширина в 1/1000 em
+}
+
+var (
+	fontsOnce sync.Once
+	fontsErr  error
+	regular   *ttfFont
+	bold      *ttfFont
+)
+
+func loadFonts() error {
+	fontsOnce.Do(func() {
+		if regular, fontsErr = parseFont("GoRegular", goregular.TTF); fontsErr != nil {
+			return
+		}
+		bold, fontsErr = parseFont("GoBold", gobold.TTF)
+	})
+	return fontsErr
+}
+
+func parseFont(name string, data []byte) (*ttfFont, error) {
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("pdf: parse font %s: %w", name, err)
+	}
+	return &ttfFont{
+		name:   name,
+		data:   data,
+		f:      f,
+		upem:   float64(f.UnitsPerEm()),
+		glyphs: map[rune]sfnt.GlyphIndex{},
+		widths: map[sfnt.GlyphIndex]int{},
+	}, nil
+}
+
+// glyph возвращает индекс глифа и его ширину; неизвестные символы — глиф 0
+func (t *ttfFont) glyph(r rune) (sfnt.GlyphIndex, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	gi, ok := t.glyphs[r]
+	if !ok {
+		gi, _ = t.f.GlyphIndex(&t.buf, r)
+		t.glyphs[r] = gi
+	}
+	w, ok := t.widths[gi]
+	if !ok {
+		adv, err := t.f.GlyphAdvance(&t.buf, gi, fixed.I(int(t.f.UnitsPerEm())), font.HintingNone)
+		if err == nil {
+			w = int(float64(adv) / 64 * 1000 / t.upem)
+		}
+		t.widths[gi] = w
+	}
+	return gi, w
+}
+
+// width — ширина строки в пунктах для заданного кегля
+func (t *ttfFont) width(s string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		_, w := t.glyph(r)
+		total += w
+	}
+	return float64(total) * size / 1000
+}
+
+// encode кодирует строку в hex-последовательность GID для оператора Tj
+func (t *ttfFont) encode(s string, used map[sfnt.GlyphIndex]rune) string {
+	var sb strings.Builder
+	sb.WriteByte('<')
+	for _, r := range s {
+		gi, _ := t.glyph(r)
+		if _, ok := used[gi]; !ok {
+			used[gi] = r
+		}
+		fmt.Fprintf(&sb, "%04X", uint16(gi))
+	}
+	sb.WriteByte('>')
+	return sb.String()
+}
+
+// metrics — параметры FontDescriptor в единицах 1/1000 em
+func (t *ttfFont) metrics() (ascent, descent, capHeight int, bbox [4]int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m, err := t.f.Metrics(&t.buf, fixed.I(int(t.f.UnitsPerEm())), font.HintingNone)
+	if err == nil {
+		ascent = int(float64(m.Ascent) / 64 * 1000 / t.upem)
+		descent = -int(float64(m.Descent) / 64 * 1000 / t.upem)
+		capHeight = int(float64(m.CapHeight) / 64 * 1000 / t.upem)
+	}
+	b, err := t.f.Bounds(&t.buf, fixed.I(int(t.f.UnitsPerEm())), font.HintingNone)
+	if err == nil {
+		bbox = [4]int{
+			int(float64(b.Min.X) / 64 * 1000 / t.upem),
+			-int(float64(b.Max.Y) / 64 * 1000 / t.upem),
+			int(float64(b.Max.X) / 64 * 1000 / t.upem),
+			-int(float64(b.Min.Y) / 64 * 1000 / t.upem),
+		}
+	}
+	return
+}
+
+// widthsArray формирует массив /W только для использованных глифов
+func (t *ttfFont) widthsArray(used map[sfnt.GlyphIndex]rune) string {
+	gids := make([]int, 0, len(used))
+	for gi := range used {
+		gids = append(gids, int(gi))
+	}
+	sort.Ints(gids)
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for _, gi := range gids {
+		_, w := t.glyph(used[sfnt.GlyphIndex(gi)])
+		fmt.Fprintf(&sb, "%d [%d] ", gi, w)
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// toUnicode строит CMap, чтобы текст из PDF можно было копировать и искать
+func toUnicode(used map[sfnt.GlyphIndex]rune) string {
+	gids := make([]int, 0, len(used))
+	for gi := range used {
+		gids = append(gids, int(gi))
+	}
+	sort.Ints(gids)
+
+	var sb strings.Builder
+	sb.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	sb.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	sb.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	sb.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for i := 0; i < len(gids); i += 100 {
+		end := i + 100
+		if end > len(gids) {
+			end = len(gids)
+		}
+		fmt.Fprintf(&sb, "%d beginbfchar\n", end-i)
+		for _, gi := range gids[i:end] {
+			r := used[sfnt.GlyphIndex(gi)]
+			if r > 0xFFFF {
+				r = 0xFFFD
+			}
+			fmt.Fprintf(&sb, "<%04X> <%04X>\n", gi, r)
+		}
+		sb.WriteString("endbfchar\n")
+	}
+	sb.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return sb.String()
+}
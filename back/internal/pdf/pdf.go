@@ -0,0 +1,217 @@
+// Package pdf — минимальный генератор PDF без внешних сервисов:
+// страницы A4, текст встроенными Go fonts (с кириллицей), линии,
+// прямоугольники и JPEG-изображения. Координаты — в пунктах от
+// левого верхнего угла страницы.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"strings"
+
+	_ "image/png"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+const (
+	PageWidth  = 595.28 // A4
+	PageHeight = 841.89
+)
+
+type Document struct {
+	pages  []*Page
+	images [][]byte // JPEG
+	used   map[*ttfFont]map[sfnt.GlyphIndex]rune
+	title  string
+}
+
+type Page struct {
+	doc    *Document
+	buf    bytes.Buffer
+	images map[int]bool
+}
+
+// New создает пустой документ
+func New(title string) (*Document, error) {
+	if err := loadFonts(); err != nil {
+		return nil, err
+	}
+	return &Document{
+		title: title,
+		used: map[*ttfFont]map[sfnt.GlyphIndex]rune{
+			regular: {},
+			bold:    {},
+		},
+	}, nil
+}
+
+func (d *Document) AddPage() *Page {
+	p := &Page{doc: d, images: map[int]bool{}}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// PageCount — число страниц
+func (d *Document) PageCount() int { return len(d.pages) }
+
+// Page возвращает страницу по номеру с нуля, например чтобы дописать колонтитулы
+func (d *Document) Page(i int) *Page { return d.pages[i] }
+
+func pick(isBold bool) *ttfFont {
+	if isBold {
+		return bold
+	}
+	return regular
+}
+
+// TextWidth возвращает ширину строки в пунктах
+func (d *Document) TextWidth(s string, size float64, isBold bool) float64 {
+	return pick(isBold).width(s, size)
+}
+
+// Wrap разбивает текст на строки не шире width (по словам, с учетом \n)
+func (d *Document) Wrap(s string, size float64, isBold bool, width float64) []string {
+	f := pick(isBold)
+	var out []string
+	for _, para := range strings.Split(s, "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			out = append(out, "")
+			continue
+		}
+		line := ""
+		for _, w := range words {
+			cand := w
+			if line != "" {
+				cand = line + " " + w
+			}
+			if line != "" && f.width(cand, size) > width {
+				out = append(out, line)
+				line = w
+				continue
+			}
+			line = cand
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// Text выводит строку; y — базовая линия от верха страницы
+func (p *Page) Text(x, y, size float64, isBold bool, s string) {
+	f := pick(isBold)
+	fontRes := "F1"
+	if isBold {
+		fontRes = "F2"
+	}
+	fmt.Fprintf(&p.buf, "BT /%s %.2f Tf %.2f %.2f Td %s Tj ET\n",
+		fontRes, size, x, PageHeight-y, f.encode(s, p.doc.used[f]))
+}
+
+// TextRight выводит строку, выровненную по правому краю в точке x
+func (p *Page) TextRight(x, y, size float64, isBold bool, s string) {
+	p.Text(x-p.doc.TextWidth(s, size, isBold), y, size, isBold, s)
+}
+
+// Paragraph выводит текст с переносом и возвращает y под последней строкой
+func (p *Page) Paragraph(x, y, width, size float64, isBold bool, s string) float64 {
+	lh := size * 1.35
+	for _, line := range p.doc.Wrap(s, size, isBold, width) {
+		p.Text(x, y, size, isBold, line)
+		y += lh
+	}
+	return y
+}
+
+// Line рисует отрезок толщиной w
+func (p *Page) Line(x1, y1, x2, y2, w float64) {
+	fmt.Fprintf(&p.buf, "%.2f w %.2f %.2f m %.2f %.2f l S\n", w, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Rect рисует прямоугольник; fill задает заливку оттенком серого (0..1), <0 — без заливки
+func (p *Page) Rect(x, y, w, h, fill float64) {
+	if fill >= 0 {
+		fmt.Fprintf(&p.buf, "q %.3f g %.2f %.2f %.2f %.2f re f Q\n", fill, x, PageHeight-y-h, w, h)
+		return
+	}
+	fmt.Fprintf(&p.buf, "0.5 w %.2f %.2f %.2f %.2f re S\n", x, PageHeight-y-h, w, h)
+}
+
+// FillRGB рисует залитый прямоугольник цветом #RRGGBB
+func (p *Page) FillRGB(x, y, w, h float64, hex string) {
+	var r, g, b int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(hex, "#"), "%02x%02x%02x", &r, &g, &b); err != nil {
+		return
+	}
+	fmt.Fprintf(&p.buf, "q %.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f Q\n",
+		float64(r)/255, float64(g)/255, float64(b)/255, x, PageHeight-y-h, w, h)
+}
+
+// Image вставляет JPEG или PNG (PNG перекодируется в JPEG)
+func (p *Page) Image(data []byte, x, y, w, h float64) error {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if format != "jpeg" {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+	idx := len(p.doc.images)
+	p.doc.images = append(p.doc.images, data)
+	p.images[idx] = true
+	fmt.Fprintf(&p.buf, "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", w, h, x, PageHeight-y-h, idx)
+	return nil
+}
+
+// Bytes собирает документ
+func (d *Document) Bytes() ([]byte, error) {
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// 1 — каталог, 2 — дерево страниц; остальные объекты нумеруются по порядку
+	catalogID, pagesID := w.reserve(), w.reserve()
+
+	fontIDs := map[*ttfFont]int{}
+	for _, f := range []*ttfFont{regular, bold} {
+		id, err := w.font(f, d.used[f])
+		if err != nil {
+			return nil, err
+		}
+		fontIDs[f] = id
+	}
+
+	imageIDs := make([]int, len(d.images))
+	for i, data := range d.images {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		imageIDs[i] = w.stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode",
+			cfg.Width, cfg.Height, colorSpace(cfg.ColorModel)), data, false)
+	}
+
+	kids := make([]string, 0, len(d.pages))
+	for _, p := range d.pages {
+		contentID := w.stream("", p.buf.Bytes(), true)
+		var xobj strings.Builder
+		for idx := range p.images {
+			fmt.Fprintf(&xobj, "/Im%d %d 0 R ", idx, imageIDs[idx])
+		}
+		pageID := w.object(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> /XObject << %s>> >> /Contents %d 0 R >>",
+			pagesID, PageWidth, PageHeight, fontIDs[regular], fontIDs[bold], xobj.String(), contentID))
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+	}
+
+	w.set(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	w.set(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	infoID := w.object(fmt.Sprintf("<< /Title %s /Producer (unicorn) >>", utf16String(d.title)))
+
+	return w.finish(catalogID, infoID), nil
+}
@@ -0,0 +1,104 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"unicode/utf16"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// writer копит объекты PDF и в конце пишет их вместе с таблицей xref
+type writer struct {
+	buf  bytes.Buffer
+	objs [][]byte
+}
+
+func (w *writer) reserve() int {
+	w.objs = append(w.objs, nil)
+	return len(w.objs)
+}
+
+func (w *writer) set(id int, body string) {
+	w.objs[id-1] = []byte(body)
+}
+
+func (w *writer) object(body string) int {
+	id := w.reserve()
+	w.set(id, body)
+	return id
+}
+
+// stream добавляет поток; deflate=true сжимает его
+func (w *writer) stream(dict string, data []byte, deflate bool) int {
+	if deflate {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		_, _ = zw.Write(data)
+		_ = zw.Close()
+		data = z.Bytes()
+		dict += " /Filter /FlateDecode"
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<< %s /Length %d >>\nstream\n", dict, len(data))
+	b.Write(data)
+	b.WriteString("\nendstream")
+	id := w.reserve()
+	w.objs[id-1] = b.Bytes()
+	return id
+}
+
+// font встраивает TrueType целиком: Type0 -> CIDFontType2 + FontFile2 + ToUnicode
+func (w *writer) font(f *ttfFont, used map[sfnt.GlyphIndex]rune) (int, error) {
+	fileID := w.stream(fmt.Sprintf("/Length1 %d", len(f.data)), f.data, true)
+	ascent, descent, capHeight, bbox := f.metrics()
+	descID := w.object(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		f.name, bbox[0], bbox[1], bbox[2], bbox[3], ascent, descent, capHeight, fileID))
+	cidID := w.object(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /DW 500 /W %s >>",
+		f.name, descID, f.widthsArray(used)))
+	cmapID := w.stream("", []byte(toUnicode(used)), true)
+	return w.object(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		f.name, cidID, cmapID)), nil
+}
+
+func (w *writer) finish(rootID, infoID int) []byte {
+	offsets := make([]int, len(w.objs))
+	for i, body := range w.objs {
+		offsets[i] = w.buf.Len()
+		fmt.Fprintf(&w.buf, "%d 0 obj\n", i+1)
+		w.buf.Write(body)
+		w.buf.WriteString("\nendobj\n")
+	}
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.objs)+1, rootID, infoID, xref)
+	return w.buf.Bytes()
+}
+
+// utf16String кодирует строку метаданных как UTF-16BE с BOM
+func utf16String(s string) string {
+	var b bytes.Buffer
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// colorSpace подбирает цветовое пространство для JPEG
+func colorSpace(m color.Model) string {
+	switch m {
+	case color.GrayModel:
+		return "/DeviceGray"
+	case color.CMYKModel:
+		return "/DeviceCMYK"
+	}
+	return "/DeviceRGB"
+}
@@ -0,0 +1,322 @@
+// Package platformstats ведет статистику платформы для админки: регистрации,
+// активность, вакансии и отклики, выручку, отток и модерацию. Срезы по дням,
+// неделям и месяцам пересчитываются в фоне и хранятся в platform_stats.
+package platformstats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/telemetry"
+)
+
+const (
+	migrationName = "platform_stats_v1"
+
+	// Подписка, не продленная за graceDays после окончания, считается оттоком
+	graceDays = 3
+	// recentDays — сколько последних дней пересчитывается на каждом проходе;
+	// с запасом на graceDays
+	recentDays = 7
+	mauDays    = 30
+)
+
+// Periods — допустимые периоды срезов
+var Periods = []string{models.PeriodDay, models.PeriodWeek, models.PeriodMonth}
+
+type Service struct {
+	stats *repo.PlatformStatsRepo
+	subs  *repo.SubscriptionRepo
+	log   *slog.Logger
+}
+
+func NewService(stats *repo.PlatformStatsRepo, subs *repo.SubscriptionRepo, log *slog.Logger) *Service {
+	return &Service{stats: stats, subs: subs, log: log.With("component", "platformstats")}
+}
+
+// BucketStart — начало среза period, в который попадает t (UTC)
+func BucketStart(period string, t time.Time) time.Time {
+	t = t.UTC().Truncate(24 * time.Hour)
+	switch period {
+	case models.PeriodWeek:
+		return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	case models.PeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
+
+// NextBucket — начало следующего среза
+func NextBucket(period string, start time.Time) time.Time {
+	switch period {
+	case models.PeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case models.PeriodMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// BucketKey — ключ среза, как его считает MongoDB в repo
+func BucketKey(period string, t time.Time) string {
+	t = t.UTC()
+	switch period {
+	case models.PeriodWeek:
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", y, w)
+	case models.PeriodMonth:
+		return t.Format("2006-01")
+	}
+	return t.Format(repo.DayLayout)
+}
+
+// RunOnce пересчитывает последние срезы
+func (s *Service) RunOnce(ctx context.Context) error {
+	job := telemetry.StartJob("platformstats")
+	defer job.Done()
+	if _, err := s.Rollup(ctx, time.Now().UTC().AddDate(0, 0, -recentDays)); err != nil {
+		job.Fail(err)
+		s.log.Error("rollup", "err", err)
+	}
+	return job.Err()
+}
+
+// Migrate один раз заполняет активность по живым сессиям и строит срезы за всю историю
+func (s *Service) Migrate(ctx context.Context, d *db.Database) error {
+	done, err := d.MigrationDone(ctx, migrationName)
+	if err != nil || done {
+		return err
+	}
+	seeded, err := s.stats.SeedActivity(ctx)
+	if err != nil {
+		return err
+	}
+	first, err := s.stats.Earliest(ctx)
+	if err != nil {
+		return err
+	}
+	if !first.IsZero() {
+		n, err := s.Rollup(ctx, first)
+		if err != nil {
+			return err
+		}
+		s.log.Info("platform stats seeded", "activity_days", seeded, "buckets", n)
+	}
+	return d.MarkMigration(ctx, migrationName)
+}
+
+// Rollup пересчитывает срезы всех периодов, начиная с тех, в которые попадает since.
+// Возвращает число записанных срезов.
+func (s *Service) Rollup(ctx context.Context, since time.Time) (int, error) {
+	now := time.Now().UTC()
+	total := 0
+	for _, period := range Periods {
+		from := BucketStart(period, since)
+		items, err := s.collect(ctx, period, from, now)
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", period, err)
+		}
+		if err := s.stats.Save(ctx, items); err != nil {
+			return total, err
+		}
+		total += len(items)
+	}
+	return total, nil
+}
+
+// collect строит срезы period с from по текущий; срезы без событий — нулевые,
+// чтобы пересчет затирал устаревшие значения
+func (s *Service) collect(ctx context.Context, period string, from, now time.Time) ([]models.PlatformStat, error) {
+	var items []models.PlatformStat
+	index := map[string]int{}
+	for b := from; !b.After(now); b = NextBucket(period, b) {
+		index[BucketKey(period, b)] = len(items)
+		items = append(items, models.PlatformStat{Period: period, Bucket: BucketKey(period, b)})
+	}
+
+	rows, err := s.stats.Buckets(ctx, period, from)
+	if err != nil {
+		return nil, err
+	}
+	subRows, err := s.subs.Buckets(ctx, period, from)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range append(rows, subRows...) {
+		if i, ok := index[row.Bucket]; ok {
+			items[i].Add(row.PlatformCounters)
+		}
+	}
+
+	paid, err := s.subs.PaidPeriods(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	churns := churnTimes(paid, now)
+	i := 0
+	for b := from; !b.After(now); b = NextBucket(period, b) {
+		next := NextBucket(period, b)
+		it := &items[i]
+		it.SubscribersStart = activeAt(paid, b)
+		for _, t := range churns {
+			if !t.Before(b) && t.Before(next) {
+				it.Churned++
+			}
+		}
+		if period == models.PeriodDay {
+			// MAU на последний день среза
+			if it.MAU, err = s.stats.ActiveUsers(ctx, next.AddDate(0, 0, -mauDays), next); err != nil {
+				return nil, err
+			}
+		}
+		it.Revenue, it.Refunded = money(it.Revenue), money(it.Refunded)
+		i++
+	}
+	return items, nil
+}
+
+// activeAt — число пользователей с подпиской, действующей в момент t
+func activeAt(paid []models.Subscription, t time.Time) int64 {
+	users := map[string]bool{}
+	for _, p := range paid {
+		if !p.StartDate.After(t) && p.EndDate.After(t) {
+			users[p.UserID] = true
+		}
+	}
+	return int64(len(users))
+}
+
+// churnTimes — моменты ухода: подписка закончилась, и за graceDays не началась
+// новая. Пока grace не истек, уход не засчитывается
+func churnTimes(paid []models.Subscription, now time.Time) []time.Time {
+	byUser := map[string][]models.Subscription{}
+	for _, p := range paid {
+		byUser[p.UserID] = append(byUser[p.UserID], p)
+	}
+	grace := graceDays * 24 * time.Hour
+	var out []time.Time
+	for _, subs := range byUser {
+		for _, p := range subs {
+			deadline := p.EndDate.Add(grace)
+			if deadline.After(now) {
+				continue
+			}
+			renewed := false
+			for _, q := range subs {
+				if q.StartDate.After(p.StartDate) && !q.StartDate.After(deadline) {
+					renewed = true
+					break
+				}
+			}
+			if !renewed {
+				out = append(out, p.EndDate)
+			}
+		}
+	}
+	return out
+}
+
+// Point — срез с производными показателями
+type Point struct {
+	models.PlatformStat
+
+	NetRevenue float64 `json:"netRevenue"`
+	// Доля отмененных среди завершенных платежей
+	PaymentFailureRate float64 `json:"paymentFailureRate"`
+	ChurnRate          float64 `json:"churnRate"`
+	// DAU/MAU, только у дневных срезов
+	Stickiness float64 `json:"stickiness,omitempty"`
+}
+
+func newPoint(st models.PlatformStat) Point {
+	p := Point{
+		PlatformStat:       st,
+		NetRevenue:         money(st.Revenue - st.Refunded),
+		PaymentFailureRate: ratio(st.PaymentsFailed, st.PaymentsSucceeded+st.PaymentsFailed),
+		ChurnRate:          ratio(st.Churned, st.SubscribersStart),
+	}
+	if st.Period == models.PeriodDay {
+		p.Stickiness = ratio(st.ActiveUsers, st.MAU)
+	}
+	return p
+}
+
+// Totals — суммы за весь ряд; активность и подписчики на начало не складываются
+type Totals struct {
+	UsersRegistered        int64   `json:"usersRegistered"`
+	CompaniesRegistered    int64   `json:"companiesRegistered"`
+	VacanciesCreated       int64   `json:"vacanciesCreated"`
+	Applications           int64   `json:"applications"`
+	Payments               int64   `json:"payments"`
+	PaymentsSucceeded      int64   `json:"paymentsSucceeded"`
+	PaymentsFailed         int64   `json:"paymentsFailed"`
+	PaymentFailureRate     float64 `json:"paymentFailureRate"`
+	PaidSubscriptions      int64   `json:"paidSubscriptions"`
+	Revenue                float64 `json:"revenue"`
+	Refunded               float64 `json:"refunded"`
+	NetRevenue             float64 `json:"netRevenue"`
+	TrialsStarted          int64   `json:"trialsStarted"`
+	Churned                int64   `json:"churned"`
+	VerificationsSubmitted int64   `json:"verificationsSubmitted"`
+	VerificationsApproved  int64   `json:"verificationsApproved"`
+	VerificationsRejected  int64   `json:"verificationsRejected"`
+}
+
+// Series — срезы period, покрывающие дни [from, to]; недостающие — нулевые
+func (s *Service) Series(ctx context.Context, period string, from, to time.Time) ([]Point, Totals, error) {
+	start := BucketStart(period, from)
+	stored, err := s.stats.List(ctx, period, BucketKey(period, start), BucketKey(period, to))
+	if err != nil {
+		return nil, Totals{}, err
+	}
+	byKey := make(map[string]models.PlatformStat, len(stored))
+	for _, st := range stored {
+		byKey[st.Bucket] = st
+	}
+	var sum models.PlatformCounters
+	out := []Point{}
+	for b := start; !b.After(to); b = NextBucket(period, b) {
+		key := BucketKey(period, b)
+		st, ok := byKey[key]
+		if !ok {
+			st = models.PlatformStat{Period: period, Bucket: key}
+		}
+		sum.Add(st.PlatformCounters)
+		out = append(out, newPoint(st))
+	}
+	return out, Totals{
+		UsersRegistered:        sum.UsersRegistered,
+		CompaniesRegistered:    sum.CompaniesRegistered,
+		VacanciesCreated:       sum.VacanciesCreated,
+		Applications:           sum.Applications,
+		Payments:               sum.Payments,
+		PaymentsSucceeded:      sum.PaymentsSucceeded,
+		PaymentsFailed:         sum.PaymentsFailed,
+		PaymentFailureRate:     ratio(sum.PaymentsFailed, sum.PaymentsSucceeded+sum.PaymentsFailed),
+		PaidSubscriptions:      sum.PaidSubscriptions,
+		Revenue:                money(sum.Revenue),
+		Refunded:               money(sum.Refunded),
+		NetRevenue:             money(sum.Revenue - sum.Refunded),
+		TrialsStarted:          sum.TrialsStarted,
+		Churned:                sum.Churned,
+		VerificationsSubmitted: sum.VerificationsSubmitted,
+		VerificationsApproved:  sum.VerificationsApproved,
+		VerificationsRejected:  sum.VerificationsRejected,
+	}, nil
+}
+
+func ratio(a, b int64) float64 {
+	if b <= 0 {
+		return 0
+	}
+	return math.Round(float64(a)/float64(b)*10000) / 10000
+}
+
+// money округляет рубли до копеек
+func money(f float64) float64 { return math.Round(f*100) / 100 }
@@ -0,0 +1,202 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DayLayout — формат дня в статистике (UTC)
+const DayLayout = "2006-01-02"
+
+type AnalyticsRepo struct{ d *db.Database }
+
+func NewAnalyticsRepo(d *db.Database) *AnalyticsRepo { return &AnalyticsRepo{d: d} }
+
+// RecordView сохраняет просмотр; false — посетитель уже учтен за этот день
+func (r *AnalyticsRepo) RecordView(ctx context.Context, v *models.VacancyView) (bool, error) {
+	if _, err := r.d.VacancyViews().InsertOne(ctx, v); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// statRow — строка дневной агрегации: вакансия, день и часть счетчиков
+type statRow struct {
+	ID struct {
+		VacancyID string `bson:"v"`
+		Day       string `bson:"d"`
+	} `bson:"_id"`
+	CompanyID           string `bson:"c"`
+	models.VacancyStats `bson:",inline"`
+}
+
+// dayOf — день даты field в формате DayLayout
+func dayOf(field string) bson.M {
+	return bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$" + field}}
+}
+
+// since — интервал от отклика до даты field, мс
+func since(field string) bson.M {
+	return bson.M{"$subtract": bson.A{"$" + field, "$createdAt"}}
+}
+
+func countIf(cond bson.M) bson.M {
+	return bson.M{"$sum": bson.M{"$cond": bson.A{cond, 1, 0}}}
+}
+
+// dailyRows группирует документы coll по вакансии и дню
+func (r *AnalyticsRepo) dailyRows(ctx context.Context, coll *mongo.Collection, match bson.M, day any, sums bson.M) ([]statRow, error) {
+	group := bson.M{"_id": bson.M{"v": "$vacancyId", "d": day}, "c": bson.M{"$first": "$companyId"}}
+	for k, v := range sums {
+		group[k] = v
+	}
+	cur, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: group}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []statRow
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Rollup пересчитывает дневную статистику начиная с дня from и удаляет
+// срезы, для которых событий больше нет. Возвращает число записанных срезов.
+func (r *AnalyticsRepo) Rollup(ctx context.Context, from time.Time) (int, error) {
+	fromDay := from.UTC().Format(DayLayout)
+	fromT, _ := time.Parse(DayLayout, fromDay)
+	apps := r.d.Applications()
+	sources := []struct {
+		coll  *mongo.Collection
+		match bson.M
+		day   any
+		sums  bson.M
+	}{
+		{r.d.VacancyViews(), bson.M{"day": bson.M{"$gte": fromDay}}, "$day", bson.M{
+			"views": bson.M{"$sum": 1},
+		}},
+		{apps, bson.M{"createdAt": bson.M{"$gte": fromT}}, dayOf("createdAt"), bson.M{
+			"applications": bson.M{"$sum": 1},
+			"knockedOut":   countIf(bson.M{"$eq": bson.A{"$knockedOut", true}}),
+		}},
+		{apps, bson.M{"viewedAt": bson.M{"$gte": fromT}}, dayOf("viewedAt"), bson.M{
+			"viewed": bson.M{"$sum": 1},
+		}},
+		// Отсеченные анкетой отклонены автоматически — это не ответ компании
+		{apps, bson.M{"decidedAt": bson.M{"$gte": fromT}, "knockedOut": bson.M{"$ne": true}}, dayOf("decidedAt"), bson.M{
+			"responded":     bson.M{"$sum": 1},
+			"rejected":      countIf(bson.M{"$eq": bson.A{"$status", "rejected"}}),
+			"responseSumMs": bson.M{"$sum": since("decidedAt")},
+		}},
+		{apps, bson.M{"acceptedAt": bson.M{"$gte": fromT}}, dayOf("acceptedAt"), bson.M{
+			"accepted":  bson.M{"$sum": 1},
+			"hireSumMs": bson.M{"$sum": since("acceptedAt")},
+		}},
+	}
+
+	days := map[[2]string]*models.VacancyStatDay{}
+	for _, src := range sources {
+		rows, err := r.dailyRows(ctx, src.coll, src.match, src.day, src.sums)
+		if err != nil {
+			return 0, err
+		}
+		for _, row := range rows {
+			key := [2]string{row.ID.VacancyID, row.ID.Day}
+			sd, ok := days[key]
+			if !ok {
+				sd = &models.VacancyStatDay{VacancyID: row.ID.VacancyID, CompanyID: row.CompanyID, Day: row.ID.Day}
+				days[key] = sd
+			}
+			sd.Add(row.VacancyStats)
+		}
+	}
+
+	now := time.Now().UTC()
+	ops := make([]mongo.WriteModel, 0, len(days))
+	for _, sd := range days {
+		sd.UpdatedAt = now
+		ops = append(ops, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"vacancyId": sd.VacancyID, "day": sd.Day}).
+			SetReplacement(sd).
+			SetUpsert(true))
+	}
+	for len(ops) > 0 {
+		n := min(len(ops), 1000)
+		if _, err := r.d.VacancyStatsDaily().BulkWrite(ctx, ops[:n], options.BulkWrite().SetOrdered(false)); err != nil {
+			return 0, err
+		}
+		ops = ops[n:]
+	}
+	_, err := r.d.VacancyStatsDaily().DeleteMany(ctx, bson.M{"day": bson.M{"$gte": fromDay}, "updatedAt": bson.M{"$lt": now}})
+	return len(days), err
+}
+
+// StatsTotal — счетчики за период, сгруппированные по полю key
+type StatsTotal struct {
+	Key                 string `bson:"_id"`
+	models.VacancyStats `bson:",inline"`
+}
+
+// SumStats суммирует дневные срезы с match за дни [fromDay, toDay],
+// группируя по полю key ("day" или "vacancyId"); результат упорядочен по ключу
+func (r *AnalyticsRepo) SumStats(ctx context.Context, match bson.M, fromDay, toDay, key string) ([]StatsTotal, error) {
+	filter := bson.M{"day": bson.M{"$gte": fromDay, "$lte": toDay}}
+	for k, v := range match {
+		filter[k] = v
+	}
+	group := bson.M{"_id": "$" + key}
+	for _, f := range []string{"views", "applications", "knockedOut", "viewed", "responded", "rejected", "accepted", "responseSumMs", "hireSumMs"} {
+		group[f] = bson.M{"$sum": "$" + f}
+	}
+	cur, err := r.d.VacancyStatsDaily().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: group}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []StatsTotal{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BackfillDecisions проставляет время решения откликам, принятым до появления
+// decidedAt и acceptedAt. Точного времени нет, берется updatedAt.
+func (r *AnalyticsRepo) BackfillDecisions(ctx context.Context) (int64, error) {
+	res, err := r.d.Applications().UpdateMany(ctx, bson.M{
+		"status":     bson.M{"$in": bson.A{"accepted", "rejected"}},
+		"knockedOut": bson.M{"$ne": true},
+		"decidedAt":  bson.M{"$exists": false},
+	}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"decidedAt": "$updatedAt"}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	_, err = r.d.Applications().UpdateMany(ctx, bson.M{
+		"status":     "accepted",
+		"acceptedAt": bson.M{"$exists": false},
+	}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"acceptedAt": "$decidedAt"}}},
+	})
+	return res.ModifiedCount, err
+}
@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"github.com/oklog/ulid/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type APIKeyRepo struct{ d *db.Database }
+
+func NewAPIKeyRepo(d *db.Database) *APIKeyRepo { return &APIKeyRepo{d: d} }
+
+func (r *APIKeyRepo) Create(ctx context.Context, k *models.APIKey) error {
+	k.KeyID = ulid.Make().String()
+	k.CreatedAt = time.Now().UTC()
+	_, err := r.d.APIKeys().InsertOne(ctx, k)
+	return err
+}
+
+func (r *APIKeyRepo) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var k models.APIKey
+	err := r.d.APIKeys().FindOne(ctx, bson.M{"keyHash": keyHash}).Decode(&k)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &k, err
+}
+
+func (r *APIKeyRepo) ListByCompany(ctx context.Context, companyID string) ([]models.APIKey, error) {
+	cur, err := r.d.APIKeys().Find(ctx, bson.M{"companyId": companyID}, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.APIKey{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CountActive — неотозванные ключи компании (истекшие тоже считаются, пока их не отзовут)
+func (r *APIKeyRepo) CountActive(ctx context.Context, companyID string) (int64, error) {
+	return r.d.APIKeys().CountDocuments(ctx, bson.M{"companyId": companyID, "revokedAt": bson.M{"$exists": false}})
+}
+
+// Revoke отзывает ключ; false — ключ не найден или уже отозван
+func (r *APIKeyRepo) Revoke(ctx context.Context, keyID, companyID string) (bool, error) {
+	res, err := r.d.APIKeys().UpdateOne(ctx,
+		bson.M{"keyId": keyID, "companyId": companyID, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": time.Now().UTC()}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// Touch обновляет время и адрес последнего запроса не чаще раза в минуту
+func (r *APIKeyRepo) Touch(ctx context.Context, keyID, ip string, now time.Time) error {
+	_, err := r.d.APIKeys().UpdateOne(ctx,
+		bson.M{"keyId": keyID, "$or": []bson.M{
+			{"lastUsedAt": bson.M{"$exists": false}},
+			{"lastUsedAt": bson.M{"$lt": now.Add(-time.Minute)}},
+		}},
+		bson.M{"$set": bson.M{"lastUsedAt": now, "lastUsedIp": ip}})
+	return err
+}
@@ -2,6 +2,8 @@ package repo
 
 import (
 	"context"
+	"regexp"
+	"sort"
 	"time"
 
 	"unicorn-auth/internal/db"
@@ -20,7 +22,9 @@ func NewApplicationRepo(d *db.Database) *ApplicationRepo { return &ApplicationRe
 func (r *ApplicationRepo) Create(ctx context.Context, a *models.Application) error {
 	now := time.Now().UTC()
 	a.ApplicationID = ulid.Make().String()
-	a.Status = "pending"
+	if a.Status == "" {
+		a.Status = "pending"
+	}
 	a.CreatedAt, a.UpdatedAt = now, now
 	_, err := r.d.Applications().InsertOne(ctx, a)
 	return err
@@ -35,12 +39,70 @@ func (r *ApplicationRepo) GetByID(ctx context.Context, appID string) (*models.Ap
 	return &a, err
 }
 
-func (r *ApplicationRepo) ListInbox(ctx context.Context, companyID, status string, limit, skip int64) ([]models.Application, error) {
-	f := bson.M{"companyId": companyID, "hidden.company": bson.M{"$ne": true}}
-	if status != "" {
-		f["status"] = status
+// InboxFilter — условия инбокса организации; пустые поля не ограничивают выборку
+type InboxFilter struct {
+	Status     string
+	Assignee   string // "none" — без ответственного, иначе userId рекрутера
+	VacancyID  string
+	KnockedOut *bool
+	Answers    []AnswerFilter // все условия должны выполняться
+}
+
+// AnswerFilter — условие на ответ анкеты
+type AnswerFilter struct {
+	QuestionID string
+	Op         string // eq, contains, gte, lte
+	Value      string
+	Number     float64 // для gte/lte
+}
+
+func (f InboxFilter) bson(orgID string) bson.M {
+	m := bson.M{"companyId": orgID, "hidden.company": bson.M{"$ne": true}}
+	if f.Status != "" {
+		m["status"] = f.Status
+	}
+	switch f.Assignee {
+	case "":
+	case "none":
+		m["assigneeId"] = bson.M{"$in": bson.A{nil, ""}}
+	default:
+		m["assigneeId"] = f.Assignee
+	}
+	if f.VacancyID != "" {
+		m["vacancyId"] = f.VacancyID
+	}
+	if f.KnockedOut != nil {
+		if *f.KnockedOut {
+			m["knockedOut"] = true
+		} else {
+			m["knockedOut"] = bson.M{"$ne": true}
+		}
 	}
-	cur, err := r.d.Applications().Find(ctx, f, options.Find().SetLimit(limit).SetSkip(skip).SetSort(bson.M{"createdAt": -1}))
+	var all bson.A
+	for _, af := range f.Answers {
+		cond := bson.M{"questionId": af.QuestionID}
+		switch af.Op {
+		case "contains":
+			cond["value"] = bson.M{"$regex": regexp.QuoteMeta(af.Value), "$options": "i"}
+		case "gte":
+			cond["number"] = bson.M{"$gte": af.Number}
+		case "lte":
+			cond["number"] = bson.M{"$lte": af.Number}
+		default:
+			cond["value"] = af.Value
+		}
+		all = append(all, bson.M{"$elemMatch": cond})
+	}
+	if len(all) > 0 {
+		m["answers"] = bson.M{"$all": all}
+	}
+	return m
+}
+
+// ListInbox возвращает отклики организации по фильтру, новые первыми
+func (r *ApplicationRepo) ListInbox(ctx context.Context, orgID string, f InboxFilter, limit, skip int64) ([]models.Application, error) {
+	filter := f.bson(orgID)
+	cur, err := r.d.Applications().Find(ctx, filter, options.Find().SetLimit(limit).SetSkip(skip).SetSort(bson.M{"createdAt": -1}))
 	if err != nil {
 		return nil, err
 	}
@@ -52,17 +114,23 @@ func (r *ApplicationRepo) ListInbox(ctx context.Context, companyID, status strin
 	return out, nil
 }
 
-func (r *ApplicationRepo) UpdateStatus(ctx context.Context, appID, companyID, status string) error {
+// UpdateStatus меняет статус; $min сохраняет время первого решения и первого приглашения
+func (r *ApplicationRepo) UpdateStatus(ctx context.Context, appID, orgID, status string) error {
+	now := time.Now().UTC()
+	first := bson.M{"decidedAt": now}
+	if status == "accepted" {
+		first["acceptedAt"] = now
+	}
 	_, err := r.d.Applications().UpdateOne(ctx,
-		bson.M{"applicationId": appID, "companyId": companyID},
-		bson.M{"$set": bson.M{"status": status, "updatedAt": time.Now().UTC()}},
+		bson.M{"applicationId": appID, "companyId": orgID},
+		bson.M{"$set": bson.M{"status": status, "updatedAt": now}, "$min": first},
 	)
 	return err
 }
 
-func (r *ApplicationRepo) ExistsCompanyResume(ctx context.Context, companyID, resumeID string) (bool, error) {
+func (r *ApplicationRepo) ExistsCompanyResume(ctx context.Context, orgID, resumeID string) (bool, error) {
 	n, err := r.d.Applications().CountDocuments(ctx, bson.M{
-		"companyId": companyID,
+		"companyId": orgID,
 		"resumeId":  resumeID,
 	})
 	if err != nil {
@@ -71,6 +139,27 @@ func (r *ApplicationRepo) ExistsCompanyResume(ctx context.Context, companyID, re
 	return n > 0, nil
 }
 
+// LatestCompanyResume — последний отклик организации с этим резюме
+func (r *ApplicationRepo) LatestCompanyResume(ctx context.Context, orgID, resumeID string) (*models.Application, error) {
+	var a models.Application
+	err := r.d.Applications().FindOne(ctx, bson.M{"companyId": orgID, "resumeId": resumeID},
+		options.FindOne().SetSort(bson.M{"createdAt": -1})).Decode(&a)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &a, err
+}
+
+// ExistsCompanySnapshot проверяет, что организация получила этот снимок резюме
+func (r *ApplicationRepo) ExistsCompanySnapshot(ctx context.Context, orgID, snapshotID string) (bool, error) {
+	n, err := r.d.Applications().CountDocuments(ctx, bson.M{"companyId": orgID, "snapshotId": snapshotID},
+		options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
 func (r *ApplicationRepo) ListByUser(ctx context.Context, userID, status string, limit, skip int64) ([]models.Application, error) {
 	f := bson.M{"userId": userID, "hidden.user": bson.M{"$ne": true}}
 	if status != "" {
@@ -96,16 +185,15 @@ func (r *ApplicationRepo) ListByUser(ctx context.Context, userID, status string,
 	}
 	return out, nil
 }
-func (r *ApplicationRepo) MarkViewed(ctx context.Context, appID, companyID string) error {
+func (r *ApplicationRepo) MarkViewed(ctx context.Context, appID, orgID string) error {
 	now := time.Now().UTC()
 	_, err := r.d.Applications().UpdateOne(
 		ctx,
-		bson.M{"applicationId": appID, "companyId": companyID},
-		bson.M{"$set": bson.M{
-			"viewed":    true,
-			"viewedAt":  now,
-			"updatedAt": now,
-		}},
+		bson.M{"applicationId": appID, "companyId": orgID},
+		bson.M{
+			"$set": bson.M{"viewed": true, "updatedAt": now},
+			"$min": bson.M{"viewedAt": now}, // первое открытие — для аналитики
+		},
 	)
 	return err
 }
@@ -129,11 +217,11 @@ func (r *ApplicationRepo) ListByUserID(ctx context.Context, userID string) ([]mo
 	return out, nil
 }
 
-// ListByCompanyID возвращает все applications компании (кроме скрытых)
-func (r *ApplicationRepo) ListByCompanyID(ctx context.Context, companyID string) ([]models.Application, error) {
+// ListByCompanyID возвращает все applications организации (кроме скрытых)
+func (r *ApplicationRepo) ListByCompanyID(ctx context.Context, orgID string) ([]models.Application, error) {
 	cur, err := r.d.Applications().Find(
 		ctx,
-		bson.M{"companyId": companyID, "hidden.company": bson.M{"$ne": true}},
+		bson.M{"companyId": orgID, "hidden.company": bson.M{"$ne": true}},
 		options.Find().SetSort(bson.M{"createdAt": -1}),
 	)
 	if err != nil {
@@ -148,8 +236,23 @@ func (r *ApplicationRepo) ListByCompanyID(ctx context.Context, companyID string)
 	return out, nil
 }
 
-// Hide скрывает отклик для пользователя или компании
-func (r *ApplicationRepo) Hide(ctx context.Context, appID, userID string, isCompany bool) error {
+// ListByVacancy возвращает отклики на вакансию в указанных статусах
+func (r *ApplicationRepo) ListByVacancy(ctx context.Context, vacancyID string, statuses ...string) ([]models.Application, error) {
+	cur, err := r.d.Applications().Find(ctx, bson.M{"vacancyId": vacancyID, "status": bson.M{"$in": statuses}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var out []models.Application
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Hide скрывает отклик для пользователя или компании; для компании ownerID — orgId
+func (r *ApplicationRepo) Hide(ctx context.Context, appID, ownerID string, isCompany bool) error {
 	field := "hidden.user"
 	if isCompany {
 		field = "hidden.company"
@@ -165,15 +268,47 @@ func (r *ApplicationRepo) Hide(ctx context.Context, appID, userID string, isComp
 
 	filter := bson.M{"applicationId": appID}
 	if isCompany {
-		filter["companyId"] = userID
+		filter["companyId"] = ownerID
 	} else {
-		filter["userId"] = userID
+		filter["userId"] = ownerID
 	}
 
 	_, err := r.d.Applications().UpdateOne(ctx, filter, update)
 	return err
 }
 
+// Assign назначает ответственного рекрутера (пустая строка снимает назначение)
+func (r *ApplicationRepo) Assign(ctx context.Context, appID, orgID, assigneeID string) (bool, error) {
+	res, err := r.d.Applications().UpdateOne(ctx,
+		bson.M{"applicationId": appID, "companyId": orgID},
+		bson.M{"$set": bson.M{"assigneeId": assigneeID, "updatedAt": time.Now().UTC()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// MoveToOrg переносит отклики аккаунта в организацию, в которую он вступил
+func (r *ApplicationRepo) MoveToOrg(ctx context.Context, fromID, orgID string) (int64, error) {
+	res, err := r.d.Applications().UpdateMany(ctx, bson.M{"companyId": fromID}, bson.M{
+		"$set": bson.M{"companyId": orgID, "assigneeId": "", "updatedAt": time.Now().UTC()},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// UnassignMember снимает назначения с участника, покинувшего организацию
+func (r *ApplicationRepo) UnassignMember(ctx context.Context, orgID, userID string) error {
+	_, err := r.d.Applications().UpdateMany(ctx,
+		bson.M{"companyId": orgID, "assigneeId": userID},
+		bson.M{"$set": bson.M{"assigneeId": "", "updatedAt": time.Now().UTC()}},
+	)
+	return err
+}
+
 // UnhideOnNewMessage снимает скрытие при получении нового сообщения
 func (r *ApplicationRepo) UnhideOnNewMessage(ctx context.Context, appID string) error {
 	update := bson.M{
@@ -207,3 +342,89 @@ func (r *ApplicationRepo) DeleteOldHidden(ctx context.Context) (int64, error) {
 	}
 	return result.DeletedCount, nil
 }
+
+// ApplicantOf сообщает, откликался ли пользователь в организацию и был ли принят
+func (r *ApplicationRepo) ApplicantOf(ctx context.Context, userID, orgID string) (applied, accepted bool, err error) {
+	n, err := r.d.Applications().CountDocuments(ctx, bson.M{"userId": userID, "companyId": orgID})
+	if err != nil || n == 0 {
+		return false, false, err
+	}
+	n, err = r.d.Applications().CountDocuments(ctx, bson.M{"userId": userID, "companyId": orgID, "status": "accepted"})
+	return true, n > 0, err
+}
+
+// ResponseStats — как компания отвечает на отклики
+type ResponseStats struct {
+	Applications int64 `json:"applications"`
+	Responded    int64 `json:"responded"`
+	// Доля откликов с решением или сообщением компании, 0..1
+	ResponseRate float64 `json:"responseRate"`
+	// Медиана времени до первого сообщения компании в чате, часы; 0 — нет данных
+	MedianReplyHours float64 `json:"medianReplyHours"`
+}
+
+// ResponseStats считает статистику по последним откликам организации с since.
+// Учитываются не больше limit последних откликов.
+func (r *ApplicationRepo) ResponseStats(ctx context.Context, orgID string, since time.Time, limit int64) (ResponseStats, error) {
+	var out ResponseStats
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"companyId": orgID, "createdAt": bson.M{"$gte": since}}}},
+		{{Key: "$sort", Value: bson.M{"createdAt": -1}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": r.d.ChatMessages().Name(),
+			"let":  bson.M{"appId": "$applicationId"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$applicationId", "$$appId"}}, "senderType": "company"}},
+				bson.M{"$sort": bson.M{"createdAt": 1}},
+				bson.M{"$limit": 1},
+				bson.M{"$project": bson.M{"_id": 0, "createdAt": 1}},
+			},
+			"as": "firstReply",
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":          0,
+			"status":       1,
+			"createdAt":    1,
+			"firstReplyAt": bson.M{"$arrayElemAt": bson.A{"$firstReply.createdAt", 0}},
+		}}},
+	}
+	cur, err := r.d.Applications().Aggregate(ctx, pipeline)
+	if err != nil {
+		return out, err
+	}
+	defer cur.Close(ctx)
+
+	var rows []struct {
+		Status       string    `bson:"status"`
+		CreatedAt    time.Time `bson:"createdAt"`
+		FirstReplyAt time.Time `bson:"firstReplyAt"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return out, err
+	}
+
+	var delays []float64
+	for _, row := range rows {
+		out.Applications++
+		replied := !row.FirstReplyAt.IsZero()
+		if replied || row.Status == "accepted" || row.Status == "rejected" {
+			out.Responded++
+		}
+		if replied && row.FirstReplyAt.After(row.CreatedAt) {
+			delays = append(delays, row.FirstReplyAt.Sub(row.CreatedAt).Hours())
+		}
+	}
+	if out.Applications > 0 {
+		out.ResponseRate = float64(out.Responded) / float64(out.Applications)
+	}
+	if n := len(delays); n > 0 {
+		sort.Float64s(delays)
+		if n%2 == 1 {
+			out.MedianReplyHours = delays[n/2]
+		} else {
+			out.MedianReplyHours = (delays[n/2-1] + delays[n/2]) / 2
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"github.com/oklog/ulid/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationRepo struct{ d *db.Database }
+
+func NewNotificationRepo(d *db.Database) *NotificationRepo { return &NotificationRepo{d: d} }
+
+func (r *NotificationRepo) Create(ctx context.Context, n *models.Notification) error {
+	n.NotificationID = ulid.Make().String()
+	n.Read = false
+	n.CreatedAt = time.Now().UTC()
+	_, err := r.d.Notifications().InsertOne(ctx, n)
+	return err
+}
+
+func (r *NotificationRepo) List(ctx context.Context, userID, typ string, unreadOnly bool, limit, skip int64) ([]models.Notification, error) {
+	filter := bson.M{"userId": userID}
+	if typ != "" {
+		filter["type"] = typ
+	}
+	if unreadOnly {
+		filter["read"] = false
+	}
+	opts := options.Find().SetSort(bson.M{"createdAt": -1}).SetLimit(limit).SetSkip(skip)
+	cur, err := r.d.Notifications().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.Notification{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UnreadCounts возвращает число непрочитанных всего и по типам событий
+func (r *NotificationRepo) UnreadCounts(ctx context.Context, userID string) (int64, map[string]int64, error) {
+	cur, err := r.d.Notifications().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"userId": userID, "read": false}}},
+		{{Key: "$group", Value: bson.M{"_id": "$type", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	defer cur.Close(ctx)
+	var rows []struct {
+		Type  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return 0, nil, err
+	}
+	var total int64
+	byType := map[string]int64{}
+	for _, row := range rows {
+		total += row.Count
+		byType[row.Type] = row.Count
+	}
+	return total, byType, nil
+}
+
+func (r *NotificationRepo) MarkRead(ctx context.Context, userID, notificationID string) (bool, error) {
+	res, err := r.d.Notifications().UpdateOne(ctx,
+		bson.M{"notificationId": notificationID, "userId": userID},
+		bson.M{"$set": bson.M{"read": true}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// MarkAllRead отмечает прочитанными все уведомления пользователя (или только одного типа)
+func (r *NotificationRepo) MarkAllRead(ctx context.Context, userID, typ string) (int64, error) {
+	filter := bson.M{"userId": userID, "read": false}
+	if typ != "" {
+		filter["type"] = typ
+	}
+	res, err := r.d.Notifications().UpdateMany(ctx, filter, bson.M{"$set": bson.M{"read": true}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// GetPrefs возвращает настройки; nil — пользователь ничего не настраивал
+func (r *NotificationRepo) GetPrefs(ctx context.Context, userID string) (*models.NotificationPrefs, error) {
+	var p models.NotificationPrefs
+	err := r.d.NotificationPrefs().FindOne(ctx, bson.M{"userId": userID}).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &p, err
+}
+
+func (r *NotificationRepo) SavePrefs(ctx context.Context, p *models.NotificationPrefs) error {
+	p.UpdatedAt = time.Now().UTC()
+	_, err := r.d.NotificationPrefs().ReplaceOne(ctx, bson.M{"userId": p.UserID}, p, options.Replace().SetUpsert(true))
+	return err
+}
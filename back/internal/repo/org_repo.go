@@ -0,0 +1,192 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"github.com/oklog/ulid/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrAlreadyMember = errors.New("user already belongs to an organization")
+
+type OrgRepo struct{ d *db.Database }
+
+func NewOrgRepo(d *db.Database) *OrgRepo { return &OrgRepo{d: d} }
+
+// Resolve возвращает организацию и роль пользователя-компании.
+// Без записи об участии пользователь — владелец своей организации с orgId = userId.
+func (r *OrgRepo) Resolve(ctx context.Context, userID string) (orgID, role string, err error) {
+	m, err := r.MemberOf(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if m == nil {
+		return userID, models.OrgRoleOwner, nil
+	}
+	return m.OrgID, m.Role, nil
+}
+
+func (r *OrgRepo) MemberOf(ctx context.Context, userID string) (*models.OrgMember, error) {
+	var m models.OrgMember
+	err := r.d.OrgMembers().FindOne(ctx, bson.M{"userId": userID}).Decode(&m)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &m, err
+}
+
+func (r *OrgRepo) GetOrg(ctx context.Context, orgID string) (*models.Organization, error) {
+	var o models.Organization
+	err := r.d.Organizations().FindOne(ctx, bson.M{"orgId": orgID}).Decode(&o)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &o, err
+}
+
+// EnsureOrg создает организацию владельца и его запись участника, если их еще нет
+func (r *OrgRepo) EnsureOrg(ctx context.Context, ownerID, name string) (*models.Organization, error) {
+	now := time.Now().UTC()
+	_, err := r.d.Organizations().UpdateOne(ctx, bson.M{"orgId": ownerID}, bson.M{"$setOnInsert": bson.M{
+		"orgId":     ownerID,
+		"ownerId":   ownerID,
+		"name":      name,
+		"createdAt": now,
+		"updatedAt": now,
+	}}, options.Update().SetUpsert(true))
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.d.OrgMembers().UpdateOne(ctx, bson.M{"userId": ownerID}, bson.M{"$setOnInsert": bson.M{
+		"orgId":    ownerID,
+		"userId":   ownerID,
+		"role":     models.OrgRoleOwner,
+		"joinedAt": now,
+	}}, options.Update().SetUpsert(true))
+	if err != nil {
+		return nil, err
+	}
+	return r.GetOrg(ctx, ownerID)
+}
+
+func (r *OrgRepo) UpdateName(ctx context.Context, orgID, name string) error {
+	_, err := r.d.Organizations().UpdateOne(ctx, bson.M{"orgId": orgID},
+		bson.M{"$set": bson.M{"name": name, "updatedAt": time.Now().UTC()}})
+	return err
+}
+
+func (r *OrgRepo) ListMembers(ctx context.Context, orgID string) ([]models.OrgMember, error) {
+	cur, err := r.d.OrgMembers().Find(ctx, bson.M{"orgId": orgID}, options.Find().SetSort(bson.M{"joinedAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []models.OrgMember
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *OrgRepo) AddMember(ctx context.Context, m *models.OrgMember) error {
+	m.JoinedAt = time.Now().UTC()
+	_, err := r.d.OrgMembers().InsertOne(ctx, m)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrAlreadyMember
+	}
+	return err
+}
+
+// UpdateMemberRole меняет роль участника; роль владельца не меняется
+func (r *OrgRepo) UpdateMemberRole(ctx context.Context, orgID, userID, role string) (bool, error) {
+	res, err := r.d.OrgMembers().UpdateOne(ctx,
+		bson.M{"orgId": orgID, "userId": userID, "role": bson.M{"$ne": models.OrgRoleOwner}},
+		bson.M{"$set": bson.M{"role": role}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// RemoveMember исключает участника; владельца исключить нельзя
+func (r *OrgRepo) RemoveMember(ctx context.Context, orgID, userID string) (bool, error) {
+	res, err := r.d.OrgMembers().DeleteOne(ctx,
+		bson.M{"orgId": orgID, "userId": userID, "role": bson.M{"$ne": models.OrgRoleOwner}})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+func (r *OrgRepo) CreateInvite(ctx context.Context, inv *models.OrgInvite) error {
+	now := time.Now().UTC()
+	inv.InviteID = ulid.Make().String()
+	inv.Status = "pending"
+	inv.CreatedAt = now
+	inv.UpdatedAt = now
+	_, err := r.d.OrgInvites().InsertOne(ctx, inv)
+	return err
+}
+
+func (r *OrgRepo) ListInvites(ctx context.Context, orgID string) ([]models.OrgInvite, error) {
+	cur, err := r.d.OrgInvites().Find(ctx,
+		bson.M{"orgId": orgID, "status": "pending", "expiresAt": bson.M{"$gt": time.Now().UTC()}},
+		options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []models.OrgInvite
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *OrgRepo) RevokeInvite(ctx context.Context, orgID, inviteID string) (bool, error) {
+	res, err := r.d.OrgInvites().UpdateOne(ctx,
+		bson.M{"orgId": orgID, "inviteId": inviteID, "status": "pending"},
+		bson.M{"$set": bson.M{"status": "revoked", "updatedAt": time.Now().UTC()}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// FindInvite возвращает действующее приглашение по хэшу токена; nil — не найдено или истекло
+func (r *OrgRepo) FindInvite(ctx context.Context, tokenHash string) (*models.OrgInvite, error) {
+	var inv models.OrgInvite
+	err := r.d.OrgInvites().FindOne(ctx,
+		bson.M{"tokenHash": tokenHash, "status": "pending", "expiresAt": bson.M{"$gt": time.Now().UTC()}}).Decode(&inv)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &inv, err
+}
+
+// ClaimInvite атомарно помечает действующее приглашение принятым; nil — не найдено или истекло
+func (r *OrgRepo) ClaimInvite(ctx context.Context, tokenHash, userID string) (*models.OrgInvite, error) {
+	var inv models.OrgInvite
+	err := r.d.OrgInvites().FindOneAndUpdate(ctx,
+		bson.M{"tokenHash": tokenHash, "status": "pending", "expiresAt": bson.M{"$gt": time.Now().UTC()}},
+		bson.M{"$set": bson.M{"status": "accepted", "acceptedBy": userID, "updatedAt": time.Now().UTC()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&inv)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &inv, err
+}
+
+// ReopenInvite возвращает приглашение в pending, если вступить не удалось
+func (r *OrgRepo) ReopenInvite(ctx context.Context, inviteID string) error {
+	_, err := r.d.OrgInvites().UpdateOne(ctx, bson.M{"inviteId": inviteID},
+		bson.M{"$set": bson.M{"status": "pending", "acceptedBy": "", "updatedAt": time.Now().UTC()}})
+	return err
+}
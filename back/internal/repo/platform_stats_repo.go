@@ -0,0 +1,233 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// bucketFormats — ключ среза в $dateToString; должен совпадать с platformstats.BucketKey
+var bucketFormats = map[string]string{
+	models.PeriodDay:   "%Y-%m-%d",
+	models.PeriodWeek:  "%G-W%V",
+	models.PeriodMonth: "%Y-%m",
+}
+
+// bucketOf — ключ среза period для даты field
+func bucketOf(period, field string) bson.M {
+	return bson.M{"$dateToString": bson.M{"format": bucketFormats[period], "date": "$" + field}}
+}
+
+// BucketRow — счетчики одного среза из агрегации
+type BucketRow struct {
+	Bucket                  string `bson:"_id"`
+	models.PlatformCounters `bson:",inline"`
+}
+
+// countBuckets считает документы coll с датой field >= from по срезам period
+func countBuckets(ctx context.Context, coll *mongo.Collection, period, field string, from time.Time, match, sums bson.M) ([]BucketRow, error) {
+	filter := bson.M{field: bson.M{"$gte": from}}
+	for k, v := range match {
+		filter[k] = v
+	}
+	group := bson.M{"_id": bucketOf(period, field)}
+	for k, v := range sums {
+		group[k] = v
+	}
+	return aggregateBuckets(ctx, coll, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: group}},
+	})
+}
+
+func aggregateBuckets(ctx context.Context, coll *mongo.Collection, pipeline mongo.Pipeline) ([]BucketRow, error) {
+	cur, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []BucketRow
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type PlatformStatsRepo struct{ d *db.Database }
+
+func NewPlatformStatsRepo(d *db.Database) *PlatformStatsRepo { return &PlatformStatsRepo{d: d} }
+
+// Buckets считает регистрации, активность, вакансии, отклики и модерацию
+// по срезам period начиная с from (from — начало среза)
+func (r *PlatformStatsRepo) Buckets(ctx context.Context, period string, from time.Time) ([]BucketRow, error) {
+	sources := []func() ([]BucketRow, error){
+		func() ([]BucketRow, error) {
+			return countBuckets(ctx, r.d.Users(), period, "createdAt", from, nil, bson.M{
+				"usersRegistered":     countIf(bson.M{"$eq": bson.A{"$type", "user"}}),
+				"companiesRegistered": countIf(bson.M{"$eq": bson.A{"$type", "company"}}),
+			})
+		},
+		func() ([]BucketRow, error) {
+			// Уникальные пользователи: сначала пары срез-пользователь
+			return aggregateBuckets(ctx, r.d.UserActivity(), mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"date": bson.M{"$gte": from}}}},
+				{{Key: "$group", Value: bson.M{"_id": bson.M{"b": bucketOf(period, "date"), "u": "$userId"}}}},
+				{{Key: "$group", Value: bson.M{"_id": "$_id.b", "activeUsers": bson.M{"$sum": 1}}}},
+			})
+		},
+		func() ([]BucketRow, error) {
+			return countBuckets(ctx, r.d.Vacancies(), period, "createdAt", from, nil, bson.M{"vacanciesCreated": bson.M{"$sum": 1}})
+		},
+		func() ([]BucketRow, error) {
+			return countBuckets(ctx, r.d.Applications(), period, "createdAt", from, nil, bson.M{"applications": bson.M{"$sum": 1}})
+		},
+		func() ([]BucketRow, error) {
+			return countBuckets(ctx, r.d.CompanyVerifications(), period, "createdAt", from, nil, bson.M{"verificationsSubmitted": bson.M{"$sum": 1}})
+		},
+		func() ([]BucketRow, error) {
+			// Решения администратора; DNS и файл подтверждаются без него
+			return countBuckets(ctx, r.d.CompanyVerifications(), period, "updatedAt", from,
+				bson.M{"reviewedBy": bson.M{"$exists": true, "$ne": ""}, "status": bson.M{"$in": bson.A{"verified", "rejected"}}},
+				bson.M{
+					"verificationsApproved": countIf(bson.M{"$eq": bson.A{"$status", "verified"}}),
+					"verificationsRejected": countIf(bson.M{"$eq": bson.A{"$status", "rejected"}}),
+				})
+		},
+	}
+	var out []BucketRow
+	for _, src := range sources {
+		rows, err := src()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rows...)
+	}
+	return out, nil
+}
+
+// ActiveUsers — уникальные активные пользователи в днях [from, to)
+func (r *PlatformStatsRepo) ActiveUsers(ctx context.Context, from, to time.Time) (int64, error) {
+	cur, err := r.d.UserActivity().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"date": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$userId"}}},
+		{{Key: "$count", Value: "n"}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+	var rows []struct {
+		N int64 `bson:"n"`
+	}
+	if err := cur.All(ctx, &rows); err != nil || len(rows) == 0 {
+		return 0, err
+	}
+	return rows[0].N, nil
+}
+
+// Earliest — дата первой регистрации; нулевая, если пользователей нет
+func (r *PlatformStatsRepo) Earliest(ctx context.Context) (time.Time, error) {
+	var u models.User
+	err := r.d.Users().FindOne(ctx, bson.M{}, options.FindOne().
+		SetSort(bson.M{"createdAt": 1}).SetProjection(bson.M{"createdAt": 1})).Decode(&u)
+	if err == mongo.ErrNoDocuments {
+		return time.Time{}, nil
+	}
+	return u.CreatedAt, err
+}
+
+// Save записывает пересчитанные срезы
+func (r *PlatformStatsRepo) Save(ctx context.Context, items []models.PlatformStat) error {
+	now := time.Now().UTC()
+	ops := make([]mongo.WriteModel, 0, len(items))
+	for i := range items {
+		items[i].UpdatedAt = now
+		ops = append(ops, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"period": items[i].Period, "bucket": items[i].Bucket}).
+			SetReplacement(&items[i]).
+			SetUpsert(true))
+	}
+	for len(ops) > 0 {
+		n := min(len(ops), 1000)
+		if _, err := r.d.PlatformStats().BulkWrite(ctx, ops[:n], options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+		ops = ops[n:]
+	}
+	return nil
+}
+
+// List — срезы period с ключами [fromBucket, toBucket] по возрастанию
+func (r *PlatformStatsRepo) List(ctx context.Context, period, fromBucket, toBucket string) ([]models.PlatformStat, error) {
+	cur, err := r.d.PlatformStats().Find(ctx,
+		bson.M{"period": period, "bucket": bson.M{"$gte": fromBucket, "$lte": toBucket}},
+		options.Find().SetSort(bson.M{"bucket": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.PlatformStat{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SeedActivity заполняет активность по датам создания еще живых сессий —
+// для DAU/MAU за время до появления учета
+func (r *PlatformStatsRepo) SeedActivity(ctx context.Context) (int64, error) {
+	cur, err := r.d.Sessions().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":  bson.M{"u": "$userId", "d": bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$createdAt"}}},
+			"date": bson.M{"$min": "$createdAt"},
+		}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+	var ops []mongo.WriteModel
+	var n int64
+	flush := func() error {
+		if len(ops) == 0 {
+			return nil
+		}
+		res, err := r.d.UserActivity().BulkWrite(ctx, ops, options.BulkWrite().SetOrdered(false))
+		if res != nil {
+			n += res.UpsertedCount
+		}
+		ops = ops[:0]
+		return err
+	}
+	for cur.Next(ctx) {
+		var row struct {
+			ID struct {
+				UserID string `bson:"u"`
+				Day    string `bson:"d"`
+			} `bson:"_id"`
+			Date time.Time `bson:"date"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return n, err
+		}
+		ops = append(ops, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"userId": row.ID.UserID, "day": row.ID.Day}).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{"date": row.Date.UTC().Truncate(24 * time.Hour)}}).
+			SetUpsert(true))
+		if len(ops) == 1000 {
+			if err := flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return n, err
+	}
+	return n, flush()
+}
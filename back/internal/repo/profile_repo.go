@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"unicorn-auth/internal/db"
@@ -11,6 +12,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// ErrDomainTaken — домен уже подтвержден другой компанией
+var ErrDomainTaken = errors.New("domain already verified by another company")
+
 type ProfileRepo struct{ d *db.Database }
 
 func NewProfileRepo(d *db.Database) *ProfileRepo { return &ProfileRepo{d: d} }
@@ -48,3 +52,63 @@ func (r *ProfileRepo) SearchCompanies(ctx context.Context, filter bson.M, limit
 	}
 	return out, nil
 }
+
+// SetVerified выставляет или снимает значок проверенной компании
+func (r *ProfileRepo) SetVerified(ctx context.Context, userID string, verified bool, method, domain string) (bool, error) {
+	now := time.Now().UTC()
+	update := bson.M{"$set": bson.M{
+		"verified":       true,
+		"verifiedMethod": method,
+		"verifiedDomain": domain,
+		"verifiedAt":     now,
+		"updatedAt":      now,
+	}}
+	if !verified {
+		update = bson.M{
+			"$set":   bson.M{"updatedAt": now},
+			"$unset": bson.M{"verified": "", "verifiedMethod": "", "verifiedDomain": "", "verifiedAt": ""},
+		}
+	}
+	res, err := r.d.Profiles().UpdateOne(ctx, bson.M{"userId": userID, "type": models.UserTypeCompany}, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, ErrDomainTaken
+	}
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// VerifiedDomainOwner — компания, подтвердившая домен; пусто — домен свободен
+func (r *ProfileRepo) VerifiedDomainOwner(ctx context.Context, domain string) (string, error) {
+	var p models.Profile
+	err := r.d.Profiles().FindOne(ctx, bson.M{"verifiedDomain": domain}).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return p.UserID, nil
+}
+
+// MapByUserIDs отдает профили по списку userId; отсутствующих в карте нет
+func (r *ProfileRepo) MapByUserIDs(ctx context.Context, userIDs []string) (map[string]models.Profile, error) {
+	out := make(map[string]models.Profile, len(userIDs))
+	if len(userIDs) == 0 {
+		return out, nil
+	}
+	cur, err := r.d.Profiles().Find(ctx, bson.M{"userId": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		var p models.Profile
+		if err := cur.Decode(&p); err != nil {
+			return nil, err
+		}
+		out[p.UserID] = p
+	}
+	return out, cur.Err()
+}
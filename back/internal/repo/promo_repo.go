@@ -0,0 +1,295 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"github.com/oklog/ulid/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrPromoExhausted   = errors.New("promo code usage limit reached")
+	ErrPromoAlreadyUsed = errors.New("promo code already used by this user")
+)
+
+type PromoRepo struct{ d *db.Database }
+
+func NewPromoRepo(d *db.Database) *PromoRepo { return &PromoRepo{d: d} }
+
+func NormPromoCode(code string) string { return strings.ToUpper(strings.TrimSpace(code)) }
+
+func (r *PromoRepo) Create(ctx context.Context, p *models.PromoCode) error {
+	now := time.Now().UTC()
+	p.PromoID = ulid.Make().String()
+	p.CodeNorm = NormPromoCode(p.Code)
+	p.UsedCount = 0
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	_, err := r.d.PromoCodes().InsertOne(ctx, p)
+	return err
+}
+
+func (r *PromoRepo) GetByID(ctx context.Context, promoID string) (*models.PromoCode, error) {
+	var p models.PromoCode
+	err := r.d.PromoCodes().FindOne(ctx, bson.M{"promoId": promoID}).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &p, err
+}
+
+func (r *PromoRepo) FindByCode(ctx context.Context, code string) (*models.PromoCode, error) {
+	var p models.PromoCode
+	err := r.d.PromoCodes().FindOne(ctx, bson.M{"codeNorm": NormPromoCode(code)}).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &p, err
+}
+
+func (r *PromoRepo) List(ctx context.Context, active string, limit, skip int64) ([]models.PromoCode, error) {
+	filter := bson.M{}
+	if active == "true" {
+		filter["active"] = true
+	} else if active == "false" {
+		filter["active"] = false
+	}
+	opts := options.Find().SetSort(bson.M{"createdAt": -1}).SetLimit(limit).SetSkip(skip)
+	cur, err := r.d.PromoCodes().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []models.PromoCode
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *PromoRepo) Update(ctx context.Context, promoID string, set bson.M) error {
+	set["updatedAt"] = time.Now().UTC()
+	_, err := r.d.PromoCodes().UpdateOne(ctx, bson.M{"promoId": promoID}, bson.M{"$set": set})
+	return err
+}
+
+// Delete удаляет промокод, только если им ни разу не воспользовались
+func (r *PromoRepo) Delete(ctx context.Context, promoID string) (bool, error) {
+	res, err := r.d.PromoCodes().DeleteOne(ctx, bson.M{"promoId": promoID, "usedCount": 0})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// PromoReservationTTL — сколько резерв ждет оплаты; потом его снимает
+// фоновая задача и использование возвращается коду
+const PromoReservationTTL = time.Hour
+
+// Reserve закрепляет использование промокода за платежом. Каждый платеж
+// получает свой резерв; резервы прежних незавершенных платежей пользователя
+// по этому коду снимаются. После оплаты повторно применить код нельзя: здесь
+// это только ранний отказ, а второе оплаченное применение отсекает уникальный
+// индекс в MarkRedeemed.
+func (r *PromoRepo) Reserve(ctx context.Context, p *models.PromoCode, red *models.PromoRedemption) error {
+	now := time.Now().UTC()
+
+	used, err := r.d.PromoRedemptions().CountDocuments(ctx,
+		bson.M{"promoId": p.PromoID, "userId": red.UserID, "status": "redeemed"}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return ErrPromoAlreadyUsed
+	}
+
+	// Если пользователь все же оплатит старый платеж, MarkRedeemed вернет ему использование
+	if _, err := r.release(ctx, bson.M{"promoId": p.PromoID, "userId": red.UserID, "status": "reserved"}); err != nil {
+		return err
+	}
+
+	// Счетчик увеличиваем атомарно с проверкой лимита
+	filter := bson.M{"promoId": p.PromoID}
+	if p.MaxUses > 0 {
+		filter["usedCount"] = bson.M{"$lt": p.MaxUses}
+	}
+	res, err := r.d.PromoCodes().UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"usedCount": 1}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrPromoExhausted
+	}
+
+	expires := now.Add(PromoReservationTTL)
+	red.RedemptionID = ulid.Make().String()
+	red.PromoID = p.PromoID
+	red.Code = p.Code
+	red.Status = "reserved"
+	red.ExpiresAt = &expires
+	red.CreatedAt = now
+	red.UpdatedAt = now
+	if _, err := r.d.PromoRedemptions().InsertOne(ctx, red); err != nil {
+		_, _ = r.d.PromoCodes().UpdateOne(ctx, bson.M{"promoId": p.PromoID}, bson.M{"$inc": bson.M{"usedCount": -1}})
+		return err
+	}
+	return nil
+}
+
+// MarkRedeemed отмечает использование промокода после успешной оплаты.
+// Оплата приходит и по снятому резерву (истек или заменен новым платежом) —
+// тогда использование снова засчитывается коду, даже сверх лимита.
+// ErrPromoAlreadyUsed — пользователь уже оплатил с этим кодом другой платеж:
+// применение помечается duplicate и коду не засчитывается
+func (r *PromoRepo) MarkRedeemed(ctx context.Context, subscriptionID string) error {
+	filter := bson.M{"subscriptionId": subscriptionID, "status": bson.M{"$in": bson.A{"reserved", "released"}}}
+	var prev models.PromoRedemption
+	err := r.d.PromoRedemptions().FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": bson.M{"status": "redeemed", "updatedAt": time.Now().UTC()}}).Decode(&prev)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		err = r.d.PromoRedemptions().FindOneAndUpdate(ctx, filter,
+			bson.M{"$set": bson.M{"status": "duplicate", "updatedAt": time.Now().UTC()}}).Decode(&prev)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Резерв держал использование — возвращаем его коду
+		if prev.Status == "reserved" {
+			if _, err := r.d.PromoCodes().UpdateOne(ctx, bson.M{"promoId": prev.PromoID, "usedCount": bson.M{"$gt": 0}},
+				bson.M{"$inc": bson.M{"usedCount": -1}}); err != nil {
+				return err
+			}
+		}
+		return ErrPromoAlreadyUsed
+	}
+	if err != nil || prev.Status != "released" {
+		return err
+	}
+	_, err = r.d.PromoCodes().UpdateOne(ctx, bson.M{"promoId": prev.PromoID}, bson.M{"$inc": bson.M{"usedCount": 1}})
+	return err
+}
+
+// Release возвращает использование, если платеж не состоялся
+func (r *PromoRepo) Release(ctx context.Context, subscriptionID string) error {
+	_, err := r.release(ctx, bson.M{"subscriptionId": subscriptionID, "status": "reserved"})
+	return err
+}
+
+// ReleaseExpired снимает резервы, не оплаченные за PromoReservationTTL
+func (r *PromoRepo) ReleaseExpired(ctx context.Context, now time.Time) (int64, error) {
+	return r.release(ctx, bson.M{"status": "reserved", "$or": bson.A{
+		bson.M{"expiresAt": bson.M{"$lte": now}},
+		// Резервы, созданные до появления срока
+		bson.M{"expiresAt": bson.M{"$exists": false}, "updatedAt": bson.M{"$lte": now.Add(-PromoReservationTTL)}},
+	}})
+}
+
+// release по одному переводит подходящие резервы в released и возвращает
+// использование коду; каждый резерв снимается ровно один раз
+func (r *PromoRepo) release(ctx context.Context, filter bson.M) (int64, error) {
+	var n int64
+	for {
+		var red models.PromoRedemption
+		err := r.d.PromoRedemptions().FindOneAndUpdate(ctx, filter,
+			bson.M{"$set": bson.M{"status": "released", "updatedAt": time.Now().UTC()}}).Decode(&red)
+		if err == mongo.ErrNoDocuments {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+		if _, err := r.d.PromoCodes().UpdateOne(ctx, bson.M{"promoId": red.PromoID, "usedCount": bson.M{"$gt": 0}},
+			bson.M{"$inc": bson.M{"usedCount": -1}}); err != nil {
+			return n, err
+		}
+	}
+}
+
+func (r *PromoRepo) ListRedemptions(ctx context.Context, promoID, status string, limit, skip int64) ([]models.PromoRedemption, error) {
+	filter := bson.M{"promoId": promoID}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.M{"createdAt": -1}).SetLimit(limit).SetSkip(skip)
+	cur, err := r.d.PromoRedemptions().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []models.PromoRedemption
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PromoReportRow — сводка по одному промокоду
+type PromoReportRow struct {
+	PromoID   string  `bson:"_id" json:"promoId"`
+	Code      string  `bson:"code" json:"code"`
+	Reserved  int64   `bson:"reserved" json:"reserved"`
+	Redeemed  int64   `bson:"redeemed" json:"redeemed"`
+	Released  int64   `bson:"released" json:"released"`
+	Duplicate int64   `bson:"duplicate" json:"duplicate"` // оплачены повторно, ждут возврата
+	Discount  float64 `bson:"discount" json:"discount"`   // сумма скидок по оплаченным
+	Revenue   float64 `bson:"revenue" json:"revenue"`     // выручка по оплаченным
+}
+
+// Report агрегирует применения промокодов за период (нулевые даты — без ограничения)
+func (r *PromoRepo) Report(ctx context.Context, from, to time.Time) ([]PromoReportRow, error) {
+	match := bson.M{}
+	if !from.IsZero() || !to.IsZero() {
+		created := bson.M{}
+		if !from.IsZero() {
+			created["$gte"] = from
+		}
+		if !to.IsZero() {
+			created["$lt"] = to
+		}
+		match["createdAt"] = created
+	}
+	countIf := func(status string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", status}}, 1, 0}}}
+	}
+	sumIf := func(field string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", "redeemed"}}, field, 0}}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       "$promoId",
+			"code":      bson.M{"$last": "$code"},
+			"reserved":  countIf("reserved"),
+			"redeemed":  countIf("redeemed"),
+			"released":  countIf("released"),
+			"duplicate": countIf("duplicate"),
+			"discount":  sumIf("$discount"),
+			"revenue":   sumIf("$amount"),
+		}}},
+		{{Key: "$sort", Value: bson.M{"redeemed": -1}}},
+	}
+	cur, err := r.d.PromoRedemptions().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []PromoReportRow{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -24,6 +24,7 @@ func (r *ResumeRepo) Create(ctx context.Context, rr *models.Resume) error {
 	now := time.Now().UTC()
 	rr.ResumeID = ulid.Make().String()
 	rr.Status = "active"
+	rr.Version = 1
 	rr.CreatedAt, rr.UpdatedAt = now, now
 	_, err := r.d.Resumes().InsertOne(ctx, rr)
 	return err
@@ -51,9 +52,11 @@ func (r *ResumeRepo) ListMine(ctx context.Context, userID string) ([]models.Resu
 	return out, nil
 }
 
+// Update меняет содержимое резюме и поднимает его версию
 func (r *ResumeRepo) Update(ctx context.Context, resumeID, userID string, set bson.M) error {
 	set["updatedAt"] = time.Now().UTC()
-	_, err := r.d.Resumes().UpdateOne(ctx, bson.M{"resumeId": resumeID, "userId": userID}, bson.M{"$set": set})
+	_, err := r.d.Resumes().UpdateOne(ctx, bson.M{"resumeId": resumeID, "userId": userID},
+		bson.M{"$set": set, "$inc": bson.M{"version": 1}})
 	return err
 }
 
@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"github.com/oklog/ulid/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ResumeSnapshotRepo struct{ d *db.Database }
+
+func NewResumeSnapshotRepo(d *db.Database) *ResumeSnapshotRepo { return &ResumeSnapshotRepo{d: d} }
+
+// Capture возвращает снимок текущей версии резюме, создавая его при первом отклике.
+// Существующий снимок не перезаписывается.
+func (r *ResumeSnapshotRepo) Capture(ctx context.Context, rr *models.Resume) (*models.ResumeSnapshot, error) {
+	snap := models.ResumeSnapshot{
+		SnapshotID: ulid.Make().String(),
+		ResumeID:   rr.ResumeID,
+		UserID:     rr.UserID,
+		Version:    rr.Version,
+		Title:      rr.Title,
+		About:      rr.About,
+		Skills:     rr.Skills,
+		Links:      rr.Links,
+		CreatedAt:  time.Now().UTC(),
+	}
+	var out models.ResumeSnapshot
+	err := r.d.ResumeSnapshots().FindOneAndUpdate(ctx,
+		bson.M{"resumeId": rr.ResumeID, "version": rr.Version},
+		bson.M{"$setOnInsert": snap},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&out)
+	if mongo.IsDuplicateKeyError(err) {
+		// Параллельный отклик успел создать снимок той же версии
+		err = r.d.ResumeSnapshots().FindOne(ctx, bson.M{"resumeId": rr.ResumeID, "version": rr.Version}).Decode(&out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *ResumeSnapshotRepo) GetByID(ctx context.Context, snapshotID string) (*models.ResumeSnapshot, error) {
+	var s models.ResumeSnapshot
+	err := r.d.ResumeSnapshots().FindOne(ctx, bson.M{"snapshotId": snapshotID}).Decode(&s)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &s, err
+}
@@ -0,0 +1,119 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"github.com/oklog/ulid/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReviewRepo struct{ d *db.Database }
+
+func NewReviewRepo(d *db.Database) *ReviewRepo { return &ReviewRepo{d: d} }
+
+// ReviewSummary — средняя оценка и число отзывов
+type ReviewSummary struct {
+	Count     int64   `bson:"count" json:"count"`
+	AvgRating float64 `bson:"avgRating" json:"avgRating"`
+}
+
+// Upsert создает отзыв или обновляет существующий отзыв пользователя о компании.
+// Ответ компании при редактировании сохраняется.
+func (r *ReviewRepo) Upsert(ctx context.Context, rv *models.CompanyReview) (*models.CompanyReview, error) {
+	now := time.Now().UTC()
+	var out models.CompanyReview
+	err := r.d.CompanyReviews().FindOneAndUpdate(ctx,
+		bson.M{"companyId": rv.CompanyID, "userId": rv.UserID},
+		bson.M{
+			"$set": bson.M{
+				"authorName":  rv.AuthorName,
+				"anonymous":   rv.Anonymous,
+				"wasAccepted": rv.WasAccepted,
+				"rating":      rv.Rating,
+				"text":        rv.Text,
+				"updatedAt":   now,
+			},
+			"$setOnInsert": bson.M{
+				"reviewId":  ulid.Make().String(),
+				"companyId": rv.CompanyID,
+				"userId":    rv.UserID,
+				"createdAt": now,
+			},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&out)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *ReviewRepo) GetByID(ctx context.Context, reviewID string) (*models.CompanyReview, error) {
+	var rv models.CompanyReview
+	err := r.d.CompanyReviews().FindOne(ctx, bson.M{"reviewId": reviewID}).Decode(&rv)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &rv, err
+}
+
+func (r *ReviewRepo) ListByCompany(ctx context.Context, companyID string, limit, skip int64) ([]models.CompanyReview, error) {
+	opts := options.Find().SetSort(bson.M{"createdAt": -1}).SetLimit(limit).SetSkip(skip)
+	cur, err := r.d.CompanyReviews().Find(ctx, bson.M{"companyId": companyID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.CompanyReview{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *ReviewRepo) Summary(ctx context.Context, companyID string) (ReviewSummary, error) {
+	var out ReviewSummary
+	cur, err := r.d.CompanyReviews().Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"companyId": companyID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       nil,
+			"count":     bson.M{"$sum": 1},
+			"avgRating": bson.M{"$avg": "$rating"},
+		}}},
+	})
+	if err != nil {
+		return out, err
+	}
+	defer cur.Close(ctx)
+	if cur.Next(ctx) {
+		err = cur.Decode(&out)
+	}
+	return out, err
+}
+
+// DeleteOwn удаляет отзыв его автором
+func (r *ReviewRepo) DeleteOwn(ctx context.Context, reviewID, userID string) (bool, error) {
+	res, err := r.d.CompanyReviews().DeleteOne(ctx, bson.M{"reviewId": reviewID, "userId": userID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// SetReply сохраняет ответ компании; nil удаляет ответ
+func (r *ReviewRepo) SetReply(ctx context.Context, reviewID, companyID string, reply *models.ReviewReply) (bool, error) {
+	update := bson.M{"$unset": bson.M{"reply": ""}}
+	if reply != nil {
+		update = bson.M{"$set": bson.M{"reply": reply}}
+	}
+	res, err := r.d.CompanyReviews().UpdateOne(ctx, bson.M{"reviewId": reviewID, "companyId": companyID}, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"github.com/oklog/ulid/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SavedSearchRepo struct{ d *db.Database }
+
+func NewSavedSearchRepo(d *db.Database) *SavedSearchRepo { return &SavedSearchRepo{d: d} }
+
+func (r *SavedSearchRepo) Create(ctx context.Context, s *models.SavedSearch) error {
+	now := time.Now().UTC()
+	s.SearchID = ulid.Make().String()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	// Рассылаем только вакансии, появившиеся после сохранения поиска
+	s.CheckedUntil = now
+	_, err := r.d.SavedSearches().InsertOne(ctx, s)
+	return err
+}
+
+func (r *SavedSearchRepo) GetByID(ctx context.Context, searchID, userID string) (*models.SavedSearch, error) {
+	var s models.SavedSearch
+	err := r.d.SavedSearches().FindOne(ctx, bson.M{"searchId": searchID, "userId": userID}).Decode(&s)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &s, err
+}
+
+func (r *SavedSearchRepo) ListByUser(ctx context.Context, userID string) ([]models.SavedSearch, error) {
+	cur, err := r.d.SavedSearches().Find(ctx, bson.M{"userId": userID}, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.SavedSearch{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *SavedSearchRepo) CountByUser(ctx context.Context, userID string) (int64, error) {
+	return r.d.SavedSearches().CountDocuments(ctx, bson.M{"userId": userID})
+}
+
+func (r *SavedSearchRepo) Update(ctx context.Context, searchID, userID string, set bson.M) (bool, error) {
+	set["updatedAt"] = time.Now().UTC()
+	res, err := r.d.SavedSearches().UpdateOne(ctx, bson.M{"searchId": searchID, "userId": userID}, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (r *SavedSearchRepo) Delete(ctx context.Context, searchID, userID string) (bool, error) {
+	res, err := r.d.SavedSearches().DeleteOne(ctx, bson.M{"searchId": searchID, "userId": userID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// ListDue возвращает активные поиски, по которым пора проверить новые вакансии
+func (r *SavedSearchRepo) ListDue(ctx context.Context, now time.Time, limit int64) ([]models.SavedSearch, error) {
+	opts := options.Find().SetSort(bson.M{"nextRunAt": 1}).SetLimit(limit)
+	cur, err := r.d.SavedSearches().Find(ctx, bson.M{"active": true, "nextRunAt": bson.M{"$lte": now}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []models.SavedSearch
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MarkRun сдвигает окно проверки и время следующего запуска
+func (r *SavedSearchRepo) MarkRun(ctx context.Context, searchID string, checkedUntil, nextRunAt time.Time, sent bool) error {
+	set := bson.M{"checkedUntil": checkedUntil, "nextRunAt": nextRunAt}
+	if sent {
+		set["lastSentAt"] = time.Now().UTC()
+	}
+	_, err := r.d.SavedSearches().UpdateOne(ctx, bson.M{"searchId": searchID}, bson.M{"$set": set})
+	return err
+}
+
+// ClaimSent отмечает вакансии отправленными и возвращает те, что пользователю еще не уходили
+func (r *SavedSearchRepo) ClaimSent(ctx context.Context, userID, searchID string, vacancyIDs []string) ([]string, error) {
+	now := time.Now().UTC()
+	var claimed []string
+	for _, id := range vacancyIDs {
+		_, err := r.d.AlertsSent().InsertOne(ctx, models.AlertSent{UserID: userID, VacancyID: id, SearchID: searchID, SentAt: now})
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return claimed, err
+		}
+		claimed = append(claimed, id)
+	}
+	return claimed, nil
+}
+
+// UnclaimSent снимает отметку, если доставить не удалось, чтобы попробовать снова
+func (r *SavedSearchRepo) UnclaimSent(ctx context.Context, userID string, vacancyIDs []string) error {
+	_, err := r.d.AlertsSent().DeleteMany(ctx, bson.M{"userId": userID, "vacancyId": bson.M{"$in": vacancyIDs}})
+	return err
+}
+
+func (r *SavedSearchRepo) ListSent(ctx context.Context, searchID string, limit int64) ([]models.AlertSent, error) {
+	opts := options.Find().SetSort(bson.M{"sentAt": -1}).SetLimit(limit)
+	cur, err := r.d.AlertsSent().Find(ctx, bson.M{"searchId": searchID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.AlertSent{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,202 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SchedulerRepo struct{ d *db.Database }
+
+func NewSchedulerRepo(d *db.Database) *SchedulerRepo { return &SchedulerRepo{d: d} }
+
+// Ensure создает запись задачи при первом запуске; расписание, измененное
+// в админке, и пауза сохраняются, обновляется только расписание по умолчанию
+func (r *SchedulerRepo) Ensure(ctx context.Context, name, defaultSchedule string, next time.Time) error {
+	now := time.Now().UTC()
+	_, err := r.d.ScheduledJobs().UpdateOne(ctx, bson.M{"name": name}, bson.M{
+		"$set":         bson.M{"defaultSchedule": defaultSchedule, "updatedAt": now},
+		"$setOnInsert": bson.M{"schedule": defaultSchedule, "paused": false, "nextRunAt": next, "createdAt": now},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *SchedulerRepo) Get(ctx context.Context, name string) (*models.ScheduledJob, error) {
+	var j models.ScheduledJob
+	err := r.d.ScheduledJobs().FindOne(ctx, bson.M{"name": name}).Decode(&j)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &j, err
+}
+
+func (r *SchedulerRepo) List(ctx context.Context) ([]models.ScheduledJob, error) {
+	cur, err := r.d.ScheduledJobs().Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.ScheduledJob{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Claim забирает запуск задачи, прочитанной как cur: срабатывает, только если
+// аренда свободна или истекла и запись не изменилась с момента чтения, —
+// из нескольких инстансов запуск получает один. Для запуска по расписанию
+// next — следующее время по cron; ручной запуск снимает запрос.
+// prevRunID — запуск, чья аренда истекла без завершения
+func (r *SchedulerRepo) Claim(ctx context.Context, cur *models.ScheduledJob, run *models.JobRun, next time.Time, lease time.Duration) (prevRunID string, ok bool, err error) {
+	now := run.StartedAt
+	filter := bson.M{
+		"name":        cur.Name,
+		"schedule":    cur.Schedule,
+		"lockedUntil": bson.M{"$not": bson.M{"$gt": now}},
+	}
+	set := bson.M{"runId": run.RunID, "lockedBy": run.Instance, "lockedUntil": now.Add(lease), "updatedAt": now}
+	update := bson.M{"$set": set}
+	if run.Trigger == models.JobTriggerManual {
+		filter["runRequestedAt"] = cur.RunRequestedAt
+		update["$unset"] = bson.M{"runRequestedAt": "", "runRequestedBy": ""}
+	}
+	if !next.IsZero() {
+		filter["paused"] = false
+		filter["nextRunAt"] = cur.NextRunAt
+		set["nextRunAt"] = next
+	}
+
+	var prev models.ScheduledJob
+	err = r.d.ScheduledJobs().FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.Before)).Decode(&prev)
+	if err == mongo.ErrNoDocuments {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := r.d.JobRuns().InsertOne(ctx, run); err != nil {
+		return prev.RunID, true, err
+	}
+	return prev.RunID, true, nil
+}
+
+// Extend продлевает аренду; false — запуск уже не владеет задачей
+func (r *SchedulerRepo) Extend(ctx context.Context, name, runID string, until time.Time) (bool, error) {
+	res, err := r.d.ScheduledJobs().UpdateOne(ctx, bson.M{"name": name, "runId": runID},
+		bson.M{"$set": bson.M{"lockedUntil": until}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// Finish записывает итог запуска и снимает аренду, если она еще у него
+func (r *SchedulerRepo) Finish(ctx context.Context, run *models.JobRun) error {
+	_, err := r.d.JobRuns().UpdateOne(ctx, bson.M{"runId": run.RunID}, bson.M{"$set": bson.M{
+		"status": run.Status, "error": run.Error, "finishedAt": run.FinishedAt, "durationMs": run.DurationMs,
+	}})
+	if err != nil {
+		return err
+	}
+	_, err = r.d.ScheduledJobs().UpdateOne(ctx, bson.M{"name": run.Job, "runId": run.RunID}, bson.M{
+		"$set": bson.M{
+			"lastRunAt": run.StartedAt, "lastStatus": run.Status, "lastError": run.Error,
+			"lastDurationMs": run.DurationMs, "updatedAt": time.Now().UTC(),
+		},
+		"$unset": bson.M{"runId": "", "lockedBy": "", "lockedUntil": ""},
+	})
+	return err
+}
+
+// Abandon помечает брошенный запуск
+func (r *SchedulerRepo) Abandon(ctx context.Context, runID string) error {
+	_, err := r.d.JobRuns().UpdateOne(ctx, bson.M{"runId": runID, "status": models.JobRunRunning},
+		bson.M{"$set": bson.M{"status": models.JobRunAbandoned}})
+	return err
+}
+
+// AcquireLease берет аренду name для holder до until: срабатывает, если аренда
+// свободна, истекла или уже у него. Запись создается при первом захвате, ключ — _id,
+// поэтому из нескольких инстансов аренду получает один
+func (r *SchedulerRepo) AcquireLease(ctx context.Context, name, holder string, until time.Time) (bool, error) {
+	now := time.Now().UTC()
+	_, err := r.d.Leases().UpdateOne(ctx,
+		bson.M{"_id": name, "$or": bson.A{bson.M{"until": bson.M{"$lte": now}}, bson.M{"holder": holder}}},
+		bson.M{"$set": bson.M{"holder": holder, "until": until, "acquiredAt": now}},
+		options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ExtendLease продлевает аренду; false — ее забрал другой инстанс
+func (r *SchedulerRepo) ExtendLease(ctx context.Context, name, holder string, until time.Time) (bool, error) {
+	res, err := r.d.Leases().UpdateOne(ctx, bson.M{"_id": name, "holder": holder},
+		bson.M{"$set": bson.M{"until": until}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// ReleaseLease отдает аренду, если она еще у holder
+func (r *SchedulerRepo) ReleaseLease(ctx context.Context, name, holder string) error {
+	_, err := r.d.Leases().UpdateOne(ctx, bson.M{"_id": name, "holder": holder},
+		bson.M{"$set": bson.M{"until": time.Now().UTC()}})
+	return err
+}
+
+func (r *SchedulerRepo) Runs(ctx context.Context, job string, limit int64) ([]models.JobRun, error) {
+	cur, err := r.d.JobRuns().Find(ctx, bson.M{"job": job},
+		options.Find().SetSort(bson.M{"startedAt": -1}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.JobRun{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RequestRun ставит ручной запуск; повторный запрос до начала запуска не копится
+func (r *SchedulerRepo) RequestRun(ctx context.Context, name, by string) (*models.ScheduledJob, error) {
+	now := time.Now().UTC()
+	return r.update(ctx, name, bson.M{"$set": bson.M{"runRequestedAt": now, "runRequestedBy": by, "updatedAt": now}})
+}
+
+// SetPaused ставит задачу на паузу или снимает с нее со следующим запуском next
+func (r *SchedulerRepo) SetPaused(ctx context.Context, name string, paused bool, next time.Time) (*models.ScheduledJob, error) {
+	set := bson.M{"paused": paused, "updatedAt": time.Now().UTC()}
+	if !paused {
+		set["nextRunAt"] = next
+	}
+	return r.update(ctx, name, bson.M{"$set": set})
+}
+
+func (r *SchedulerRepo) SetSchedule(ctx context.Context, name, schedule string, next time.Time) (*models.ScheduledJob, error) {
+	return r.update(ctx, name, bson.M{"$set": bson.M{"schedule": schedule, "nextRunAt": next, "updatedAt": time.Now().UTC()}})
+}
+
+func (r *SchedulerRepo) update(ctx context.Context, name string, update bson.M) (*models.ScheduledJob, error) {
+	var j models.ScheduledJob
+	err := r.d.ScheduledJobs().FindOneAndUpdate(ctx, bson.M{"name": name}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&j)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &j, err
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/oklog/ulid/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type SessionRepo struct{ d *db.Database }
@@ -48,3 +49,17 @@ func (r *SessionRepo) Revoke(ctx context.Context, sessionID string) error {
 	_, err := r.d.Sessions().UpdateOne(ctx, bson.M{"sessionId": sessionID}, bson.M{"$set": bson.M{"revoked": true}})
 	return err
 }
+
+// MarkActive отмечает, что пользователь входил или обновлял сессию сегодня (для DAU/MAU)
+func (r *SessionRepo) MarkActive(ctx context.Context, userID string) error {
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	_, err := r.d.UserActivity().UpdateOne(ctx,
+		bson.M{"userId": userID, "day": day.Format(DayLayout)},
+		bson.M{"$setOnInsert": bson.M{"date": day}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil // параллельный запрос уже отметил
+	}
+	return err
+}
@@ -0,0 +1,226 @@
+package repo
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"github.com/oklog/ulid/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SkillRepo struct{ d *db.Database }
+
+func NewSkillRepo(d *db.Database) *SkillRepo { return &SkillRepo{d: d} }
+
+// Create добавляет навык; занятый ключ написания дает ошибку дубликата
+func (r *SkillRepo) Create(ctx context.Context, s *models.Skill) error {
+	now := time.Now().UTC()
+	s.SkillID = ulid.Make().String()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	if s.Synonyms == nil {
+		s.Synonyms = []string{}
+	}
+	_, err := r.d.Skills().InsertOne(ctx, s)
+	return err
+}
+
+func (r *SkillRepo) GetByID(ctx context.Context, skillID string) (*models.Skill, error) {
+	var s models.Skill
+	err := r.d.Skills().FindOne(ctx, bson.M{"skillId": skillID}).Decode(&s)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &s, err
+}
+
+func (r *SkillRepo) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.Skill, error) {
+	cur, err := r.d.Skills().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.Skill{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// All возвращает весь справочник (для нормализации в памяти)
+func (r *SkillRepo) All(ctx context.Context) ([]models.Skill, error) {
+	return r.find(ctx, bson.M{}, nil)
+}
+
+// List — справочник для админки: популярные сначала; prefix — начало ключа написания
+func (r *SkillRepo) List(ctx context.Context, prefix, category string, limit, skip int64) ([]models.Skill, error) {
+	filter := bson.M{}
+	if prefix != "" {
+		filter["keys"] = bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}
+	}
+	if category != "" {
+		filter["category"] = category
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "usage", Value: -1}, {Key: "name", Value: 1}}).SetLimit(limit).SetSkip(skip)
+	return r.find(ctx, filter, opts)
+}
+
+func (r *SkillRepo) Update(ctx context.Context, skillID string, set bson.M) error {
+	set["updatedAt"] = time.Now().UTC()
+	_, err := r.d.Skills().UpdateOne(ctx, bson.M{"skillId": skillID}, bson.M{"$set": set})
+	return err
+}
+
+func (r *SkillRepo) Delete(ctx context.Context, skillIDs ...string) (int64, error) {
+	res, err := r.d.Skills().DeleteMany(ctx, bson.M{"skillId": bson.M{"$in": skillIDs}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// Restore возвращает удаленные навыки как были, с прежними ID
+func (r *SkillRepo) Restore(ctx context.Context, skills []models.Skill) error {
+	docs := make([]any, len(skills))
+	for i := range skills {
+		docs[i] = skills[i]
+	}
+	_, err := r.d.Skills().InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+// InsertIfAbsent добавляет навык, если ни одно из его написаний не занято
+func (r *SkillRepo) InsertIfAbsent(ctx context.Context, s *models.Skill) (bool, error) {
+	n, err := r.d.Skills().CountDocuments(ctx, bson.M{"keys": bson.M{"$in": s.Keys}})
+	if err != nil || n > 0 {
+		return false, err
+	}
+	if err := r.Create(ctx, s); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// SetUsage записывает пересчитанную популярность навыков
+func (r *SkillRepo) SetUsage(ctx context.Context, usage map[string]int64) error {
+	if len(usage) == 0 {
+		return nil
+	}
+	ops := make([]mongo.WriteModel, 0, len(usage))
+	for id, n := range usage {
+		ops = append(ops, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"skillId": id, "usage": bson.M{"$ne": n}}).
+			SetUpdate(bson.M{"$set": bson.M{"usage": n}}))
+	}
+	_, err := r.d.Skills().BulkWrite(ctx, ops, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// skillField — где хранятся теги и навыки: коллекция и поле-массив
+type skillField struct {
+	coll  *mongo.Collection
+	field string
+}
+
+func (r *SkillRepo) skillFields() []skillField {
+	return []skillField{
+		{r.d.Vacancies(), "tags"},
+		{r.d.Resumes(), "skills"},
+		{r.d.SavedSearches(), "tags"},
+	}
+}
+
+// TagCounts — сколько вакансий и резюме используют каждое написание тега
+func (r *SkillRepo) TagCounts(ctx context.Context) (map[string]int64, error) {
+	out := map[string]int64{}
+	for _, f := range r.skillFields()[:2] {
+		cur, err := f.coll.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{f.field + ".0": bson.M{"$exists": true}}}},
+			{{Key: "$unwind", Value: "$" + f.field}},
+			{{Key: "$group", Value: bson.M{"_id": "$" + f.field, "n": bson.M{"$sum": 1}}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		var rows []struct {
+			ID string `bson:"_id"`
+			N  int64  `bson:"n"`
+		}
+		err = cur.All(ctx, &rows)
+		cur.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			out[row.ID] += row.N
+		}
+	}
+	return out, nil
+}
+
+// Rewrite применяет fn к тегам вакансий, навыкам резюме и тегам сохраненных
+// поисков. values ограничивает документы теми, где есть одно из значений;
+// пустой — все документы с непустым списком. Возвращает число измененных.
+func (r *SkillRepo) Rewrite(ctx context.Context, values []string, fn func([]string) []string) (int64, error) {
+	var changed int64
+	for _, f := range r.skillFields() {
+		filter := bson.M{f.field + ".0": bson.M{"$exists": true}}
+		if len(values) > 0 {
+			filter = bson.M{f.field: bson.M{"$in": values}}
+		}
+		cur, err := f.coll.Find(ctx, filter, options.Find().SetProjection(bson.M{f.field: 1}))
+		if err != nil {
+			return changed, err
+		}
+		for cur.Next(ctx) {
+			var doc bson.M
+			if err := cur.Decode(&doc); err != nil {
+				cur.Close(ctx)
+				return changed, err
+			}
+			arr, _ := doc[f.field].(bson.A)
+			old := make([]string, 0, len(arr))
+			for _, v := range arr {
+				if s, ok := v.(string); ok {
+					old = append(old, s)
+				}
+			}
+			upd := fn(old)
+			if equalStrings(old, upd) {
+				continue
+			}
+			if _, err := f.coll.UpdateOne(ctx, bson.M{"_id": doc["_id"]}, bson.M{"$set": bson.M{f.field: upd}}); err != nil {
+				cur.Close(ctx)
+				return changed, err
+			}
+			changed++
+		}
+		err = cur.Err()
+		cur.Close(ctx)
+		if err != nil {
+			return changed, err
+		}
+	}
+	return changed, nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
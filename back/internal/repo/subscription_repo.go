@@ -10,6 +10,7 @@ import (
 	"github.com/oklog/ulid/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type SubscriptionRepo struct{ d *db.Database }
@@ -27,6 +28,26 @@ func (r *SubscriptionRepo) Create(ctx context.Context, s *models.Subscription) e
 	return err
 }
 
+// NextInvID выдает номер платежа (InvId) из счетчика: $inc атомарен, поэтому
+// одновременные оплаты получают разные номера
+func (r *SubscriptionRepo) NextInvID(ctx context.Context) (int64, error) {
+	return r.nextSeq(ctx, "invId")
+}
+
+// NextInvoiceNo выдает порядковый номер счета
+func (r *SubscriptionRepo) NextInvoiceNo(ctx context.Context) (int64, error) {
+	return r.nextSeq(ctx, "invoice")
+}
+
+func (r *SubscriptionRepo) nextSeq(ctx context.Context, name string) (int64, error) {
+	var c struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := r.d.Counters().FindOneAndUpdate(ctx, bson.M{"_id": name}, bson.M{"$inc": bson.M{"seq": int64(1)}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&c)
+	return c.Seq, err
+}
+
 func (r *SubscriptionRepo) FindByInvID(ctx context.Context, invID int64) (*models.Subscription, error) {
 	var s models.Subscription
 	err := r.d.Subscriptions().FindOne(ctx, bson.M{"invId": invID}).Decode(&s)
@@ -49,16 +70,253 @@ func (r *SubscriptionRepo) UpdateStatus(ctx context.Context, invID int64, status
 	return err
 }
 
+// Activate переводит ожидающий оплаты платеж в paid одной операцией и
+// возвращает обновленную подписку; nil — платеж уже обработан
+func (r *SubscriptionRepo) Activate(ctx context.Context, invID int64, startDate, endDate time.Time) (*models.Subscription, error) {
+	var s models.Subscription
+	err := r.d.Subscriptions().FindOneAndUpdate(ctx, bson.M{"invId": invID, "status": "pending"},
+		bson.M{"$set": bson.M{
+			"status":    "paid",
+			"startDate": startDate,
+			"endDate":   endDate,
+			"updatedAt": time.Now().UTC(),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&s)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &s, err
+}
+
+// CancelPending отменяет платеж, если он еще ждет оплаты; false — уже обработан
+func (r *SubscriptionRepo) CancelPending(ctx context.Context, invID int64) (bool, error) {
+	res, err := r.d.Subscriptions().UpdateOne(ctx, bson.M{"invId": invID, "status": "pending"},
+		bson.M{"$set": bson.M{"status": "cancelled", "updatedAt": time.Now().UTC()}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
 func (r *SubscriptionRepo) GetActiveByUserID(ctx context.Context, userID string) (*models.Subscription, error) {
 	var s models.Subscription
 	filter := bson.M{
 		"userId":  userID,
-		"status":  "paid",
+		"status":  bson.M{"$in": bson.A{"paid", "trial", "partially_refunded"}},
 		"endDate": bson.M{"$gt": time.Now().UTC()},
 	}
-	err := r.d.Subscriptions().FindOne(ctx, filter).Decode(&s)
+	err := r.d.Subscriptions().FindOne(ctx, filter, options.FindOne().SetSort(bson.M{"endDate": -1})).Decode(&s)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
 	return &s, err
 }
+
+func (r *SubscriptionRepo) GetByID(ctx context.Context, subscriptionID string) (*models.Subscription, error) {
+	var s models.Subscription
+	err := r.d.Subscriptions().FindOne(ctx, bson.M{"subscriptionId": subscriptionID}).Decode(&s)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &s, err
+}
+
+// SetExternal сохраняет провайдера и ID платежа на его стороне
+func (r *SubscriptionRepo) SetExternal(ctx context.Context, invID int64, provider, externalID string) error {
+	_, err := r.d.Subscriptions().UpdateOne(ctx, bson.M{"invId": invID}, bson.M{"$set": bson.M{
+		"provider":   provider,
+		"externalId": externalID,
+		"updatedAt":  time.Now().UTC(),
+	}})
+	return err
+}
+
+// ListByUserID возвращает все платежи пользователя, новые первые
+// DeleteTrial удаляет пробный период, который не удалось включить
+func (r *SubscriptionRepo) DeleteTrial(ctx context.Context, subscriptionID string) error {
+	_, err := r.d.Subscriptions().DeleteOne(ctx, bson.M{"subscriptionId": subscriptionID, "status": "trial"})
+	return err
+}
+
+// FlagReview отмечает платеж для проверки администратором
+func (r *SubscriptionRepo) FlagReview(ctx context.Context, subscriptionID, reason string) error {
+	_, err := r.d.Subscriptions().UpdateOne(ctx, bson.M{"subscriptionId": subscriptionID},
+		bson.M{"$set": bson.M{"review": reason, "updatedAt": time.Now().UTC()}})
+	return err
+}
+
+func (r *SubscriptionRepo) ListByUserID(ctx context.Context, userID string) ([]models.Subscription, error) {
+	cur, err := r.d.Subscriptions().Find(ctx, bson.M{"userId": userID}, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []models.Subscription
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// refundable — подписка оплачена, сумма возвратов не менялась с момента
+// чтения (prevRefunded) и другой возврат не идет
+func refundable(subscriptionID, prevRefunded string) bson.M {
+	filter := bson.M{
+		"subscriptionId": subscriptionID,
+		"status":         bson.M{"$in": bson.A{"paid", "partially_refunded"}},
+		"refundPending":  bson.M{"$in": bson.A{nil, ""}},
+		"refundedSum":    prevRefunded,
+	}
+	if prevRefunded == "" {
+		filter["refundedSum"] = bson.M{"$in": bson.A{nil, ""}}
+	}
+	return filter
+}
+
+// ReserveRefund резервирует сумму возврата до запроса к провайдеру;
+// false — подписка изменилась или по ней уже идет другой возврат
+func (r *SubscriptionRepo) ReserveRefund(ctx context.Context, subscriptionID, prevRefunded, amount string) (bool, error) {
+	now := time.Now().UTC()
+	res, err := r.d.Subscriptions().UpdateOne(ctx, refundable(subscriptionID, prevRefunded),
+		bson.M{"$set": bson.M{"refundPending": amount, "refundPendingAt": now, "updatedAt": now}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// ReleaseRefund снимает резерв, если провайдер не выполнил возврат
+func (r *SubscriptionRepo) ReleaseRefund(ctx context.Context, subscriptionID, amount string) error {
+	_, err := r.d.Subscriptions().UpdateOne(ctx, bson.M{"subscriptionId": subscriptionID, "refundPending": amount},
+		bson.M{"$unset": bson.M{"refundPending": "", "refundPendingAt": ""}, "$set": bson.M{"updatedAt": time.Now().UTC()}})
+	return err
+}
+
+// AddRefund записывает зарезервированный возврат и переводит подписку в
+// refunded/partially_refunded; полный возврат завершает подписку сейчас.
+// false — резерва нет или сумма возвратов изменилась
+func (r *SubscriptionRepo) AddRefund(ctx context.Context, subscriptionID, prevRefunded, status, refundedSum string, refund models.SubscriptionRefund) (bool, error) {
+	now := time.Now().UTC()
+	filter := refundable(subscriptionID, prevRefunded)
+	filter["refundPending"] = refund.Amount
+	set := bson.M{
+		"status":      status,
+		"refundedSum": refundedSum,
+		"updatedAt":   now,
+	}
+	if status == "refunded" {
+		set["endDate"] = now
+	}
+	res, err := r.d.Subscriptions().UpdateOne(ctx, filter, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"refundPending": "", "refundPendingAt": ""},
+		"$push":  bson.M{"refunds": refund},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// TrialReport считает выданные пробные периоды и сколько из этих пользователей потом оплатили подписку
+func (r *SubscriptionRepo) TrialReport(ctx context.Context) (started, converted int64, err error) {
+	isTrial := bson.M{"$eq": bson.A{"$status", "trial"}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": bson.M{"$in": bson.A{"trial", "paid", "refunded", "partially_refunded"}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$userId",
+			"trialAt":  bson.M{"$min": bson.M{"$cond": bson.A{isTrial, "$createdAt", nil}}},
+			"lastPaid": bson.M{"$max": bson.M{"$cond": bson.A{isTrial, nil, "$createdAt"}}},
+		}}},
+		{{Key: "$match", Value: bson.M{"trialAt": bson.M{"$ne": nil}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     nil,
+			"started": bson.M{"$sum": 1},
+			"converted": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{"$lastPaid", "$trialAt"}}, 1, 0,
+			}}},
+		}}},
+	}
+	cur, err := r.d.Subscriptions().Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cur.Close(ctx)
+	var rows []struct {
+		Started   int64 `bson:"started"`
+		Converted int64 `bson:"converted"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return 0, 0, err
+	}
+	if len(rows) == 0 {
+		return 0, 0, nil
+	}
+	return rows[0].Started, rows[0].Converted, nil
+}
+
+// paidStatuses — подписки, за которые пришли деньги
+var paidStatuses = bson.A{"paid", "refunded", "partially_refunded"}
+
+// Buckets считает платежи, выручку, возвраты и пробные периоды по срезам period начиная с from
+func (r *SubscriptionRepo) Buckets(ctx context.Context, period string, from time.Time) ([]BucketRow, error) {
+	isPaid := bson.M{"$in": bson.A{"$status", paidStatuses}}
+	sources := []func() ([]BucketRow, error){
+		func() ([]BucketRow, error) {
+			// Попытки оплаты: бесплатные по промокоду и пробные не в счет
+			return countBuckets(ctx, r.d.Subscriptions(), period, "createdAt", from,
+				bson.M{"status": bson.M{"$ne": "trial"}, "amount": bson.M{"$gt": 0}},
+				bson.M{
+					"payments":          bson.M{"$sum": 1},
+					"paymentsSucceeded": countIf(isPaid),
+					"paymentsFailed":    countIf(bson.M{"$eq": bson.A{"$status", "cancelled"}}),
+				})
+		},
+		func() ([]BucketRow, error) {
+			return countBuckets(ctx, r.d.Subscriptions(), period, "startDate", from,
+				bson.M{"status": bson.M{"$in": paidStatuses}},
+				bson.M{"paidSubscriptions": bson.M{"$sum": 1}, "revenue": bson.M{"$sum": "$amount"}})
+		},
+		func() ([]BucketRow, error) {
+			return countBuckets(ctx, r.d.Subscriptions(), period, "createdAt", from,
+				bson.M{"status": "trial"}, bson.M{"trialsStarted": bson.M{"$sum": 1}})
+		},
+		func() ([]BucketRow, error) {
+			return aggregateBuckets(ctx, r.d.Subscriptions(), mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"refunds.createdAt": bson.M{"$gte": from}}}},
+				{{Key: "$unwind", Value: "$refunds"}},
+				{{Key: "$match", Value: bson.M{"refunds.createdAt": bson.M{"$gte": from}}}},
+				{{Key: "$group", Value: bson.M{
+					"_id":      bucketOf(period, "refunds.createdAt"),
+					"refunded": bson.M{"$sum": bson.M{"$toDouble": "$refunds.amount"}},
+				}}},
+			})
+		},
+	}
+	var out []BucketRow
+	for _, src := range sources {
+		rows, err := src()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rows...)
+	}
+	return out, nil
+}
+
+// PaidPeriods — оплаченные подписки (кроме полностью возвращенных), которые
+// заканчиваются не раньше endAfter; для подсчета подписчиков и оттока
+func (r *SubscriptionRepo) PaidPeriods(ctx context.Context, endAfter time.Time) ([]models.Subscription, error) {
+	cur, err := r.d.Subscriptions().Find(ctx,
+		bson.M{"status": bson.M{"$in": bson.A{"paid", "partially_refunded"}}, "endDate": bson.M{"$gte": endAfter}},
+		options.Find().SetProjection(bson.M{"userId": 1, "startDate": 1, "endDate": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []models.Subscription
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TelegramRepo struct{ d *db.Database }
+
+func NewTelegramRepo(d *db.Database) *TelegramRepo { return &TelegramRepo{d: d} }
+
+// SaveCode заменяет прежние коды пользователя новым
+func (r *TelegramRepo) SaveCode(ctx context.Context, userID, codeHash string, expiresAt time.Time) error {
+	if _, err := r.d.TelegramCodes().DeleteMany(ctx, bson.M{"userId": userID}); err != nil {
+		return err
+	}
+	_, err := r.d.TelegramCodes().InsertOne(ctx, &models.TelegramCode{
+		CodeHash:  codeHash,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now().UTC(),
+	})
+	return err
+}
+
+// ClaimCode атомарно забирает действующий код; "" — не найден или истек
+func (r *TelegramRepo) ClaimCode(ctx context.Context, codeHash string) (string, error) {
+	var code models.TelegramCode
+	err := r.d.TelegramCodes().FindOneAndDelete(ctx,
+		bson.M{"codeHash": codeHash, "expiresAt": bson.M{"$gt": time.Now().UTC()}}).Decode(&code)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return code.UserID, nil
+}
+
+// Link привязывает чат к пользователю; прежняя привязка этого чата снимается
+func (r *TelegramRepo) Link(ctx context.Context, userID string, chatID int64, username string) error {
+	if _, err := r.d.TelegramLinks().DeleteMany(ctx, bson.M{"chatId": chatID, "userId": bson.M{"$ne": userID}}); err != nil {
+		return err
+	}
+	_, err := r.d.TelegramLinks().UpdateOne(ctx, bson.M{"userId": userID},
+		bson.M{"$set": bson.M{"chatId": chatID, "username": username, "linkedAt": time.Now().UTC()}},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *TelegramRepo) GetByUser(ctx context.Context, userID string) (*models.TelegramLink, error) {
+	var l models.TelegramLink
+	err := r.d.TelegramLinks().FindOne(ctx, bson.M{"userId": userID}).Decode(&l)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &l, err
+}
+
+func (r *TelegramRepo) GetByChat(ctx context.Context, chatID int64) (*models.TelegramLink, error) {
+	var l models.TelegramLink
+	err := r.d.TelegramLinks().FindOne(ctx, bson.M{"chatId": chatID}).Decode(&l)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &l, err
+}
+
+func (r *TelegramRepo) Unlink(ctx context.Context, userID string) (bool, error) {
+	res, err := r.d.TelegramLinks().DeleteOne(ctx, bson.M{"userId": userID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+func (r *TelegramRepo) UnlinkChat(ctx context.Context, chatID int64) (bool, error) {
+	res, err := r.d.TelegramLinks().DeleteOne(ctx, bson.M{"chatId": chatID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+func (r *TelegramRepo) SaveRef(ctx context.Context, chatID, messageID int64, applicationID string) error {
+	_, err := r.d.TelegramRefs().InsertOne(ctx, &models.TelegramMessageRef{
+		ChatID:        chatID,
+		MessageID:     messageID,
+		ApplicationID: applicationID,
+		CreatedAt:     time.Now().UTC(),
+	})
+	return err
+}
+
+func (r *TelegramRepo) FindRef(ctx context.Context, chatID, messageID int64) (*models.TelegramMessageRef, error) {
+	var ref models.TelegramMessageRef
+	err := r.d.TelegramRefs().FindOne(ctx, bson.M{"chatId": chatID, "messageId": messageID}).Decode(&ref)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &ref, err
+}
+
+// UpdateOffset возвращает номер следующего обновления getUpdates; он хранится
+// в записи аренды "telegram", чтобы новый владелец аренды продолжил с него
+func (r *TelegramRepo) UpdateOffset(ctx context.Context) (int64, error) {
+	var l struct {
+		Offset int64 `bson:"updateOffset"`
+	}
+	err := r.d.Leases().FindOne(ctx, bson.M{"_id": "telegram"}).Decode(&l)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	return l.Offset, err
+}
+
+// SaveUpdateOffset запоминает номер следующего обновления; номер только
+// растет, чтобы запоздавший прежний владелец аренды не откатил его назад.
+// Запись аренды создает AcquireLease, здесь она не создается.
+func (r *TelegramRepo) SaveUpdateOffset(ctx context.Context, offset int64) error {
+	_, err := r.d.Leases().UpdateOne(ctx, bson.M{"_id": "telegram"},
+		bson.M{"$max": bson.M{"updateOffset": offset}})
+	return err
+}
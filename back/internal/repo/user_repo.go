@@ -137,3 +137,21 @@ func (r *UserRepo) CountAll(ctx context.Context) (int64, error) {
 func (r *UserRepo) CountByType(ctx context.Context, typ string) (int64, error) {
 	return r.d.Users().CountDocuments(ctx, bson.M{"type": typ, "status.deleted": false})
 }
+
+// ClaimTrial атомарно отмечает, что пользователь взял пробный период; false — уже брал
+func (r *UserRepo) ClaimTrial(ctx context.Context, userID string) (bool, error) {
+	res, err := r.d.Users().UpdateOne(ctx,
+		bson.M{"userId": userID, "subscription.trialUsed": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"subscription.trialUsed": true, "updatedAt": time.Now().UTC()}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// UnclaimTrial возвращает пробный период, если выдать его не удалось
+func (r *UserRepo) UnclaimTrial(ctx context.Context, userID string) error {
+	_, err := r.d.Users().UpdateOne(ctx, bson.M{"userId": userID},
+		bson.M{"$set": bson.M{"subscription.trialUsed": false, "updatedAt": time.Now().UTC()}})
+	return err
+}
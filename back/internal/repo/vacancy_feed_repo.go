@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type VacancyFeedRepo struct{ d *db.Database }
+
+func NewVacancyFeedRepo(d *db.Database) *VacancyFeedRepo { return &VacancyFeedRepo{d: d} }
+
+func (r *VacancyFeedRepo) Get(ctx context.Context, companyID string) (*models.VacancyFeed, error) {
+	var f models.VacancyFeed
+	err := r.d.VacancyFeeds().FindOne(ctx, bson.M{"companyId": companyID}).Decode(&f)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &f, err
+}
+
+// Save создает или меняет фид компании; следующий опрос — сразу
+func (r *VacancyFeedRepo) Save(ctx context.Context, companyID, url, createdBy string, active bool) (*models.VacancyFeed, error) {
+	now := time.Now().UTC()
+	var f models.VacancyFeed
+	err := r.d.VacancyFeeds().FindOneAndUpdate(ctx, bson.M{"companyId": companyID},
+		bson.M{
+			"$set":         bson.M{"url": url, "active": active, "createdBy": createdBy, "nextRunAt": now, "updatedAt": now},
+			"$setOnInsert": bson.M{"createdAt": now},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&f)
+	return &f, err
+}
+
+func (r *VacancyFeedRepo) Delete(ctx context.Context, companyID string) (bool, error) {
+	res, err := r.d.VacancyFeeds().DeleteOne(ctx, bson.M{"companyId": companyID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+func (r *VacancyFeedRepo) ListDue(ctx context.Context, now time.Time, limit int64) ([]models.VacancyFeed, error) {
+	cur, err := r.d.VacancyFeeds().Find(ctx, bson.M{"active": true, "nextRunAt": bson.M{"$lte": now}},
+		options.Find().SetSort(bson.M{"nextRunAt": 1}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []models.VacancyFeed
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MarkRun сохраняет итог опроса; res == nil — фид не удалось получить или разобрать
+func (r *VacancyFeedRepo) MarkRun(ctx context.Context, companyID string, res *models.ImportResult, lastErr string, next time.Time) error {
+	now := time.Now().UTC()
+	set := bson.M{"lastRunAt": now, "lastError": lastErr, "nextRunAt": next, "updatedAt": now}
+	if res != nil {
+		set["lastResult"] = res
+	}
+	_, err := r.d.VacancyFeeds().UpdateOne(ctx, bson.M{"companyId": companyID}, bson.M{"$set": set})
+	return err
+}
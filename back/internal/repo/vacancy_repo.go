@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"regexp"
 	"time"
 
 	"unicorn-auth/internal/db"
@@ -23,19 +24,91 @@ type VacancyPublic struct {
 	ResponsesCount int64 `bson:"responsesCount" json:"responsesCount"`
 }
 
+// CountActiveByCompany считает вакансии в лимите тарифа: опубликованные,
+// приостановленные и черновики с запланированной публикацией
 func (r *VacancyRepo) CountActiveByCompany(ctx context.Context, companyID string) (int64, error) {
-	return r.d.Vacancies().CountDocuments(ctx, bson.M{"companyId": companyID, "status": "active"})
+	return r.d.Vacancies().CountDocuments(ctx, bson.M{"companyId": companyID, "$or": bson.A{
+		bson.M{"status": bson.M{"$in": models.VacancySlotStatuses}},
+		bson.M{"status": models.VacancyDraft, "publishAt": bson.M{"$ne": nil}},
+	}})
 }
 
+// Create сохраняет вакансию; без статуса она публикуется сразу на стандартный срок
 func (r *VacancyRepo) Create(ctx context.Context, v *models.Vacancy) error {
 	now := time.Now().UTC()
 	v.VacancyID = ulid.Make().String()
-	v.Status = "active"
+	if v.Status == "" {
+		v.Status = models.VacancyPublished
+	}
+	if v.Status == models.VacancyPublished {
+		v.PublishedAt = &now
+		if v.ExpiresAt == nil {
+			exp := now.Add(models.VacancyTerm)
+			v.ExpiresAt = &exp
+		}
+	}
 	v.CreatedAt, v.UpdatedAt = now, now
 	_, err := r.d.Vacancies().InsertOne(ctx, v)
 	return err
 }
 
+// Transition меняет вакансию, только если ее статус из from; nil — вакансии нет
+// или статус уже другой
+func (r *VacancyRepo) Transition(ctx context.Context, vacancyID, companyID string, from []string, set bson.M, unset ...string) (*models.Vacancy, error) {
+	set["updatedAt"] = time.Now().UTC()
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		u := bson.M{}
+		for _, f := range unset {
+			u[f] = ""
+		}
+		update["$unset"] = u
+	}
+	var v models.Vacancy
+	err := r.d.Vacancies().FindOneAndUpdate(ctx,
+		bson.M{"vacancyId": vacancyID, "companyId": companyID, "status": bson.M{"$in": from}},
+		update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&v)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &v, err
+}
+
+// ClaimScheduled публикует один черновик, время публикации которого наступило.
+// Срок считается от момента публикации, если не был задан явно.
+func (r *VacancyRepo) ClaimScheduled(ctx context.Context, now time.Time) (*models.Vacancy, error) {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"status":      models.VacancyPublished,
+			"publishedAt": now,
+			"expiresAt":   bson.M{"$ifNull": bson.A{"$expiresAt", now.Add(models.VacancyTerm)}},
+			"updatedAt":   now,
+		}}},
+		{{Key: "$unset", Value: "publishAt"}},
+	}
+	var v models.Vacancy
+	err := r.d.Vacancies().FindOneAndUpdate(ctx,
+		bson.M{"status": models.VacancyDraft, "publishAt": bson.M{"$lte": now}},
+		update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&v)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &v, err
+}
+
+// ClaimExpired переводит в expired одну вакансию с истекшим сроком
+func (r *VacancyRepo) ClaimExpired(ctx context.Context, now time.Time) (*models.Vacancy, error) {
+	var v models.Vacancy
+	err := r.d.Vacancies().FindOneAndUpdate(ctx,
+		bson.M{"status": bson.M{"$in": models.VacancySlotStatuses}, "expiresAt": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"status": models.VacancyExpired, "closedAt": now, "updatedAt": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&v)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &v, err
+}
+
 func (r *VacancyRepo) GetByID(ctx context.Context, vacancyID string) (*models.Vacancy, error) {
 	var v models.Vacancy
 	err := r.d.Vacancies().FindOne(ctx, bson.M{"vacancyId": vacancyID}).Decode(&v)
@@ -45,6 +118,16 @@ func (r *VacancyRepo) GetByID(ctx context.Context, vacancyID string) (*models.Va
 	return &v, err
 }
 
+// GetByExternalID ищет вакансию организации по идентификатору из HR-системы
+func (r *VacancyRepo) GetByExternalID(ctx context.Context, companyID, externalID string) (*models.Vacancy, error) {
+	var v models.Vacancy
+	err := r.d.Vacancies().FindOne(ctx, bson.M{"companyId": companyID, "externalId": externalID}).Decode(&v)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &v, err
+}
+
 func (r *VacancyRepo) Update(ctx context.Context, vacancyID, companyID string, set bson.M) error {
 	set["updatedAt"] = time.Now().UTC()
 	_, err := r.d.Vacancies().UpdateOne(ctx, bson.M{"vacancyId": vacancyID, "companyId": companyID}, bson.M{"$set": set})
@@ -57,14 +140,12 @@ func (r *VacancyRepo) Delete(ctx context.Context, vacancyID, companyID string) e
 }
 
 func (r *VacancyRepo) ListPublic(ctx context.Context, limit, skip int64) ([]VacancyPublic, error) {
-	cutoff := time.Now().UTC().Add(-30 * 24 * time.Hour)
-
 	pipeline := mongo.Pipeline{
-		bson.D{{Key: "$match", Value: bson.M{"status": "active", "createdAt": bson.M{"$gte": cutoff}}}},
-		// Сортировка: сначала премиум (isPremium: true), потом по дате создания
+		bson.D{{Key: "$match", Value: VacancyFilter{}.bson()}},
+		// Сортировка: сначала премиум (isPremium: true), потом по дате публикации
 		bson.D{{Key: "$sort", Value: bson.D{
-			{Key: "isPremium", Value: -1}, // -1 = true первые
-			{Key: "createdAt", Value: -1}, // новые первые
+			{Key: "isPremium", Value: -1},   // -1 = true первые
+			{Key: "publishedAt", Value: -1}, // новые первые
 		}}},
 		bson.D{{Key: "$skip", Value: skip}},
 		bson.D{{Key: "$limit", Value: limit}},
@@ -100,20 +181,48 @@ func (r *VacancyRepo) ListPublic(ctx context.Context, limit, skip int64) ([]Vaca
 	return out, nil
 }
 
-// CountActive возвращает количество активных вакансий (статус "active")
+// CountActive возвращает количество опубликованных вакансий
 func (r *VacancyRepo) CountActive(ctx context.Context) (int64, error) {
-	return r.d.Vacancies().CountDocuments(ctx, bson.M{"status": "active"})
+	return r.d.Vacancies().CountDocuments(ctx, VacancyFilter{}.bson())
 }
 
-// UpdateAllByCompanyID обновляет все вакансии компании
+// DistinctTags возвращает все теги, встречающиеся в вакансиях
+func (r *VacancyRepo) DistinctTags(ctx context.Context) ([]string, error) {
+	vals, err := r.d.Vacancies().Distinct(ctx, "tags", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(vals))
+	for _, v := range vals {
+		if s, ok := v.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// UpdateAllByCompanyID обновляет все незакрытые вакансии компании
 func (r *VacancyRepo) UpdateAllByCompanyID(ctx context.Context, companyID string, set bson.M) error {
 	set["updatedAt"] = time.Now().UTC()
 	_, err := r.d.Vacancies().UpdateMany(ctx,
-		bson.M{"companyId": companyID, "status": "active"},
+		bson.M{"companyId": companyID, "status": bson.M{"$nin": bson.A{models.VacancyClosed, models.VacancyExpired}}},
 		bson.M{"$set": set})
 	return err
 }
 
+// MoveToOrg переносит вакансии аккаунта в организацию, в которую он вступил.
+// externalId снимается: ключи импорта принадлежали фиду прежнего аккаунта
+func (r *VacancyRepo) MoveToOrg(ctx context.Context, fromID, orgID string, verified bool) (int64, error) {
+	res, err := r.d.Vacancies().UpdateMany(ctx, bson.M{"companyId": fromID}, bson.M{
+		"$set":   bson.M{"companyId": orgID, "companyVerified": verified, "updatedAt": time.Now().UTC()},
+		"$unset": bson.M{"externalId": ""},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
 // ListByCompanyID возвращает все вакансии компании
 func (r *VacancyRepo) ListByCompanyID(ctx context.Context, companyID string) ([]models.Vacancy, error) {
 	cur, err := r.d.Vacancies().Find(
@@ -132,3 +241,73 @@ func (r *VacancyRepo) ListByCompanyID(ctx context.Context, companyID string) ([]
 	}
 	return out, nil
 }
+
+// SetCompanyVerified обновляет копию значка проверки во всех вакансиях компании
+func (r *VacancyRepo) SetCompanyVerified(ctx context.Context, companyID string, verified bool) error {
+	_, err := r.d.Vacancies().UpdateMany(ctx, bson.M{"companyId": companyID},
+		bson.M{"$set": bson.M{"companyVerified": verified}})
+	return err
+}
+
+// VacancyFilter — условия поиска вакансий; пустые поля не ограничивают выборку
+type VacancyFilter struct {
+	Query     string   // подстрока в названии или описании
+	Tags      []string // хотя бы один из тегов
+	Location  string   // точное совпадение без учета регистра
+	SalaryMin int64    // верхняя граница вилки не ниже
+}
+
+// bson — опубликованные вакансии с неистекшим сроком (фоновый переход в expired
+// идет раз в минуту, поэтому срок проверяется и здесь)
+func (f VacancyFilter) bson() bson.M {
+	filter := bson.M{"status": models.VacancyPublished, "expiresAt": bson.M{"$gt": time.Now().UTC()}}
+	if f.Query != "" {
+		q := bson.M{"$regex": regexp.QuoteMeta(f.Query), "$options": "i"}
+		filter["$or"] = bson.A{bson.M{"title": q}, bson.M{"description": q}}
+	}
+	if len(f.Tags) > 0 {
+		filter["tags"] = bson.M{"$in": f.Tags}
+	}
+	if f.Location != "" {
+		filter["location"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.Location) + "$", "$options": "i"}
+	}
+	if f.SalaryMin > 0 {
+		filter["$and"] = bson.A{bson.M{"$or": bson.A{
+			bson.M{"salaryTo": bson.M{"$gte": f.SalaryMin}},
+			bson.M{"salaryFrom": bson.M{"$gte": f.SalaryMin}},
+		}}}
+	}
+	return filter
+}
+
+// FindPublishedBetween ищет открытые вакансии по фильтру, опубликованные в (after, before]
+func (r *VacancyRepo) FindPublishedBetween(ctx context.Context, f VacancyFilter, after, before time.Time, limit int64) ([]models.Vacancy, error) {
+	filter := f.bson()
+	filter["publishedAt"] = bson.M{"$gt": after, "$lte": before}
+	opts := options.Find().SetSort(bson.D{{Key: "isPremium", Value: -1}, {Key: "publishedAt", Value: -1}}).SetLimit(limit)
+	cur, err := r.d.Vacancies().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []models.Vacancy
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListActive — открытые вакансии по фильтру, недавно опубликованные первыми
+func (r *VacancyRepo) ListActive(ctx context.Context, f VacancyFilter, limit int64) ([]models.Vacancy, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "publishedAt", Value: -1}}).SetLimit(limit)
+	cur, err := r.d.Vacancies().Find(ctx, f.bson(), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []models.Vacancy
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
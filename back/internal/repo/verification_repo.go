@@ -0,0 +1,140 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"github.com/oklog/ulid/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type VerificationRepo struct{ d *db.Database }
+
+func NewVerificationRepo(d *db.Database) *VerificationRepo { return &VerificationRepo{d: d} }
+
+func (r *VerificationRepo) Create(ctx context.Context, v *models.CompanyVerification) error {
+	now := time.Now().UTC()
+	v.VerificationID = ulid.Make().String()
+	v.Status = "pending"
+	v.CreatedAt = now
+	v.UpdatedAt = now
+	_, err := r.d.CompanyVerifications().InsertOne(ctx, v)
+	return err
+}
+
+func (r *VerificationRepo) GetByID(ctx context.Context, verificationID string) (*models.CompanyVerification, error) {
+	var v models.CompanyVerification
+	err := r.d.CompanyVerifications().FindOne(ctx, bson.M{"verificationId": verificationID}).Decode(&v)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &v, err
+}
+
+// FindPending возвращает незавершенную заявку компании тем же способом (и на тот же домен)
+func (r *VerificationRepo) FindPending(ctx context.Context, companyID, method, domain string) (*models.CompanyVerification, error) {
+	filter := bson.M{"companyId": companyID, "method": method, "status": "pending"}
+	if domain != "" {
+		filter["domain"] = domain
+	}
+	var v models.CompanyVerification
+	err := r.d.CompanyVerifications().FindOne(ctx, filter).Decode(&v)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &v, err
+}
+
+func (r *VerificationRepo) ListByCompany(ctx context.Context, companyID string, limit int64) ([]models.CompanyVerification, error) {
+	opts := options.Find().SetSort(bson.M{"createdAt": -1}).SetLimit(limit)
+	cur, err := r.d.CompanyVerifications().Find(ctx, bson.M{"companyId": companyID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.CompanyVerification{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List — очередь заявок для администратора, старые сверху
+func (r *VerificationRepo) List(ctx context.Context, status, method string, limit, skip int64) ([]models.CompanyVerification, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	if method != "" {
+		filter["method"] = method
+	}
+	opts := options.Find().SetSort(bson.M{"createdAt": 1}).SetLimit(limit).SetSkip(skip)
+	cur, err := r.d.CompanyVerifications().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.CompanyVerification{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ClaimCheck атомарно отмечает начало проверки домена, если прошлая была
+// не позже чем interval назад; nil — проверять рано или заявка уже не pending
+func (r *VerificationRepo) ClaimCheck(ctx context.Context, verificationID string, interval time.Duration) (*models.CompanyVerification, error) {
+	now := time.Now().UTC()
+	var v models.CompanyVerification
+	err := r.d.CompanyVerifications().FindOneAndUpdate(ctx,
+		bson.M{
+			"verificationId": verificationID,
+			"status":         "pending",
+			"$or": bson.A{
+				bson.M{"lastCheckAt": bson.M{"$exists": false}},
+				bson.M{"lastCheckAt": bson.M{"$lte": now.Add(-interval)}},
+			},
+		},
+		bson.M{"$set": bson.M{"lastCheckAt": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&v)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// MarkChecked записывает результат автоматической проверки домена
+func (r *VerificationRepo) MarkChecked(ctx context.Context, verificationID string, passed bool, lastErr string) error {
+	now := time.Now().UTC()
+	set := bson.M{"lastCheckAt": now, "lastError": lastErr, "updatedAt": now}
+	if passed {
+		set["status"] = "verified"
+	}
+	_, err := r.d.CompanyVerifications().UpdateOne(ctx,
+		bson.M{"verificationId": verificationID, "status": "pending"}, bson.M{"$set": set})
+	return err
+}
+
+// Review фиксирует решение администратора; только для заявок в статусе pending
+func (r *VerificationRepo) Review(ctx context.Context, verificationID, status, adminID, note string) (bool, error) {
+	res, err := r.d.CompanyVerifications().UpdateOne(ctx,
+		bson.M{"verificationId": verificationID, "status": "pending"},
+		bson.M{"$set": bson.M{
+			"status":     status,
+			"reviewedBy": adminID,
+			"reviewNote": note,
+			"updatedAt":  time.Now().UTC(),
+		}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
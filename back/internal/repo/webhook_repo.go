@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+
+	"github.com/oklog/ulid/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Сколько последних попыток хранить в журнале доставки
+const webhookAttemptLogSize = 10
+
+type WebhookRepo struct{ d *db.Database }
+
+func NewWebhookRepo(d *db.Database) *WebhookRepo { return &WebhookRepo{d: d} }
+
+func (r *WebhookRepo) Create(ctx context.Context, w *models.Webhook) error {
+	now := time.Now().UTC()
+	w.WebhookID = ulid.Make().String()
+	w.CreatedAt = now
+	w.UpdatedAt = now
+	_, err := r.d.Webhooks().InsertOne(ctx, w)
+	return err
+}
+
+func (r *WebhookRepo) GetByID(ctx context.Context, webhookID, companyID string) (*models.Webhook, error) {
+	var w models.Webhook
+	err := r.d.Webhooks().FindOne(ctx, bson.M{"webhookId": webhookID, "companyId": companyID}).Decode(&w)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &w, err
+}
+
+func (r *WebhookRepo) ListByCompany(ctx context.Context, companyID string) ([]models.Webhook, error) {
+	cur, err := r.d.Webhooks().Find(ctx, bson.M{"companyId": companyID}, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.Webhook{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *WebhookRepo) CountByCompany(ctx context.Context, companyID string) (int64, error) {
+	return r.d.Webhooks().CountDocuments(ctx, bson.M{"companyId": companyID})
+}
+
+// ListSubscribed — активные вебхуки компании, подписанные на тип события
+func (r *WebhookRepo) ListSubscribed(ctx context.Context, companyID, eventType string) ([]models.Webhook, error) {
+	cur, err := r.d.Webhooks().Find(ctx, bson.M{"companyId": companyID, "active": true, "events": eventType})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []models.Webhook
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *WebhookRepo) Update(ctx context.Context, webhookID, companyID string, set bson.M) (bool, error) {
+	set["updatedAt"] = time.Now().UTC()
+	res, err := r.d.Webhooks().UpdateOne(ctx, bson.M{"webhookId": webhookID, "companyId": companyID}, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// Delete удаляет вебхук; неотправленные события по нему закрываются как failed
+func (r *WebhookRepo) Delete(ctx context.Context, webhookID, companyID string) (bool, error) {
+	res, err := r.d.Webhooks().DeleteOne(ctx, bson.M{"webhookId": webhookID, "companyId": companyID})
+	if err != nil || res.DeletedCount == 0 {
+		return false, err
+	}
+	_, err = r.d.WebhookDeliveries().UpdateMany(ctx,
+		bson.M{"webhookId": webhookID, "status": bson.M{"$in": []string{models.WebhookPending, models.WebhookDelivering}}},
+		bson.M{"$set": bson.M{"status": models.WebhookFailed, "updatedAt": time.Now().UTC()}})
+	return true, err
+}
+
+func (r *WebhookRepo) CreateDelivery(ctx context.Context, d *models.WebhookDelivery) error {
+	now := time.Now().UTC()
+	d.DeliveryID = ulid.Make().String()
+	d.Status = models.WebhookPending
+	d.Attempts = 0
+	d.AttemptLog = nil
+	d.NextAttemptAt = now
+	d.CreatedAt = now
+	d.UpdatedAt = now
+	_, err := r.d.WebhookDeliveries().InsertOne(ctx, d)
+	return err
+}
+
+// ClaimDue забирает одну доставку, время которой пришло. Зависшие в delivering
+// (воркер упал посреди отправки) забираются снова после истечения аренды.
+func (r *WebhookRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	err := r.d.WebhookDeliveries().FindOneAndUpdate(ctx,
+		bson.M{"$or": []bson.M{
+			{"status": models.WebhookPending, "nextAttemptAt": bson.M{"$lte": now}},
+			{"status": models.WebhookDelivering, "lockedUntil": bson.M{"$lte": now}},
+		}},
+		bson.M{"$set": bson.M{"status": models.WebhookDelivering, "lockedUntil": now.Add(lease), "updatedAt": now}},
+		options.FindOneAndUpdate().SetSort(bson.M{"nextAttemptAt": 1}).SetReturnDocument(options.After)).Decode(&d)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &d, err
+}
+
+// FinishAttempt записывает попытку и переводит доставку в status;
+// для pending next — время следующей попытки
+func (r *WebhookRepo) FinishAttempt(ctx context.Context, deliveryID string, a models.WebhookAttempt, status string, next time.Time) error {
+	set := bson.M{"status": status, "nextAttemptAt": next, "updatedAt": a.At}
+	if status == models.WebhookDelivered {
+		set["deliveredAt"] = a.At
+	}
+	_, err := r.d.WebhookDeliveries().UpdateOne(ctx, bson.M{"deliveryId": deliveryID}, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"lockedUntil": ""},
+		"$inc":   bson.M{"attempts": 1},
+		"$push":  bson.M{"attemptLog": bson.M{"$each": []models.WebhookAttempt{a}, "$slice": -webhookAttemptLogSize}},
+	})
+	return err
+}
+
+// Fail закрывает доставку без попытки (например, вебхук отключен)
+func (r *WebhookRepo) Fail(ctx context.Context, deliveryID string) error {
+	_, err := r.d.WebhookDeliveries().UpdateOne(ctx, bson.M{"deliveryId": deliveryID},
+		bson.M{"$set": bson.M{"status": models.WebhookFailed, "updatedAt": time.Now().UTC()}, "$unset": bson.M{"lockedUntil": ""}})
+	return err
+}
+
+func (r *WebhookRepo) GetDelivery(ctx context.Context, deliveryID, webhookID, companyID string) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	err := r.d.WebhookDeliveries().FindOne(ctx,
+		bson.M{"deliveryId": deliveryID, "webhookId": webhookID, "companyId": companyID}).Decode(&d)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return &d, err
+}
+
+// ListDeliveries — журнал доставок вебхука, новые сверху; тело события не возвращается
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, webhookID, companyID, status string, limit, skip int64) ([]models.WebhookDelivery, error) {
+	filter := bson.M{"webhookId": webhookID, "companyId": companyID}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.M{"createdAt": -1}).SetLimit(limit).SetSkip(skip).
+		SetProjection(bson.M{"payload": 0})
+	cur, err := r.d.WebhookDeliveries().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	out := []models.WebhookDelivery{}
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
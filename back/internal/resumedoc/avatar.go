@@ -0,0 +1,49 @@
+package resumedoc
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // аватары бывают в WebP
+)
+
+// avatarSide — сторона квадратного фото в пикселях; больше для печати не нужно
+const avatarSide = 400
+
+// thumbnail обрезает фото до квадрата по центру, уменьшает и кодирует в JPEG.
+// Нераспознанное изображение — nil: документ соберется без фото.
+func thumbnail(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	b := src.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	if side == 0 {
+		return nil
+	}
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2))
+	out := side
+	if out > avatarSide {
+		out = avatarSide
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, out, out))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
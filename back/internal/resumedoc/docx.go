@@ -0,0 +1,299 @@
+package resumedoc
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// docxStyle — шрифт и цвета шаблона в DOCX
+type docxStyle struct {
+	font   string
+	size   int // кегль основного текста в полупунктах
+	accent string
+}
+
+var docxStyles = map[string]docxStyle{
+	TemplateClassic: {font: "Cambria", size: 21, accent: "1F2937"},
+	TemplateModern:  {font: "Calibri", size: 21, accent: strings.TrimPrefix(accent, "#")},
+	TemplateCompact: {font: "Arial", size: 17, accent: "374151"},
+}
+
+const (
+	nsW   = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	nsR   = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsWP  = "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+	nsA   = "http://schemas.openxmlformats.org/drawingml/2006/main"
+	nsPic = "http://schemas.openxmlformats.org/drawingml/2006/picture"
+
+	emuPerPt = 12700
+)
+
+// DOCX рендерит резюме в Word по шаблону
+func DOCX(in Input, template string) ([]byte, error) {
+	if !ValidTemplate(template) {
+		template = TemplateClassic
+	}
+	st := docxStyles[template]
+	photo := template != TemplateCompact && len(in.Avatar) > 0
+
+	var body strings.Builder
+	switch template {
+	case TemplateModern:
+		modernDOCX(&body, in, photo)
+	case TemplateCompact:
+		compactDOCX(&body, in)
+	default:
+		classicDOCX(&body, in, photo)
+	}
+	fmt.Fprintf(&body, `<w:p><w:pPr><w:spacing w:before="240"/></w:pPr>%s</w:p>`, run(in.footer(), runOpts{size: 14, color: "9CA3AF"}))
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(contentTypes)},
+		{"_rels/.rels", []byte(rootRels)},
+		{"docProps/core.xml", []byte(coreProps(in))},
+		{"word/styles.xml", []byte(styles(st))},
+		{"word/_rels/document.xml.rels", []byte(documentRels(photo))},
+		{"word/document.xml", []byte(document(body.String()))},
+	}
+	if photo {
+		files = append(files, struct {
+			name string
+			data []byte
+		}{"word/media/avatar.jpg", in.Avatar})
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func classicDOCX(b *strings.Builder, in Input, photo bool) {
+	if photo {
+		fmt.Fprintf(b, `<w:p><w:pPr><w:jc w:val="right"/></w:pPr>%s</w:p>`, drawing(90))
+	}
+	if in.Name != "" {
+		para(b, "Title", in.Name)
+	}
+	para(b, "Subtitle", in.Title)
+	if in.Location != "" {
+		para(b, "", in.Location)
+	}
+	para(b, "Heading1", "О себе")
+	text(b, in.About)
+	if skills := in.skills(); len(skills) > 0 {
+		para(b, "Heading1", "Навыки")
+		para(b, "", strings.Join(skills, ", "))
+	}
+	if links := in.links(); len(links) > 0 {
+		para(b, "Heading1", "Ссылки")
+		for _, l := range links {
+			para(b, "", "• "+l)
+		}
+	}
+}
+
+func compactDOCX(b *strings.Builder, in Input) {
+	head := in.Title
+	if in.Name != "" {
+		head = in.Name + " · " + in.Title
+	}
+	para(b, "Title", head)
+	meta := in.links()
+	if in.Location != "" {
+		meta = append([]string{in.Location}, meta...)
+	}
+	if len(meta) > 0 {
+		para(b, "", strings.Join(meta, "  ·  "))
+	}
+	if skills := in.skills(); len(skills) > 0 {
+		fmt.Fprintf(b, `<w:p>%s%s</w:p>`, run("Навыки: ", runOpts{bold: true}), run(strings.Join(skills, ", "), runOpts{}))
+	}
+	para(b, "Heading1", "О себе")
+	text(b, in.About)
+}
+
+// modernDOCX — таблица из двух колонок: слева фото, навыки и ссылки, справа текст
+func modernDOCX(b *strings.Builder, in Input, photo bool) {
+	const sideW, mainW = 3200, 6438 // твипы; вместе — ширина текста A4 с полями
+	fmt.Fprintf(b, `<w:tbl><w:tblPr><w:tblW w:w="%d" w:type="dxa"/><w:tblLayout w:type="fixed"/>`+
+		`<w:tblBorders><w:top w:val="single" w:sz="24" w:color="%s"/></w:tblBorders>`+
+		`<w:tblCellMar><w:top w:w="160" w:type="dxa"/><w:left w:w="160" w:type="dxa"/><w:right w:w="160" w:type="dxa"/></w:tblCellMar></w:tblPr>`+
+		`<w:tblGrid><w:gridCol w:w="%d"/><w:gridCol w:w="%d"/></w:tblGrid><w:tr>`,
+		sideW+mainW, docxStyles[TemplateModern].accent, sideW, mainW)
+
+	fmt.Fprintf(b, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/><w:shd w:val="clear" w:color="auto" w:fill="F2F2F2"/></w:tcPr>`, sideW)
+	if photo {
+		fmt.Fprintf(b, `<w:p><w:pPr><w:jc w:val="center"/></w:pPr>%s</w:p>`, drawing(110))
+	}
+	if in.Name != "" {
+		fmt.Fprintf(b, `<w:p>%s</w:p>`, run(in.Name, runOpts{bold: true, size: 28}))
+	}
+	if in.Location != "" {
+		para(b, "", in.Location)
+	}
+	sideList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(b, `<w:p><w:pPr><w:spacing w:before="200"/></w:pPr>%s</w:p>`, run(title, runOpts{bold: true, size: 18}))
+		for _, it := range items {
+			fmt.Fprintf(b, `<w:p>%s</w:p>`, run(it, runOpts{size: 18}))
+		}
+	}
+	sideList("НАВЫКИ", in.skills())
+	sideList("ССЫЛКИ", in.links())
+	b.WriteString(`</w:tc>`)
+
+	fmt.Fprintf(b, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/></w:tcPr>`, mainW)
+	para(b, "Title", in.Title)
+	para(b, "Heading1", "О себе")
+	text(b, in.About)
+	b.WriteString(`</w:tc></w:tr></w:tbl>`)
+}
+
+type runOpts struct {
+	bold  bool
+	size  int // полупункты; 0 — по стилю
+	color string
+}
+
+func run(s string, o runOpts) string {
+	var props strings.Builder
+	if o.bold {
+		props.WriteString(`<w:b/>`)
+	}
+	if o.color != "" {
+		fmt.Fprintf(&props, `<w:color w:val="%s"/>`, o.color)
+	}
+	if o.size > 0 {
+		fmt.Fprintf(&props, `<w:sz w:val="%d"/>`, o.size)
+	}
+	rpr := ""
+	if props.Len() > 0 {
+		rpr = "<w:rPr>" + props.String() + "</w:rPr>"
+	}
+	return fmt.Sprintf(`<w:r>%s<w:t xml:space="preserve">%s</w:t></w:r>`, rpr, esc(s))
+}
+
+func para(b *strings.Builder, style, s string) {
+	b.WriteString(`<w:p>`)
+	if style != "" {
+		fmt.Fprintf(b, `<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, style)
+	}
+	b.WriteString(run(s, runOpts{}))
+	b.WriteString(`</w:p>`)
+}
+
+// text — многострочный текст: пустая строка разделяет абзацы, перевод строки — <w:br/>
+func text(b *strings.Builder, s string) {
+	for _, p := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n\n") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		b.WriteString(`<w:p>`)
+		for i, line := range strings.Split(p, "\n") {
+			if i > 0 {
+				b.WriteString(`<w:r><w:br/></w:r>`)
+			}
+			b.WriteString(run(line, runOpts{}))
+		}
+		b.WriteString(`</w:p>`)
+	}
+}
+
+// drawing — фото из word/media/avatar.jpg, квадрат со стороной side пунктов
+func drawing(side int) string {
+	emu := side * emuPerPt
+	return fmt.Sprintf(`<w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0">`+
+		`<wp:extent cx="%[1]d" cy="%[1]d"/><wp:docPr id="1" name="avatar"/>`+
+		`<a:graphic xmlns:a="%[2]s"><a:graphicData uri="%[3]s"><pic:pic xmlns:pic="%[3]s">`+
+		`<pic:nvPicPr><pic:cNvPr id="1" name="avatar.jpg"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="rIdAvatar"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%[1]d" cy="%[1]d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r>`, emu, nsA, nsPic)
+}
+
+func esc(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func document(body string) string {
+	// A4, поля 2 см
+	return xml.Header + `<w:document xmlns:w="` + nsW + `" xmlns:r="` + nsR + `" xmlns:wp="` + nsWP + `"><w:body>` +
+		body +
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="567" w:footer="567" w:gutter="0"/></w:sectPr>` +
+		`</w:body></w:document>`
+}
+
+func styles(st docxStyle) string {
+	return fmt.Sprintf(xml.Header+`<w:styles xmlns:w="%[1]s">`+
+		`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="%[2]s" w:hAnsi="%[2]s" w:cs="%[2]s" w:eastAsia="%[2]s"/><w:sz w:val="%[3]d"/><w:lang w:val="ru-RU"/></w:rPr></w:rPrDefault>`+
+		`<w:pPrDefault><w:pPr><w:spacing w:after="60" w:line="276" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>`+
+		`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>`+
+		`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:rPr><w:b/><w:color w:val="%[4]s"/><w:sz w:val="%[5]d"/></w:rPr></w:style>`+
+		`<w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/><w:rPr><w:sz w:val="%[6]d"/></w:rPr></w:style>`+
+		`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="240" w:after="80"/>`+
+		`<w:pBdr><w:bottom w:val="single" w:sz="4" w:space="1" w:color="%[4]s"/></w:pBdr><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:color w:val="%[4]s"/><w:sz w:val="%[6]d"/></w:rPr></w:style>`+
+		`</w:styles>`, nsW, st.font, st.size, st.accent, st.size*2, st.size+5)
+}
+
+const contentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Default Extension="jpg" ContentType="image/jpeg"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+	`</Types>`
+
+const rootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+func documentRels(photo bool) string {
+	s := xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`
+	if photo {
+		s += `<Relationship Id="rIdAvatar" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/avatar.jpg"/>`
+	}
+	return s + `</Relationships>`
+}
+
+func coreProps(in Input) string {
+	title := in.Title
+	if in.Name != "" {
+		title = in.Name + " — " + in.Title
+	}
+	modified := in.UpdatedAt
+	if modified.IsZero() {
+		modified = time.Now()
+	}
+	return xml.Header + `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+		`<dc:title>` + esc(title) + `</dc:title><dc:creator>` + esc(in.Name) + `</dc:creator>` +
+		`<dcterms:modified xsi:type="dcterms:W3CDTF">` + modified.UTC().Format(time.RFC3339) + `</dcterms:modified>` +
+		`</cp:coreProperties>`
+}
@@ -0,0 +1,219 @@
+package resumedoc
+
+import (
+	"fmt"
+	"strings"
+
+	"unicorn-auth/internal/pdf"
+)
+
+const (
+	margin  = 50.0
+	bottom  = pdf.PageHeight - 60
+	accent  = "#3B5BDB"
+	sidebar = 180.0 // ширина боковой колонки шаблона modern
+)
+
+// column — колонка текста с переносом на новую страницу
+type column struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	x, w float64
+	y    float64
+	top  float64 // откуда начинается текст на следующих страницах
+
+	onPage func(p *pdf.Page) // оформление новой страницы
+}
+
+// need переносит вывод на новую страницу, если h пунктов не помещается
+func (c *column) need(h float64) {
+	if c.y+h <= bottom {
+		return
+	}
+	c.page = c.doc.AddPage()
+	c.y = c.top
+	if c.onPage != nil {
+		c.onPage(c.page)
+	}
+}
+
+func (c *column) heading(size float64, s string) {
+	c.need(size*1.35 + 24)
+	c.y += 10
+	c.page.Text(c.x, c.y+size, size, true, s)
+	c.y += size + 5
+	c.page.Line(c.x, c.y, c.x+c.w, c.y, 0.5)
+	c.y += 6
+}
+
+func (c *column) paragraph(size float64, isBold bool, s string) {
+	lh := size * 1.35
+	for _, para := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		for _, line := range c.doc.Wrap(para, size, isBold, c.w) {
+			c.need(lh)
+			c.y += lh
+			c.page.Text(c.x, c.y-size*0.3, size, isBold, fit(c.doc, line, size, isBold, c.w))
+		}
+	}
+}
+
+func (c *column) list(size float64, items []string) {
+	for _, it := range items {
+		c.paragraph(size, false, "• "+it)
+	}
+}
+
+// fit обрезает строку без пробелов (длинную ссылку), которая не влезает в ширину
+func fit(doc *pdf.Document, s string, size float64, isBold bool, width float64) string {
+	if doc.TextWidth(s, size, isBold) <= width {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 1 && doc.TextWidth(string(r)+"…", size, isBold) > width {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
+
+// PDF рендерит резюме в PDF по шаблону
+func PDF(in Input, template string) ([]byte, error) {
+	title := in.Title
+	if in.Name != "" {
+		title = in.Name + " — " + in.Title
+	}
+	doc, err := pdf.New(title)
+	if err != nil {
+		return nil, err
+	}
+	switch template {
+	case TemplateModern:
+		modernPDF(doc, in)
+	case TemplateCompact:
+		compactPDF(doc, in)
+	default:
+		classicPDF(doc, in)
+	}
+	return doc.Bytes()
+}
+
+// avatar рисует фото; битое изображение пропускается
+func avatar(p *pdf.Page, data []byte, x, y, size float64) bool {
+	if len(data) == 0 {
+		return false
+	}
+	return p.Image(data, x, y, size, size) == nil
+}
+
+func classicPDF(doc *pdf.Document, in Input) {
+	p := doc.AddPage()
+	c := &column{doc: doc, page: p, x: margin, w: pdf.PageWidth - 2*margin, y: margin, top: margin}
+
+	textW := c.w
+	if avatar(p, in.Avatar, pdf.PageWidth-margin-80, margin, 80) {
+		textW -= 95
+	}
+	head := &column{doc: doc, page: p, x: margin, w: textW, y: margin, top: margin}
+	if in.Name != "" {
+		head.paragraph(20, true, in.Name)
+	}
+	head.paragraph(14, false, in.Title)
+	if in.Location != "" {
+		head.y += 4
+		head.paragraph(10, false, in.Location)
+	}
+	c.y = head.y
+	if c.y < margin+85 && textW < c.w {
+		c.y = margin + 85
+	}
+
+	c.heading(12, "О себе")
+	c.paragraph(10, false, in.About)
+	if skills := in.skills(); len(skills) > 0 {
+		c.heading(12, "Навыки")
+		c.paragraph(10, false, strings.Join(skills, ", "))
+	}
+	if links := in.links(); len(links) > 0 {
+		c.heading(12, "Ссылки")
+		c.list(10, links)
+	}
+	footers(doc, in)
+}
+
+func modernPDF(doc *pdf.Document, in Input) {
+	background := func(p *pdf.Page) {
+		p.FillRGB(0, 0, pdf.PageWidth, 8, accent)
+		p.Rect(0, 8, sidebar, pdf.PageHeight-8, 0.95)
+	}
+	p := doc.AddPage()
+	background(p)
+
+	side := &column{doc: doc, page: p, x: 24, w: sidebar - 48, y: 36, top: 36}
+	if avatar(p, in.Avatar, (sidebar-110)/2, side.y, 110) {
+		side.y += 122
+	}
+	if in.Name != "" {
+		side.paragraph(14, true, in.Name)
+	}
+	if in.Location != "" {
+		side.paragraph(9, false, in.Location)
+	}
+	// Боковая колонка не переносится: лишнее отрезается, чтобы не ломать верстку
+	sideList := func(title string, items []string) {
+		if len(items) == 0 || side.y > bottom-40 {
+			return
+		}
+		side.y += 14
+		p.Text(side.x, side.y, 10, true, title)
+		side.y += 4
+		for _, it := range items {
+			if side.y > bottom-12 {
+				break
+			}
+			side.y += 12
+			p.Text(side.x, side.y, 9, false, fit(doc, it, 9, false, side.w))
+		}
+	}
+	sideList("НАВЫКИ", in.skills())
+	sideList("ССЫЛКИ", in.links())
+
+	main := &column{doc: doc, page: p, x: sidebar + 30, w: pdf.PageWidth - sidebar - 30 - margin, y: 36, top: margin, onPage: background}
+	main.paragraph(20, true, in.Title)
+	main.heading(12, "О себе")
+	main.paragraph(10, false, in.About)
+	footers(doc, in)
+}
+
+func compactPDF(doc *pdf.Document, in Input) {
+	p := doc.AddPage()
+	c := &column{doc: doc, page: p, x: 40, w: pdf.PageWidth - 80, y: 40, top: 40}
+	head := in.Title
+	if in.Name != "" {
+		head = in.Name + " · " + in.Title
+	}
+	c.paragraph(13, true, head)
+	var meta []string
+	if in.Location != "" {
+		meta = append(meta, in.Location)
+	}
+	meta = append(meta, in.links()...)
+	if len(meta) > 0 {
+		c.paragraph(8, false, strings.Join(meta, "  ·  "))
+	}
+	if skills := in.skills(); len(skills) > 0 {
+		c.y += 4
+		c.paragraph(9, true, "Навыки: "+strings.Join(skills, ", "))
+	}
+	c.heading(10, "О себе")
+	c.paragraph(9, false, in.About)
+	footers(doc, in)
+}
+
+// footers подписывает страницы после того, как известно их число
+func footers(doc *pdf.Document, in Input) {
+	n := doc.PageCount()
+	for i := 0; i < n; i++ {
+		p := doc.Page(i)
+		p.Text(margin, pdf.PageHeight-30, 8, false, in.footer())
+		p.TextRight(pdf.PageWidth-margin, pdf.PageHeight-30, 8, false, fmt.Sprintf("%d / %d", i+1, n))
+	}
+}
@@ -0,0 +1,115 @@
+// Package resumedoc собирает резюме кандидата в PDF и DOCX по одному из шаблонов.
+// Все рендерится на сервере чистым Go, без браузера и внешних сервисов.
+package resumedoc
+
+import (
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/models"
+)
+
+// Шаблоны оформления
+const (
+	TemplateClassic = "classic" // одна колонка, фото справа
+	TemplateModern  = "modern"  // боковая колонка с фото, навыками и ссылками
+	TemplateCompact = "compact" // плотно, без фото
+)
+
+var Templates = []string{TemplateClassic, TemplateModern, TemplateCompact}
+
+// ValidTemplate проверяет название шаблона
+func ValidTemplate(t string) bool {
+	for _, v := range Templates {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Input — что попадает в документ
+type Input struct {
+	Title  string
+	About  string
+	Skills []string
+	Links  []string
+
+	Name     string // displayName профиля
+	Location string
+	Profile  []string // ссылки из профиля
+	Avatar   []byte   // квадратный JPEG; nil — без фото
+
+	Version   int
+	UpdatedAt time.Time
+}
+
+// FromResume собирает Input из резюме и профиля кандидата
+func FromResume(rr *models.Resume, p *models.Profile, avatar []byte) Input {
+	in := Input{
+		Title:     rr.Title,
+		About:     rr.About,
+		Skills:    rr.Skills,
+		Links:     rr.Links,
+		Version:   rr.Version,
+		UpdatedAt: rr.UpdatedAt,
+	}
+	in.withProfile(p, avatar)
+	return in
+}
+
+// FromSnapshot собирает Input из снимка, полученного компанией с откликом
+func FromSnapshot(s *models.ResumeSnapshot, p *models.Profile, avatar []byte) Input {
+	in := Input{
+		Title:     s.Title,
+		About:     s.About,
+		Skills:    s.Skills,
+		Links:     s.Links,
+		Version:   s.Version,
+		UpdatedAt: s.CreatedAt,
+	}
+	in.withProfile(p, avatar)
+	return in
+}
+
+func (in *Input) withProfile(p *models.Profile, avatar []byte) {
+	if p != nil {
+		in.Name = p.DisplayName
+		in.Location = p.Location
+		in.Profile = p.Links
+		in.Avatar = thumbnail(avatar)
+	}
+}
+
+// links — ссылки резюме и профиля без повторов
+func (in Input) links() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, l := range append(append([]string{}, in.Links...), in.Profile...) {
+		l = strings.TrimSpace(l)
+		if l != "" && !seen[l] {
+			seen[l] = true
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func (in Input) skills() []string {
+	out := make([]string, 0, len(in.Skills))
+	for _, s := range in.Skills {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// footer — подпись внизу страниц
+func (in Input) footer() string {
+	s := "Резюме с Unicornstar"
+	if !in.UpdatedAt.IsZero() {
+		s += " · " + in.UpdatedAt.UTC().Format("02.01.2006")
+	}
+	return s
+}
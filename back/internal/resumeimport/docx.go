@@ -0,0 +1,102 @@
+package resumeimport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+const maxDocxXML = 16 << 20
+
+// extractDOCX достает текст абзацев из word/document.xml и внешние ссылки
+func extractDOCX(data []byte) (text string, links []string, err error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", nil, ErrUnreadable
+	}
+	var doc, rels *zip.File
+	for _, f := range zr.File {
+		switch f.Name {
+		case "word/document.xml":
+			doc = f
+		case "word/_rels/document.xml.rels":
+			rels = f
+		}
+	}
+	if doc == nil {
+		return "", nil, ErrUnreadable
+	}
+	rc, err := doc.Open()
+	if err != nil {
+		return "", nil, ErrUnreadable
+	}
+	defer rc.Close()
+
+	var sb strings.Builder
+	dec := xml.NewDecoder(io.LimitReader(rc, maxDocxXML))
+	inText := false
+	for sb.Len() < maxTextRunes*4 {
+		tok, err := dec.Token()
+		if err != nil {
+			break // обрезанный или битый XML — берем что успели прочитать
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteByte('\t')
+			case "br", "cr":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	if rels != nil {
+		links = docxLinks(rels)
+	}
+	text = sb.String()
+	if strings.TrimSpace(text) == "" {
+		return "", links, ErrNoText
+	}
+	return text, links, nil
+}
+
+// docxLinks — адреса гиперссылок из связей документа
+func docxLinks(f *zip.File) []string {
+	rc, err := f.Open()
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+	var rels struct {
+		Items []struct {
+			Target     string `xml:"Target,attr"`
+			TargetMode string `xml:"TargetMode,attr"`
+			Type       string `xml:"Type,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(rc, 1<<20)).Decode(&rels); err != nil {
+		return nil
+	}
+	var out []string
+	for _, r := range rels.Items {
+		if r.TargetMode == "External" && strings.HasSuffix(r.Type, "/hyperlink") {
+			out = append(out, r.Target)
+		}
+	}
+	return out
+}
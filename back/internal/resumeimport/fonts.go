@@ -0,0 +1,241 @@
+package resumeimport
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const maxCMapRange = 1 << 16
+
+// fontDecoder переводит коды символов строки PDF в Unicode
+type fontDecoder struct {
+	cmap    map[uint32]string // из /ToUnicode
+	codeLen int               // байт на код в cmap
+	simple  [256]rune         // однобайтовая кодировка простого шрифта
+}
+
+// cp1252 — символы 0x80..0x9F кодировки WinAnsi; остальное совпадает с Latin-1
+var cp1252 = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// glyphNames — имена глифов, встречающиеся в /Differences
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$', "percent": '%',
+	"ampersand": '&', "quotesingle": '\'', "quoteright": '’', "quoteleft": '‘', "parenleft": '(',
+	"parenright": ')', "asterisk": '*', "plus": '+', "comma": ',', "hyphen": '-', "minus": '-',
+	"period": '.', "slash": '/', "colon": ':', "semicolon": ';', "less": '<', "equal": '=',
+	"greater": '>', "question": '?', "at": '@', "bracketleft": '[', "backslash": '\\',
+	"bracketright": ']', "underscore": '_', "bar": '|', "braceleft": '{', "braceright": '}',
+	"bullet": '•', "endash": '–', "emdash": '—', "quotedblleft": '“', "quotedblright": '”',
+	"guillemotleft": '«', "guillemotright": '»', "ellipsis": '…', "numero": '№', "afii61352": '№',
+	"zero": '0', "one": '1', "two": '2', "three": '3', "four": '4', "five": '5', "six": '6',
+	"seven": '7', "eight": '8', "nine": '9', "nbspace": ' ', "copyright": '©', "registered": '®',
+}
+
+// glyphRune разбирает имя глифа: стандартные, uniXXXX и кириллица afiiNNNNN
+func glyphRune(name string) rune {
+	if r, ok := glyphNames[name]; ok {
+		return r
+	}
+	if len(name) == 1 && (name[0] >= 'A' && name[0] <= 'Z' || name[0] >= 'a' && name[0] <= 'z') {
+		return rune(name[0])
+	}
+	if strings.HasPrefix(name, "uni") && len(name) == 7 {
+		if v, err := strconv.ParseUint(name[3:], 16, 16); err == nil {
+			return rune(v)
+		}
+	}
+	if strings.HasPrefix(name, "afii") {
+		n, err := strconv.Atoi(name[4:])
+		if err != nil {
+			return 0
+		}
+		switch {
+		case n == 10023:
+			return 'Ё'
+		case n == 10071:
+			return 'ё'
+		case n >= 10017 && n <= 10048:
+			return 'А' + rune(n-10017)
+		case n >= 10065 && n <= 10096:
+			return 'а' + rune(n-10065)
+		}
+	}
+	return 0
+}
+
+func (d *pdfDoc) newFont(fd pdfDict) *fontDecoder {
+	f := &fontDecoder{codeLen: 1}
+	if fd["Subtype"] == pdfName("Type0") {
+		f.codeLen = 2
+	}
+	for i := 0; i < 256; i++ {
+		switch {
+		case i >= 0x80 && i < 0xA0:
+			f.simple[i] = cp1252[i-0x80]
+		case i >= 0x20:
+			f.simple[i] = rune(i)
+		}
+	}
+	if enc, ok := d.dict(fd["Encoding"]); ok {
+		if diffs, ok := d.resolve(enc["Differences"]).(pdfArray); ok {
+			code := 0
+			for _, v := range diffs {
+				switch x := v.(type) {
+				case float64:
+					code = int(x)
+				case pdfName:
+					if code >= 0 && code < 256 {
+						if r := glyphRune(string(x)); r != 0 {
+							f.simple[code] = r
+						}
+					}
+					code++
+				}
+			}
+		}
+	}
+	if ref, ok := fd["ToUnicode"].(pdfRef); ok {
+		f.parseCMap(d.streamOf(ref.num))
+	}
+	return f
+}
+
+func codeOf(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+func utf16String(b []byte) string {
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(u))
+}
+
+// parseCMap читает bfchar и bfrange из потока /ToUnicode
+func (f *fontDecoder) parseCMap(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	f.cmap = map[uint32]string{}
+	l := &lexer{data: data}
+	var prev []any
+	for {
+		v, ok := l.object(0)
+		if !ok {
+			return
+		}
+		kw, isKw := v.(pdfKeyword)
+		if !isKw {
+			prev = append(prev, v)
+			if len(prev) > 4 {
+				prev = prev[1:]
+			}
+			continue
+		}
+		prev = prev[:0]
+		switch kw {
+		case "begincodespacerange":
+			if lo, ok := l.object(0); ok {
+				if s, ok := lo.(pdfString); ok && len(s) > 0 && len(s) <= 4 {
+					f.codeLen = len(s)
+				}
+			}
+		case "beginbfchar":
+			for {
+				src, ok1 := l.object(0)
+				if k, ok := src.(pdfKeyword); !ok1 || ok && k == "endbfchar" {
+					break
+				}
+				dst, ok2 := l.object(0)
+				s, isSrc := src.(pdfString)
+				t, isDst := dst.(pdfString)
+				if !ok2 {
+					return
+				}
+				if isSrc && isDst {
+					f.cmap[codeOf(s)] = utf16String(t)
+				}
+			}
+		case "beginbfrange":
+			for {
+				lo, ok1 := l.object(0)
+				if k, ok := lo.(pdfKeyword); !ok1 || ok && k == "endbfrange" {
+					break
+				}
+				hi, _ := l.object(0)
+				dst, ok2 := l.object(0)
+				if !ok2 {
+					return
+				}
+				ls, ok3 := lo.(pdfString)
+				hs, ok4 := hi.(pdfString)
+				if !ok3 || !ok4 {
+					continue
+				}
+				from, to := codeOf(ls), codeOf(hs)
+				if to < from || to-from > maxCMapRange {
+					continue
+				}
+				switch t := dst.(type) {
+				case pdfString:
+					base := []rune(utf16String(t))
+					if len(base) == 0 {
+						continue
+					}
+					for c := from; c <= to; c++ {
+						r := append([]rune{}, base...)
+						r[len(r)-1] += rune(c - from)
+						f.cmap[c] = string(r)
+					}
+				case pdfArray:
+					for i, it := range t {
+						if s, ok := it.(pdfString); ok && from+uint32(i) <= to {
+							f.cmap[from+uint32(i)] = utf16String(s)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// decode переводит строку показа текста в Unicode
+func (f *fontDecoder) decode(s pdfString) string {
+	var sb strings.Builder
+	if f.cmap == nil {
+		if f.codeLen == 2 {
+			// Type0 без ToUnicode: надежда на Identity с кодами Unicode
+			for i := 0; i+1 < len(s); i += 2 {
+				if r := rune(s[i])<<8 | rune(s[i+1]); r >= 0x20 {
+					sb.WriteRune(r)
+				}
+			}
+			return sb.String()
+		}
+		for _, c := range s {
+			if r := f.simple[c]; r != 0 {
+				sb.WriteRune(r)
+			}
+		}
+		return sb.String()
+	}
+	for i := 0; i < len(s); {
+		n := min(f.codeLen, len(s)-i)
+		if t, ok := f.cmap[codeOf(s[i:i+n])]; ok {
+			sb.WriteString(t)
+		} else if n == 1 && f.simple[s[i]] != 0 {
+			sb.WriteRune(f.simple[s[i]])
+		}
+		i += n
+	}
+	return sb.String()
+}
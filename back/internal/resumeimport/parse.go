@@ -0,0 +1,458 @@
+// Package resumeimport превращает загруженное резюме (PDF или DOCX) в черновик:
+// текст извлекается в процессе, без внешних сервисов, и эвристически
+// раскладывается на должность, описание, навыки и ссылки.
+package resumeimport
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"unicorn-auth/internal/taxonomy"
+)
+
+const (
+	maxTitleRunes = 120
+	maxAboutRunes = 8000
+	maxSkills     = 30
+	maxUnmatched  = 20
+	maxLinks      = 10
+)
+
+var ErrUnsupported = errors.New("unsupported_file_type")
+
+// Draft — черновик резюме; поля совпадают с телом POST /api/resumes
+type Draft struct {
+	Title  string   `json:"title"`
+	About  string   `json:"about"`
+	Skills []string `json:"skills"`
+	Links  []string `json:"links"`
+}
+
+// Result — черновик и навыки из раздела навыков, которых нет в словаре
+type Result struct {
+	Draft     Draft
+	Unmatched []string
+}
+
+// Parse извлекает текст из PDF или DOCX и собирает черновик
+func Parse(data []byte, dict *Dictionary) (*Result, error) {
+	var (
+		text  string
+		links []string
+		err   error
+	)
+	switch {
+	case bytes.HasPrefix(bytes.TrimLeft(data, " \r\n\t"), []byte("%PDF")):
+		text, links, err = extractPDF(data)
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		text, links, err = extractDOCX(data)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	return build(text, links, dict), nil
+}
+
+// Разделы резюме по заголовкам
+const (
+	secPreamble = "preamble"
+	secTitle    = "title"
+	secAbout    = "about"
+	secSkills   = "skills"
+	secOther    = "other"
+	secLinks    = "links" // ссылки собираются отдельно, в описание не попадают
+)
+
+var headings = map[string]string{
+	"желаемая должность": secTitle, "должность": secTitle, "позиция": secTitle,
+	"position": secTitle, "desired position": secTitle,
+
+	"о себе": secAbout, "обо мне": secAbout, "кратко о себе": secAbout, "профиль": secAbout,
+	"цель": secAbout, "summary": secAbout, "about": secAbout, "about me": secAbout,
+	"profile": secAbout, "objective": secAbout, "professional summary": secAbout,
+
+	"навыки": secSkills, "ключевые навыки": secSkills, "профессиональные навыки": secSkills,
+	"технические навыки": secSkills, "стек": secSkills, "стек технологий": secSkills,
+	"технологии": secSkills, "компетенции": secSkills, "skills": secSkills, "key skills": secSkills,
+	"technical skills": secSkills, "tech stack": secSkills, "technologies": secSkills,
+
+	"опыт работы": secOther, "опыт": secOther, "experience": secOther, "work experience": secOther,
+	"employment": secOther, "образование": secOther, "education": secOther, "проекты": secOther,
+	"projects": secOther, "языки": secOther, "знание языков": secOther, "languages": secOther,
+	"курсы": secOther, "courses": secOther, "сертификаты": secOther, "certificates": secOther,
+	"повышение квалификации": secOther, "достижения": secOther, "achievements": secOther,
+	"дополнительная информация": secOther, "additional information": secOther,
+	"публикации": secOther, "интересы": secOther, "хобби": secOther, "interests": secOther,
+	"контакты": secOther, "contacts": secOther,
+
+	"ссылки": secLinks, "links": secLinks, "портфолио": secLinks, "portfolio": secLinks,
+}
+
+// roleWords — слова, по которым строка похожа на название должности
+var roleWords = []string{
+	"developer", "разработчик", "engineer", "инженер", "программист", "analyst", "аналитик",
+	"designer", "дизайнер", "manager", "менеджер", "тестировщик", "qa", "devops", "lead",
+	"тимлид", "architect", "архитектор", "специалист", "specialist", "администратор",
+	"administrator", "scientist", "consultant", "консультант", "маркетолог", "бухгалтер",
+	"руководитель", "head", "director", "директор", "стажер", "стажёр", "intern", "sre",
+	"frontend", "backend", "fullstack", "full-stack", "mobile",
+}
+
+var (
+	urlRe     = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'()]+|\b(?:github\.com|gitlab\.com|linkedin\.com|t\.me|habr\.com|career\.habr\.com|hh\.ru)/[^\s<>"'()]+`)
+	emailRe   = regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.-]+`)
+	phoneRe   = regexp.MustCompile(`\+?\d[\d\s()-]{8,}\d`)
+	noiseRe   = regexp.MustCompile(`(?i)^(?:\d{1,3}(?:\s*/\s*\d{1,3})?|(?:page|стр\.?|страница)\s*\d+(?:\s*(?:of|из|/)\s*\d+)?|резюме с unicornstar.*)$`)
+	contactRe = regexp.MustCompile(`(?i)^(?:тел\.?|телефон|phone|mobile|e-?mail|почта|telegram|телеграм|skype|адрес|address|город|проживает|location)\b`)
+	splitRe   = regexp.MustCompile(`[,;•·|\n]+`)
+	parenRe   = regexp.MustCompile(`\s*\([^)]*\)`)
+)
+
+type section struct {
+	kind    string
+	heading string
+	lines   []string
+}
+
+func build(text string, extLinks []string, dict *Dictionary) *Result {
+	lines := normalize(text)
+	secs := split(lines)
+	res := &Result{}
+	res.Draft.Links = collectLinks(text, extLinks)
+
+	var preamble *section
+	if len(secs) > 0 && secs[0].kind == secPreamble {
+		preamble = &secs[0]
+	}
+
+	// Имя — первая строка преамбулы, если похожа на ФИО
+	name := ""
+	if preamble != nil && len(preamble.lines) > 0 && looksLikeName(preamble.lines[0]) {
+		name = preamble.lines[0]
+	}
+
+	res.Draft.Title = findTitle(secs, name)
+
+	// Навыки: раздел навыков по словарю, затем упоминания по всему тексту
+	seen := map[string]bool{}
+	addSkill := func(s string) {
+		if k := strings.ToLower(s); !seen[k] && len(res.Draft.Skills) < maxSkills {
+			seen[k] = true
+			res.Draft.Skills = append(res.Draft.Skills, s)
+		}
+	}
+	unmatched := map[string]bool{}
+	for _, s := range secs {
+		if s.kind != secSkills {
+			continue
+		}
+		for _, tok := range splitRe.Split(strings.Join(s.lines, "\n"), -1) {
+			found := matchToken(tok, dict)
+			for _, f := range found {
+				addSkill(f)
+			}
+			tok = unlabel(cleanToken(tok))
+			if len(found) == 0 && tok != "" && !urlRe.MatchString(tok) && utf8.RuneCountInString(tok) <= 30 && len(strings.Fields(tok)) <= 3 {
+				if k := strings.ToLower(tok); !unmatched[k] && len(res.Unmatched) < maxUnmatched {
+					unmatched[k] = true
+					res.Unmatched = append(res.Unmatched, tok)
+				}
+			}
+		}
+	}
+	for _, s := range scan(lines, dict, false) {
+		addSkill(s)
+	}
+
+	// Описание: "о себе", остаток преамбулы, затем прочие разделы с заголовками
+	var parts []string
+	for _, s := range secs {
+		if s.kind != secAbout {
+			continue
+		}
+		var keep []string
+		for _, l := range s.lines {
+			if !isContact(l) {
+				keep = append(keep, l)
+			}
+		}
+		if len(keep) > 0 {
+			parts = append(parts, strings.Join(keep, "\n"))
+		}
+	}
+	if preamble != nil {
+		var rest []string
+		for _, l := range preamble.lines {
+			if l == name || l == res.Draft.Title || isContact(l) {
+				continue
+			}
+			rest = append(rest, l)
+		}
+		if len(rest) > 0 {
+			parts = append(parts, strings.Join(rest, "\n"))
+		}
+	}
+	for _, s := range secs {
+		if s.kind == secOther && len(s.lines) > 0 {
+			parts = append(parts, s.heading+"\n"+strings.Join(s.lines, "\n"))
+		}
+	}
+	res.Draft.About = truncate(strings.TrimSpace(strings.Join(parts, "\n\n")), maxAboutRunes)
+	if res.Draft.Skills == nil {
+		res.Draft.Skills = []string{}
+	}
+	return res
+}
+
+// normalize — строки без лишних пробелов, колонтитулов и повторных пустых строк
+func normalize(text string) []string {
+	text = strings.NewReplacer("\u00a0", " ", "\t", " ", "\r\n", "\n", "\r", "\n", "\u00ad", "").Replace(text)
+	var out []string
+	for _, l := range strings.Split(text, "\n") {
+		l = strings.Join(strings.Fields(l), " ")
+		if noiseRe.MatchString(l) {
+			continue
+		}
+		if l == "" && (len(out) == 0 || out[len(out)-1] == "") {
+			continue
+		}
+		out = append(out, l)
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// headingOf распознает заголовок раздела, в том числе "Навыки: Go, Docker"
+func headingOf(l string) (kind, heading, rest string) {
+	if utf8.RuneCountInString(l) <= 60 {
+		if k, ok := headings[headingKey(l)]; ok {
+			return k, strings.TrimRight(l, ": "), ""
+		}
+	}
+	if i := strings.Index(l, ":"); i > 0 && i <= 60 {
+		if k, ok := headings[headingKey(l[:i])]; ok {
+			return k, strings.TrimSpace(l[:i]), strings.TrimSpace(l[i+1:])
+		}
+	}
+	return "", "", ""
+}
+
+func headingKey(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	return strings.TrimFunc(s, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSpace(r) })
+}
+
+func split(lines []string) []section {
+	secs := []section{{kind: secPreamble}}
+	for _, l := range lines {
+		if kind, heading, rest := headingOf(l); kind != "" {
+			secs = append(secs, section{kind: kind, heading: heading})
+			if rest != "" {
+				secs[len(secs)-1].lines = append(secs[len(secs)-1].lines, rest)
+			}
+			continue
+		}
+		cur := &secs[len(secs)-1]
+		if l == "" && len(cur.lines) == 0 {
+			continue
+		}
+		cur.lines = append(cur.lines, l)
+	}
+	for i := range secs {
+		for len(secs[i].lines) > 0 && secs[i].lines[len(secs[i].lines)-1] == "" {
+			secs[i].lines = secs[i].lines[:len(secs[i].lines)-1]
+		}
+	}
+	return secs
+}
+
+func looksLikeName(l string) bool {
+	words := strings.Fields(l)
+	if len(words) < 2 || len(words) > 4 || isContact(l) {
+		return false
+	}
+	for _, w := range words {
+		r, _ := utf8.DecodeRuneInString(w)
+		if !unicode.IsUpper(r) {
+			return false
+		}
+		for _, c := range w {
+			if !unicode.IsLetter(c) && c != '-' && c != '.' {
+				return false
+			}
+		}
+	}
+	return !hasRoleWord(l)
+}
+
+func isContact(l string) bool {
+	if emailRe.MatchString(l) || phoneRe.MatchString(l) || contactRe.MatchString(l) {
+		return true
+	}
+	// Строка только из ссылок
+	rest := strings.TrimFunc(urlRe.ReplaceAllString(l, ""), func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+	return rest == "" && l != ""
+}
+
+func hasRoleWord(l string) bool {
+	for _, w := range strings.FieldsFunc(strings.ToLower(l), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-'
+	}) {
+		for _, rw := range roleWords {
+			if w == rw {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findTitle: явная "Желаемая должность", затем строка с названием роли в начале,
+// затем первая короткая строка преамбулы
+func findTitle(secs []section, name string) string {
+	for _, s := range secs {
+		if s.kind == secTitle && len(s.lines) > 0 {
+			return truncate(s.lines[0], maxTitleRunes)
+		}
+	}
+	checked := 0
+	for _, s := range secs {
+		for _, l := range s.lines {
+			if checked++; checked > 10 {
+				break
+			}
+			if l != "" && l != name && !isContact(l) && utf8.RuneCountInString(l) <= 80 && hasRoleWord(l) {
+				return truncate(l, maxTitleRunes)
+			}
+		}
+	}
+	if len(secs) > 0 && secs[0].kind == secPreamble {
+		for _, l := range secs[0].lines {
+			if l != "" && l != name && !isContact(l) && utf8.RuneCountInString(l) <= 80 {
+				return truncate(l, maxTitleRunes)
+			}
+		}
+	}
+	return ""
+}
+
+// cleanToken убирает пояснения в скобках: "Go (3 года)"
+func cleanToken(tok string) string {
+	tok = parenRe.ReplaceAllString(tok, "")
+	return strings.Trim(strings.TrimSpace(tok), "-–—*.")
+}
+
+// unlabel убирает подпись вида "Языки программирования: "
+func unlabel(tok string) string {
+	if i := strings.Index(tok, ":"); i > 0 {
+		return strings.TrimSpace(tok[i+1:])
+	}
+	return tok
+}
+
+// matchToken сопоставляет элемент списка навыков со словарем целиком,
+// по частям через "/" и, если не вышло, по словам внутри
+func matchToken(tok string, dict *Dictionary) []string {
+	tok = cleanToken(tok)
+	if tok == "" {
+		return nil
+	}
+	if s, ok := dict.Lookup(tok); ok {
+		return []string{s}
+	}
+	if tok = unlabel(tok); tok == "" {
+		return nil
+	}
+	if s, ok := dict.Lookup(tok); ok {
+		return []string{s}
+	}
+	var out []string
+	if strings.Contains(tok, "/") {
+		for _, p := range strings.Split(tok, "/") {
+			if s, ok := dict.Lookup(p); ok {
+				out = append(out, s)
+			}
+		}
+		if len(out) > 0 {
+			return out
+		}
+	}
+	return scan([]string{tok}, dict, true)
+}
+
+// scan ищет навыки словаря в тексте по сочетаниям до трех слов;
+// неоднозначные короткие слова засчитываются только при allowAmbiguous
+func scan(lines []string, dict *Dictionary, allowAmbiguous bool) []string {
+	var out []string
+	for _, l := range lines {
+		words := strings.FieldsFunc(l, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#.-", r)
+		})
+		for i := 0; i < len(words); {
+			matched := 0
+			for n := min(dict.maxWords, len(words)-i); n >= 1; n-- {
+				term := strings.Join(words[i:i+n], " ")
+				s, ok := dict.Lookup(term)
+				if !ok || (!allowAmbiguous && ambiguous[taxonomy.Key(term)]) {
+					continue
+				}
+				out = append(out, s)
+				matched = n
+				break
+			}
+			i += max(matched, 1)
+		}
+	}
+	return out
+}
+
+// collectLinks — http(s)-ссылки из аннотаций и текста, без повторов
+func collectLinks(text string, ext []string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, l := range append(append([]string{}, ext...), urlRe.FindAllString(text, -1)...) {
+		l = strings.TrimRight(strings.TrimSpace(l), ".,;:!?")
+		lower := strings.ToLower(l)
+		if strings.HasPrefix(lower, "mailto:") || strings.HasPrefix(lower, "tel:") || l == "" {
+			continue
+		}
+		if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+			if strings.Contains(lower, "://") {
+				continue
+			}
+			l = "https://" + l
+		}
+		k := strings.TrimRight(strings.ToLower(l), "/")
+		if seen[k] || len(l) > 300 {
+			continue
+		}
+		seen[k] = true
+		out = append(out, l)
+		if len(out) >= maxLinks {
+			break
+		}
+	}
+	return out
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)[:n]
+	// Режем по границе строки, если она недалеко
+	if i := strings.LastIndex(string(r), "\n"); i > len(string(r))*3/4 {
+		return strings.TrimSpace(string(r)[:i])
+	}
+	return strings.TrimSpace(string(r))
+}
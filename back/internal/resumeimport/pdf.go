@@ -0,0 +1,458 @@
+package resumeimport
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	maxStreamSize = 16 << 20 // распакованный поток
+	maxTotalSize  = 64 << 20 // все распакованные потоки документа
+	maxPages      = 30
+	maxTextRunes  = 100000
+)
+
+var (
+	ErrUnreadable = errors.New("unreadable_file")
+	ErrNoText     = errors.New("no_text") // скан без текстового слоя
+)
+
+type pdfObject struct {
+	val    any
+	stream []byte // сырые данные потока, если есть
+}
+
+type pdfDoc struct {
+	objs    map[int]*pdfObject
+	decoded map[int][]byte
+	fonts   map[int]*fontDecoder
+	budget  int
+}
+
+var objHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// extractPDF достает текст страниц и ссылки из аннотаций. Перекрестные таблицы
+// не читаются: объекты ищутся сканированием, так переживаются и битые файлы.
+func extractPDF(data []byte) (text string, links []string, err error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \r\n\t"), []byte("%PDF")) {
+		return "", nil, ErrUnreadable
+	}
+	d := &pdfDoc{objs: map[int]*pdfObject{}, decoded: map[int][]byte{}, fonts: map[int]*fontDecoder{}, budget: maxTotalSize}
+	d.scan(data)
+	if len(d.objs) == 0 {
+		return "", nil, ErrUnreadable
+	}
+	pages := d.pages()
+	if len(pages) == 0 {
+		return "", nil, ErrUnreadable
+	}
+
+	var sb strings.Builder
+	for i, page := range pages {
+		if sb.Len() > maxTextRunes*4 {
+			break
+		}
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		d.pageText(page, &sb)
+	}
+	links = d.uriLinks()
+	text = sb.String()
+	if strings.TrimSpace(text) == "" {
+		return "", links, ErrNoText
+	}
+	return text, links, nil
+}
+
+func (d *pdfDoc) scan(data []byte) {
+	for _, m := range objHeader.FindAllSubmatchIndex(data, -1) {
+		num := atoi(data[m[2]:m[3]])
+		l := &lexer{data: data, pos: m[1]}
+		val, ok := l.object(0)
+		if !ok {
+			continue
+		}
+		obj := &pdfObject{val: val}
+		if dict, isDict := val.(pdfDict); isDict {
+			l.skipSpace()
+			if bytes.HasPrefix(data[l.pos:], []byte("stream")) {
+				obj.stream = streamData(data, l.pos+len("stream"), dict)
+			}
+		}
+		d.objs[num] = obj
+	}
+
+	// Объекты внутри потоков объектов (PDF 1.5+)
+	for num, obj := range d.objs {
+		dict, ok := obj.val.(pdfDict)
+		if !ok || dict["Type"] != pdfName("ObjStm") {
+			continue
+		}
+		raw := d.streamOf(num)
+		n, _ := dict["N"].(float64)
+		first, _ := dict["First"].(float64)
+		// Числа сверяем с размером данных до int(): огромное значение при
+		// преобразовании становится отрицательным и проходит проверки
+		if raw == nil || first < 0 || first > float64(len(raw)) {
+			continue
+		}
+		n = min(n, first)
+		head := &lexer{data: raw[:int(first)]}
+		for i := 0; i < int(n); i++ {
+			a, ok1 := head.object(0)
+			b, ok2 := head.object(0)
+			on, isNum := a.(float64)
+			off, isOff := b.(float64)
+			if !ok1 || !ok2 || !isNum || !isOff || off < 0 || off > float64(len(raw))-first {
+				break
+			}
+			if _, exists := d.objs[int(on)]; exists {
+				continue
+			}
+			l := &lexer{data: raw, pos: int(first) + int(off)}
+			if val, ok := l.object(0); ok {
+				d.objs[int(on)] = &pdfObject{val: val}
+			}
+		}
+	}
+}
+
+// streamData выделяет данные потока; /Length может быть косвенной, тогда ищем endstream
+func streamData(data []byte, pos int, dict pdfDict) []byte {
+	if pos > len(data) {
+		return nil
+	}
+	if pos < len(data) && data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(data) && data[pos] == '\n' {
+		pos++
+	}
+	if n, ok := dict["Length"].(float64); ok && n >= 0 && n <= float64(len(data)-pos) {
+		end := pos + int(n)
+		rest := bytes.TrimLeft(data[end:min(end+16, len(data))], " \r\n\t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return data[pos:end]
+		}
+	}
+	end := bytes.Index(data[pos:], []byte("endstream"))
+	if end < 0 {
+		return nil
+	}
+	return bytes.TrimRight(data[pos:pos+end], "\r\n")
+}
+
+func atoi(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n*10 + int(c-'0')
+		if n > 1<<24 {
+			return -1
+		}
+	}
+	return n
+}
+
+// resolve раскрывает ссылку на объект
+func (d *pdfDoc) resolve(v any) any {
+	for i := 0; i < 8; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		obj := d.objs[ref.num]
+		if obj == nil {
+			return nil
+		}
+		v = obj.val
+	}
+	return nil
+}
+
+func (d *pdfDoc) dict(v any) (pdfDict, bool) {
+	dict, ok := d.resolve(v).(pdfDict)
+	return dict, ok
+}
+
+// streamOf возвращает распакованные данные потока объекта num
+func (d *pdfDoc) streamOf(num int) []byte {
+	if b, ok := d.decoded[num]; ok {
+		return b
+	}
+	obj := d.objs[num]
+	if obj == nil || obj.stream == nil {
+		return nil
+	}
+	dict, _ := obj.val.(pdfDict)
+	data := obj.stream
+	var filters []any
+	switch f := d.resolve(dict["Filter"]).(type) {
+	case pdfName:
+		filters = []any{f}
+	case pdfArray:
+		filters = f
+	}
+	for _, f := range filters {
+		if d.resolve(f) != pdfName("FlateDecode") {
+			data = nil // изображения и прочие фильтры текст не содержат
+			break
+		}
+		data = d.inflate(data)
+	}
+	d.decoded[num] = data
+	return data
+}
+
+func (d *pdfDoc) inflate(data []byte) []byte {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	defer zr.Close()
+	limit := min(maxStreamSize, d.budget)
+	out, _ := io.ReadAll(io.LimitReader(zr, int64(limit))) // обрезанный поток читаем как есть
+	d.budget -= len(out)
+	return out
+}
+
+// catalogPages — корень дерева страниц из каталога
+func (d *pdfDoc) catalogPages() any {
+	for _, obj := range d.objs {
+		if dict, ok := obj.val.(pdfDict); ok && dict["Type"] == pdfName("Catalog") {
+			return dict["Pages"]
+		}
+	}
+	return nil
+}
+
+// pages — страницы по порядку дерева с унаследованными ресурсами
+func (d *pdfDoc) pages() []pdfDict {
+	var out []pdfDict
+	var walk func(v any, res any, depth int)
+	walk = func(v any, res any, depth int) {
+		node, ok := d.dict(v)
+		if !ok || depth > 32 || len(out) >= maxPages {
+			return
+		}
+		if r, ok := node["Resources"]; ok {
+			res = r
+		}
+		if kids, ok := d.resolve(node["Kids"]).(pdfArray); ok {
+			for _, k := range kids {
+				walk(k, res, depth+1)
+			}
+			return
+		}
+		if node["Type"] == pdfName("Page") || node["Contents"] != nil {
+			page := pdfDict{"Contents": node["Contents"], "Resources": res}
+			out = append(out, page)
+		}
+	}
+	walk(d.catalogPages(), nil, 0)
+	if len(out) > 0 {
+		return out
+	}
+	// Без каталога — все страницы в порядке номеров объектов
+	nums := make([]int, 0, len(d.objs))
+	for num, obj := range d.objs {
+		if dict, ok := obj.val.(pdfDict); ok && dict["Type"] == pdfName("Page") {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+	for _, num := range nums[:min(len(nums), maxPages)] {
+		out = append(out, d.objs[num].val.(pdfDict))
+	}
+	return out
+}
+
+// contents склеивает потоки содержимого страницы
+func (d *pdfDoc) contents(page pdfDict) []byte {
+	var refs []any
+	switch c := page["Contents"].(type) {
+	case pdfRef:
+		if arr, ok := d.resolve(c).(pdfArray); ok {
+			refs = arr
+		} else {
+			refs = []any{c}
+		}
+	case pdfArray:
+		refs = c
+	}
+	var buf bytes.Buffer
+	for _, r := range refs {
+		if ref, ok := r.(pdfRef); ok {
+			buf.Write(d.streamOf(ref.num))
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// pageFonts — шрифты из ресурсов страницы по имени (/F1)
+func (d *pdfDoc) pageFonts(page pdfDict) map[pdfName]*fontDecoder {
+	out := map[pdfName]*fontDecoder{}
+	res, _ := d.dict(page["Resources"])
+	fonts, _ := d.dict(res["Font"])
+	for name, v := range fonts {
+		num := -1
+		if ref, ok := v.(pdfRef); ok {
+			num = ref.num
+			if f, ok := d.fonts[num]; ok {
+				out[name] = f
+				continue
+			}
+		}
+		fd, _ := d.dict(v)
+		f := d.newFont(fd)
+		if num >= 0 {
+			d.fonts[num] = f
+		}
+		out[name] = f
+	}
+	return out
+}
+
+// pageText разбирает операторы вывода текста; переводы строк — по смене базовой линии
+func (d *pdfDoc) pageText(page pdfDict, sb *strings.Builder) {
+	fonts := d.pageFonts(page)
+	var font *fontDecoder
+	var operands []any
+	lastY, haveY := 0.0, false
+
+	newline := func() {
+		s := sb.String()
+		if len(s) > 0 && !strings.HasSuffix(s, "\n") {
+			sb.WriteByte('\n')
+		}
+	}
+	space := func() {
+		s := sb.String()
+		if len(s) > 0 && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+			sb.WriteByte(' ')
+		}
+	}
+	show := func(v any) {
+		if s, ok := v.(pdfString); ok && font != nil {
+			sb.WriteString(font.decode(s))
+		}
+	}
+	num := func(i int) float64 {
+		if i < 0 || i >= len(operands) {
+			return 0
+		}
+		n, _ := operands[i].(float64)
+		return n
+	}
+
+	l := &lexer{data: d.contents(page)}
+	for sb.Len() < maxTextRunes*4 {
+		v, ok := l.object(0)
+		if !ok {
+			break
+		}
+		op, isOp := v.(pdfKeyword)
+		if !isOp {
+			operands = append(operands, v)
+			if len(operands) > 64 {
+				operands = operands[1:]
+			}
+			continue
+		}
+		switch op {
+		case "BI":
+			// Встроенное изображение: пропускаем данные до EI
+			if i := bytes.Index(l.data[l.pos:], []byte("EI")); i >= 0 {
+				l.pos += i + 2
+			} else {
+				l.pos = len(l.data)
+			}
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok {
+					font = fonts[name]
+				}
+			}
+		case "Td", "TD":
+			ty := num(len(operands) - 1)
+			if ty != 0 {
+				newline()
+			} else if num(len(operands)-2) != 0 {
+				space()
+			}
+		case "Tm":
+			y := num(len(operands) - 1)
+			if haveY && (y-lastY > 1 || lastY-y > 1) {
+				newline()
+			} else if haveY {
+				space()
+			}
+			lastY, haveY = y, true
+		case "T*":
+			newline()
+		case "Tj":
+			if len(operands) > 0 {
+				show(operands[len(operands)-1])
+			}
+		case "'", "\"":
+			newline()
+			if len(operands) > 0 {
+				show(operands[len(operands)-1])
+			}
+		case "TJ":
+			if len(operands) == 0 {
+				break
+			}
+			if arr, ok := operands[len(operands)-1].(pdfArray); ok {
+				for _, it := range arr {
+					if n, ok := it.(float64); ok {
+						// Большой отступ внутри TJ — пробел между словами
+						if n < -200 {
+							space()
+						}
+						continue
+					}
+					show(it)
+				}
+			}
+		case "ET":
+			space()
+		}
+		operands = operands[:0]
+	}
+	newline()
+}
+
+// uriLinks — адреса из ссылок-аннотаций (/URI), в том числе вложенных в страницы
+func (d *pdfDoc) uriLinks() []string {
+	var out []string
+	var walk func(v any, depth int)
+	walk = func(v any, depth int) {
+		if depth > 8 || len(out) >= maxLinks*4 {
+			return
+		}
+		switch x := v.(type) {
+		case pdfDict:
+			if s, ok := x["URI"].(pdfString); ok {
+				out = append(out, string(s))
+			}
+			for _, it := range x {
+				walk(it, depth+1)
+			}
+		case pdfArray:
+			for _, it := range x {
+				walk(it, depth+1)
+			}
+		}
+	}
+	for _, obj := range d.objs {
+		walk(obj.val, 0)
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package resumeimport
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const huge = "100000000000000000000000000000"
+
+// pdfFile склеивает объекты в файл без таблицы xref — парсер ее не читает
+func pdfFile(objs ...string) []byte {
+	var sb strings.Builder
+	sb.WriteString("%PDF-1.4\n")
+	for i, o := range objs {
+		fmt.Fprintf(&sb, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	sb.WriteString("%%EOF\n")
+	return []byte(sb.String())
+}
+
+func stream(dict, data string) string {
+	return fmt.Sprintf("<< %s >>\nstream\n%s\nendstream", dict, data)
+}
+
+// page — документ из одной страницы с текстом text; length — значение /Length
+// потока содержимого, пусто — настоящая длина
+func page(text, length string) []byte {
+	content := "BT /F1 12 Tf 72 700 Td (" + text + ") Tj ET"
+	if length == "" {
+		length = fmt.Sprint(len(content))
+	}
+	return pdfFile(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		stream("/Length "+length, content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+}
+
+// objStm — поток объектов с каталогом и деревом страниц; first и off подменяют /First и смещение каталога
+func objStm(n, first, off string) []byte {
+	catalog := "<< /Type /Catalog /Pages 2 0 R >>"
+	pages := "<< /Type /Pages /Kids [3 0 R] /Count 1 >>"
+	head := fmt.Sprintf("1 %s 2 %d ", off, len(catalog)+1)
+	if first == "" {
+		first = fmt.Sprint(len(head))
+	}
+	body := head + catalog + " " + pages
+	content := "BT /F1 12 Tf (From stream) Tj ET"
+	return pdfFile(
+		stream(fmt.Sprintf("/Type /ObjStm /N %s /First %s /Length %d", n, first, len(body)), body),
+		"<< /Type /XRef >>",
+		"<< /Type /Page /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		stream(fmt.Sprintf("/Length %d", len(content)), content),
+		"<< /Type /Font /Subtype /Type1 >>",
+	)
+}
+
+func TestExtractPDF(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"length", page("Go developer", ""), "Go developer"},
+		{"indirect length", page("Go developer", "9 0 R"), "Go developer"},
+		{"wrong length", page("Go developer", "3"), "Go developer"},
+		{"huge length", page("Go developer", huge), "Go developer"},
+		{"object stream", objStm("2", "", "0"), "From stream"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, _, err := extractPDF(tc.data)
+			if err != nil || !strings.Contains(text, tc.want) {
+				t.Fatalf("extractPDF = %q, %v; want %q", text, err, tc.want)
+			}
+		})
+	}
+}
+
+// Битые и враждебные файлы не должны ронять разбор
+func TestExtractPDFMalformed(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{"not a pdf", []byte("hello")},
+		{"empty", []byte("%PDF-1.7")},
+		{"negative length", page("x", "-5")},
+		{"length past end", []byte("%PDF-1.4\n1 0 obj\n<< /Length 500 >>\nstream\nBT (x) Tj ET")},
+		{"huge length without endstream", []byte("%PDF-1.4\n1 0 obj\n<< /Length " + huge + " >>\nstream\nBT (x) Tj ET")},
+		{"stream at end of file", []byte("%PDF-1.4\n1 0 obj\n<< /Length 1 >>\nstream")},
+		{"huge object number", []byte("%PDF-1.4\n" + huge + " 0 obj\n<< /Type /Page /Contents 1 0 R >>\nendobj")},
+		{"huge first", objStm("2", huge, "0")},
+		{"negative first", objStm("2", "-1", "0")},
+		{"first past end", objStm("2", "100000", "0")},
+		{"huge offset", objStm("2", "", huge)},
+		{"negative offset", objStm("2", "", "-7")},
+		{"huge count", objStm(huge, "", "0")},
+		{"huge differences code", pdfFile(
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] >>",
+			"<< /Type /Page /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+			stream("/Length 22", "BT /F1 12 Tf (x) Tj ET"),
+			"<< /Type /Font /Encoding << /Differences ["+huge+" /a /b -3 /c] >> >>",
+		)},
+		{"unterminated dictionary", []byte("%PDF-1.4\n1 0 obj\n<< /Type /Page /Contents [1 0 R")},
+		{"deep nesting", []byte("%PDF-1.4\n1 0 obj\n" + strings.Repeat("[", 10000) + "\nendobj")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("extractPDF panicked: %v", r)
+				}
+			}()
+			_, _, err := extractPDF(tc.data)
+			if err != nil && !errors.Is(err, ErrUnreadable) && !errors.Is(err, ErrNoText) {
+				t.Fatalf("extractPDF error = %v", err)
+			}
+		})
+	}
+}
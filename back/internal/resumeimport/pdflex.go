@@ -0,0 +1,273 @@
+package resumeimport
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Объекты PDF: числа, имена, строки, массивы, словари, ссылки и операторы
+type (
+	pdfName    string
+	pdfString  []byte
+	pdfArray   []any
+	pdfDict    map[pdfName]any
+	pdfRef     struct{ num, gen int }
+	pdfKeyword string
+)
+
+// lexer читает объекты PDF из файла или потока содержимого страницы
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isDelim(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// object читает следующий объект; ok == false — конец данных
+func (l *lexer) object(depth int) (any, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) || depth > 64 {
+		return nil, false
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		return pdfName(l.name()), true
+	case c == '(':
+		l.pos++
+		return pdfString(l.literal()), true
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		d := pdfDict{}
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return d, true
+			}
+			if l.data[l.pos] == '>' {
+				l.pos = min(l.pos+2, len(l.data))
+				return d, true
+			}
+			k, ok := l.object(depth + 1)
+			if !ok {
+				return d, true
+			}
+			name, isName := k.(pdfName)
+			v, ok := l.object(depth + 1)
+			if !ok {
+				return d, true
+			}
+			if isName {
+				d[name] = v
+			}
+		}
+	case c == '<':
+		l.pos++
+		return pdfString(l.hex()), true
+	case c == '[':
+		l.pos++
+		var arr pdfArray
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return arr, true
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return arr, true
+			}
+			v, ok := l.object(depth + 1)
+			if !ok {
+				return arr, true
+			}
+			arr = append(arr, v)
+		}
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return pdfKeyword([]byte{c}), true
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		n := l.number()
+		// "n g R" — ссылка на объект
+		if n == float64(int(n)) && n >= 0 {
+			save := l.pos
+			l.skipSpace()
+			start := l.pos
+			for l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+				l.pos++
+			}
+			if l.pos > start {
+				gen, _ := strconv.Atoi(string(l.data[start:l.pos]))
+				l.skipSpace()
+				if l.pos < len(l.data) && l.data[l.pos] == 'R' && (l.pos+1 == len(l.data) || isSpace(l.data[l.pos+1]) || isDelim(l.data[l.pos+1])) {
+					l.pos++
+					return pdfRef{num: int(n), gen: gen}, true
+				}
+			}
+			l.pos = save
+		}
+		return n, true
+	default:
+		start := l.pos
+		for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelim(l.data[l.pos]) {
+			l.pos++
+		}
+		if l.pos == start {
+			l.pos++
+		}
+		switch kw := string(l.data[start:l.pos]); kw {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		case "null":
+			return nil, true
+		default:
+			return pdfKeyword(kw), true
+		}
+	}
+}
+
+func (l *lexer) name() string {
+	var b []byte
+	for l.pos < len(l.data) && !isSpace(l.data[l.pos]) && !isDelim(l.data[l.pos]) {
+		c := l.data[l.pos]
+		if c == '#' && l.pos+2 < len(l.data) {
+			if v, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				b = append(b, byte(v))
+				l.pos += 3
+				continue
+			}
+		}
+		b = append(b, c)
+		l.pos++
+	}
+	return string(b)
+}
+
+func (l *lexer) number() float64 {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.data) && (l.data[l.pos] == '.' || (l.data[l.pos] >= '0' && l.data[l.pos] <= '9')) {
+		l.pos++
+	}
+	n, _ := strconv.ParseFloat(string(l.data[start:l.pos]), 64)
+	return n
+}
+
+// literal читает строку в скобках с экранированием; '(' уже прочитана
+func (l *lexer) literal() []byte {
+	var b []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return b
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return b
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// hex читает строку вида <48656C6C6F>; '<' уже прочитана
+func (l *lexer) hex() []byte {
+	var b []byte
+	hi, half := byte(0), false
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		if c == '>' {
+			break
+		}
+		v, ok := hexVal(c)
+		if !ok {
+			continue
+		}
+		if half {
+			b = append(b, hi<<4|v)
+		} else {
+			hi = v
+		}
+		half = !half
+	}
+	if half {
+		b = append(b, hi<<4)
+	}
+	return b
+}
+
+func hexVal(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
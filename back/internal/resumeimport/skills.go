@@ -0,0 +1,59 @@
+package resumeimport
+
+import (
+	"strings"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/taxonomy"
+)
+
+// ambiguous — короткие слова, которые в тексте часто не означают навык;
+// засчитываются только из раздела навыков
+var ambiguous = map[string]bool{
+	"go": true, "c": true, "r": true, "ts": true, "js": true, "ml": true,
+	"node": true, "rest": true, "spring": true, "unity": true, "swift": true, "elastic": true,
+	"ruby": true, "dart": true, "git": true, "scrum": true, "agile": true, "excel": true,
+}
+
+// Dictionary сопоставляет написания навыков с каноническими
+type Dictionary struct {
+	terms    map[string]string
+	maxWords int
+}
+
+// NewDictionary собирает словарь из справочника навыков и тегов вакансий вне его
+func NewDictionary(skills []models.Skill, tags []string) *Dictionary {
+	d := &Dictionary{terms: map[string]string{}, maxWords: 1}
+	for _, sk := range skills {
+		d.add(sk.Name, sk.Name)
+		for _, syn := range sk.Synonyms {
+			d.add(syn, sk.Name)
+		}
+	}
+	for _, t := range tags {
+		if t = strings.TrimSpace(t); t != "" && len([]rune(t)) <= 40 {
+			d.add(t, t)
+		}
+	}
+	return d
+}
+
+func (d *Dictionary) add(term, canon string) {
+	k := taxonomy.Key(term)
+	if k == "" {
+		return
+	}
+	if _, ok := d.terms[k]; ok {
+		return
+	}
+	d.terms[k] = canon
+	if n := len(strings.Fields(term)); n > d.maxWords {
+		d.maxWords = min(n, 3)
+	}
+}
+
+// Lookup возвращает каноническое написание навыка
+func (d *Dictionary) Lookup(s string) (string, bool) {
+	v, ok := d.terms[taxonomy.Key(s)]
+	return v, ok
+}
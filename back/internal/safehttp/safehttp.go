@@ -0,0 +1,70 @@
+// Package safehttp — HTTP-клиент для адресов, которые вводят пользователи
+// (домены компаний, вебхуки, фиды вакансий). Клиент не подключается к локальным
+// и приватным адресам, иначе такие запросы стали бы SSRF.
+package safehttp
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// NewClient: timeout — на весь запрос; maxRedirects — сколько https-редиректов
+// выполнять (0 — не ходить по редиректам, вернуть ответ 3xx как есть)
+func NewClient(timeout time.Duration, maxRedirects int) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !Allowed(net.ParseIP(host)) {
+				return fmt.Errorf("address %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects || req.URL.Scheme != "https" {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+}
+
+// Allowed — можно ли подключаться к адресу
+func Allowed(ip net.IP) bool {
+	return ip != nil && !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsMulticast()
+}
+
+// ValidURL проверяет адрес, введенный пользователем: только https и доменное имя.
+// Внутренние адреса, в которые резолвится домен, отсекает клиент при подключении.
+func ValidURL(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) > 2048 {
+		return "", false
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil || u.Fragment != "" {
+		return "", false
+	}
+	host := u.Hostname()
+	if net.ParseIP(host) != nil || host == "localhost" || !strings.Contains(host, ".") {
+		return "", false
+	}
+	return u.String(), true
+}
@@ -0,0 +1,454 @@
+// Package scheduler запускает периодические задачи по cron-расписанию.
+// Расписание и история запусков хранятся в MongoDB; инстанс забирает запуск
+// атомарно с арендой и продлевает ее, пока работает, поэтому при нескольких
+// репликах каждую задачу выполняет один инстанс. Той же арендой Exclusive
+// ограничивает работу без расписания (миграции при запуске) одним инстансом.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"unicorn-auth/internal/logging"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/telemetry"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// Lease — аренда запуска; продлевается каждую треть срока.
+	// Если инстанс пропал, задачу через Lease заберет другой
+	Lease = time.Minute
+	// finishTimeout — сколько ждать записи итога после остановки
+	finishTimeout = 5 * time.Second
+	// leaseRetry — как часто Exclusive пробует взять занятую аренду
+	leaseRetry  = 2 * time.Second
+	maxErrorLen = 1000
+)
+
+var (
+	ErrUnknownJob  = errors.New("unknown job")
+	ErrBadSchedule = errors.New("bad schedule")
+)
+
+// Func — один проход задачи; ошибка попадает в историю запусков
+type Func func(ctx context.Context) error
+
+type entry struct {
+	name     string
+	schedule string
+	run      Func
+}
+
+// Job — задача в расписании; Registered — задача есть в этой версии сервиса
+type Job struct {
+	models.ScheduledJob
+	Registered bool `json:"registered"`
+	Running    bool `json:"running"`
+}
+
+type Scheduler struct {
+	repo     *repo.SchedulerRepo
+	log      *slog.Logger
+	instance string
+	lease    time.Duration
+	retry    time.Duration
+
+	mu   sync.Mutex
+	jobs map[string]*entry
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+func New(r *repo.SchedulerRepo, log *slog.Logger) *Scheduler {
+	host, _ := os.Hostname()
+	instance := fmt.Sprintf("%s-%d-%s", host, os.Getpid(), ulid.Make().String()[20:])
+	return &Scheduler{
+		repo:     r,
+		log:      log.With("component", "scheduler", "instance", instance),
+		instance: instance,
+		lease:    Lease,
+		retry:    leaseRetry,
+		jobs:     map[string]*entry{},
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Instance — идентификатор инстанса в lockedBy и истории запусков
+func (s *Scheduler) Instance() string { return s.instance }
+
+// Parse разбирает cron-выражение из пяти полей или @hourly/@daily/@every 10m; время — UTC
+func Parse(spec string) (cron.Schedule, error) {
+	sched, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadSchedule, err)
+	}
+	return sched, nil
+}
+
+// Register добавляет задачу с расписанием по умолчанию; вызывается до Sync
+func (s *Scheduler) Register(name, defaultSchedule string, run Func) {
+	if _, err := Parse(defaultSchedule); err != nil {
+		panic(fmt.Sprintf("scheduler: job %s: %v", name, err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[name] = &entry{name: name, schedule: defaultSchedule, run: run}
+}
+
+// Sync создает в Mongo записи зарегистрированных задач
+func (s *Scheduler) Sync(ctx context.Context) error {
+	now := time.Now().UTC()
+	for _, e := range s.entries() {
+		sched, _ := Parse(e.schedule)
+		if err := s.repo.Ensure(ctx, e.name, e.schedule, sched.Next(now)); err != nil {
+			return fmt.Errorf("%s: %w", e.name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) entries() []*entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*entry, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].name < out[b].name })
+	return out
+}
+
+func (s *Scheduler) entry(name string) *entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[name]
+}
+
+// Start раз в poll забирает задачи, время которых пришло, и запускает их.
+// После отмены ctx ждет завершения начатых запусков
+func (s *Scheduler) Start(ctx context.Context, poll time.Duration) {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	defer s.wg.Wait()
+	for {
+		s.Tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Tick — один проход: запускает задачи, у которых подошло время или есть
+// ручной запрос. Пропущенные запуски не догоняются: следующий считается от now
+func (s *Scheduler) Tick(ctx context.Context) {
+	job := telemetry.StartJob("scheduler")
+	defer job.Done()
+
+	list, err := s.repo.List(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		job.Fail(err)
+		s.log.Error("list scheduled jobs", "err", err)
+		return
+	}
+	now := time.Now().UTC()
+	for i := range list {
+		cur := &list[i]
+		e := s.entry(cur.Name)
+		if e == nil {
+			continue
+		}
+		if cur.LockedUntil != nil && cur.LockedUntil.After(now) {
+			continue
+		}
+		manual := cur.RunRequestedAt != nil
+		scheduled := !cur.Paused && !cur.NextRunAt.After(now)
+		if !manual && !scheduled {
+			continue
+		}
+
+		var next time.Time
+		if scheduled {
+			sched, err := Parse(cur.Schedule)
+			if err != nil {
+				job.Fail(err)
+				s.log.Error("parse schedule", "job", cur.Name, "schedule", cur.Schedule, "err", err)
+				continue
+			}
+			next = sched.Next(now)
+		}
+		run := &models.JobRun{
+			RunID:     ulid.Make().String(),
+			Job:       cur.Name,
+			Instance:  s.instance,
+			Trigger:   models.JobTriggerSchedule,
+			Status:    models.JobRunRunning,
+			StartedAt: now,
+		}
+		if manual {
+			run.Trigger = models.JobTriggerManual
+			run.TriggeredBy = cur.RunRequestedBy
+		}
+
+		prevRunID, ok, err := s.repo.Claim(ctx, cur, run, next, s.lease)
+		if err != nil {
+			job.Fail(err)
+			s.log.Error("claim job", "job", cur.Name, "err", err)
+			if !ok {
+				continue
+			}
+		}
+		if !ok {
+			// Запуск забрал другой инстанс
+			continue
+		}
+		if prevRunID != "" {
+			s.log.Warn("previous run abandoned", "job", cur.Name, "run_id", prevRunID)
+			if err := s.repo.Abandon(ctx, prevRunID); err != nil {
+				s.log.Error("abandon run", "job", cur.Name, "run_id", prevRunID, "err", err)
+			}
+		}
+		s.wg.Add(1)
+		go s.execute(ctx, e, run)
+	}
+}
+
+// execute выполняет запуск, продлевая аренду; при потере аренды контекст
+// задачи отменяется, чтобы она не шла параллельно с другим инстансом
+func (s *Scheduler) execute(ctx context.Context, e *entry, run *models.JobRun) {
+	defer s.wg.Done()
+	log := s.log.With("job", e.name, "run_id", run.RunID)
+	log.Info("job started", "trigger", run.Trigger)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	beat := s.heartbeat(runCtx, cancel, log, func(ctx context.Context, until time.Time) (bool, error) {
+		return s.repo.Extend(ctx, e.name, run.RunID, until)
+	})
+
+	err := s.call(runCtx, e)
+	cancel()
+	<-beat
+
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	run.DurationMs = finished.Sub(run.StartedAt).Milliseconds()
+	run.Status = models.JobRunOK
+	if err != nil {
+		run.Status = models.JobRunError
+		run.Error = logging.Redact(err.Error())
+		if len(run.Error) > maxErrorLen {
+			run.Error = run.Error[:maxErrorLen]
+		}
+		log.Error("job failed", "duration_ms", run.DurationMs, "err", err)
+	} else {
+		log.Info("job finished", "duration_ms", run.DurationMs)
+	}
+
+	// Итог пишется и после остановки сервиса
+	fctx, fcancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer fcancel()
+	if err := s.repo.Finish(fctx, run); err != nil {
+		log.Error("finish run", "err", err)
+	}
+}
+
+// heartbeat продлевает аренду каждую треть срока, пока не отменен ctx;
+// если аренду забрали, вызывает cancel. Канал закрывается по завершении
+func (s *Scheduler) heartbeat(ctx context.Context, cancel context.CancelFunc, log *slog.Logger,
+	extend func(ctx context.Context, until time.Time) (bool, error)) <-chan struct{} {
+	beat := make(chan struct{})
+	go func() {
+		defer close(beat)
+		ticker := time.NewTicker(s.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			ok, err := extend(ctx, time.Now().UTC().Add(s.lease))
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Warn("extend lease", "err", err)
+				}
+				continue
+			}
+			if !ok {
+				log.Error("lease lost, cancelling run")
+				cancel()
+				return
+			}
+		}
+	}()
+	return beat
+}
+
+// Exclusive ждет аренду name и выполняет run, продлевая ее; остальные инстансы
+// ждут, пока run не завершится. Если аренду забрал другой инстанс (этот не
+// продлевал ее дольше Lease), контекст run отменяется. Без аренды до отмены
+// ctx возвращает ctx.Err()
+func (s *Scheduler) Exclusive(ctx context.Context, name string, run Func) error {
+	log := s.log.With("lease", name)
+	for {
+		ok, err := s.repo.AcquireLease(ctx, name, s.instance, time.Now().UTC().Add(s.lease))
+		if ok {
+			break
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Warn("acquire lease", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.retry):
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	beat := s.heartbeat(runCtx, cancel, log, func(ctx context.Context, until time.Time) (bool, error) {
+		return s.repo.ExtendLease(ctx, name, s.instance, until)
+	})
+	err := s.call(runCtx, &entry{name: name, run: run})
+	cancel()
+	<-beat
+
+	rctx, rcancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer rcancel()
+	if rerr := s.repo.ReleaseLease(rctx, name, s.instance); rerr != nil {
+		log.Error("release lease", "err", rerr)
+	}
+	return err
+}
+
+func (s *Scheduler) call(ctx context.Context, e *entry) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			s.log.Error("job panic", "job", e.name, "panic", fmt.Sprint(p), "stack", string(debug.Stack()))
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return e.run(ctx)
+}
+
+// List — задачи из Mongo с пометкой, известны ли они этой версии и идут ли сейчас
+func (s *Scheduler) List(ctx context.Context) ([]Job, error) {
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Job, len(list))
+	for i := range list {
+		out[i] = s.view(&list[i])
+	}
+	return out, nil
+}
+
+func (s *Scheduler) view(j *models.ScheduledJob) Job {
+	return Job{
+		ScheduledJob: *j,
+		Registered:   s.entry(j.Name) != nil,
+		Running:      j.LockedUntil != nil && j.LockedUntil.After(time.Now()),
+	}
+}
+
+// Runs — последние запуски задачи
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int64) ([]models.JobRun, error) {
+	j, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if j == nil {
+		return nil, ErrUnknownJob
+	}
+	return s.repo.Runs(ctx, name, limit)
+}
+
+// Trigger ставит ручной запуск; его заберет первый свободный инстанс,
+// в том числе для задачи на паузе. Если задача сейчас идет, запуск
+// начнется после ее завершения
+func (s *Scheduler) Trigger(ctx context.Context, name, by string) (*Job, error) {
+	if s.entry(name) == nil {
+		return nil, ErrUnknownJob
+	}
+	j, err := s.repo.RequestRun(ctx, name, by)
+	if err != nil || j == nil {
+		return nil, orUnknown(err)
+	}
+	// Локальный цикл забирает запуск сразу, не дожидаясь опроса
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	v := s.view(j)
+	return &v, nil
+}
+
+// SetPaused ставит задачу на паузу или снимает с нее; после паузы
+// следующий запуск считается от текущего времени
+func (s *Scheduler) SetPaused(ctx context.Context, name string, paused bool) (*Job, error) {
+	j, err := s.repo.Get(ctx, name)
+	if err != nil || j == nil {
+		return nil, orUnknown(err)
+	}
+	var next time.Time
+	if !paused {
+		sched, err := Parse(j.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		next = sched.Next(time.Now().UTC())
+	}
+	j, err = s.repo.SetPaused(ctx, name, paused, next)
+	if err != nil || j == nil {
+		return nil, orUnknown(err)
+	}
+	v := s.view(j)
+	return &v, nil
+}
+
+// SetSchedule меняет расписание; пустое — вернуть расписание по умолчанию
+func (s *Scheduler) SetSchedule(ctx context.Context, name, spec string) (*Job, error) {
+	j, err := s.repo.Get(ctx, name)
+	if err != nil || j == nil {
+		return nil, orUnknown(err)
+	}
+	if spec == "" {
+		spec = j.DefaultSchedule
+	}
+	sched, err := Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	j, err = s.repo.SetSchedule(ctx, name, spec, sched.Next(time.Now().UTC()))
+	if err != nil || j == nil {
+		return nil, orUnknown(err)
+	}
+	v := s.view(j)
+	return &v, nil
+}
+
+func orUnknown(err error) error {
+	if err != nil {
+		return err
+	}
+	return ErrUnknownJob
+}
@@ -0,0 +1,370 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+
+	"github.com/oklog/ulid/v2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Тесты идут против настоящей MongoDB: MONGO_TEST_URI=mongodb://127.0.0.1:27017.
+// Каждый тест работает в своей базе и удаляет ее в конце
+func testDB(t *testing.T) *db.Database {
+	t.Helper()
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+	d := db.Connect(context.Background(), uri, "scheduler_test_"+strings.ToLower(ulid.Make().String()), nil)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = d.DB.Drop(ctx)
+		_ = d.Close(ctx)
+	})
+	return d
+}
+
+// instances — n планировщиков одной базы, как на n репликах
+func instances(t *testing.T, d *db.Database, n int, name, spec string, run Func) []*Scheduler {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	out := make([]*Scheduler, n)
+	for i := range out {
+		s := New(repo.NewSchedulerRepo(d), log)
+		s.lease = 600 * time.Millisecond
+		s.retry = 50 * time.Millisecond
+		s.Register(name, spec, run)
+		if err := s.Sync(context.Background()); err != nil {
+			t.Fatalf("sync: %v", err)
+		}
+		out[i] = s
+	}
+	return out
+}
+
+// tickAll запускает проход на всех инстансах одновременно и ждет начатые запуски
+func tickAll(ctx context.Context, all []*Scheduler) {
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, s := range all {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			s.Tick(ctx)
+		}()
+	}
+	close(start)
+	wg.Wait()
+	for _, s := range all {
+		s.wg.Wait()
+	}
+}
+
+// makeDue сдвигает время запуска в прошлое
+func makeDue(t *testing.T, d *db.Database, name string) {
+	t.Helper()
+	_, err := d.ScheduledJobs().UpdateOne(context.Background(), bson.M{"name": name},
+		bson.M{"$set": bson.M{"nextRunAt": time.Now().UTC().Add(-time.Second)}})
+	if err != nil {
+		t.Fatalf("make due: %v", err)
+	}
+}
+
+func runs(t *testing.T, d *db.Database, name string) []models.JobRun {
+	t.Helper()
+	list, err := repo.NewSchedulerRepo(d).Runs(context.Background(), name, 100)
+	if err != nil {
+		t.Fatalf("runs: %v", err)
+	}
+	return list
+}
+
+func counter(n *atomic.Int64) Func {
+	return func(ctx context.Context) error {
+		n.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}
+}
+
+func TestDueJobRunsOncePerTick(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	var n atomic.Int64
+	all := instances(t, d, 4, "count", "@hourly", counter(&n))
+
+	// Время еще не пришло
+	tickAll(ctx, all)
+	if got := n.Load(); got != 0 {
+		t.Fatalf("runs before due = %d, want 0", got)
+	}
+
+	const ticks = 5
+	for i := 1; i <= ticks; i++ {
+		makeDue(t, d, "count")
+		tickAll(ctx, all)
+		if got := n.Load(); got != int64(i) {
+			t.Fatalf("after tick %d: runs = %d, want %d", i, got, i)
+		}
+	}
+
+	list := runs(t, d, "count")
+	if len(list) != ticks {
+		t.Fatalf("job_runs = %d, want %d", len(list), ticks)
+	}
+	for _, r := range list {
+		if r.Status != models.JobRunOK || r.Trigger != models.JobTriggerSchedule {
+			t.Fatalf("run %s: status %s, trigger %s", r.RunID, r.Status, r.Trigger)
+		}
+	}
+	j, err := repo.NewSchedulerRepo(d).Get(ctx, "count")
+	if err != nil || j == nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if j.LockedBy != "" || j.LastStatus != models.JobRunOK || !j.NextRunAt.After(time.Now()) {
+		t.Fatalf("job after runs: lockedBy %q, lastStatus %q, nextRunAt %s", j.LockedBy, j.LastStatus, j.NextRunAt)
+	}
+}
+
+func TestExpiredLeaseTakenOver(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	var n atomic.Int64
+	all := instances(t, d, 2, "count", "@hourly", counter(&n))
+	dead, alive := all[0], all[1]
+
+	// Первый инстанс забрал запуск и пропал, не продлевая аренду
+	makeDue(t, d, "count")
+	cur, err := dead.repo.Get(ctx, "count")
+	if err != nil || cur == nil {
+		t.Fatalf("get job: %v", err)
+	}
+	now := time.Now().UTC()
+	lost := &models.JobRun{RunID: ulid.Make().String(), Job: "count", Instance: dead.Instance(),
+		Trigger: models.JobTriggerSchedule, Status: models.JobRunRunning, StartedAt: now}
+	if _, ok, err := dead.repo.Claim(ctx, cur, lost, now.Add(-time.Millisecond), dead.lease); !ok || err != nil {
+		t.Fatalf("claim: ok %v, err %v", ok, err)
+	}
+
+	// Пока аренда действует, запуск не забирается
+	tickAll(ctx, []*Scheduler{alive})
+	if got := n.Load(); got != 0 {
+		t.Fatalf("runs under live lease = %d, want 0", got)
+	}
+
+	time.Sleep(dead.lease + 100*time.Millisecond)
+	tickAll(ctx, []*Scheduler{alive})
+	if got := n.Load(); got != 1 {
+		t.Fatalf("runs after lease expired = %d, want 1", got)
+	}
+
+	byID := map[string]models.JobRun{}
+	for _, r := range runs(t, d, "count") {
+		byID[r.RunID] = r
+	}
+	if r := byID[lost.RunID]; r.Status != models.JobRunAbandoned {
+		t.Fatalf("lost run status = %q, want %q", r.Status, models.JobRunAbandoned)
+	}
+	delete(byID, lost.RunID)
+	if len(byID) != 1 {
+		t.Fatalf("takeover runs = %d, want 1", len(byID))
+	}
+	for _, r := range byID {
+		if r.Instance != alive.Instance() || r.Status != models.JobRunOK {
+			t.Fatalf("takeover run: instance %s, status %s", r.Instance, r.Status)
+		}
+	}
+}
+
+func TestLostLeaseCancelsRun(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	cancelled := make(chan struct{})
+	all := instances(t, d, 1, "block", "@hourly", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+
+	makeDue(t, d, "block")
+	all[0].Tick(ctx)
+	// Аренду перехватил другой инстанс
+	if _, err := d.ScheduledJobs().UpdateOne(ctx, bson.M{"name": "block"},
+		bson.M{"$set": bson.M{"runId": "other"}}); err != nil {
+		t.Fatalf("steal lease: %v", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run was not cancelled after the lease was lost")
+	}
+	all[0].wg.Wait()
+}
+
+func TestManualTriggerRunsOnce(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	var n atomic.Int64
+	all := instances(t, d, 3, "count", "@hourly", counter(&n))
+
+	before, err := all[0].repo.Get(ctx, "count")
+	if err != nil || before == nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if _, err := all[1].Trigger(ctx, "count", "admin-1"); err != nil {
+		t.Fatalf("trigger: %v", err)
+	}
+	// Повторное нажатие до начала запуска не копится
+	if _, err := all[2].Trigger(ctx, "count", "admin-1"); err != nil {
+		t.Fatalf("trigger: %v", err)
+	}
+	tickAll(ctx, all)
+	tickAll(ctx, all)
+	if got := n.Load(); got != 1 {
+		t.Fatalf("manual runs = %d, want 1", got)
+	}
+
+	list := runs(t, d, "count")
+	if len(list) != 1 || list[0].Trigger != models.JobTriggerManual || list[0].TriggeredBy != "admin-1" {
+		t.Fatalf("runs = %+v, want one manual run by admin-1", list)
+	}
+	after, err := all[0].repo.Get(ctx, "count")
+	if err != nil || after == nil {
+		t.Fatalf("get job: %v", err)
+	}
+	if after.RunRequestedAt != nil {
+		t.Fatal("run request was not cleared")
+	}
+	if !after.NextRunAt.Equal(before.NextRunAt) {
+		t.Fatalf("manual run moved schedule: %s -> %s", before.NextRunAt, after.NextRunAt)
+	}
+}
+
+func TestPausedJobNotClaimed(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	var n atomic.Int64
+	all := instances(t, d, 3, "count", "@hourly", counter(&n))
+
+	if _, err := all[0].SetPaused(ctx, "count", true); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	makeDue(t, d, "count")
+	tickAll(ctx, all)
+	if got := n.Load(); got != 0 {
+		t.Fatalf("paused job ran %d times", got)
+	}
+	if list := runs(t, d, "count"); len(list) != 0 {
+		t.Fatalf("paused job has %d runs", len(list))
+	}
+
+	// Ручной запуск работает и на паузе, расписание остается на паузе
+	if _, err := all[0].Trigger(ctx, "count", "admin-1"); err != nil {
+		t.Fatalf("trigger: %v", err)
+	}
+	tickAll(ctx, all)
+	tickAll(ctx, all)
+	if got := n.Load(); got != 1 {
+		t.Fatalf("manual run on paused job: runs = %d, want 1", got)
+	}
+
+	// После возобновления запуск считается от текущего времени
+	j, err := all[0].SetPaused(ctx, "count", false)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if !j.NextRunAt.After(time.Now()) {
+		t.Fatalf("nextRunAt after resume = %s, want in the future", j.NextRunAt)
+	}
+	tickAll(ctx, all)
+	if got := n.Load(); got != 1 {
+		t.Fatalf("resumed job ran before its time: runs = %d", got)
+	}
+}
+
+func TestExclusive(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	all := instances(t, d, 3, "noop", "@hourly", func(context.Context) error { return nil })
+
+	var active, peak, done atomic.Int64
+	var wg sync.WaitGroup
+	for _, s := range all {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.Exclusive(ctx, "migrations", func(ctx context.Context) error {
+				cur := active.Add(1)
+				defer active.Add(-1)
+				for {
+					p := peak.Load()
+					if cur <= p || peak.CompareAndSwap(p, cur) {
+						break
+					}
+				}
+				time.Sleep(100 * time.Millisecond)
+				done.Add(1)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("exclusive: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if done.Load() != 3 || peak.Load() != 1 {
+		t.Fatalf("exclusive: done %d, peak concurrency %d; want 3 and 1", done.Load(), peak.Load())
+	}
+}
+
+func TestExclusiveTakesOverExpiredLease(t *testing.T) {
+	d := testDB(t)
+	ctx := context.Background()
+	all := instances(t, d, 1, "noop", "@hourly", func(context.Context) error { return nil })
+	r := repo.NewSchedulerRepo(d)
+
+	// Аренду держит пропавший инстанс
+	if ok, err := r.AcquireLease(ctx, "migrations", "dead", time.Now().UTC().Add(300*time.Millisecond)); !ok || err != nil {
+		t.Fatalf("acquire: ok %v, err %v", ok, err)
+	}
+	if ok, err := r.AcquireLease(ctx, "migrations", "other", time.Now().UTC().Add(time.Minute)); ok || err != nil {
+		t.Fatalf("acquire held lease: ok %v, err %v; want false", ok, err)
+	}
+
+	started := time.Now()
+	ran := false
+	err := all[0].Exclusive(ctx, "migrations", func(context.Context) error {
+		ran = true
+		return nil
+	})
+	if err != nil || !ran {
+		t.Fatalf("exclusive: ran %v, err %v", ran, err)
+	}
+	if waited := time.Since(started); waited < 200*time.Millisecond {
+		t.Fatalf("exclusive ran after %s, before the held lease expired", waited)
+	}
+
+	// Без аренды Exclusive выходит по отмене контекста
+	if ok, err := r.AcquireLease(ctx, "migrations", "other", time.Now().UTC().Add(time.Minute)); !ok || err != nil {
+		t.Fatalf("acquire released lease: ok %v, err %v", ok, err)
+	}
+	cctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if err := all[0].Exclusive(cctx, "migrations", func(context.Context) error { return nil }); err == nil {
+		t.Fatal("exclusive ran while another instance held the lease")
+	}
+}
@@ -0,0 +1,221 @@
+// Package screening проверяет анкеты вакансий и ответы соискателей на них.
+package screening
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"unicorn-auth/internal/models"
+
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	MaxQuestions    = 20
+	MaxOptions      = 20
+	maxQuestionText = 300
+	maxOptionText   = 100
+	maxIDLen        = 32
+	MaxTextAnswer   = 2000
+)
+
+var (
+	ErrTooManyQuestions = errors.New("too_many_questions")
+	ErrInvalidQuestion  = errors.New("invalid_question")
+)
+
+// Normalize проверяет анкету, обрезает пробелы и назначает id новым вопросам.
+// Переданные id сохраняются, чтобы фильтры по ответам в инбоксе не ломались.
+func Normalize(in []models.Question) ([]models.Question, error) {
+	if len(in) > MaxQuestions {
+		return nil, ErrTooManyQuestions
+	}
+	out := make([]models.Question, 0, len(in))
+	seen := map[string]bool{}
+	for _, q := range in {
+		q.ID = strings.TrimSpace(q.ID)
+		q.Text = strings.TrimSpace(q.Text)
+		if q.ID == "" {
+			q.ID = strings.ToLower(ulid.Make().String())
+		}
+		if len(q.ID) > maxIDLen || seen[q.ID] || q.Text == "" || utf8.RuneCountInString(q.Text) > maxQuestionText {
+			return nil, ErrInvalidQuestion
+		}
+		seen[q.ID] = true
+		if !validQuestion(&q) {
+			return nil, ErrInvalidQuestion
+		}
+		out = append(out, q)
+	}
+	return out, nil
+}
+
+func validQuestion(q *models.Question) bool {
+	ko := q.Knockout
+	switch q.Type {
+	case models.QuestionYesNo:
+		q.Options, q.Min, q.Max = nil, nil, nil
+		return ko == nil || (ko.Yes != nil && len(ko.Options) == 0 && ko.Min == nil && ko.Max == nil)
+
+	case models.QuestionChoice:
+		q.Min, q.Max = nil, nil
+		if len(q.Options) < 2 || len(q.Options) > MaxOptions {
+			return false
+		}
+		opts := map[string]bool{}
+		for i, o := range q.Options {
+			o = strings.TrimSpace(o)
+			if o == "" || utf8.RuneCountInString(o) > maxOptionText || opts[o] {
+				return false
+			}
+			opts[o] = true
+			q.Options[i] = o
+		}
+		if ko == nil {
+			return true
+		}
+		if ko.Yes != nil || ko.Min != nil || ko.Max != nil || len(ko.Options) == 0 {
+			return false
+		}
+		for i, o := range ko.Options {
+			o = strings.TrimSpace(o)
+			if !opts[o] {
+				return false
+			}
+			ko.Options[i] = o
+		}
+		return true
+
+	case models.QuestionNumber:
+		q.Options = nil
+		if !finite(q.Min) || !finite(q.Max) || (q.Min != nil && q.Max != nil && *q.Min > *q.Max) {
+			return false
+		}
+		if ko == nil {
+			return true
+		}
+		return ko.Yes == nil && len(ko.Options) == 0 && (ko.Min != nil || ko.Max != nil) &&
+			finite(ko.Min) && finite(ko.Max) && (ko.Min == nil || ko.Max == nil || *ko.Min <= *ko.Max)
+
+	case models.QuestionText:
+		q.Options, q.Min, q.Max = nil, nil, nil
+		return ko == nil // текст не оценивается автоматически
+	}
+	return false
+}
+
+func finite(f *float64) bool {
+	return f == nil || (!math.IsNaN(*f) && !math.IsInf(*f, 0))
+}
+
+// Answer — ответ из запроса на отклик: bool, строка или число в зависимости от типа вопроса
+type Answer struct {
+	QuestionID string `json:"questionId"`
+	Value      any    `json:"value"`
+}
+
+// AnswerError — ответ на конкретный вопрос не прошел проверку
+type AnswerError struct {
+	QuestionID string
+	Code       string // answer_required, invalid_answer, unknown_question
+}
+
+func (e *AnswerError) Error() string { return e.Code }
+
+// Evaluate проверяет ответы по анкете. knockedOut — хотя бы один ответ не прошел
+// отсекающее условие; такой отклик отклоняется автоматически.
+func Evaluate(questions []models.Question, answers []Answer) (out []models.ScreeningAnswer, knockedOut bool, err error) {
+	byID := make(map[string]Answer, len(answers))
+	for _, a := range answers {
+		if _, dup := byID[a.QuestionID]; dup {
+			return nil, false, &AnswerError{QuestionID: a.QuestionID, Code: "invalid_answer"}
+		}
+		byID[a.QuestionID] = a
+	}
+	known := make(map[string]bool, len(questions))
+	for _, q := range questions {
+		known[q.ID] = true
+	}
+	for _, a := range answers {
+		if !known[a.QuestionID] {
+			return nil, false, &AnswerError{QuestionID: a.QuestionID, Code: "unknown_question"}
+		}
+	}
+
+	for _, q := range questions {
+		a, ok := byID[q.ID]
+		if s, isStr := a.Value.(string); isStr && strings.TrimSpace(s) == "" {
+			ok = false
+		}
+		if !ok || a.Value == nil {
+			if q.Required {
+				return nil, false, &AnswerError{QuestionID: q.ID, Code: "answer_required"}
+			}
+			continue
+		}
+		sa, ok := check(q, a.Value)
+		if !ok {
+			return nil, false, &AnswerError{QuestionID: q.ID, Code: "invalid_answer"}
+		}
+		knockedOut = knockedOut || sa.KnockedOut
+		out = append(out, sa)
+	}
+	return out, knockedOut, nil
+}
+
+func check(q models.Question, value any) (models.ScreeningAnswer, bool) {
+	sa := models.ScreeningAnswer{QuestionID: q.ID, Question: q.Text, Type: q.Type}
+	ko := q.Knockout
+	switch q.Type {
+	case models.QuestionYesNo:
+		yes, ok := value.(bool)
+		if !ok {
+			return sa, false
+		}
+		sa.Value = "no"
+		if yes {
+			sa.Value = "yes"
+		}
+		sa.KnockedOut = ko != nil && ko.Yes != nil && *ko.Yes != yes
+
+	case models.QuestionChoice:
+		opt, ok := value.(string)
+		if !ok || !contains(q.Options, opt) {
+			return sa, false
+		}
+		sa.Value = opt
+		sa.KnockedOut = ko != nil && !contains(ko.Options, opt)
+
+	case models.QuestionNumber:
+		n, ok := value.(float64)
+		if !ok || math.IsNaN(n) || math.IsInf(n, 0) || (q.Min != nil && n < *q.Min) || (q.Max != nil && n > *q.Max) {
+			return sa, false
+		}
+		sa.Value, sa.Number = strconv.FormatFloat(n, 'f', -1, 64), &n
+		sa.KnockedOut = ko != nil && ((ko.Min != nil && n < *ko.Min) || (ko.Max != nil && n > *ko.Max))
+
+	case models.QuestionText:
+		text, ok := value.(string)
+		text = strings.TrimSpace(text)
+		if !ok || utf8.RuneCountInString(text) > MaxTextAnswer {
+			return sa, false
+		}
+		sa.Value = text
+
+	default:
+		return sa, false
+	}
+	return sa, true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
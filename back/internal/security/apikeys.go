@@ -0,0 +1,27 @@
+package security
+
+import (
+	"context"
+	"errors"
+)
+
+// APIKeyPrefix — так начинаются ключи API; по нему RequireAuth отличает ключ от JWT
+const APIKeyPrefix = "uk_"
+
+var (
+	ErrAPIKeyInvalid     = errors.New("invalid_api_key")
+	ErrAPIKeyRateLimited = errors.New("rate_limited")
+)
+
+// APIKeyPrincipal — от чьего имени действует ключ
+type APIKeyPrincipal struct {
+	KeyID    string
+	UserID   string
+	UserType string
+	Scopes   []string
+}
+
+// APIKeyVerifier проверяет ключ API, лимит запросов по нему и отмечает использование
+type APIKeyVerifier interface {
+	Verify(ctx context.Context, rawKey, ip string) (*APIKeyPrincipal, error)
+}
@@ -3,6 +3,9 @@ package security
 type Security struct {
 	Tokens *TokenService
 	Crypt  *AESCrypt
+
+	// Проверка ключей API; nil — вход по ключам выключен
+	APIKeys APIKeyVerifier
 }
 
 func NewSecurity(jwtSecret, totpEncKeyB64 string) (*Security, error) {
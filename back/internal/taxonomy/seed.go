@@ -0,0 +1,99 @@
+package taxonomy
+
+// seedSkill — запись встроенного справочника
+type seedSkill struct {
+	Name     string
+	Category string
+	Synonyms []string
+}
+
+// builtin — курируемый справочник, которым заполняется пустая коллекция skills
+var builtin = []seedSkill{
+	{"Go", "language", []string{"golang", "go lang"}},
+	{"Python", "language", []string{"python3"}},
+	{"Java", "language", nil},
+	{"Kotlin", "language", nil},
+	{"Scala", "language", nil},
+	{"JavaScript", "language", []string{"js", "ecmascript"}},
+	{"TypeScript", "language", []string{"ts"}},
+	{"PHP", "language", nil},
+	{"Ruby", "language", nil},
+	{"C", "language", nil},
+	{"C++", "language", []string{"cpp"}},
+	{"C#", "language", []string{"csharp"}},
+	{"Rust", "language", nil},
+	{"Swift", "language", nil},
+	{"Objective-C", "language", nil},
+	{"Dart", "language", nil},
+	{"1С", "language", []string{"1c", "1с:предприятие", "1c:enterprise"}},
+	{"SQL", "language", nil},
+	{"HTML", "language", []string{"html5"}},
+	{"CSS", "language", []string{"css3"}},
+
+	{"Node.js", "framework", []string{"node"}},
+	{"React", "framework", []string{"react.js", "reactjs"}},
+	{"Vue.js", "framework", []string{"vue"}},
+	{"Angular", "framework", nil},
+	{"Next.js", "framework", nil},
+	{"SASS", "framework", []string{"scss"}},
+	{"Laravel", "framework", nil},
+	{"Symfony", "framework", nil},
+	{"Ruby on Rails", "framework", []string{"rails", "ror"}},
+	{".NET", "framework", []string{"dotnet", "asp.net", ".net core"}},
+	{"Django", "framework", nil},
+	{"FastAPI", "framework", nil},
+	{"Flask", "framework", nil},
+	{"Spring", "framework", []string{"spring boot"}},
+	{"gRPC", "framework", nil},
+	{"GraphQL", "framework", nil},
+	{"REST", "framework", []string{"rest api", "restful"}},
+	{"Microservices", "framework", []string{"микросервисы", "микросервисная архитектура"}},
+
+	{"PostgreSQL", "database", []string{"postgres", "postgre", "psql"}},
+	{"MySQL", "database", nil},
+	{"MongoDB", "database", []string{"mongo"}},
+	{"Redis", "database", nil},
+	{"ClickHouse", "database", nil},
+	{"Elasticsearch", "database", []string{"elastic"}},
+	{"Kafka", "database", []string{"apache kafka"}},
+	{"RabbitMQ", "database", nil},
+
+	{"Docker", "devops", nil},
+	{"Kubernetes", "devops", []string{"k8s"}},
+	{"Helm", "devops", nil},
+	{"Terraform", "devops", nil},
+	{"Ansible", "devops", nil},
+	{"Linux", "devops", nil},
+	{"Nginx", "devops", nil},
+	{"CI/CD", "devops", nil},
+	{"GitLab CI", "devops", nil},
+	{"GitHub Actions", "devops", nil},
+	{"Jenkins", "devops", nil},
+	{"Prometheus", "devops", nil},
+	{"Grafana", "devops", nil},
+
+	{"AWS", "cloud", []string{"amazon web services"}},
+	{"GCP", "cloud", []string{"google cloud"}},
+	{"Azure", "cloud", nil},
+
+	{"Pandas", "data", nil},
+	{"NumPy", "data", nil},
+	{"PyTorch", "data", nil},
+	{"TensorFlow", "data", nil},
+	{"Machine Learning", "data", []string{"ml", "машинное обучение"}},
+
+	{"Flutter", "mobile", nil},
+	{"Android", "mobile", nil},
+	{"iOS", "mobile", nil},
+
+	{"Figma", "design", nil},
+	{"Photoshop", "design", []string{"adobe photoshop"}},
+	{"Unity", "tool", nil},
+	{"Git", "tool", nil},
+	{"Jira", "tool", nil},
+	{"Selenium", "tool", nil},
+	{"Excel", "tool", []string{"ms excel"}},
+
+	{"Scrum", "management", nil},
+	{"Agile", "management", nil},
+}
@@ -0,0 +1,527 @@
+// Package taxonomy ведет справочник навыков: канонические названия, синонимы
+// и категории. Теги вакансий, навыки резюме и теги сохраненных поисков при
+// записи приводятся к каноническим названиям, так что "golang", "Go" и
+// "GO lang" становятся одним тегом.
+package taxonomy
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"unicorn-auth/internal/db"
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/telemetry"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	cacheTTL     = 5 * time.Minute
+	maxNameRunes = 50
+	maxSynonyms  = 50
+	maxUnknown   = 200
+
+	migrationName = "skills_taxonomy_v1"
+)
+
+var (
+	ErrInvalid  = errors.New("bad_request")
+	ErrNotFound = errors.New("not_found")
+	ErrExists   = errors.New("skill_exists") // написание уже занято другим навыком
+)
+
+// Key — ключ сравнения написаний: нижний регистр, только буквы, цифры, "+" и "#".
+// "Node.js", "nodejs" и "Node JS" дают один ключ.
+func Key(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Clean убирает лишние пробелы в написании тега
+func Clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+type Service struct {
+	skills *repo.SkillRepo
+
+	mu    sync.Mutex
+	byKey map[string]string // ключ написания → каноническое название
+	list  []models.Skill
+	at    time.Time
+
+	log *slog.Logger
+}
+
+func NewService(skills *repo.SkillRepo, log *slog.Logger) *Service {
+	return &Service{skills: skills, log: log.With("component", "taxonomy")}
+}
+
+// load возвращает справочник из кэша или перечитывает его из базы
+func (s *Service) load(ctx context.Context) (map[string]string, []models.Skill, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.byKey != nil && time.Since(s.at) < cacheTTL {
+		return s.byKey, s.list, nil
+	}
+	list, err := s.skills.All(ctx)
+	if err != nil {
+		return s.byKey, s.list, err
+	}
+	byKey := make(map[string]string, len(list)*2)
+	for _, sk := range list {
+		for _, k := range sk.Keys {
+			byKey[k] = sk.Name
+		}
+	}
+	s.byKey, s.list, s.at = byKey, list, time.Now()
+	return byKey, list, nil
+}
+
+// Invalidate сбрасывает кэш после правки справочника
+func (s *Service) Invalidate() {
+	s.mu.Lock()
+	s.byKey = nil
+	s.mu.Unlock()
+}
+
+// Skills — весь справочник (из кэша)
+func (s *Service) Skills(ctx context.Context) ([]models.Skill, error) {
+	_, list, err := s.load(ctx)
+	return list, err
+}
+
+// Normalize приводит теги к каноническим названиям и убирает повторы.
+// Неизвестные теги остаются как написаны, без лишних пробелов. Если справочник
+// недоступен, запись не блокируется: теги только чистятся.
+func (s *Service) Normalize(ctx context.Context, raw []string) []string {
+	byKey, _, err := s.load(ctx)
+	if err != nil {
+		s.log.Error("load skills", "err", err)
+	}
+	return normalize(byKey, raw)
+}
+
+func normalize(byKey map[string]string, raw []string) []string {
+	if raw == nil {
+		return nil
+	}
+	out := make([]string, 0, len(raw))
+	seen := map[string]bool{}
+	for _, t := range raw {
+		t = Clean(t)
+		k := Key(t)
+		if k == "" {
+			continue
+		}
+		if name, ok := byKey[k]; ok {
+			t, k = name, Key(name)
+		}
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Prepare проверяет название, синонимы и категорию и считает ключи написаний
+func Prepare(sk *models.Skill) error {
+	sk.Name = Clean(sk.Name)
+	nameKey := Key(sk.Name)
+	if nameKey == "" || utf8.RuneCountInString(sk.Name) > maxNameRunes || len(sk.Synonyms) > maxSynonyms {
+		return ErrInvalid
+	}
+	if sk.Category == "" {
+		sk.Category = "other"
+	}
+	valid := false
+	for _, c := range models.SkillCategories {
+		valid = valid || c == sk.Category
+	}
+	if !valid {
+		return ErrInvalid
+	}
+	keys := []string{nameKey}
+	seen := map[string]bool{nameKey: true}
+	syns := make([]string, 0, len(sk.Synonyms))
+	for _, syn := range sk.Synonyms {
+		syn = Clean(syn)
+		k := Key(syn)
+		if utf8.RuneCountInString(syn) > maxNameRunes {
+			return ErrInvalid
+		}
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		keys = append(keys, k)
+		syns = append(syns, syn)
+	}
+	sk.Synonyms, sk.Keys = syns, keys
+	return nil
+}
+
+// Suggest — автодополнение по началу любого написания, популярные сначала;
+// пустой запрос — самые популярные навыки
+func (s *Service) Suggest(ctx context.Context, q string, limit int64) ([]models.Skill, error) {
+	k := Key(q)
+	if k == "" && strings.TrimSpace(q) != "" {
+		return []models.Skill{}, nil
+	}
+	return s.skills.List(ctx, k, "", limit, 0)
+}
+
+func (s *Service) Create(ctx context.Context, sk *models.Skill) error {
+	if err := Prepare(sk); err != nil {
+		return err
+	}
+	err := s.skills.Create(ctx, sk)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrExists
+	}
+	s.Invalidate()
+	return err
+}
+
+// Update меняет название, синонимы и категорию. Документы, где навык записан
+// под старым названием, переименовываются.
+func (s *Service) Update(ctx context.Context, skillID string, name *string, synonyms *[]string, category *string) (*models.Skill, error) {
+	cur, err := s.skills.GetByID(ctx, skillID)
+	if err != nil || cur == nil {
+		return nil, orNotFound(err)
+	}
+	upd := *cur
+	if name != nil {
+		upd.Name = *name
+	}
+	if synonyms != nil {
+		upd.Synonyms = *synonyms
+	}
+	if category != nil {
+		upd.Category = *category
+	}
+	if err := Prepare(&upd); err != nil {
+		return nil, err
+	}
+	err = s.skills.Update(ctx, skillID, bson.M{"name": upd.Name, "synonyms": upd.Synonyms, "category": upd.Category, "keys": upd.Keys})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Invalidate()
+	if upd.Name != cur.Name {
+		if _, err := s.rename(ctx, []string{cur.Name}, upd.Keys, upd.Name); err != nil {
+			return nil, err
+		}
+	}
+	return &upd, nil
+}
+
+func (s *Service) Delete(ctx context.Context, skillID string) error {
+	n, err := s.skills.Delete(ctx, skillID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	s.Invalidate()
+	return nil
+}
+
+// Merge вливает навыки fromIDs и произвольные написания terms в навык intoID:
+// их названия становятся синонимами, а вакансии, резюме и сохраненные поиски
+// переписываются на каноническое название. Возвращает навык и число документов.
+func (s *Service) Merge(ctx context.Context, intoID string, fromIDs, terms []string) (*models.Skill, int64, error) {
+	into, err := s.skills.GetByID(ctx, intoID)
+	if err != nil || into == nil {
+		return nil, 0, orNotFound(err)
+	}
+	if len(fromIDs)+len(terms) == 0 || len(fromIDs)+len(terms) > maxSynonyms {
+		return nil, 0, ErrInvalid
+	}
+	upd := *into
+	upd.Synonyms = append([]string{}, into.Synonyms...)
+	values := []string{}
+	var merged []models.Skill
+	owned := map[string]bool{}
+	for _, k := range into.Keys {
+		owned[k] = true
+	}
+	for _, id := range fromIDs {
+		if id == intoID {
+			return nil, 0, ErrInvalid
+		}
+		from, err := s.skills.GetByID(ctx, id)
+		if err != nil || from == nil {
+			return nil, 0, orNotFound(err)
+		}
+		merged = append(merged, *from)
+		upd.Synonyms = append(append(upd.Synonyms, from.Name), from.Synonyms...)
+		values = append(append(values, from.Name), from.Synonyms...)
+		for _, k := range from.Keys {
+			owned[k] = true
+		}
+	}
+	s.Invalidate()
+	byKey, _, err := s.load(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, t := range terms {
+		// Написание, занятое третьим навыком, сливается только вместе с ним
+		if _, taken := byKey[Key(t)]; taken && !owned[Key(t)] {
+			return nil, 0, ErrExists
+		}
+		upd.Synonyms = append(upd.Synonyms, t)
+		values = append(values, Clean(t), t)
+	}
+	if err := Prepare(&upd); err != nil {
+		return nil, 0, err
+	}
+
+	if len(fromIDs) > 0 {
+		if _, err := s.skills.Delete(ctx, fromIDs...); err != nil {
+			return nil, 0, err
+		}
+	}
+	err = s.skills.Update(ctx, intoID, bson.M{"synonyms": upd.Synonyms, "keys": upd.Keys})
+	if err != nil && len(merged) > 0 {
+		// Возвращаем удаленные навыки вместе с синонимами
+		if rerr := s.skills.Restore(ctx, merged); rerr != nil {
+			s.log.Error("restore skills after failed merge", "skill_ids", fromIDs, "err", rerr)
+		}
+	}
+	s.Invalidate()
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, 0, ErrExists
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	changed, err := s.rename(ctx, values, upd.Keys, upd.Name)
+	if err != nil {
+		return nil, changed, err
+	}
+	if err := s.RecountUsage(ctx); err != nil {
+		s.log.Error("recount usage", "err", err)
+	}
+	return &upd, changed, nil
+}
+
+// Split выделяет часть синонимов навыка в новый навык с названием name.
+// Уже сохраненные документы не меняются: в них записано каноническое
+// название исходного навыка, и понять, какое написание было у автора, нельзя.
+func (s *Service) Split(ctx context.Context, skillID, name string, synonyms []string, category string) (*models.Skill, error) {
+	src, err := s.skills.GetByID(ctx, skillID)
+	if err != nil || src == nil {
+		return nil, orNotFound(err)
+	}
+	move := map[string]bool{}
+	for _, syn := range synonyms {
+		move[Key(syn)] = true
+	}
+	if Key(name) == Key(src.Name) || len(move) == 0 {
+		return nil, ErrInvalid
+	}
+	rest := []string{}
+	var moved []string
+	for _, syn := range src.Synonyms {
+		if move[Key(syn)] {
+			moved = append(moved, syn)
+			delete(move, Key(syn))
+		} else {
+			rest = append(rest, syn)
+		}
+	}
+	if len(move) > 0 {
+		return nil, ErrInvalid // можно выделить только синонимы этого навыка
+	}
+	if category == "" {
+		category = src.Category
+	}
+	sk := &models.Skill{Name: name, Synonyms: moved, Category: category}
+	if err := Prepare(sk); err != nil {
+		return nil, err
+	}
+	left := *src
+	left.Synonyms = rest
+	if err := Prepare(&left); err != nil {
+		return nil, err
+	}
+
+	if err := s.skills.Update(ctx, skillID, bson.M{"synonyms": left.Synonyms, "keys": left.Keys}); err != nil {
+		return nil, err
+	}
+	err = s.skills.Create(ctx, sk)
+	if err != nil {
+		// Возвращаем синонимы исходному навыку
+		if rerr := s.skills.Update(ctx, skillID, bson.M{"synonyms": src.Synonyms, "keys": src.Keys}); rerr != nil {
+			s.log.Error("restore skill after failed split", "skill_id", skillID, "err", rerr)
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			err = ErrExists
+		}
+		s.Invalidate()
+		return nil, err
+	}
+	s.Invalidate()
+	return sk, nil
+}
+
+// rename заменяет в документах значения values и любые написания с ключами keys
+// на name
+func (s *Service) rename(ctx context.Context, values, keys []string, name string) (int64, error) {
+	match := map[string]bool{}
+	for _, k := range keys {
+		match[k] = true
+	}
+	return s.skills.Rewrite(ctx, values, func(tags []string) []string {
+		out := make([]string, 0, len(tags))
+		seen := map[string]bool{}
+		for _, t := range tags {
+			if match[Key(t)] {
+				t = name
+			}
+			if k := Key(t); !seen[k] {
+				seen[k] = true
+				out = append(out, t)
+			}
+		}
+		return out
+	})
+}
+
+// TagCount — написание тега вне справочника и сколько раз оно встречается
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// Unknown — теги вакансий и навыки резюме, которых нет в справочнике;
+// кандидаты на слияние с существующими навыками или на новые записи
+func (s *Service) Unknown(ctx context.Context) ([]TagCount, error) {
+	byKey, _, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.skills.TagCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := []TagCount{}
+	for tag, n := range counts {
+		if _, ok := byKey[Key(tag)]; !ok {
+			out = append(out, TagCount{Tag: tag, Count: n})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Tag < out[j].Tag
+	})
+	if len(out) > maxUnknown {
+		out = out[:maxUnknown]
+	}
+	return out, nil
+}
+
+// RecountUsage пересчитывает популярность навыков по вакансиям и резюме
+func (s *Service) RecountUsage(ctx context.Context) error {
+	s.Invalidate()
+	_, list, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	counts, err := s.skills.TagCounts(ctx)
+	if err != nil {
+		return err
+	}
+	idByKey := map[string]string{}
+	usage := make(map[string]int64, len(list))
+	for _, sk := range list {
+		usage[sk.SkillID] = 0
+		for _, k := range sk.Keys {
+			idByKey[k] = sk.SkillID
+		}
+	}
+	for tag, n := range counts {
+		if id, ok := idByKey[Key(tag)]; ok {
+			usage[id] += n
+		}
+	}
+	return s.skills.SetUsage(ctx, usage)
+}
+
+// RunOnce пересчитывает популярность для автодополнения
+func (s *Service) RunOnce(ctx context.Context) error {
+	job := telemetry.StartJob("taxonomy")
+	defer job.Done()
+	if err := s.RecountUsage(ctx); err != nil {
+		job.Fail(err)
+		s.log.Error("recount usage", "err", err)
+	}
+	return job.Err()
+}
+
+// Migrate один раз заполняет справочник встроенными навыками и приводит
+// к нему теги уже сохраненных вакансий, резюме и поисков
+func (s *Service) Migrate(ctx context.Context, d *db.Database) error {
+	done, err := d.MigrationDone(ctx, migrationName)
+	if err != nil || done {
+		return err
+	}
+	added := 0
+	for _, b := range builtin {
+		sk := &models.Skill{Name: b.Name, Synonyms: b.Synonyms, Category: b.Category}
+		if err := Prepare(sk); err != nil {
+			return err
+		}
+		ok, err := s.skills.InsertIfAbsent(ctx, sk)
+		if err != nil {
+			return err
+		}
+		if ok {
+			added++
+		}
+	}
+	s.Invalidate()
+	byKey, _, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	changed, err := s.skills.Rewrite(ctx, nil, func(tags []string) []string { return normalize(byKey, tags) })
+	if err != nil {
+		return err
+	}
+	s.log.Info("skills taxonomy seeded", "skills", added, "documents", changed)
+	if err := s.RecountUsage(ctx); err != nil {
+		return err
+	}
+	return d.MarkMigration(ctx, migrationName)
+}
+
+func orNotFound(err error) error {
+	if err != nil {
+		return err
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,148 @@
+// Package telegram — минимальный клиент Bot API: long polling, отправка сообщений
+// и ответы на нажатия inline-кнопок.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultAPIURL = "https://api.telegram.org"
+
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username,omitempty"`
+}
+
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+type Message struct {
+	MessageID      int64    `json:"message_id"`
+	From           *User    `json:"from,omitempty"`
+	Chat           Chat     `json:"chat"`
+	Text           string   `json:"text,omitempty"`
+	ReplyToMessage *Message `json:"reply_to_message,omitempty"`
+}
+
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    User     `json:"from"`
+	Message *Message `json:"message,omitempty"`
+	Data    string   `json:"data,omitempty"`
+}
+
+type Update struct {
+	UpdateID      int64          `json:"update_id"`
+	Message       *Message       `json:"message,omitempty"`
+	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+}
+
+type InlineButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+type InlineKeyboard struct {
+	InlineKeyboard [][]InlineButton `json:"inline_keyboard"`
+}
+
+// Client — методы Bot API, которыми пользуется бот.
+// В разработке вместо api.telegram.org подставляется локальная заглушка (TELEGRAM_API_URL).
+type Client interface {
+	GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error)
+	SendMessage(ctx context.Context, chatID int64, text string, kb *InlineKeyboard) (int64, error)
+	AnswerCallback(ctx context.Context, callbackID, text string) error
+}
+
+// HTTPClient ходит в Bot API по HTTPS
+type HTTPClient struct {
+	baseURL string // .../bot<token>/
+	http    *http.Client
+}
+
+// NewHTTPClient: apiURL пустой — официальный api.telegram.org
+func NewHTTPClient(apiURL, token string) *HTTPClient {
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+	return &HTTPClient{
+		baseURL: strings.TrimRight(apiURL, "/") + "/bot" + token + "/",
+		// таймаут задается контекстом: getUpdates держит соединение до timeout секунд
+		http: &http.Client{},
+	}
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+}
+
+func (c *HTTPClient) call(ctx context.Context, method string, body any, out any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+method, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// в ошибке net/http есть URL с токеном бота — не пишем его в лог
+		return fmt.Errorf("telegram: %s: request failed", method)
+	}
+	defer resp.Body.Close()
+	var r apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("telegram: %s: http %d", method, resp.StatusCode)
+	}
+	if !r.OK {
+		return fmt.Errorf("telegram: %s: %s", method, r.Description)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(r.Result, out)
+}
+
+func (c *HTTPClient) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout+10*time.Second)
+	defer cancel()
+	var out []Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout / time.Second),
+		"allowed_updates": []string{"message", "callback_query"},
+	}, &out)
+	return out, err
+}
+
+func (c *HTTPClient) SendMessage(ctx context.Context, chatID int64, text string, kb *InlineKeyboard) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	body := map[string]any{"chat_id": chatID, "text": text, "disable_web_page_preview": true}
+	if kb != nil {
+		body["reply_markup"] = kb
+	}
+	var m Message
+	if err := c.call(ctx, "sendMessage", body, &m); err != nil {
+		return 0, err
+	}
+	return m.MessageID, nil
+}
+
+func (c *HTTPClient) AnswerCallback(ctx context.Context, callbackID, text string) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	return c.call(ctx, "answerCallbackQuery", map[string]any{"callback_query_id": callbackID, "text": text}, nil)
+}
@@ -0,0 +1,135 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testToken = "123:secret"
+
+type request struct {
+	path string
+	body map[string]any
+}
+
+// stub — Bot API: запоминает запросы и отвечает на метод заданным телом
+func stub(t *testing.T, replies map[string]string) (*httptest.Server, <-chan request) {
+	t.Helper()
+	reqs := make(chan request, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("%s: decode body: %v", r.URL.Path, err)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" || r.Method != http.MethodPost {
+			t.Errorf("%s: %s with content type %q", r.URL.Path, r.Method, ct)
+		}
+		reqs <- request{path: r.URL.Path, body: body}
+		method := strings.TrimPrefix(r.URL.Path, "/bot"+testToken+"/")
+		reply, ok := replies[method]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			reply = `{"ok":false,"description":"Not Found"}`
+		}
+		_, _ = w.Write([]byte(reply))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, reqs
+}
+
+func TestSendMessage(t *testing.T) {
+	srv, reqs := stub(t, map[string]string{
+		"sendMessage": `{"ok":true,"result":{"message_id":42,"chat":{"id":7,"type":"private"}}}`,
+	})
+	c := NewHTTPClient(srv.URL+"/", testToken)
+
+	kb := &InlineKeyboard{InlineKeyboard: [][]InlineButton{{{Text: "Принять", CallbackData: "acc:1"}}}}
+	id, err := c.SendMessage(context.Background(), 7, "Новый отклик", kb)
+	if err != nil || id != 42 {
+		t.Fatalf("SendMessage = %d, %v; want 42", id, err)
+	}
+
+	r := <-reqs
+	if r.path != "/bot"+testToken+"/sendMessage" {
+		t.Fatalf("path = %s", r.path)
+	}
+	if r.body["chat_id"] != float64(7) || r.body["text"] != "Новый отклик" || r.body["disable_web_page_preview"] != true {
+		t.Fatalf("body = %v", r.body)
+	}
+	markup, _ := json.Marshal(r.body["reply_markup"])
+	if string(markup) != `{"inline_keyboard":[[{"callback_data":"acc:1","text":"Принять"}]]}` {
+		t.Fatalf("reply_markup = %s", markup)
+	}
+
+	// Без клавиатуры reply_markup не передается
+	if _, err := c.SendMessage(context.Background(), 7, "Текст", nil); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if r := <-reqs; r.body["reply_markup"] != nil {
+		t.Fatalf("reply_markup without keyboard = %v", r.body["reply_markup"])
+	}
+}
+
+func TestGetUpdates(t *testing.T) {
+	srv, reqs := stub(t, map[string]string{
+		"getUpdates": `{"ok":true,"result":[
+			{"update_id":10,"message":{"message_id":1,"from":{"id":5,"username":"anna"},"chat":{"id":5,"type":"private"},"text":"/help"}},
+			{"update_id":11,"callback_query":{"id":"cb1","from":{"id":5},"message":{"message_id":2,"chat":{"id":5,"type":"private"}},"data":"acc:01H"}}
+		]}`,
+	})
+	c := NewHTTPClient(srv.URL, testToken)
+
+	updates, err := c.GetUpdates(context.Background(), 10, 25*time.Second)
+	if err != nil {
+		t.Fatalf("GetUpdates: %v", err)
+	}
+	if len(updates) != 2 || updates[0].Message.Text != "/help" || updates[0].Message.From.Username != "anna" ||
+		updates[1].CallbackQuery.Data != "acc:01H" || updates[1].CallbackQuery.Message.MessageID != 2 {
+		t.Fatalf("updates = %+v", updates)
+	}
+	r := <-reqs
+	allowed, _ := json.Marshal(r.body["allowed_updates"])
+	if r.body["offset"] != float64(10) || r.body["timeout"] != float64(25) || string(allowed) != `["message","callback_query"]` {
+		t.Fatalf("body = %v", r.body)
+	}
+}
+
+func TestAnswerCallback(t *testing.T) {
+	srv, reqs := stub(t, map[string]string{"answerCallbackQuery": `{"ok":true,"result":true}`})
+	c := NewHTTPClient(srv.URL, testToken)
+	if err := c.AnswerCallback(context.Background(), "cb1", "Отклик принят"); err != nil {
+		t.Fatalf("AnswerCallback: %v", err)
+	}
+	if r := <-reqs; r.body["callback_query_id"] != "cb1" || r.body["text"] != "Отклик принят" {
+		t.Fatalf("body = %v", r.body)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv, _ := stub(t, map[string]string{
+		"getUpdates":  `{"ok":false,"error_code":409,"description":"Conflict: terminated by other getUpdates request"}`,
+		"sendMessage": `<html>Bad Gateway</html>`,
+	})
+	c := NewHTTPClient(srv.URL, testToken)
+
+	_, err := c.GetUpdates(context.Background(), 0, time.Second)
+	if err == nil || err.Error() != "telegram: getUpdates: Conflict: terminated by other getUpdates request" {
+		t.Fatalf("GetUpdates error = %v", err)
+	}
+	_, err = c.SendMessage(context.Background(), 7, "x", nil)
+	if err == nil || err.Error() != "telegram: sendMessage: http 200" {
+		t.Fatalf("SendMessage error = %v", err)
+	}
+
+	// Ошибка соединения не раскрывает токен из URL
+	srv.Close()
+	_, err = c.SendMessage(context.Background(), 7, "x", nil)
+	if err == nil || strings.Contains(err.Error(), testToken) {
+		t.Fatalf("SendMessage error after close = %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package telemetry
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "unicorn-auth"
+
+// Стандартные методы; остальные попадают в метку как OTHER
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodConnect: true,
+	http.MethodOptions: true, http.MethodTrace: true,
+}
+
+// Middleware замеряет запрос и открывает серверный span. Подключается первым,
+// до gin.Recovery, чтобы паника попала в метрики как 500
+func Middleware() gin.HandlerFunc {
+	tracer := otel.Tracer(tracerName)
+	return func(c *gin.Context) {
+		start := time.Now()
+		// Шаблон, а не путь: /api/vacancies/:id, иначе метка растет без предела
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		// Метод от клиента произвольный — ограничиваем так же, как путь
+		method := c.Request.Method
+		if !knownMethods[method] {
+			method = "OTHER"
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", method),
+				attribute.String("http.route", route),
+			))
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Package telemetry — метрики Prometheus и трейсы OpenTelemetry для HTTP,
+// MongoDB, платежных уведомлений и фоновых задач.
+package telemetry
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "unicorn"
+
+// Registry — метрики сервиса; отдается на /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "http", Name: "request_duration_seconds",
+		Help:    "Время обработки HTTP-запроса по шаблону маршрута и статусу.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "http", Name: "rate_limited_total",
+		Help: "Запросы, отклоненные ограничением частоты.",
+	}, []string{"limiter"})
+
+	mongoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "mongo", Name: "command_duration_seconds",
+		Help:    "Время выполнения команды MongoDB.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"command", "collection", "outcome"})
+
+	mongoSlow = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "mongo", Name: "slow_commands_total",
+		Help: "Команды MongoDB дольше порога MONGO_SLOW_MS.",
+	}, []string{"command", "collection"})
+
+	paymentCallbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "payments", Name: "callbacks_total",
+		Help: "Уведомления платежных провайдеров по результату обработки.",
+	}, []string{"provider", "outcome"})
+
+	jobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "jobs", Name: "runs_total",
+		Help: "Запуски фоновых задач.",
+	}, []string{"job", "outcome"})
+
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "jobs", Name: "duration_seconds",
+		Help:    "Длительность прохода фоновой задачи.",
+		Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+	}, []string{"job"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpDuration, rateLimited, mongoDuration, mongoSlow, paymentCallbacks, jobRuns, jobDuration,
+	)
+}
+
+// Handler отдает метрики; с непустым token требует Authorization: Bearer <token>
+func Handler(token string) gin.HandlerFunc {
+	h := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+	return func(c *gin.Context) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte("Bearer "+token)) != 1 {
+			c.AbortWithStatusJSON(401, gin.H{"ok": false, "error": "unauthorized"})
+			return
+		}
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// RateLimited учитывает запрос, отклоненный ограничителем limiter
+func RateLimited(limiter string) {
+	rateLimited.WithLabelValues(limiter).Inc()
+}
+
+// Исходы обработки уведомления об оплате
+const (
+	PaymentPaid      = "paid"
+	PaymentCancelled = "cancelled"
+	PaymentDuplicate = "duplicate" // повтор по уже обработанному платежу
+	PaymentRejected  = "rejected"  // неверная подпись или формат
+	PaymentNotFound  = "not_found"
+	PaymentError     = "error"
+)
+
+// PaymentCallback учитывает уведомление провайдера и исход его обработки
+func PaymentCallback(provider, outcome string) {
+	paymentCallbacks.WithLabelValues(provider, outcome).Inc()
+}
+
+// Job — один проход фоновой задачи:
+//
+//	job := telemetry.StartJob("lifecycle")
+//	defer job.Done()
+//	...
+//	job.Fail(err)
+type Job struct {
+	name  string
+	start time.Time
+	err   error
+}
+
+// JobObserver получает итог каждого прохода; задается до запуска задач
+type JobObserver func(name string, start time.Time, d time.Duration, err error)
+
+var jobObserver JobObserver
+
+// ObserveJobs подключает наблюдателя за проходами фоновых задач
+func ObserveJobs(fn JobObserver) {
+	jobObserver = fn
+}
+
+func StartJob(name string) *Job {
+	return &Job{name: name, start: time.Now()}
+}
+
+// Fail помечает проход неудачным; запоминается первая ошибка, nil игнорируется
+func (j *Job) Fail(err error) {
+	if err != nil && j.err == nil {
+		j.err = err
+	}
+}
+
+// Err — первая ошибка прохода
+func (j *Job) Err() error {
+	return j.err
+}
+
+// Done записывает результат и длительность прохода
+func (j *Job) Done() {
+	d := time.Since(j.start)
+	outcome := "ok"
+	if j.err != nil {
+		outcome = "error"
+	}
+	jobRuns.WithLabelValues(j.name, outcome).Inc()
+	jobDuration.WithLabelValues(j.name).Observe(d.Seconds())
+	if jobObserver != nil {
+		jobObserver(j.name, j.start, d, j.err)
+	}
+}
@@ -0,0 +1,97 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// mongoMonitor замеряет команды драйвера: гистограмма, счетчик медленных
+// с записью в лог и клиентский span на каждую команду
+type mongoMonitor struct {
+	slow   time.Duration
+	log    *slog.Logger
+	tracer trace.Tracer
+	calls  sync.Map // callKey -> *mongoCall
+}
+
+type callKey struct {
+	conn string
+	id   int64
+}
+
+type mongoCall struct {
+	collection string
+	span       trace.Span
+}
+
+// MongoMonitor — монитор команд для options.Client().SetMonitor;
+// slow — порог записи команды в лог, 0 — не писать
+func MongoMonitor(slow time.Duration, log *slog.Logger) *event.CommandMonitor {
+	m := &mongoMonitor{slow: slow, log: log, tracer: otel.Tracer(tracerName)}
+	return &event.CommandMonitor{
+		Started: m.started,
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			m.finished(e.CommandFinishedEvent, "")
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			m.finished(e.CommandFinishedEvent, e.Failure)
+		},
+	}
+}
+
+func (m *mongoMonitor) started(ctx context.Context, e *event.CommandStartedEvent) {
+	coll := collectionOf(e.Command)
+	_, span := m.tracer.Start(ctx, "mongo "+e.CommandName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "mongodb"),
+			attribute.String("db.name", e.DatabaseName),
+			attribute.String("db.operation", e.CommandName),
+			attribute.String("db.mongodb.collection", coll),
+		))
+	m.calls.Store(callKey{e.ConnectionID, e.RequestID}, &mongoCall{collection: coll, span: span})
+}
+
+func (m *mongoMonitor) finished(e event.CommandFinishedEvent, failure string) {
+	v, ok := m.calls.LoadAndDelete(callKey{e.ConnectionID, e.RequestID})
+	if !ok {
+		return
+	}
+	call := v.(*mongoCall)
+	outcome := "ok"
+	if failure != "" {
+		outcome = "error"
+		call.span.SetStatus(codes.Error, failure)
+	}
+	call.span.End()
+	mongoDuration.WithLabelValues(e.CommandName, call.collection, outcome).Observe(e.Duration.Seconds())
+	if m.slow > 0 && e.Duration >= m.slow {
+		mongoSlow.WithLabelValues(e.CommandName, call.collection).Inc()
+		m.log.Warn("slow mongo command", "command", e.CommandName, "collection", call.collection, "duration_ms", e.Duration.Milliseconds())
+	}
+}
+
+// collectionOf — коллекция команды: значение первого поля (find, insert,
+// aggregate...) или поле collection у getMore
+func collectionOf(cmd bson.Raw) string {
+	el, err := cmd.IndexErr(0)
+	if err != nil {
+		return ""
+	}
+	if s, ok := el.Value().StringValueOK(); ok {
+		return s
+	}
+	if s, ok := cmd.Lookup("collection").StringValueOK(); ok {
+		return s
+	}
+	return ""
+}
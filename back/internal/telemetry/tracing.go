@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// InitTracing настраивает трейсы. Заголовки traceparent и baggage
+// пробрасываются всегда; экспорт по OTLP/HTTP включается только с enabled.
+// Адрес, заголовки и сэмплирование берутся из стандартных переменных
+// OTEL_EXPORTER_OTLP_*, OTEL_TRACES_SAMPLER*, OTEL_SERVICE_NAME.
+// Возвращает функцию, которая дописывает накопленные span'ы при остановке.
+func InitTracing(ctx context.Context, enabled bool) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+	exp, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// OTEL_SERVICE_NAME и OTEL_RESOURCE_ATTRIBUTES перекрывают имя по умолчанию
+	res, err := resource.Merge(
+		resource.NewSchemaless(attribute.String("service.name", tracerName)),
+		resource.Environment(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
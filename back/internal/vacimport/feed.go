@@ -0,0 +1,124 @@
+package vacimport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/safehttp"
+	"unicorn-auth/internal/telemetry"
+)
+
+const (
+	// FeedInterval — как часто опрашивается фид
+	FeedInterval = time.Hour
+	// Размер фида; больше MaxRows вакансий все равно не примется
+	maxFeedBytes = 5 << 20
+	// Сколько строк итога хранится в фиде: ошибки важнее успешных строк
+	keepRows = 50
+)
+
+var ErrFeedTooLarge = errors.New("feed_too_large")
+
+// Poller периодически импортирует XML-фиды компаний
+type Poller struct {
+	feeds    *repo.VacancyFeedRepo
+	importer *Importer
+	client   *http.Client
+	log      *slog.Logger
+}
+
+// NewPoller: client == nil — клиент, который не ходит на внутренние адреса
+func NewPoller(feeds *repo.VacancyFeedRepo, importer *Importer, client *http.Client, log *slog.Logger) *Poller {
+	if client == nil {
+		client = safehttp.NewClient(30*time.Second, 3)
+	}
+	return &Poller{feeds: feeds, importer: importer, client: client, log: log.With("component", "vacimport")}
+}
+
+// RunOnce опрашивает фиды, у которых подошло время
+func (p *Poller) RunOnce(ctx context.Context) error {
+	job := telemetry.StartJob("vacimport")
+	defer job.Done()
+	due, err := p.feeds.ListDue(ctx, time.Now().UTC(), 50)
+	if err != nil {
+		job.Fail(err)
+		p.log.Error("list due feeds", "err", err)
+		return err
+	}
+	for i := range due {
+		f := &due[i]
+		res, err := p.Run(ctx, f, false)
+		lastErr := ""
+		if err != nil {
+			lastErr = err.Error()
+			p.log.Warn("import feed", "company_id", f.CompanyID, "err", err)
+		}
+		if err := p.feeds.MarkRun(ctx, f.CompanyID, trim(res), lastErr, time.Now().UTC().Add(FeedInterval)); err != nil {
+			job.Fail(err)
+			p.log.Error("mark feed", "company_id", f.CompanyID, "err", err)
+		}
+	}
+	return job.Err()
+}
+
+// Run скачивает фид и импортирует его
+func (p *Poller) Run(ctx context.Context, f *models.VacancyFeed, dryRun bool) (*models.ImportResult, error) {
+	rows, err := p.fetch(ctx, f.URL)
+	if err != nil {
+		return nil, err
+	}
+	return p.importer.Apply(ctx, f.CompanyID, f.CreatedBy, rows, dryRun)
+}
+
+func (p *Poller) fetch(ctx context.Context, url string) ([]Row, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/xml, text/xml")
+	req.Header.Set("User-Agent", "Unicorn-Feed/1.0")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch_failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch_failed: http %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetch_failed")
+	}
+	if len(body) > maxFeedBytes {
+		return nil, ErrFeedTooLarge
+	}
+	return Parse(FormatXML, bytes.NewReader(body))
+}
+
+// trim оставляет в сохраняемом итоге только первые строки, ошибочные — в первую очередь
+func trim(res *models.ImportResult) *models.ImportResult {
+	if res == nil || len(res.Rows) <= keepRows {
+		return res
+	}
+	out := *res
+	out.Rows = make([]models.ImportRowResult, 0, keepRows)
+	for _, r := range res.Rows {
+		if r.Action == ActionError && len(out.Rows) < keepRows {
+			out.Rows = append(out.Rows, r)
+		}
+	}
+	for _, r := range res.Rows {
+		if r.Action != ActionError && len(out.Rows) < keepRows {
+			out.Rows = append(out.Rows, r)
+		}
+	}
+	return &out
+}
@@ -0,0 +1,173 @@
+package vacimport
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/taxonomy"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Действия со строкой импорта
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionUnchanged = "unchanged"
+	ActionError     = "error"
+)
+
+var ErrNoOwner = errors.New("owner_not_found")
+
+// Importer создает и обновляет вакансии организации по externalId
+type Importer struct {
+	users    *repo.UserRepo
+	profiles *repo.ProfileRepo
+	vac      *repo.VacancyRepo
+	tax      *taxonomy.Service
+}
+
+func NewImporter(users *repo.UserRepo, profiles *repo.ProfileRepo, vac *repo.VacancyRepo, tax *taxonomy.Service) *Importer {
+	return &Importer{users: users, profiles: profiles, vac: vac, tax: tax}
+}
+
+// Apply применяет строки; dryRun — только проверка, без записи.
+// Новые вакансии сверх лимита тарифа (как в POST /api/vacancies) получают ошибку limit_reached.
+func (im *Importer) Apply(ctx context.Context, orgID, userID string, rows []Row, dryRun bool) (*models.ImportResult, error) {
+	// Лимиты и премиум определяются подпиской владельца организации
+	owner, err := im.users.FindByUserID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, ErrNoOwner
+	}
+	premium := owner.Subscription.Active
+	limit := models.VacancyLimit(premium)
+	active, err := im.vac.CountActiveByCompany(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	verified := false
+	if p, _ := im.profiles.GetByUserID(ctx, orgID); p != nil {
+		verified = p.Verified
+	}
+
+	res := &models.ImportResult{DryRun: dryRun, Total: len(rows), Rows: make([]models.ImportRowResult, 0, len(rows))}
+	seen := map[string]bool{}
+	for _, row := range rows {
+		rr := models.ImportRowResult{Row: row.Line, ExternalID: row.ExternalID}
+		// Теги в справочных названиях, иначе неизменную строку сочтем измененной
+		row.Tags = im.tax.Normalize(ctx, row.Tags)
+		rr.Errors = validate(row)
+		if row.ExternalID != "" {
+			if seen[row.ExternalID] {
+				rr.Errors = append(rr.Errors, "duplicate_external_id")
+			}
+			seen[row.ExternalID] = true
+		}
+		if len(rr.Errors) > 0 {
+			rr.Action = ActionError
+			res.Failed++
+			res.Rows = append(res.Rows, rr)
+			continue
+		}
+
+		existing, err := im.vac.GetByExternalID(ctx, orgID, row.ExternalID)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case existing != nil && same(existing, row):
+			rr.Action, rr.VacancyID = ActionUnchanged, existing.VacancyID
+		case existing != nil:
+			rr.Action, rr.VacancyID = ActionUpdated, existing.VacancyID
+			if !dryRun {
+				err = im.vac.Update(ctx, existing.VacancyID, orgID, bson.M{
+					"title":       row.Title,
+					"description": row.Description,
+					"location":    row.Location,
+					"tags":        row.Tags,
+					"salaryFrom":  row.SalaryFrom,
+					"salaryTo":    row.SalaryTo,
+				})
+			}
+		case active >= limit:
+			rr.Action, rr.Errors = ActionError, []string{"limit_reached"}
+		default:
+			rr.Action = ActionCreated
+			active++
+			if !dryRun {
+				v := &models.Vacancy{
+					CompanyID:       orgID,
+					CreatedBy:       userID,
+					ExternalID:      row.ExternalID,
+					Title:           row.Title,
+					Description:     row.Description,
+					Location:        row.Location,
+					Tags:            row.Tags,
+					SalaryFrom:      row.SalaryFrom,
+					SalaryTo:        row.SalaryTo,
+					IsPremium:       premium,
+					CompanyVerified: verified,
+				}
+				if premium {
+					v.ColorCode = "#FFD700"
+				}
+				err = im.vac.Create(ctx, v)
+				rr.VacancyID = v.VacancyID
+				if mongo.IsDuplicateKeyError(err) {
+					// ту же вакансию параллельно создал другой импорт
+					rr.Action, rr.VacancyID, rr.Errors, err = ActionError, "", []string{"conflict"}, nil
+					active--
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch rr.Action {
+		case ActionCreated:
+			res.Created++
+		case ActionUpdated:
+			res.Updated++
+		case ActionUnchanged:
+			res.Unchanged++
+		default:
+			res.Failed++
+		}
+		res.Rows = append(res.Rows, rr)
+	}
+	return res, nil
+}
+
+// validate — те же правила, что у POST /api/vacancies, плюс обязательный externalId
+func validate(row Row) []string {
+	errs := append([]string(nil), row.errs...)
+	if row.ExternalID == "" {
+		errs = append(errs, "external_id_required")
+	} else if len(row.ExternalID) > 100 {
+		errs = append(errs, "external_id_too_long")
+	}
+	if strings.TrimSpace(row.Title) == "" {
+		errs = append(errs, "title_required")
+	}
+	if strings.TrimSpace(row.Description) == "" {
+		errs = append(errs, "description_required")
+	}
+	if !models.ValidSalary(row.SalaryFrom, row.SalaryTo) {
+		errs = append(errs, "invalid_salary")
+	}
+	return errs
+}
+
+func same(v *models.Vacancy, row Row) bool {
+	return v.Title == row.Title && v.Description == row.Description && v.Location == row.Location &&
+		v.SalaryFrom == row.SalaryFrom && v.SalaryTo == row.SalaryTo &&
+		(len(v.Tags) == 0 && len(row.Tags) == 0 || reflect.DeepEqual(v.Tags, row.Tags))
+}
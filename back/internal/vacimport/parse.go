@@ -0,0 +1,289 @@
+// Package vacimport — массовый импорт вакансий из CSV, JSON и XML (в том числе
+// периодически опрашиваемого фида HR-XML). Импорт идемпотентен по externalId.
+package vacimport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Форматы файлов импорта
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatXML  = "xml"
+)
+
+// MaxRows — сколько вакансий принимается за один импорт
+const MaxRows = 500
+
+var (
+	ErrBadFormat   = errors.New("unsupported_format")
+	ErrParse       = errors.New("parse_error")
+	ErrTooManyRows = errors.New("too_many_rows")
+	ErrEmpty       = errors.New("empty_file")
+)
+
+// Row — вакансия из файла; Line — номер строки CSV или элемента JSON/XML (с 1)
+type Row struct {
+	Line        int      `json:"-"`
+	ExternalID  string   `json:"externalId"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Location    string   `json:"location,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	SalaryFrom  int64    `json:"salaryFrom,omitempty"`
+	SalaryTo    int64    `json:"salaryTo,omitempty"`
+
+	// Ошибки разбора значений (например, зарплата не число)
+	errs []string
+}
+
+// DetectFormat определяет формат по явному параметру, Content-Type или имени файла
+func DetectFormat(explicit, contentType, filename string) string {
+	switch f := strings.ToLower(strings.TrimSpace(explicit)); f {
+	case FormatCSV, FormatJSON, FormatXML:
+		return f
+	}
+	ct := strings.ToLower(contentType)
+	switch {
+	case strings.Contains(ct, "csv"):
+		return FormatCSV
+	case strings.Contains(ct, "json"):
+		return FormatJSON
+	case strings.Contains(ct, "xml"):
+		return FormatXML
+	}
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV
+	case strings.HasSuffix(name, ".json"):
+		return FormatJSON
+	case strings.HasSuffix(name, ".xml"):
+		return FormatXML
+	}
+	return ""
+}
+
+// Parse читает файл целиком; ошибки отдельных значений попадают в строки, а не в err
+func Parse(format string, r io.Reader) ([]Row, error) {
+	var rows []Row
+	var err error
+	switch format {
+	case FormatCSV:
+		rows, err = parseCSV(r)
+	case FormatJSON:
+		rows, err = parseJSON(r)
+	case FormatXML:
+		rows, err = parseXML(r)
+	default:
+		return nil, ErrBadFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmpty
+	}
+	if len(rows) > MaxRows {
+		return nil, ErrTooManyRows
+	}
+	return rows, nil
+}
+
+// CSV: первая строка — заголовок; теги через ";"
+func parseCSV(r io.Reader) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, ErrParse
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		h = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")) // BOM из Excel
+		col[strings.ToLower(h)] = i
+	}
+	if _, ok := col["title"]; !ok {
+		return nil, ErrParse
+	}
+	get := func(rec []string, name string) string {
+		if i, ok := col[strings.ToLower(name)]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var rows []Row
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrParse
+		}
+		if len(rows) >= MaxRows {
+			return nil, ErrTooManyRows
+		}
+		row := Row{
+			Line:        line,
+			ExternalID:  get(rec, "externalId"),
+			Title:       get(rec, "title"),
+			Description: get(rec, "description"),
+			Location:    get(rec, "location"),
+			Tags:        splitTags(get(rec, "tags")),
+		}
+		row.SalaryFrom = row.parseInt("salaryFrom", get(rec, "salaryFrom"))
+		row.SalaryTo = row.parseInt("salaryTo", get(rec, "salaryTo"))
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// JSON: массив объектов или {"vacancies": [...]}
+func parseJSON(r io.Reader) ([]Row, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, ErrParse
+	}
+	var rows []Row
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		var wrapped struct {
+			Vacancies []Row `json:"vacancies"`
+		}
+		if err := json.Unmarshal(raw, &wrapped); err != nil {
+			return nil, ErrParse
+		}
+		rows = wrapped.Vacancies
+	}
+	for i := range rows {
+		r := &rows[i]
+		r.Line = i + 1
+		r.ExternalID = strings.TrimSpace(r.ExternalID)
+		r.Title = strings.TrimSpace(r.Title)
+		r.Description = strings.TrimSpace(r.Description)
+		r.Location = strings.TrimSpace(r.Location)
+		r.Tags = cleanTags(r.Tags)
+	}
+	return rows, nil
+}
+
+// Простой XML: <vacancies><vacancy><externalId/>...</vacancy></vacancies>
+type xmlVacancy struct {
+	ExternalID  string   `xml:"externalId"`
+	Title       string   `xml:"title"`
+	Description string   `xml:"description"`
+	Location    string   `xml:"location"`
+	Tags        []string `xml:"tags>tag"`
+	SalaryFrom  string   `xml:"salaryFrom"`
+	SalaryTo    string   `xml:"salaryTo"`
+}
+
+// Подмножество HR-XML PositionOpening
+type hrxmlOpening struct {
+	ID          string `xml:"PositionRecordInfo>Id>IdValue"`
+	Title       string `xml:"PositionProfile>PositionDetail>PositionTitle"`
+	Location    string `xml:"PositionProfile>PositionDetail>PhysicalLocation>Name"`
+	Description string `xml:"PositionProfile>FormattedPositionDescription>Value"`
+	PayMin      string `xml:"PositionProfile>PositionDetail>RemunerationPackage>BasePay>BasePayAmountMin"`
+	PayMax      string `xml:"PositionProfile>PositionDetail>RemunerationPackage>BasePay>BasePayAmountMax"`
+	Competency  []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"PositionProfile>PositionDetail>Competency"`
+}
+
+// Корень с любым именем: <vacancies>, <PositionOpenings>, ... либо сам <PositionOpening>
+type xmlDoc struct {
+	XMLName   xml.Name
+	Vacancies []xmlVacancy   `xml:"vacancy"`
+	Openings  []hrxmlOpening `xml:"PositionOpening"`
+}
+
+func parseXML(r io.Reader) ([]Row, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, ErrParse
+	}
+	var doc xmlDoc
+	if err := xml.Unmarshal(raw, &doc); err != nil {
+		return nil, ErrParse
+	}
+	if doc.XMLName.Local == "PositionOpening" {
+		var one hrxmlOpening
+		if err := xml.Unmarshal(raw, &one); err != nil {
+			return nil, ErrParse
+		}
+		doc.Openings = []hrxmlOpening{one}
+	}
+
+	var rows []Row
+	for _, v := range doc.Vacancies {
+		row := Row{
+			Line:        len(rows) + 1,
+			ExternalID:  strings.TrimSpace(v.ExternalID),
+			Title:       strings.TrimSpace(v.Title),
+			Description: strings.TrimSpace(v.Description),
+			Location:    strings.TrimSpace(v.Location),
+			Tags:        cleanTags(v.Tags),
+		}
+		row.SalaryFrom = row.parseInt("salaryFrom", v.SalaryFrom)
+		row.SalaryTo = row.parseInt("salaryTo", v.SalaryTo)
+		rows = append(rows, row)
+	}
+	for _, o := range doc.Openings {
+		row := Row{
+			Line:        len(rows) + 1,
+			ExternalID:  strings.TrimSpace(o.ID),
+			Title:       strings.TrimSpace(o.Title),
+			Description: strings.TrimSpace(o.Description),
+			Location:    strings.TrimSpace(o.Location),
+		}
+		for _, c := range o.Competency {
+			row.Tags = append(row.Tags, c.Name)
+		}
+		row.Tags = cleanTags(row.Tags)
+		row.SalaryFrom = row.parseInt("salaryFrom", o.PayMin)
+		row.SalaryTo = row.parseInt("salaryTo", o.PayMax)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (r *Row) parseInt(field, s string) int64 {
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	if s == "" {
+		return 0
+	}
+	// 150000.00 из выгрузок учетных систем
+	if i := strings.IndexByte(s, '.'); i >= 0 && strings.Trim(s[i+1:], "0") == "" {
+		s = s[:i]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		r.errs = append(r.errs, "invalid_"+field)
+		return 0
+	}
+	return n
+}
+
+func splitTags(s string) []string {
+	return cleanTags(strings.Split(s, ";"))
+}
+
+func cleanTags(in []string) []string {
+	var out []string
+	for _, t := range in {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
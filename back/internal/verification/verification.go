@@ -0,0 +1,203 @@
+// Package verification подтверждает владение доменом компании
+// через TXT-запись или файл в /.well-known и выдает значок проверки.
+package verification
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/safehttp"
+)
+
+const (
+	// TXTPrefix — поддомен для TXT-записи: _unicorn-verify.example.com
+	TXTPrefix = "_unicorn-verify"
+	// WellKnownPath — путь файла подтверждения на сайте компании
+	WellKnownPath = "/.well-known/unicorn-verification.txt"
+	// valuePrefix — значение TXT-записи и строка файла: unicorn-verify=<token>
+	valuePrefix = "unicorn-verify="
+)
+
+var (
+	ErrRecordNotFound = errors.New("txt_record_not_found")
+	ErrFileNotFound   = errors.New("file_not_found")
+	ErrTokenMismatch  = errors.New("token_mismatch")
+	ErrLookupFailed   = errors.New("lookup_failed")
+	ErrOffDomain      = errors.New("redirect_off_domain")
+)
+
+// Resolver — DNS-запросы; *net.Resolver подходит без обертки, в тестах подменяется
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+type Checker struct {
+	resolver Resolver
+	client   *http.Client
+}
+
+// NewChecker собирает проверку; nil-аргументы заменяются системным резолвером
+// и HTTP-клиентом, который не ходит на внутренние адреса.
+func NewChecker(resolver Resolver, client *http.Client) *Checker {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	if client == nil {
+		client = safehttp.NewClient(10*time.Second, 2)
+	}
+	return &Checker{resolver: resolver, client: client}
+}
+
+// Value — строка, которую компания должна разместить у себя
+func Value(token string) string { return valuePrefix + token }
+
+// TXTHost — имя, на котором ищется TXT-запись
+func TXTHost(domain string) string { return TXTPrefix + "." + domain }
+
+// FileURL — адрес файла подтверждения
+func FileURL(domain string) string { return "https://" + domain + WellKnownPath }
+
+func NewToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// NormalizeDomain приводит ввод пользователя (URL или домен) к виду example.com
+func NormalizeDomain(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "https://")
+	s = strings.TrimPrefix(s, "http://")
+	if i := strings.IndexAny(s, "/?#"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSuffix(s, ".")
+	s = strings.TrimPrefix(s, "www.")
+	if len(s) < 4 || len(s) > 253 || !strings.Contains(s, ".") || net.ParseIP(s) != nil {
+		return "", false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return "", false
+			}
+		}
+	}
+	return s, true
+}
+
+// CheckDNS ищет TXT-запись с токеном на _unicorn-verify.<domain>, затем на самом домене
+func (ch *Checker) CheckDNS(ctx context.Context, domain, token string) error {
+	want := Value(token)
+	found := false
+	for _, host := range []string{TXTHost(domain), domain} {
+		records, err := ch.resolver.LookupTXT(ctx, host)
+		if err != nil {
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+				continue
+			}
+			return ErrLookupFailed
+		}
+		for _, rec := range records {
+			rec = strings.TrimSpace(rec)
+			if rec == want {
+				return nil
+			}
+			if strings.HasPrefix(rec, valuePrefix) {
+				found = true
+			}
+		}
+	}
+	if found {
+		return ErrTokenMismatch
+	}
+	return ErrRecordNotFound
+}
+
+// CheckFile скачивает https://<domain>/.well-known/unicorn-verification.txt и ищет в нем токен.
+// Редиректы допускаются только в пределах домена и www.<domain>: файл на чужом
+// хосте не подтверждает владение доменом
+func (ch *Checker) CheckFile(ctx context.Context, domain, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, FileURL(domain), nil)
+	if err != nil {
+		return ErrLookupFailed
+	}
+	client := *ch.client
+	client.CheckRedirect = func(r *http.Request, via []*http.Request) error {
+		if host := r.URL.Hostname(); host != domain && host != "www."+domain {
+			return ErrOffDomain
+		}
+		if ch.client.CheckRedirect != nil {
+			return ch.client.CheckRedirect(r, via)
+		}
+		return nil
+	}
+	resp, err := client.Do(req)
+	if errors.Is(err, ErrOffDomain) {
+		return ErrOffDomain
+	}
+	if err != nil {
+		return ErrLookupFailed
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ErrFileNotFound
+	}
+
+	want := Value(token)
+	sc := bufio.NewScanner(io.LimitReader(resp.Body, 4<<10))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == want || line == token {
+			return nil
+		}
+	}
+	return ErrTokenMismatch
+}
+
+// DomainTaken — домен уже подтвержден другой компанией
+func DomainTaken(ctx context.Context, profiles *repo.ProfileRepo, companyID, domain string) (bool, error) {
+	if domain == "" {
+		return false, nil
+	}
+	owner, err := profiles.VerifiedDomainOwner(ctx, domain)
+	if err != nil {
+		return false, err
+	}
+	return owner != "" && owner != companyID, nil
+}
+
+// Grant выдает значок компании и проставляет его во всех ее вакансиях;
+// repo.ErrDomainTaken — домен за это время подтвердила другая компания
+func Grant(ctx context.Context, profiles *repo.ProfileRepo, vac *repo.VacancyRepo, companyID, method, domain string) error {
+	ok, err := profiles.SetVerified(ctx, companyID, true, method, domain)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("verification: company profile %s not found", companyID)
+	}
+	return vac.SetCompanyVerified(ctx, companyID, true)
+}
+
+// Revoke снимает значок
+func Revoke(ctx context.Context, profiles *repo.ProfileRepo, vac *repo.VacancyRepo, companyID string) error {
+	if _, err := profiles.SetVerified(ctx, companyID, false, "", ""); err != nil {
+		return err
+	}
+	return vac.SetCompanyVerified(ctx, companyID, false)
+}
@@ -0,0 +1,141 @@
+package verification
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testToken = "9f2c0a"
+
+// fakeResolver отдает TXT-записи по имени; отсутствующее имя — NXDOMAIN
+type fakeResolver struct {
+	records map[string][]string
+	err     error
+}
+
+func (r fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	recs, ok := r.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return recs, nil
+}
+
+func TestCheckDNS(t *testing.T) {
+	cases := []struct {
+		name string
+		res  fakeResolver
+		want error
+	}{
+		{"subdomain", fakeResolver{records: map[string][]string{
+			"_unicorn-verify.romashka.ru": {"unicorn-verify=" + testToken},
+		}}, nil},
+		{"apex among other records", fakeResolver{records: map[string][]string{
+			"romashka.ru": {"v=spf1 -all", " unicorn-verify=" + testToken + " "},
+		}}, nil},
+		{"mismatch", fakeResolver{records: map[string][]string{
+			"_unicorn-verify.romashka.ru": {"unicorn-verify=other"},
+		}}, ErrTokenMismatch},
+		{"not found", fakeResolver{records: map[string][]string{
+			"romashka.ru": {"v=spf1 -all"},
+		}}, ErrRecordNotFound},
+		{"lookup failed", fakeResolver{err: &net.DNSError{Err: "server misbehaving", IsTemporary: true}}, ErrLookupFailed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ch := NewChecker(tc.res, nil)
+			if err := ch.CheckDNS(context.Background(), "romashka.ru", testToken); !errors.Is(err, tc.want) {
+				t.Fatalf("CheckDNS = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+// fileClient ходит на тестовый HTTPS-сервер, какой бы хост ни был в адресе
+func fileClient(srv *httptest.Server, timeout time.Duration) *http.Client {
+	addr := srv.Listener.Addr().String()
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != WellKnownPath {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Host {
+		case "romashka.ru", "www.hosted.ru":
+			_, _ = w.Write([]byte("# unicorn\nunicorn-verify=" + testToken + "\n"))
+		case "bare.ru":
+			_, _ = w.Write([]byte(testToken))
+		case "other.ru":
+			_, _ = w.Write([]byte("unicorn-verify=other\n"))
+		case "hosted.ru":
+			http.Redirect(w, r, "https://www.hosted.ru"+WellKnownPath, http.StatusMovedPermanently)
+		case "redirect.ru":
+			http.Redirect(w, r, "https://romashka.ru"+WellKnownPath, http.StatusFound)
+		case "slow.ru":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		domain string
+		want   error
+	}{
+		{"romashka.ru", nil},
+		{"bare.ru", nil},
+		{"hosted.ru", nil},
+		{"other.ru", ErrTokenMismatch},
+		{"missing.ru", ErrFileNotFound},
+		{"redirect.ru", ErrOffDomain},
+		{"slow.ru", ErrLookupFailed},
+	}
+	ch := NewChecker(fakeResolver{}, fileClient(srv, 300*time.Millisecond))
+	for _, tc := range cases {
+		t.Run(tc.domain, func(t *testing.T) {
+			if err := ch.CheckFile(context.Background(), tc.domain, testToken); !errors.Is(err, tc.want) {
+				t.Fatalf("CheckFile(%s) = %v, want %v", tc.domain, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	cases := map[string]string{
+		"https://www.Romashka.ru/about?x=1": "romashka.ru",
+		"romashka.ru.":                      "romashka.ru",
+		"127.0.0.1":                         "",
+		"-bad.ru":                           "",
+		"localhost":                         "",
+	}
+	for in, want := range cases {
+		got, ok := NormalizeDomain(in)
+		if got != want || ok != (want != "") {
+			t.Errorf("NormalizeDomain(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package webhooks
+
+import "unicorn-auth/internal/models"
+
+// Данные событий (поле data). Схема описана в docs/webhooks-api-spec.md.
+
+func ApplicationData(a *models.Application, v *models.Vacancy) map[string]any {
+	data := map[string]any{
+		"applicationId": a.ApplicationID,
+		"vacancyId":     a.VacancyID,
+		"resumeId":      a.ResumeID,
+		"candidateId":   a.UserID,
+		"status":        a.Status,
+		"message":       a.Message,
+	}
+	if v != nil {
+		data["vacancyTitle"] = v.Title
+	}
+	if a.SnapshotID != "" {
+		data["snapshotId"] = a.SnapshotID
+	}
+	if a.CoverLetter != "" {
+		data["coverLetter"] = a.CoverLetter
+	}
+	if len(a.Answers) > 0 {
+		data["answers"] = a.Answers
+		data["knockedOut"] = a.KnockedOut
+	}
+	return data
+}
+
+func MessageData(m *models.ChatMessage) map[string]any {
+	return map[string]any{
+		"applicationId": m.ApplicationID,
+		"messageId":     m.MessageID,
+		"senderId":      m.SenderID,
+		"text":          m.Text,
+		"createdAt":     m.CreatedAt,
+	}
+}
+
+func VacancyData(v *models.Vacancy) map[string]any {
+	data := map[string]any{
+		"vacancyId": v.VacancyID,
+		"title":     v.Title,
+		"status":    v.Status,
+	}
+	if v.ExternalID != "" {
+		data["externalId"] = v.ExternalID
+	}
+	if v.ClosedAt != nil {
+		data["closedAt"] = v.ClosedAt
+	}
+	return data
+}
@@ -0,0 +1,252 @@
+// Package webhooks отправляет события платформы на адреса компаний (интеграции с ATS).
+// События сначала записываются в Mongo, затем воркер доставляет их с повторами.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"unicorn-auth/internal/models"
+	"unicorn-auth/internal/repo"
+	"unicorn-auth/internal/safehttp"
+	"unicorn-auth/internal/telemetry"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Типы событий
+const (
+	EventApplicationCreated = "application.created"
+	EventApplicationStatus  = "application.status_changed"
+	EventMessageReceived    = "message.received"
+	EventVacancyClosed      = "vacancy.closed"
+	// EventPing отправляется только вручную, для проверки адреса
+	EventPing = "ping"
+)
+
+// EventTypes — события, на которые можно подписаться
+var EventTypes = []string{EventApplicationCreated, EventApplicationStatus, EventMessageReceived, EventVacancyClosed}
+
+// Заголовки запроса
+const (
+	HeaderEvent     = "X-Unicorn-Event"
+	HeaderDelivery  = "X-Unicorn-Delivery"
+	HeaderTimestamp = "X-Unicorn-Timestamp"
+	HeaderSignature = "X-Unicorn-Signature"
+)
+
+const (
+	// MaxAttempts — после стольких неудачных попыток доставка помечается failed
+	MaxAttempts = 8
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+	// Аренда доставки воркером; после нее зависшая доставка забирается снова
+	lease     = 2 * time.Minute
+	batchSize = 100
+	// Сколько адресов опрашивается одновременно
+	parallel = 8
+)
+
+// Envelope — тело запроса
+type Envelope struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	CompanyID string    `json:"companyId"`
+	Data      any       `json:"data"`
+}
+
+// Dispatcher ставит события в очередь и доставляет их
+type Dispatcher struct {
+	hooks  *repo.WebhookRepo
+	client *http.Client
+	log    *slog.Logger
+}
+
+// NewDispatcher: client == nil — клиент, который не ходит на внутренние адреса
+func NewDispatcher(hooks *repo.WebhookRepo, client *http.Client, log *slog.Logger) *Dispatcher {
+	if client == nil {
+		client = safehttp.NewClient(10*time.Second, 0)
+	}
+	return &Dispatcher{hooks: hooks, client: client, log: log.With("component", "webhooks")}
+}
+
+// Publish ставит событие в очередь всех подписанных вебхуков компании.
+// Ошибки только пишутся в лог: интеграция не должна ломать основной запрос.
+func (d *Dispatcher) Publish(ctx context.Context, companyID, eventType string, data any) {
+	if d == nil || companyID == "" {
+		return
+	}
+	hooks, err := d.hooks.ListSubscribed(ctx, companyID, eventType)
+	if err != nil {
+		d.log.Error("list subscribed webhooks", "company_id", companyID, "err", err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+	env := Envelope{ID: ulid.Make().String(), Type: eventType, CreatedAt: time.Now().UTC(), CompanyID: companyID, Data: data}
+	body, err := json.Marshal(env)
+	if err != nil {
+		d.log.Error("marshal event", "event", eventType, "err", err)
+		return
+	}
+	for _, w := range hooks {
+		del := &models.WebhookDelivery{WebhookID: w.WebhookID, CompanyID: companyID, EventID: env.ID, EventType: eventType, Payload: string(body)}
+		if err := d.hooks.CreateDelivery(ctx, del); err != nil {
+			d.log.Error("enqueue delivery", "event", eventType, "webhook_id", w.WebhookID, "err", err)
+		}
+	}
+}
+
+// Ping ставит в очередь тестовое событие для одного вебхука
+func (d *Dispatcher) Ping(ctx context.Context, w *models.Webhook) (*models.WebhookDelivery, error) {
+	env := Envelope{ID: ulid.Make().String(), Type: EventPing, CreatedAt: time.Now().UTC(), CompanyID: w.CompanyID,
+		Data: map[string]any{"webhookId": w.WebhookID}}
+	body, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+	del := &models.WebhookDelivery{WebhookID: w.WebhookID, CompanyID: w.CompanyID, EventID: env.ID, EventType: EventPing, Payload: string(body)}
+	if err := d.hooks.CreateDelivery(ctx, del); err != nil {
+		return nil, err
+	}
+	return del, nil
+}
+
+// Replay отправляет событие повторно новой доставкой с тем же телом и eventId,
+// чтобы получатель мог отбросить дубль
+func (d *Dispatcher) Replay(ctx context.Context, orig *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	del := &models.WebhookDelivery{
+		WebhookID: orig.WebhookID,
+		CompanyID: orig.CompanyID,
+		EventID:   orig.EventID,
+		EventType: orig.EventType,
+		Payload:   orig.Payload,
+		ReplayOf:  orig.DeliveryID,
+	}
+	if err := d.hooks.CreateDelivery(ctx, del); err != nil {
+		return nil, err
+	}
+	return del, nil
+}
+
+// RunOnce отправляет все доставки, время которых пришло
+func (d *Dispatcher) RunOnce(ctx context.Context) error {
+	job := telemetry.StartJob("webhooks")
+	defer job.Done()
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i := 0; i < batchSize && ctx.Err() == nil; i++ {
+		del, err := d.hooks.ClaimDue(ctx, time.Now().UTC(), lease)
+		if err != nil {
+			job.Fail(err)
+			d.log.Error("claim due deliveries", "err", err)
+			break
+		}
+		if del == nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.deliver(ctx, del)
+		}()
+	}
+	wg.Wait()
+	return job.Err()
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, del *models.WebhookDelivery) {
+	w, err := d.hooks.GetByID(ctx, del.WebhookID, del.CompanyID)
+	if err != nil {
+		d.log.Error("load webhook", "webhook_id", del.WebhookID, "err", err)
+		return // доставка вернется в работу после истечения аренды
+	}
+	if w == nil || !w.Active {
+		if err := d.hooks.Fail(ctx, del.DeliveryID); err != nil {
+			d.log.Error("fail delivery", "delivery_id", del.DeliveryID, "err", err)
+		}
+		return
+	}
+
+	started := time.Now().UTC()
+	code, sendErr := d.send(ctx, w, del)
+	a := models.WebhookAttempt{At: time.Now().UTC(), StatusCode: code, DurationMs: time.Since(started).Milliseconds()}
+	status, next := models.WebhookDelivered, a.At
+	if sendErr != nil {
+		a.Error = sendErr.Error()
+		status, next = models.WebhookPending, a.At.Add(Backoff(del.Attempts+1))
+		if del.Attempts+1 >= MaxAttempts {
+			status = models.WebhookFailed
+		}
+	}
+	if err := d.hooks.FinishAttempt(ctx, del.DeliveryID, a, status, next); err != nil {
+		d.log.Error("save attempt", "delivery_id", del.DeliveryID, "err", err)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, w *models.Webhook, del *models.WebhookDelivery) (int, error) {
+	body := []byte(del.Payload)
+	ts := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Unicorn-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, del.EventType)
+	req.Header.Set(HeaderDelivery, del.DeliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, "sha256="+Sign(w.Secret, ts, body))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, fmt.Errorf("http %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign — HMAC-SHA256 от "<timestamp>.<тело>" в hex.
+// Метка времени входит в подпись, чтобы перехваченный запрос нельзя было повторить позже.
+func Sign(secret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff — пауза перед попыткой attempt+1: 30с, 1м, 2м, ... не больше 6ч
+func Backoff(attempt int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
+
+func NewSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}